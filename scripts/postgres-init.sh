@@ -57,12 +57,14 @@ create table if not exists resources (
     name                    varchar(200)                    not null,
     type                    smallint                        not null,
     options                 jsonb,
+    environments            jsonb,
     created_at              timestamp                       not null,
     created_by              bigint                          not null,
     updated_at              timestamp                       not null,
     updated_by              bigint                          not null
 );
 
+alter table resources add column if not exists environments jsonb;
 alter table resources owner to illa_builder;
 
 -- actions
@@ -170,4 +172,115 @@ ADD CONSTRAINT set_states_displayname_constrainte UNIQUE (version, app_ref_id, v
 
 alter table set_states owner to illa_builder;
 
+-- action_schedules
+create table if not exists action_schedules (
+    id                      bigserial                       not null primary key,
+    uid                     uuid default gen_random_uuid()  not null,
+    team_id                 bigserial                       not null,
+    app_ref_id              bigint                          not null,
+    action_name             varchar(255)                    not null,
+    schedule                varchar(255)                    not null,
+    enabled                 boolean                         not null,
+    push_to_room            boolean                         not null,
+    next_run_at             timestamp                       not null,
+    last_run_at             timestamp,
+    last_status             varchar(16),
+    last_result             jsonb,
+    last_error              text,
+    created_at              timestamp                       not null,
+    created_by              bigint                          not null,
+    updated_at              timestamp                       not null,
+    updated_by              bigint                          not null
+);
+
+create index if not exists action_schedules_team_id_and_app_ref_id on action_schedules (team_id, app_ref_id);
+create index if not exists action_schedules_enabled_and_next_run_at on action_schedules (enabled, next_run_at);
+
+alter table action_schedules owner to illa_builder;
+
+-- action_schedule_runs
+create table if not exists action_schedule_runs (
+    id                      bigserial                       not null primary key,
+    team_id                 bigserial                       not null,
+    app_ref_id              bigint                          not null,
+    schedule_ref_id         bigint                          not null,
+    action_ref_id           bigint                          not null,
+    version                 bigint                          not null,
+    status                  varchar(16)                     not null,
+    result                  jsonb,
+    error                   text,
+    started_at              timestamp                       not null,
+    duration                bigint                          not null
+);
+
+create index if not exists action_schedule_runs_schedule_ref_id on action_schedule_runs (schedule_ref_id);
+
+alter table action_schedule_runs owner to illa_builder;
+
+-- action_run_logs
+create table if not exists action_run_logs (
+    id                      bigserial                       not null primary key,
+    team_id                 bigserial                       not null,
+    kind                    varchar(16)                     not null,
+    source                  varchar(16)                     not null,
+    app_ref_id              bigint                          not null,
+    workflow_ref_id         bigint                          not null,
+    action_ref_id           bigint                          not null,
+    action_name             varchar(255)                    not null,
+    action_type             smallint                        not null,
+    version                 bigint                          not null,
+    resource_ref_id         bigint                          not null,
+    status                  varchar(16)                     not null,
+    row_count               bigint                          not null,
+    parameters              jsonb,
+    error                   text,
+    executed_by             bigint                          not null,
+    started_at              timestamp                       not null,
+    duration                bigint                          not null
+);
+
+create index if not exists action_run_logs_team_id_started_at on action_run_logs (team_id, started_at);
+create index if not exists action_run_logs_team_id_app_ref_id on action_run_logs (team_id, app_ref_id);
+create index if not exists action_run_logs_team_id_workflow_ref_id on action_run_logs (team_id, workflow_ref_id);
+
+alter table action_run_logs owner to illa_builder;
+
+-- team_variables
+create table if not exists team_variables (
+    id                      bigserial                       not null primary key,
+    uid                     uuid default gen_random_uuid()  not null,
+    team_id                 bigserial                       not null,
+    name                    varchar(128)                    not null,
+    value                   text                            not null,
+    is_secret               boolean                         not null,
+    description             varchar(255)                    not null,
+    created_at              timestamp                       not null,
+    created_by              bigint                          not null,
+    updated_at              timestamp                       not null,
+    updated_by              bigint                          not null
+);
+
+create unique index if not exists team_variables_team_id_name_is_secret on team_variables (team_id, name, is_secret);
+
+alter table team_variables owner to illa_builder;
+
+-- flow_action_webhooks
+create table if not exists flow_action_webhooks (
+    id                      bigserial                       not null primary key,
+    uid                     uuid default gen_random_uuid()  not null,
+    team_id                 bigserial                       not null,
+    workflow_id             bigint                          not null,
+    trigger_name            varchar(255)                    not null,
+    secret                  text                            not null,
+    created_at              timestamp                       not null,
+    created_by              bigint                          not null,
+    updated_at              timestamp                       not null,
+    updated_by              bigint                          not null
+);
+
+create unique index if not exists flow_action_webhooks_team_id_workflow_id_trigger_name on flow_action_webhooks (team_id, workflow_id, trigger_name);
+create unique index if not exists flow_action_webhooks_uid on flow_action_webhooks (uid);
+
+alter table flow_action_webhooks owner to illa_builder;
+
 EOF
@@ -15,6 +15,7 @@
 package aiagent
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -32,7 +33,6 @@ func (r *AIAgentConnector) ValidateResourceOptions(resourceOptions map[string]in
 }
 
 func (r *AIAgentConnector) ValidateActionTemplate(actionOptions map[string]interface{}) (common.ValidateResult, error) {
-	fmt.Printf("[DUMP] actionOptions: %+v \n", actionOptions)
 	_, errorInNewRequest := resourcemanager.NewRunAIAgentRequest(actionOptions)
 	if errorInNewRequest != nil {
 		return common.ValidateResult{Valid: false}, errorInNewRequest
@@ -42,16 +42,16 @@ func (r *AIAgentConnector) ValidateActionTemplate(actionOptions map[string]inter
 }
 
 // AI Agent have no test connection method
-func (r *AIAgentConnector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (r *AIAgentConnector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	return common.ConnectionResult{Success: false}, errors.New("unsupported type: AI Agent")
 }
 
 // AI Agent have no meta info
-func (r *AIAgentConnector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (r *AIAgentConnector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 	return common.MetaInfoResult{Success: false}, errors.New("unsupported type: AI Agent")
 }
 
-func (r *AIAgentConnector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+func (r *AIAgentConnector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
 	res := common.RuntimeResult{
 		Success: false,
 		Rows:    []map[string]interface{}{},
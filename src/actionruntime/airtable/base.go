@@ -15,6 +15,7 @@
 package airtable
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -27,7 +28,7 @@ import (
 	"github.com/mitchellh/mapstructure"
 )
 
-func (a *Connector) ListRecords() (common.RuntimeResult, error) {
+func (a *Connector) ListRecords(ctx context.Context) (common.RuntimeResult, error) {
 	// format `list` method config
 	var listConfig ListConfig
 	if err := mapstructure.Decode(a.Action.Config, &listConfig); err != nil {
@@ -89,7 +90,7 @@ func (a *Connector) ListRecords() (common.RuntimeResult, error) {
 
 	// call `List Records` method
 	restyClient := resty.New()
-	listReq := restyClient.R().SetHeader("Content-Type", "application/json")
+	listReq := restyClient.R().SetContext(ctx).SetHeader("Content-Type", "application/json")
 	if a.Resource.AuthenticationType == API_KEY_AUTHENTICATION {
 		listReq.SetAuthToken(a.Resource.AuthenticationConfig[API_KEY_AUTHENTICATION])
 	} else if a.Resource.AuthenticationType == PERSONAL_TOKEN_AUTHENTICATION {
@@ -122,7 +123,7 @@ func (a *Connector) ListRecords() (common.RuntimeResult, error) {
 	return common.RuntimeResult{Success: true, Rows: []map[string]interface{}{respMap}}, nil
 }
 
-func (a *Connector) GetRecord() (common.RuntimeResult, error) {
+func (a *Connector) GetRecord(ctx context.Context) (common.RuntimeResult, error) {
 	// format `get` method config
 	var getConfig GetConfig
 	if err := mapstructure.Decode(a.Action.Config, &getConfig); err != nil {
@@ -137,7 +138,7 @@ func (a *Connector) GetRecord() (common.RuntimeResult, error) {
 
 	// call `Get Record` method
 	restyClient := resty.New()
-	getReq := restyClient.R().SetHeader("Content-Type", "application/json")
+	getReq := restyClient.R().SetContext(ctx).SetHeader("Content-Type", "application/json")
 	if a.Resource.AuthenticationType == API_KEY_AUTHENTICATION {
 		getReq.SetAuthToken(a.Resource.AuthenticationConfig[API_KEY_AUTHENTICATION])
 	} else if a.Resource.AuthenticationType == PERSONAL_TOKEN_AUTHENTICATION {
@@ -170,7 +171,7 @@ func (a *Connector) GetRecord() (common.RuntimeResult, error) {
 	return common.RuntimeResult{Success: true, Rows: []map[string]interface{}{respMap}}, nil
 }
 
-func (a *Connector) CreateRecords() (common.RuntimeResult, error) {
+func (a *Connector) CreateRecords(ctx context.Context) (common.RuntimeResult, error) {
 	// format `create` method config
 	var createConfig CreateConfig
 	if err := mapstructure.Decode(a.Action.Config, &createConfig); err != nil {
@@ -197,7 +198,7 @@ func (a *Connector) CreateRecords() (common.RuntimeResult, error) {
 
 	// call `Create Records` method
 	restyClient := resty.New()
-	createReq := restyClient.R().SetHeader("Content-Type", "application/json")
+	createReq := restyClient.R().SetContext(ctx).SetHeader("Content-Type", "application/json")
 	if a.Resource.AuthenticationType == API_KEY_AUTHENTICATION {
 		createReq.SetAuthToken(a.Resource.AuthenticationConfig[API_KEY_AUTHENTICATION])
 	} else if a.Resource.AuthenticationType == PERSONAL_TOKEN_AUTHENTICATION {
@@ -230,7 +231,7 @@ func (a *Connector) CreateRecords() (common.RuntimeResult, error) {
 	return common.RuntimeResult{Success: true, Rows: []map[string]interface{}{respMap}}, nil
 }
 
-func (a *Connector) UpdateMultipleRecords() (common.RuntimeResult, error) {
+func (a *Connector) UpdateMultipleRecords(ctx context.Context) (common.RuntimeResult, error) {
 	// format `bulkUpdate` method config
 	var bulkUpdateConfig BulkUpdateConfig
 	if err := mapstructure.Decode(a.Action.Config, &bulkUpdateConfig); err != nil {
@@ -249,7 +250,7 @@ func (a *Connector) UpdateMultipleRecords() (common.RuntimeResult, error) {
 
 	// call `Update Multiple Records` method
 	restyClient := resty.New()
-	bulkUpdateReq := restyClient.R().SetHeader("Content-Type", "application/json")
+	bulkUpdateReq := restyClient.R().SetContext(ctx).SetHeader("Content-Type", "application/json")
 	if a.Resource.AuthenticationType == API_KEY_AUTHENTICATION {
 		bulkUpdateReq.SetAuthToken(a.Resource.AuthenticationConfig[API_KEY_AUTHENTICATION])
 	} else if a.Resource.AuthenticationType == PERSONAL_TOKEN_AUTHENTICATION {
@@ -282,7 +283,7 @@ func (a *Connector) UpdateMultipleRecords() (common.RuntimeResult, error) {
 	return common.RuntimeResult{Success: true, Rows: []map[string]interface{}{respMap}}, nil
 }
 
-func (a *Connector) UpdateRecord() (common.RuntimeResult, error) {
+func (a *Connector) UpdateRecord(ctx context.Context) (common.RuntimeResult, error) {
 	// format `update` method config
 	var updateConfig UpdateConfig
 	if err := mapstructure.Decode(a.Action.Config, &updateConfig); err != nil {
@@ -301,7 +302,7 @@ func (a *Connector) UpdateRecord() (common.RuntimeResult, error) {
 
 	// call `Update Multiple Records` method
 	restyClient := resty.New()
-	updateReq := restyClient.R().SetHeader("Content-Type", "application/json")
+	updateReq := restyClient.R().SetContext(ctx).SetHeader("Content-Type", "application/json")
 	if a.Resource.AuthenticationType == API_KEY_AUTHENTICATION {
 		updateReq.SetAuthToken(a.Resource.AuthenticationConfig[API_KEY_AUTHENTICATION])
 	} else if a.Resource.AuthenticationType == PERSONAL_TOKEN_AUTHENTICATION {
@@ -334,7 +335,7 @@ func (a *Connector) UpdateRecord() (common.RuntimeResult, error) {
 	return common.RuntimeResult{Success: true, Rows: []map[string]interface{}{respMap}}, nil
 }
 
-func (a *Connector) DeleteMultipleRecords() (common.RuntimeResult, error) {
+func (a *Connector) DeleteMultipleRecords(ctx context.Context) (common.RuntimeResult, error) {
 	// format `bulkDelete` method config
 	var bulkDeleteConfig BulkDeleteConfig
 	if err := mapstructure.Decode(a.Action.Config, &bulkDeleteConfig); err != nil {
@@ -354,7 +355,7 @@ func (a *Connector) DeleteMultipleRecords() (common.RuntimeResult, error) {
 	}
 	deleteIdsQueryParams := "?" + strings.Join(deleteIds, "&")
 	restyClient := resty.New()
-	deleteReq := restyClient.R().SetHeader("Content-Type", "application/json")
+	deleteReq := restyClient.R().SetContext(ctx).SetHeader("Content-Type", "application/json")
 	if a.Resource.AuthenticationType == API_KEY_AUTHENTICATION {
 		deleteReq.SetAuthToken(a.Resource.AuthenticationConfig[API_KEY_AUTHENTICATION])
 	} else if a.Resource.AuthenticationType == PERSONAL_TOKEN_AUTHENTICATION {
@@ -387,7 +388,7 @@ func (a *Connector) DeleteMultipleRecords() (common.RuntimeResult, error) {
 	return common.RuntimeResult{Success: true, Rows: []map[string]interface{}{respMap}}, nil
 }
 
-func (a *Connector) DeleteRecord() (common.RuntimeResult, error) {
+func (a *Connector) DeleteRecord(ctx context.Context) (common.RuntimeResult, error) {
 	// format `delete` method config
 	var deleteConfig DeleteConfig
 	if err := mapstructure.Decode(a.Action.Config, &deleteConfig); err != nil {
@@ -402,7 +403,7 @@ func (a *Connector) DeleteRecord() (common.RuntimeResult, error) {
 
 	// call `Delete Record` method
 	restyClient := resty.New()
-	deleteReq := restyClient.R().SetHeader("Content-Type", "application/json")
+	deleteReq := restyClient.R().SetContext(ctx).SetHeader("Content-Type", "application/json")
 	if a.Resource.AuthenticationType == API_KEY_AUTHENTICATION {
 		deleteReq.SetAuthToken(a.Resource.AuthenticationConfig[API_KEY_AUTHENTICATION])
 	} else if a.Resource.AuthenticationType == PERSONAL_TOKEN_AUTHENTICATION {
@@ -15,6 +15,7 @@
 package airtable
 
 import (
+	"context"
 	"errors"
 
 	"github.com/go-playground/validator/v10"
@@ -69,15 +70,15 @@ func (a *Connector) ValidateActionTemplate(actionOptions map[string]interface{})
 	return common.ValidateResult{Valid: true}, nil
 }
 
-func (a *Connector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (a *Connector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	return common.ConnectionResult{Success: true}, nil
 }
 
-func (a *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (a *Connector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 	return common.MetaInfoResult{Success: true}, nil
 }
 
-func (a *Connector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+func (a *Connector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
 	// format resource options
 	if err := mapstructure.Decode(resourceOptions, &a.Resource); err != nil {
 		return common.RuntimeResult{Success: false}, err
@@ -93,19 +94,19 @@ func (a *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 	var errRun error
 	switch a.Action.Method {
 	case LIST_METHOD:
-		result, errRun = a.ListRecords()
+		result, errRun = a.ListRecords(ctx)
 	case GET_METHOD:
-		result, errRun = a.GetRecord()
+		result, errRun = a.GetRecord(ctx)
 	case CREATE_METHOD:
-		result, errRun = a.CreateRecords()
+		result, errRun = a.CreateRecords(ctx)
 	case BULKUPDATE_METHOD:
-		result, errRun = a.UpdateMultipleRecords()
+		result, errRun = a.UpdateMultipleRecords(ctx)
 	case UPDATE_METHOD:
-		result, errRun = a.UpdateRecord()
+		result, errRun = a.UpdateRecord(ctx)
 	case BULKDELETE_METHOD:
-		result, errRun = a.DeleteMultipleRecords()
+		result, errRun = a.DeleteMultipleRecords(ctx)
 	case DELETE_METHOD:
-		result, errRun = a.DeleteRecord()
+		result, errRun = a.DeleteRecord(ctx)
 	default:
 		errRun = errors.New("invalid action method")
 	}
@@ -15,6 +15,7 @@
 package appwrite
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 
@@ -58,7 +59,7 @@ func (a *Connector) ValidateActionTemplate(actionOptions map[string]interface{})
 	return common.ValidateResult{Valid: true}, nil
 }
 
-func (a *Connector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (a *Connector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	// get appwrite database client
 	db, err := a.getClientWithOpts(resourceOptions)
 	if err != nil {
@@ -77,7 +78,7 @@ func (a *Connector) TestConnection(resourceOptions map[string]interface{}) (comm
 	return common.ConnectionResult{Success: true}, nil
 }
 
-func (a *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (a *Connector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 	// get appwrite database client
 	db, err := a.getClientWithOpts(resourceOptions)
 	if err != nil {
@@ -130,7 +131,7 @@ func (a *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.
 	}, nil
 }
 
-func (a *Connector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+func (a *Connector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
 	// get appwrite database client
 	db, err := a.getClientWithOpts(resourceOptions)
 	if err != nil {
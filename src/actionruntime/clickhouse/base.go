@@ -15,20 +15,23 @@
 package clickhouse
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"database/sql"
 	"encoding/pem"
 	"errors"
-	"fmt"
+	"net"
+	"regexp"
+	"strconv"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
 	"github.com/mitchellh/mapstructure"
 )
 
 const (
-	tableSQLStr  = "SHOW TABLES"
-	columnSQLStr = "DESCRIBE TABLE "
+	CONNECTION_POOL_TYPE = "clickhouse"
 )
 
 func (c *Connector) getConnectionWithOptions(resourceOptions map[string]interface{}) (*sql.DB, error) {
@@ -37,7 +40,7 @@ func (c *Connector) getConnectionWithOptions(resourceOptions map[string]interfac
 	}
 
 	opts := clickhouse.Options{
-		Addr: []string{fmt.Sprintf("%s:%d", c.ResourceOpts.Host, c.ResourceOpts.Port)},
+		Addr: []string{net.JoinHostPort(c.ResourceOpts.Host, strconv.Itoa(c.ResourceOpts.Port))},
 		Auth: clickhouse.Auth{
 			Database: c.ResourceOpts.DatabaseName,
 			Username: c.ResourceOpts.Username,
@@ -46,11 +49,11 @@ func (c *Connector) getConnectionWithOptions(resourceOptions map[string]interfac
 	}
 
 	if c.ResourceOpts.SSL.SSL {
-		t := &tls.Config{InsecureSkipVerify: false}
+		t := &tls.Config{InsecureSkipVerify: false, ServerName: c.ResourceOpts.Host}
 		opts.TLS = t
 	}
 	if c.ResourceOpts.SSL.SSL && c.ResourceOpts.SSL.SelfSigned {
-		t := &tls.Config{}
+		t := &tls.Config{ServerName: c.ResourceOpts.Host}
 		pool := x509.NewCertPool()
 		if ok := pool.AppendCertsFromPEM([]byte(c.ResourceOpts.SSL.CACert)); !ok {
 			return nil, errors.New("clickhouse SSL/TLS Connection failed")
@@ -68,49 +71,79 @@ func (c *Connector) getConnectionWithOptions(resourceOptions map[string]interfac
 		opts.TLS = t
 	}
 
+	// dial through the bastion host when the ssh tunnel enabled
+	dial, err := common.NewSSHTunnelDialFunc(c.ResourceOpts.SSHTunnel)
+	if err != nil {
+		return nil, err
+	}
+	if dial != nil {
+		opts.DialContext = newSSHTunnelDialContext(dial, opts.TLS)
+	}
 	db := clickhouse.OpenDB(&opts)
 
 	return db, nil
 }
 
-func tablesInfo(db *sql.DB, dbName string) []string {
-	tableNames := make([]string, 0, 0)
-	tableRows, err := db.Query(tableSQLStr, dbName)
-	if err != nil {
-		return nil
-	}
-	for tableRows.Next() {
-		var tableName string
-		err = tableRows.Scan(&tableName)
-		if err != nil {
-			return nil
+// newSSHTunnelDialContext return the dial context through ssh tunnel, the driver skips its own tls when the dial context set,
+// so the tls handshake is done here.
+func newSSHTunnelDialContext(dial common.SSHTunnelDialFunc, tlsConfig *tls.Config) func(ctx context.Context, addr string) (net.Conn, error) {
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, "tcp", addr)
+		if err != nil || tlsConfig == nil {
+			return conn, err
+		}
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
 		}
+		return tlsConn, nil
+	}
+}
 
-		tableNames = append(tableNames, tableName)
+// getPooledConnectionWithOptions return the pooled *sql.DB for resource, call release after the query finished.
+func (c *Connector) getPooledConnectionWithOptions(ctx context.Context, resourceOptions map[string]interface{}) (*sql.DB, func(), error) {
+	if err := mapstructure.Decode(resourceOptions, &c.ResourceOpts); err != nil {
+		return nil, nil, err
 	}
+	return common.GetConnectionPoolManager().AcquireSQLDB(ctx, CONNECTION_POOL_TYPE, resourceOptions, func() (*sql.DB, error) {
+		return c.getConnectionWithOptions(resourceOptions)
+	})
+}
 
-	return tableNames
+// the clickhouse error codes, clickhouse returns no SQLSTATE
+var clickhouseErrorClasses = map[int32]string{
+	62:  common.QUERY_ERROR_CLASS_SYNTAX,     // SYNTAX_ERROR
+	47:  common.QUERY_ERROR_CLASS_NOT_FOUND,  // UNKNOWN_IDENTIFIER
+	60:  common.QUERY_ERROR_CLASS_NOT_FOUND,  // UNKNOWN_TABLE
+	81:  common.QUERY_ERROR_CLASS_NOT_FOUND,  // UNKNOWN_DATABASE
+	46:  common.QUERY_ERROR_CLASS_NOT_FOUND,  // UNKNOWN_FUNCTION
+	497: common.QUERY_ERROR_CLASS_PERMISSION, // ACCESS_DENIED
+	516: common.QUERY_ERROR_CLASS_PERMISSION, // AUTHENTICATION_FAILED
+	6:   common.QUERY_ERROR_CLASS_DATA,       // CANNOT_PARSE_TEXT
+	27:  common.QUERY_ERROR_CLASS_DATA,       // CANNOT_PARSE_INPUT_ASSERTION_FAILED
+	53:  common.QUERY_ERROR_CLASS_DATA,       // TYPE_MISMATCH
+	159: common.QUERY_ERROR_CLASS_TIMEOUT,    // TIMEOUT_EXCEEDED
+	394: common.QUERY_ERROR_CLASS_TIMEOUT,    // QUERY_WAS_CANCELLED
 }
 
-func fieldsInfo(db *sql.DB, dbName string, tableNames []string) map[string]interface{} {
-	columns := make(map[string]interface{})
-	for _, tableName := range tableNames {
-		tmpSQLStr := columnSQLStr + tableName
-		columnRows, err := db.Query(tmpSQLStr)
-		if err != nil {
-			return nil
-		}
-		tables := make(map[string]interface{})
-		for columnRows.Next() {
-			var Name, Type, defaultType, defaultExpression, ttlExpression, comment, codecExpression string
-			err = columnRows.Scan(&Name, &Type, &defaultType, &defaultExpression, &ttlExpression, &comment, &codecExpression)
-			if err != nil {
-				return nil
-			}
-			tables[Name] = map[string]string{"data_type": Type}
+var clickhouseErrorLocationPattern = regexp.MustCompile(`\(line (\d+), col (\d+)\)`)
 
-		}
-		columns[tableName] = tables
+// convertQueryError convert the clickhouse server exception to common.QueryError, the other errors are returned as is.
+func convertQueryError(err error) error {
+	var exception *clickhouse.Exception
+	if !errors.As(err, &exception) {
+		return err
+	}
+	class, hit := clickhouseErrorClasses[exception.Code]
+	if !hit {
+		class = common.QUERY_ERROR_CLASS_UNKNOWN
+	}
+	queryError := common.NewQueryError(err, class, strconv.Itoa(int(exception.Code)), "", exception.Message)
+	if matched := clickhouseErrorLocationPattern.FindStringSubmatch(exception.Message); len(matched) == 3 {
+		lineNumber, _ := strconv.Atoi(matched[1])
+		position, _ := strconv.Atoi(matched[2])
+		queryError.SetLocation(lineNumber, position)
 	}
-	return columns
+	return queryError
 }
@@ -0,0 +1,99 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+)
+
+// the tables in the limit, the columns are fetched for these tables only
+const schemaMetaLimitedTablesSQL = `SELECT name FROM system.tables
+WHERE database = currentDatabase() AND is_temporary = 0
+ORDER BY name LIMIT ?`
+
+// the tables of the connected database, clickhouse has no foreign key, and the data skipping indexes are not listed
+const (
+	schemaMetaTablesSQL = `SELECT database, name, engine FROM system.tables
+WHERE database = currentDatabase() AND is_temporary = 0
+ORDER BY name LIMIT ?`
+	schemaMetaColumnsSQL = `SELECT database, table, name, type, default_kind, default_expression, is_in_primary_key FROM system.columns
+WHERE database = currentDatabase() AND table IN (` + schemaMetaLimitedTablesSQL + `)
+ORDER BY table, position`
+)
+
+var schemaMetaTableKinds = map[string]string{
+	"View":             common.TABLE_KIND_VIEW,
+	"MaterializedView": common.TABLE_KIND_MATERIALIZED_VIEW,
+}
+
+// schemaMeta fetch the tables, views and columns of the connected database from the system tables.
+// the primary key columns are in the column order, since the system.columns only marks them.
+func schemaMeta(ctx context.Context, db *sql.DB) (*common.SchemaMeta, error) {
+	builder := common.NewSchemaMetaBuilder()
+
+	// tables, fetch one more table for detecting the truncation
+	tableRows, err := db.QueryContext(ctx, schemaMetaTablesSQL, common.SCHEMA_META_MAX_TABLES+1)
+	if err != nil {
+		return nil, err
+	}
+	defer tableRows.Close()
+	for tableRows.Next() {
+		var schemaName, tableName, engine string
+		if err := tableRows.Scan(&schemaName, &tableName, &engine); err != nil {
+			return nil, err
+		}
+		kind, hit := schemaMetaTableKinds[engine]
+		if !hit {
+			kind = common.TABLE_KIND_TABLE
+		}
+		builder.AddTable(schemaName, tableName, kind)
+	}
+	if err := tableRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// columns and primary keys
+	columnRows, err := db.QueryContext(ctx, schemaMetaColumnsSQL, common.SCHEMA_META_MAX_TABLES)
+	if err != nil {
+		return nil, err
+	}
+	defer columnRows.Close()
+	for columnRows.Next() {
+		var schemaName, tableName, defaultKind, defaultExpression string
+		var inPrimaryKey uint8
+		column := &common.ColumnMeta{}
+		if err := columnRows.Scan(&schemaName, &tableName, &column.Name, &column.DataType, &defaultKind, &defaultExpression, &inPrimaryKey); err != nil {
+			return nil, err
+		}
+		column.Nullable = strings.HasPrefix(column.DataType, "Nullable(")
+		// the MATERIALIZED, EPHEMERAL and ALIAS expressions are not the default value of insert
+		if defaultKind == "DEFAULT" {
+			column.Default = &defaultExpression
+		}
+		builder.AddColumn(schemaName, tableName, column)
+		if inPrimaryKey == 1 {
+			builder.AddPrimaryKeyColumn(schemaName, tableName, column.Name)
+		}
+	}
+	if err := columnRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return builder.Build(), nil
+}
@@ -15,6 +15,7 @@
 package clickhouse
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -61,7 +62,7 @@ func (c *Connector) ValidateActionTemplate(actionOptions map[string]interface{})
 	return common.ValidateResult{Valid: true}, nil
 }
 
-func (c *Connector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (c *Connector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	// get clickhouse connection
 	db, err := c.getConnectionWithOptions(resourceOptions)
 	if err != nil {
@@ -70,41 +71,58 @@ func (c *Connector) TestConnection(resourceOptions map[string]interface{}) (comm
 	defer db.Close()
 
 	// test clickhouse connection
-	if err := db.Ping(); err != nil {
+	if err := db.PingContext(ctx); err != nil {
 		return common.ConnectionResult{Success: false}, err
 	}
 
 	return common.ConnectionResult{Success: true}, nil
 }
 
-func (c *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (c *Connector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 	// get clickhouse connection
-	db, err := c.getConnectionWithOptions(resourceOptions)
+	db, releaseConnection, err := c.getPooledConnectionWithOptions(ctx, resourceOptions)
 	if err != nil {
 		return common.MetaInfoResult{Success: false}, err
 	}
-	defer db.Close()
+	defer releaseConnection()
 
 	// test clickhouse connection
-	if err := db.Ping(); err != nil {
+	if err := db.PingContext(ctx); err != nil {
 		return common.MetaInfoResult{Success: false}, err
 	}
 
-	columns := fieldsInfo(db, c.ResourceOpts.DatabaseName, tablesInfo(db, c.ResourceOpts.DatabaseName))
+	// get clickhouse tables information, the tables are keyed by table name in legacy schema
+	detail, err := schemaMeta(ctx, db)
+	if err != nil {
+		return common.MetaInfoResult{Success: false}, err
+	}
 
 	return common.MetaInfoResult{
 		Success: true,
-		Schema:  columns,
+		Schema:  detail.ExportLegacySchema(c.ResourceOpts.DatabaseName),
+		Detail:  detail,
 	}, nil
 }
 
-func (c *Connector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+func (c *Connector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+	return common.CollectStream(ctx, func(emit common.RowEmitter) (common.RuntimeResult, error) {
+		return c.RunStream(ctx, resourceOptions, actionOptions, rawActionOptions, emit)
+	})
+}
+
+// RunStream run the action and emit the selected rows one by one, the driver errors are converted to common.QueryError.
+func (c *Connector) RunStream(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}, emit common.RowEmitter) (common.RuntimeResult, error) {
+	result, err := c.runStream(ctx, resourceOptions, actionOptions, rawActionOptions, emit)
+	return result, convertQueryError(err)
+}
+
+func (c *Connector) runStream(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}, emit common.RowEmitter) (common.RuntimeResult, error) {
 	// get clickhouse connection
-	db, err := c.getConnectionWithOptions(resourceOptions)
+	db, releaseConnection, err := c.getPooledConnectionWithOptions(ctx, resourceOptions)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, errors.New("failed to get clickhouse connection")
 	}
-	defer db.Close()
+	defer releaseConnection()
 
 	// format query
 	if err := mapstructure.Decode(actionOptions, &c.ActionOpts); err != nil {
@@ -129,6 +147,10 @@ func (c *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 	if errInEscapeSQL != nil {
 		return queryResult, errInEscapeSQL
 	}
+	// clickhouse has no transaction, so the read-only resource is guarded by the classifier only
+	if errInCheckReadOnly := common.CheckReadOnlySQL(resourceOptions, resourcelist.TYPE_CLICKHOUSE_ID, escapedSQL); errInCheckReadOnly != nil {
+		return queryResult, errInCheckReadOnly
+	}
 
 	// check if m.Action.Query is select query
 	isSelectQuery := false
@@ -140,31 +162,31 @@ func (c *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 
 	// fetch data
 	if isSelectQuery && c.ActionOpts.IsSafeMode() {
-		rows, err := db.Query(escapedSQL, sqlArgs...)
+		rows, err := db.QueryContext(ctx, escapedSQL, sqlArgs...)
 		if err != nil {
 			return queryResult, err
 		}
-		mapRes, err := common.RetrieveToMap(rows)
+		defer rows.Close()
+		columns, err := common.EmitRows(rows, emit)
 		if err != nil {
 			return queryResult, err
 		}
-		defer rows.Close()
+		queryResult.Columns = columns
 		queryResult.Success = true
-		queryResult.Rows = mapRes
 	} else if isSelectQuery && !c.ActionOpts.IsSafeMode() {
-		rows, err := db.Query(escapedSQL)
+		rows, err := db.QueryContext(ctx, escapedSQL)
 		if err != nil {
 			return queryResult, err
 		}
-		mapRes, err := common.RetrieveToMap(rows)
+		defer rows.Close()
+		columns, err := common.EmitRows(rows, emit)
 		if err != nil {
 			return queryResult, err
 		}
-		defer rows.Close()
+		queryResult.Columns = columns
 		queryResult.Success = true
-		queryResult.Rows = mapRes
 	} else if !isSelectQuery && c.ActionOpts.IsSafeMode() { // update, insert, delete data
-		execResult, err := db.Exec(escapedSQL, sqlArgs...)
+		execResult, err := db.ExecContext(ctx, escapedSQL, sqlArgs...)
 		if err != nil {
 			return queryResult, err
 		}
@@ -175,7 +197,7 @@ func (c *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 		queryResult.Success = true
 		queryResult.Extra["message"] = fmt.Sprintf("Affeted %d rows.", affectedRows)
 	} else if !isSelectQuery && !c.ActionOpts.IsSafeMode() { // update, insert, delete data
-		execResult, err := db.Exec(escapedSQL)
+		execResult, err := db.ExecContext(ctx, escapedSQL)
 		if err != nil {
 			return queryResult, err
 		}
@@ -32,6 +32,7 @@ type Resource struct {
 	Username     string
 	Password     string
 	SSL          SSLOptions
+	SSHTunnel    common.SSHTunnelOptions
 }
 
 type SSLOptions struct {
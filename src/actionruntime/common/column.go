@@ -0,0 +1,100 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"strconv"
+)
+
+// ColumnSchema describe a column of the query result in query order.
+// the Name is the key in result rows, which is disambiguated when the query returns duplicate column names,
+// the OriginalName is the column name returned by database.
+// the Nullable is nil when the driver can not tell.
+type ColumnSchema struct {
+	Name         string `json:"name"`
+	OriginalName string `json:"originalName"`
+	DatabaseType string `json:"databaseType"`
+	Nullable     *bool  `json:"nullable"`
+}
+
+func NewColumnSchema(name string, databaseType string, nullable *bool) *ColumnSchema {
+	return &ColumnSchema{
+		Name:         name,
+		OriginalName: name,
+		DatabaseType: databaseType,
+		Nullable:     nullable,
+	}
+}
+
+// NewColumnSchemasBySQLColumnTypes build the column schemas by the database/sql column types.
+func NewColumnSchemasBySQLColumnTypes(columnTypes []*sql.ColumnType) []*ColumnSchema {
+	columns := make([]*ColumnSchema, 0, len(columnTypes))
+	for _, columnType := range columnTypes {
+		var nullable *bool
+		if isNullable, ok := columnType.Nullable(); ok {
+			nullable = &isNullable
+		}
+		columns = append(columns, NewColumnSchema(columnType.Name(), columnType.DatabaseTypeName(), nullable))
+	}
+	return DisambiguateColumnSchemas(columns)
+}
+
+// NewColumnSchemasByDriverRows build the column schemas by the driver rows, the type is filled when the driver supports it.
+func NewColumnSchemasByDriverRows(rows driver.Rows) []*ColumnSchema {
+	columnNames := rows.Columns()
+	columns := make([]*ColumnSchema, 0, len(columnNames))
+	for i, columnName := range columnNames {
+		databaseType := ""
+		if typedRows, ok := rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+			databaseType = typedRows.ColumnTypeDatabaseTypeName(i)
+		}
+		var nullable *bool
+		if nullableRows, ok := rows.(driver.RowsColumnTypeNullable); ok {
+			if isNullable, ok := nullableRows.ColumnTypeNullable(i); ok {
+				nullable = &isNullable
+			}
+		}
+		columns = append(columns, NewColumnSchema(columnName, databaseType, nullable))
+	}
+	return DisambiguateColumnSchemas(columns)
+}
+
+// DisambiguateColumnSchemas rename the duplicate column names (like the "id" of joined tables) to "id_1", "id_2"...
+// so they will not overwrite each other in result rows. the first one keeps the original name.
+func DisambiguateColumnSchemas(columns []*ColumnSchema) []*ColumnSchema {
+	taken := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		taken[column.Name] = true
+	}
+	seen := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		if !seen[column.Name] {
+			seen[column.Name] = true
+			continue
+		}
+		for suffix := 1; ; suffix++ {
+			candidate := column.OriginalName + "_" + strconv.Itoa(suffix)
+			if !taken[candidate] {
+				column.Name = candidate
+				break
+			}
+		}
+		taken[column.Name] = true
+		seen[column.Name] = true
+	}
+	return columns
+}
@@ -0,0 +1,43 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestColumnSchemas(names ...string) []*ColumnSchema {
+	columns := make([]*ColumnSchema, 0, len(names))
+	for _, name := range names {
+		columns = append(columns, NewColumnSchema(name, "INT", nil))
+	}
+	return columns
+}
+
+func TestDisambiguateColumnSchemas(t *testing.T) {
+	testCases := []struct {
+		name     string
+		columns  []string
+		expected []string
+	}{
+		{name: "no duplicate", columns: []string{"id", "name"}, expected: []string{"id", "name"}},
+		{name: "one duplicate", columns: []string{"id", "name", "id"}, expected: []string{"id", "name", "id_1"}},
+		{name: "three duplicates", columns: []string{"id", "id", "id"}, expected: []string{"id", "id_1", "id_2"}},
+		{name: "suffix collides with real column", columns: []string{"id", "id", "id_1"}, expected: []string{"id", "id_2", "id_1"}},
+		{name: "suffix collides with real column before", columns: []string{"id_1", "id", "id"}, expected: []string{"id_1", "id", "id_2"}},
+		{name: "duplicate real suffixed column", columns: []string{"id", "id_1", "id", "id_1"}, expected: []string{"id", "id_1", "id_2", "id_1_1"}},
+		{name: "empty", columns: []string{}, expected: []string{}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			columns := DisambiguateColumnSchemas(newTestColumnSchemas(testCase.columns...))
+			names := make([]string, 0, len(columns))
+			for i, column := range columns {
+				names = append(names, column.Name)
+				assert.Equal(t, testCase.columns[i], column.OriginalName, "the original name should be preserved")
+				assert.Equal(t, "INT", column.DatabaseType)
+			}
+			assert.Equal(t, testCase.expected, names)
+		})
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	DEFAULT_CONNECTION_POOL_IDLE_TIMEOUT    = 10 * time.Minute
+	DEFAULT_CONNECTION_POOL_EVICTION_PERIOD = 1 * time.Minute
+)
+
+type resourceIDContextKey struct{}
+
+// ContextWithResourceID attach the resource ID to the action run context,
+// so the connection pool can invalidate the pooled connections of the resource when it was updated or deleted.
+func ContextWithResourceID(ctx context.Context, resourceID int) context.Context {
+	return context.WithValue(ctx, resourceIDContextKey{}, resourceID)
+}
+
+func ResourceIDFromContext(ctx context.Context) int {
+	resourceID, _ := ctx.Value(resourceIDContextKey{}).(int)
+	return resourceID
+}
+
+// ConnectionDialer open a new connection, the returned closer will be called when the connection evicted from pool.
+type ConnectionDialer func() (connection interface{}, closer func(), err error)
+
+type pooledConnection struct {
+	resourceID int
+	connection interface{}
+	closer     func()
+	refCount   int
+	lastUsedAt time.Time
+	removed    bool
+}
+
+// ConnectionPoolManager keep the opened connections for resources, keyed by resource ID and options hash.
+// idle connections are evicted after idleTimeout, and all connections of a resource can be invalidated by InvalidateResource.
+type ConnectionPoolManager struct {
+	mutex       sync.Mutex
+	connections map[string]*pooledConnection
+	idleTimeout time.Duration
+}
+
+var connectionPoolManager *ConnectionPoolManager
+var connectionPoolManagerOnce sync.Once
+
+func GetConnectionPoolManager() *ConnectionPoolManager {
+	connectionPoolManagerOnce.Do(func() {
+		connectionPoolManager = NewConnectionPoolManager(DEFAULT_CONNECTION_POOL_IDLE_TIMEOUT)
+		go connectionPoolManager.runEviction(DEFAULT_CONNECTION_POOL_EVICTION_PERIOD)
+	})
+	return connectionPoolManager
+}
+
+func NewConnectionPoolManager(idleTimeout time.Duration) *ConnectionPoolManager {
+	return &ConnectionPoolManager{
+		connections: make(map[string]*pooledConnection),
+		idleTimeout: idleTimeout,
+	}
+}
+
+// Acquire return the pooled connection for given connector type and resource options, dial a new one when missing.
+// the returned release function must be called after the connection is no longer used.
+func (m *ConnectionPoolManager) Acquire(ctx context.Context, connectorType string, resourceOptions map[string]interface{}, dialer ConnectionDialer) (interface{}, func(), error) {
+	resourceID := ResourceIDFromContext(ctx)
+	key, errInBuildKey := buildConnectionPoolKey(resourceID, connectorType, resourceOptions)
+	if errInBuildKey != nil {
+		return nil, nil, errInBuildKey
+	}
+
+	// hit
+	if pc := m.acquireExists(key); pc != nil {
+		return pc.connection, m.releaseFunc(pc), nil
+	}
+
+	// miss, dial without lock, so the slow dial will not block other resources
+	connection, closer, errInDial := dialer()
+	if errInDial != nil {
+		return nil, nil, errInDial
+	}
+	m.mutex.Lock()
+	if pc, hit := m.connections[key]; hit {
+		// another goroutine dialed the same resource first, use it and drop ours
+		pc.refCount++
+		pc.lastUsedAt = time.Now()
+		m.mutex.Unlock()
+		closer()
+		return pc.connection, m.releaseFunc(pc), nil
+	}
+	pc := &pooledConnection{
+		resourceID: resourceID,
+		connection: connection,
+		closer:     closer,
+		refCount:   1,
+		lastUsedAt: time.Now(),
+	}
+	m.connections[key] = pc
+	m.mutex.Unlock()
+	return pc.connection, m.releaseFunc(pc), nil
+}
+
+// AcquireSQLDB is the Acquire for database/sql based connectors, the *sql.DB itself is a connection pool.
+func (m *ConnectionPoolManager) AcquireSQLDB(ctx context.Context, connectorType string, resourceOptions map[string]interface{}, dialer func() (*sql.DB, error)) (*sql.DB, func(), error) {
+	connection, release, err := m.Acquire(ctx, connectorType, resourceOptions, func() (interface{}, func(), error) {
+		db, err := dialer()
+		if err != nil {
+			return nil, nil, err
+		}
+		return db, func() { db.Close() }, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return connection.(*sql.DB), release, nil
+}
+
+// InvalidateResource remove all pooled connections of the resource, the connections in use will be closed after released.
+func (m *ConnectionPoolManager) InvalidateResource(resourceID int) {
+	m.mutex.Lock()
+	closers := make([]func(), 0)
+	for key, pc := range m.connections {
+		if pc.resourceID != resourceID {
+			continue
+		}
+		delete(m.connections, key)
+		pc.removed = true
+		if pc.refCount == 0 {
+			closers = append(closers, pc.closer)
+		}
+	}
+	m.mutex.Unlock()
+	for _, closer := range closers {
+		closer()
+	}
+}
+
+// EvictIdle close the connections which are not used since idleTimeout.
+func (m *ConnectionPoolManager) EvictIdle() {
+	m.mutex.Lock()
+	closers := make([]func(), 0)
+	deadline := time.Now().Add(-m.idleTimeout)
+	for key, pc := range m.connections {
+		if pc.refCount > 0 || pc.lastUsedAt.After(deadline) {
+			continue
+		}
+		delete(m.connections, key)
+		pc.removed = true
+		closers = append(closers, pc.closer)
+	}
+	m.mutex.Unlock()
+	for _, closer := range closers {
+		closer()
+	}
+}
+
+func (m *ConnectionPoolManager) runEviction(period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for range ticker.C {
+		m.EvictIdle()
+	}
+}
+
+func (m *ConnectionPoolManager) acquireExists(key string) *pooledConnection {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	pc, hit := m.connections[key]
+	if !hit {
+		return nil
+	}
+	pc.refCount++
+	pc.lastUsedAt = time.Now()
+	return pc
+}
+
+func (m *ConnectionPoolManager) releaseFunc(pc *pooledConnection) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.mutex.Lock()
+			pc.refCount--
+			pc.lastUsedAt = time.Now()
+			needClose := pc.removed && pc.refCount == 0
+			m.mutex.Unlock()
+			if needClose {
+				pc.closer()
+			}
+		})
+	}
+}
+
+func buildConnectionPoolKey(resourceID int, connectorType string, resourceOptions map[string]interface{}) (string, error) {
+	// json.Marshal sort the map keys, so the same options always have the same hash
+	optionsInJSON, errInMarshal := json.Marshal(resourceOptions)
+	if errInMarshal != nil {
+		return "", errInMarshal
+	}
+	optionsHash := sha256.Sum256(optionsInJSON)
+	return fmt.Sprintf("%d:%s:%s", resourceID, connectorType, hex.EncodeToString(optionsHash[:])), nil
+}
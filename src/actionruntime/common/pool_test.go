@@ -0,0 +1,136 @@
+package common
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDialer count the dialed and closed connections, every connection is a distinct *int.
+type fakeDialer struct {
+	dialed int32
+	closed int32
+}
+
+func (dialer *fakeDialer) dial() (interface{}, func(), error) {
+	id := int(atomic.AddInt32(&dialer.dialed, 1))
+	return &id, func() { atomic.AddInt32(&dialer.closed, 1) }, nil
+}
+
+func (dialer *fakeDialer) dialedCount() int {
+	return int(atomic.LoadInt32(&dialer.dialed))
+}
+
+func (dialer *fakeDialer) closedCount() int {
+	return int(atomic.LoadInt32(&dialer.closed))
+}
+
+var testPoolOptions = map[string]interface{}{"host": "127.0.0.1", "port": "5432"}
+
+func TestConnectionPoolHit(t *testing.T) {
+	manager := NewConnectionPoolManager(time.Hour)
+	dialer := &fakeDialer{}
+	ctx := ContextWithResourceID(context.Background(), 1)
+
+	connection, release, err := manager.Acquire(ctx, "postgresql", testPoolOptions, dialer.dial)
+	assert.Nil(t, err)
+	release()
+	release() // release is idempotent
+	reused, releaseReused, err := manager.Acquire(ctx, "postgresql", testPoolOptions, dialer.dial)
+	assert.Nil(t, err)
+	releaseReused()
+	assert.True(t, connection == reused, "the pooled connection should be reused")
+	assert.Equal(t, 1, dialer.dialedCount())
+
+	// the changed options or other resource dial a new connection
+	_, releaseChanged, _ := manager.Acquire(ctx, "postgresql", map[string]interface{}{"host": "127.0.0.2", "port": "5432"}, dialer.dial)
+	releaseChanged()
+	_, releaseOther, _ := manager.Acquire(ContextWithResourceID(context.Background(), 2), "postgresql", testPoolOptions, dialer.dial)
+	releaseOther()
+	assert.Equal(t, 3, dialer.dialedCount())
+	assert.Equal(t, 0, dialer.closedCount())
+}
+
+func TestConnectionPoolConcurrentMiss(t *testing.T) {
+	manager := NewConnectionPoolManager(time.Hour)
+	dialer := &fakeDialer{}
+	ctx := ContextWithResourceID(context.Background(), 1)
+
+	// both goroutines miss and dial, the dials return only after both started
+	var dialing sync.WaitGroup
+	dialing.Add(2)
+	blockingDial := func() (interface{}, func(), error) {
+		dialing.Done()
+		dialing.Wait()
+		return dialer.dial()
+	}
+	connections := make([]interface{}, 2)
+	var acquiring sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		acquiring.Add(1)
+		go func(i int) {
+			defer acquiring.Done()
+			connection, release, err := manager.Acquire(ctx, "postgresql", testPoolOptions, blockingDial)
+			assert.Nil(t, err)
+			connections[i] = connection
+			release()
+		}(i)
+	}
+	acquiring.Wait()
+
+	assert.Equal(t, 2, dialer.dialedCount())
+	assert.Equal(t, 1, dialer.closedCount(), "the connection of the losing dial should be closed")
+	assert.True(t, connections[0] == connections[1], "both goroutines should use the pooled connection")
+	assert.Equal(t, 1, len(manager.connections))
+}
+
+func TestConnectionPoolInvalidateInUse(t *testing.T) {
+	manager := NewConnectionPoolManager(time.Hour)
+	dialer := &fakeDialer{}
+	ctx := ContextWithResourceID(context.Background(), 1)
+
+	_, releaseFirst, _ := manager.Acquire(ctx, "postgresql", testPoolOptions, dialer.dial)
+	_, releaseSecond, _ := manager.Acquire(ctx, "postgresql", testPoolOptions, dialer.dial)
+	manager.InvalidateResource(1)
+	assert.Equal(t, 0, len(manager.connections))
+	assert.Equal(t, 0, dialer.closedCount(), "the connection in use should not be closed")
+
+	// the next acquire dial a new connection with the updated resource
+	_, releaseNew, _ := manager.Acquire(ctx, "postgresql", testPoolOptions, dialer.dial)
+	assert.Equal(t, 2, dialer.dialedCount())
+
+	releaseFirst()
+	assert.Equal(t, 0, dialer.closedCount())
+	releaseSecond()
+	assert.Equal(t, 1, dialer.closedCount(), "the invalidated connection should be closed on the last release")
+	releaseNew()
+	assert.Equal(t, 1, dialer.closedCount())
+
+	// the idle connection is closed at once
+	manager.InvalidateResource(1)
+	assert.Equal(t, 2, dialer.closedCount())
+}
+
+func TestConnectionPoolEvictIdle(t *testing.T) {
+	manager := NewConnectionPoolManager(0)
+	dialer := &fakeDialer{}
+
+	_, releaseIdle, _ := manager.Acquire(ContextWithResourceID(context.Background(), 1), "postgresql", testPoolOptions, dialer.dial)
+	releaseIdle()
+	_, releaseInUse, _ := manager.Acquire(ContextWithResourceID(context.Background(), 2), "postgresql", testPoolOptions, dialer.dial)
+	time.Sleep(time.Millisecond)
+
+	manager.EvictIdle()
+	assert.Equal(t, 1, dialer.closedCount(), "only the idle connection should be evicted")
+	assert.Equal(t, 1, len(manager.connections))
+
+	releaseInUse()
+	time.Sleep(time.Millisecond)
+	manager.EvictIdle()
+	assert.Equal(t, 2, dialer.closedCount())
+	assert.Equal(t, 0, len(manager.connections))
+}
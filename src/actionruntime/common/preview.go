@@ -0,0 +1,134 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	parser_sql "github.com/illacloud/builder-backend/src/utils/parser/sql"
+)
+
+const (
+	RESULT_EXTRA_FIELD_PREVIEW       = "preview"
+	RESULT_EXTRA_FIELD_AFFECTED_ROWS = "affectedRows"
+)
+
+// PreviewDataConnector is the connector which can run the write action in a rolled back transaction,
+// so the affected rows can be confirmed before committing.
+type PreviewDataConnector interface {
+	DataConnector
+	RunPreview(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (RuntimeResult, error)
+}
+
+// previewRefusedKeywords are the DML statements which side effects can not be rolled back,
+// like "COPY ... TO PROGRAM" runs shell command and "COPY ... TO '/path'" writes the server file in postgresql.
+var previewRefusedKeywords = map[string]bool{
+	"copy": true,
+}
+
+// CheckPreviewSQL only allow previewing a single DML statement, the DDL statements are refused since some databases commit them implicitly.
+func CheckPreviewSQL(resourceType int, sql string) (*parser_sql.ClassifiedStatement, error) {
+	statements, errInClassify := parser_sql.NewSQLClassifier(resourceType).Classify(sql)
+	if errInClassify != nil {
+		return nil, errInClassify
+	}
+	if len(statements) != 1 {
+		return nil, fmt.Errorf("preview only supports single statement, got %d statements", len(statements))
+	}
+	if !statements[0].IsDML() {
+		return nil, fmt.Errorf("preview only supports data modifying statement, got %s statement", statements[0].ExportKindName())
+	}
+	if previewRefusedKeywords[statements[0].Keyword] {
+		return nil, fmt.Errorf("preview does not support %s statement, its side effects can not be rolled back", strings.ToUpper(statements[0].Keyword))
+	}
+	return statements[0], nil
+}
+
+// PreviewRowCounter collect the returned rows within the result limits and count all of them as the affected rows.
+type PreviewRowCounter struct {
+	collector *RowCollector
+	rows      int64
+}
+
+func NewPreviewRowCounter(ctx context.Context) *PreviewRowCounter {
+	return &PreviewRowCounter{
+		collector: NewRowCollector(ctx),
+	}
+}
+
+func (counter *PreviewRowCounter) Emit(row map[string]interface{}) error {
+	counter.rows++
+	if errInEmit := counter.collector.Emit(row); errInEmit != nil && !errors.Is(errInEmit, ErrResultLimitReached) {
+		return errInEmit
+	}
+	return nil
+}
+
+func (counter *PreviewRowCounter) ExportRowCount() int64 {
+	return counter.rows
+}
+
+// NewPreviewResult build the preview result with the affected row count and the returned rows.
+func NewPreviewResult(counter *PreviewRowCounter, columns []*ColumnSchema, affectedRows int64) RuntimeResult {
+	result := RuntimeResult{
+		Success: true,
+		Rows:    counter.collector.ExportRows(),
+		Columns: columns,
+		Extra: map[string]interface{}{
+			RESULT_EXTRA_FIELD_PREVIEW:       true,
+			RESULT_EXTRA_FIELD_AFFECTED_ROWS: affectedRows,
+			"message":                        fmt.Sprintf("Preview affected %d rows, the changes have been rolled back.", affectedRows),
+		},
+	}
+	counter.collector.MarkResult(&result)
+	return result
+}
+
+// RunPreviewInSQLTx run the DML statement in a transaction and roll it back,
+// the returned rows are collected when the statement has the returning clause.
+func RunPreviewInSQLTx(ctx context.Context, db *sql.DB, statement *parser_sql.ClassifiedStatement, query string, args ...interface{}) (RuntimeResult, error) {
+	tx, errInBegin := db.BeginTx(ctx, nil)
+	if errInBegin != nil {
+		return RuntimeResult{Success: false}, errInBegin
+	}
+	defer tx.Rollback()
+
+	counter := NewPreviewRowCounter(ctx)
+	if statement.Returning {
+		rows, errInQuery := tx.QueryContext(ctx, query, args...)
+		if errInQuery != nil {
+			return RuntimeResult{Success: false}, errInQuery
+		}
+		defer rows.Close()
+		columns, errInEmit := EmitRows(rows, counter.Emit)
+		if errInEmit != nil {
+			return RuntimeResult{Success: false}, errInEmit
+		}
+		return NewPreviewResult(counter, columns, counter.ExportRowCount()), nil
+	}
+	execResult, errInExec := tx.ExecContext(ctx, query, args...)
+	if errInExec != nil {
+		return RuntimeResult{Success: false}, errInExec
+	}
+	affectedRows, errInGetAffectedRows := execResult.RowsAffected()
+	if errInGetAffectedRows != nil {
+		return RuntimeResult{Success: false}, errInGetAffectedRows
+	}
+	return NewPreviewResult(counter, nil, affectedRows), nil
+}
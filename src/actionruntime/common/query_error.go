@@ -0,0 +1,133 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"errors"
+)
+
+const (
+	QUERY_ERROR_CLASS_SYNTAX     = "syntax"
+	QUERY_ERROR_CLASS_NOT_FOUND  = "notFound"
+	QUERY_ERROR_CLASS_CONSTRAINT = "constraint"
+	QUERY_ERROR_CLASS_DATA       = "data"
+	QUERY_ERROR_CLASS_PERMISSION = "permission"
+	QUERY_ERROR_CLASS_CONNECTION = "connection"
+	QUERY_ERROR_CLASS_TIMEOUT    = "timeout"
+	QUERY_ERROR_CLASS_UNKNOWN    = "unknown"
+)
+
+// QueryError is the connector-agnostic database error, built by the connectors from their driver errors.
+// the LineNumber and Position (character offset in line) are 1-based, zero means unknown.
+type QueryError struct {
+	Class      string `json:"class"`
+	VendorCode string `json:"vendorCode,omitempty"`
+	SQLState   string `json:"sqlState,omitempty"`
+	Message    string `json:"message"`
+	LineNumber int    `json:"lineNumber,omitempty"`
+	Position   int    `json:"position,omitempty"`
+	Hint       string `json:"hint,omitempty"`
+	Err        error  `json:"-"`
+}
+
+// NewQueryError wrap the driver error, the class is inferred by SQLSTATE when not given.
+func NewQueryError(err error, class string, vendorCode string, sqlState string, message string) *QueryError {
+	if class == "" {
+		class = ClassifySQLState(sqlState)
+	}
+	return &QueryError{
+		Class:      class,
+		VendorCode: vendorCode,
+		SQLState:   sqlState,
+		Message:    message,
+		Err:        err,
+	}
+}
+
+func (queryError *QueryError) Error() string {
+	return queryError.Err.Error()
+}
+
+func (queryError *QueryError) Unwrap() error {
+	return queryError.Err
+}
+
+func (queryError *QueryError) SetLocation(lineNumber int, position int) {
+	queryError.LineNumber = lineNumber
+	queryError.Position = position
+}
+
+// SetLocationByOffset set the line number and position by the 1-based character offset in query.
+func (queryError *QueryError) SetLocationByOffset(query string, offset int) {
+	if offset <= 0 {
+		return
+	}
+	lineNumber, position, count := 1, 0, 0
+	for _, r := range query {
+		count++
+		if count >= offset {
+			position++
+			break
+		}
+		if r == '\n' {
+			lineNumber++
+			position = 0
+			continue
+		}
+		position++
+	}
+	queryError.SetLocation(lineNumber, position)
+}
+
+func (queryError *QueryError) SetHint(hint string) {
+	queryError.Hint = hint
+}
+
+// AsQueryError find the QueryError in the error chain.
+func AsQueryError(err error) (*QueryError, bool) {
+	var queryError *QueryError
+	if errors.As(err, &queryError) {
+		return queryError, true
+	}
+	return nil, false
+}
+
+// ClassifySQLState classify the error by the SQLSTATE class (the first 2 characters).
+func ClassifySQLState(sqlState string) string {
+	switch {
+	case sqlState == "42501":
+		return QUERY_ERROR_CLASS_PERMISSION
+	case sqlState == "42S02" || sqlState == "42S22" || sqlState == "42P01" || sqlState == "42703" || sqlState == "42883":
+		return QUERY_ERROR_CLASS_NOT_FOUND
+	case sqlState == "57014" || sqlState == "HYT00":
+		return QUERY_ERROR_CLASS_TIMEOUT
+	}
+	if len(sqlState) < 2 {
+		return QUERY_ERROR_CLASS_UNKNOWN
+	}
+	switch sqlState[:2] {
+	case "42":
+		return QUERY_ERROR_CLASS_SYNTAX
+	case "23":
+		return QUERY_ERROR_CLASS_CONSTRAINT
+	case "22":
+		return QUERY_ERROR_CLASS_DATA
+	case "28":
+		return QUERY_ERROR_CLASS_PERMISSION
+	case "08":
+		return QUERY_ERROR_CLASS_CONNECTION
+	}
+	return QUERY_ERROR_CLASS_UNKNOWN
+}
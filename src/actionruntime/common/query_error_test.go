@@ -0,0 +1,47 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryErrorSetLocationByOffset(t *testing.T) {
+	query := "SELECT *\nFROM users\nWHER id = 1"
+	testCases := []struct {
+		name       string
+		query      string
+		offset     int
+		lineNumber int
+		position   int
+	}{
+		{name: "unknown offset", query: query, offset: 0, lineNumber: 0, position: 0},
+		{name: "first character", query: query, offset: 1, lineNumber: 1, position: 1},
+		{name: "first line", query: query, offset: 8, lineNumber: 1, position: 8},
+		{name: "line break", query: query, offset: 9, lineNumber: 1, position: 9},
+		{name: "second line", query: query, offset: 10, lineNumber: 2, position: 1},
+		{name: "third line", query: query, offset: 21, lineNumber: 3, position: 1},
+		{name: "multi-byte characters are counted once", query: "SELECT 'é' FORM t", offset: 12, lineNumber: 1, position: 12},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			queryError := NewQueryError(errors.New("syntax error"), "", "42601", "42601", "syntax error")
+			queryError.SetLocationByOffset(testCase.query, testCase.offset)
+			assert.Equal(t, testCase.lineNumber, queryError.LineNumber)
+			assert.Equal(t, testCase.position, queryError.Position)
+		})
+	}
+}
+
+func TestAsQueryError(t *testing.T) {
+	queryError := NewQueryError(errors.New("permission denied"), "", "42501", "42501", "permission denied")
+	assert.Equal(t, QUERY_ERROR_CLASS_PERMISSION, queryError.Class)
+
+	found, ok := AsQueryError(fmt.Errorf("run action failed: %w", queryError))
+	assert.True(t, ok)
+	assert.Equal(t, queryError, found)
+	_, ok = AsQueryError(errors.New("connection refused"))
+	assert.False(t, ok)
+}
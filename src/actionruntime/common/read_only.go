@@ -0,0 +1,116 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	parser_sql "github.com/illacloud/builder-backend/src/utils/parser/sql"
+)
+
+// the resource option, the SQL resources only run read and transaction control statements when it is true.
+// the statements are classified before execution, then the database enforces it in a read only transaction
+// (see BeginReadOnlySQLTx), except snowflake and clickhouse, which have no read only transaction, so the guarantee is classifier-only.
+const RESOURCE_OPTION_FIELD_READ_ONLY = "readOnly"
+
+// the statement begins the read only transaction of oracle, it must be the first statement in transaction.
+const ORACLE_SET_TRANSACTION_READ_ONLY = "SET TRANSACTION READ ONLY"
+
+func IsReadOnlyResource(resourceOptions map[string]interface{}) bool {
+	switch readOnly := resourceOptions[RESOURCE_OPTION_FIELD_READ_ONLY].(type) {
+	case bool:
+		return readOnly
+	case string:
+		isReadOnly, _ := strconv.ParseBool(readOnly)
+		return isReadOnly
+	}
+	return false
+}
+
+// CheckReadOnlySQL classify every statement of the script before execution when the resource is read-only,
+// the first write statement (including the read calling side effect function) is reported as a permission QueryError with its line number.
+func CheckReadOnlySQL(resourceOptions map[string]interface{}, resourceType int, sql string) error {
+	if !IsReadOnlyResource(resourceOptions) {
+		return nil
+	}
+	statement, errInCheck := parser_sql.NewSQLClassifier(resourceType).CheckReadOnly(sql)
+	if errInCheck == nil {
+		return nil
+	}
+	if statement == nil {
+		// the script can not be classified, refuse it on read-only resource
+		return NewQueryError(errInCheck, QUERY_ERROR_CLASS_PERMISSION, "", "", "can not classify the query on read-only resource: "+errInCheck.Error())
+	}
+	queryError := NewQueryError(errInCheck, QUERY_ERROR_CLASS_PERMISSION, "", "", errInCheck.Error())
+	queryError.SetLocation(statement.LineNum, 0)
+	return queryError
+}
+
+// CheckReadOnlyGUI refuse the gui mode action on read-only resource, since the gui mode always writes the records.
+func CheckReadOnlyGUI(resourceOptions map[string]interface{}) error {
+	if !IsReadOnlyResource(resourceOptions) {
+		return nil
+	}
+	errInCheck := errors.New("the gui mode action writes records, which is not allowed on read-only resource")
+	return NewQueryError(errInCheck, QUERY_ERROR_CLASS_PERMISSION, "", "", errInCheck.Error())
+}
+
+// SQLQueryer is the *sql.DB or *sql.Tx which runs the query.
+type SQLQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// BeginReadOnlySQLTx begin a read only transaction to run the query on read-only resource, which refuses the writes the classifier can not see,
+// like the writes in stored functions. the db itself is returned when the resource is not read-only.
+// the transaction has nothing to commit, so the returned end func always rolls it back.
+func BeginReadOnlySQLTx(ctx context.Context, db *sql.DB, resourceOptions map[string]interface{}) (SQLQueryer, func(), error) {
+	if !IsReadOnlyResource(resourceOptions) {
+		return db, func() {}, nil
+	}
+	tx, errInBegin := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if errInBegin != nil {
+		return nil, nil, errInBegin
+	}
+	return tx, func() { tx.Rollback() }, nil
+}
+
+// BeginReadOnlySQLTxByStatement is BeginReadOnlySQLTx for the drivers which refuse the read only TxOptions (like go-ora),
+// it begins the transaction with default options and makes it read only by the statement (like ORACLE_SET_TRANSACTION_READ_ONLY).
+func BeginReadOnlySQLTxByStatement(ctx context.Context, db *sql.DB, resourceOptions map[string]interface{}, statement string) (SQLQueryer, func(), error) {
+	if !IsReadOnlyResource(resourceOptions) {
+		return db, func() {}, nil
+	}
+	tx, errInBegin := db.BeginTx(ctx, nil)
+	if errInBegin != nil {
+		return nil, nil, errInBegin
+	}
+	if _, errInSet := tx.ExecContext(ctx, statement); errInSet != nil {
+		tx.Rollback()
+		return nil, nil, errInSet
+	}
+	return tx, func() { tx.Rollback() }, nil
+}
+
+// ExportSQLTxOptions return the read only transaction options for read-only resource, and nil (the default options) for others.
+func ExportSQLTxOptions(resourceOptions map[string]interface{}) *sql.TxOptions {
+	if !IsReadOnlyResource(resourceOptions) {
+		return nil
+	}
+	return &sql.TxOptions{ReadOnly: true}
+}
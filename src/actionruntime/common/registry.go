@@ -0,0 +1,103 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/illacloud/builder-backend/src/utils/resourcelist"
+)
+
+// ConnectorCapabilities tell the frontend what the connector supports.
+// the MetaInfo, TestConnection and GUIMode are declared by connector,
+// the Streaming, Preview and Transaction are detected by the optional interfaces the connector implemented.
+type ConnectorCapabilities struct {
+	MetaInfo       bool `json:"metaInfo"`
+	TestConnection bool `json:"testConnection"`
+	GUIMode        bool `json:"guiMode"`
+	Streaming      bool `json:"streaming"`
+	Preview        bool `json:"preview"`
+	Transaction    bool `json:"transaction"`
+}
+
+// ConnectorRegistration declare a resource type served by connector, the New build a connector for every run.
+// the SecretFields are the option field paths (split by ".") encrypted at rest and masked in api response.
+type ConnectorRegistration struct {
+	Type         string
+	ID           int
+	Capabilities ConnectorCapabilities
+	SecretFields []string
+	New          func() DataConnector
+}
+
+var connectorRegistry = struct {
+	sync.RWMutex
+	registrations map[int]*ConnectorRegistration
+}{
+	registrations: map[int]*ConnectorRegistration{},
+}
+
+// RegisterConnector register the connector of resource type, it is called in the init() of connector package,
+// so adding a connector only needs importing its package (see actionruntime/connectors).
+// it panics when the registration is invalid or the type is already registered, like database/sql.Register.
+func RegisterConnector(registration ConnectorRegistration) {
+	if registration.Type == "" || registration.New == nil {
+		panic("common: RegisterConnector with empty type or nil constructor")
+	}
+	connectorRegistry.Lock()
+	defer connectorRegistry.Unlock()
+	if registered, hit := connectorRegistry.registrations[registration.ID]; hit {
+		panic(fmt.Sprintf("common: RegisterConnector called twice for type %s (ID %d)", registered.Type, registration.ID))
+	}
+	resourcelist.RegisterResourceType(registration.Type, registration.ID)
+	if registration.SecretFields != nil {
+		resourcelist.RegisterSecretFields(registration.Type, registration.SecretFields)
+	}
+
+	// detect the capabilities of optional interfaces
+	connector := registration.New()
+	_, registration.Capabilities.Streaming = connector.(StreamingDataConnector)
+	_, registration.Capabilities.Preview = connector.(PreviewDataConnector)
+	_, registration.Capabilities.Transaction = connector.(TransactionalDataConnector)
+	connectorRegistry.registrations[registration.ID] = &registration
+}
+
+// NewConnector build the connector of resource type ID.
+func NewConnector(id int) (DataConnector, error) {
+	connectorRegistry.RLock()
+	registration, hit := connectorRegistry.registrations[id]
+	connectorRegistry.RUnlock()
+	if !hit {
+		return nil, errors.New("invalid ActionType: unsupported type " + resourcelist.GetResourceIDMappedType(id))
+	}
+	return registration.New(), nil
+}
+
+// ExportConnectorRegistrations export all registered connectors ordered by type ID.
+func ExportConnectorRegistrations() []*ConnectorRegistration {
+	connectorRegistry.RLock()
+	defer connectorRegistry.RUnlock()
+	registrations := make([]*ConnectorRegistration, 0, len(connectorRegistry.registrations))
+	for _, registration := range connectorRegistry.registrations {
+		registrations = append(registrations, registration)
+	}
+	sort.Slice(registrations, func(i, j int) bool {
+		return registrations[i].ID < registrations[j].ID
+	})
+	return registrations
+}
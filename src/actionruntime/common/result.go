@@ -0,0 +1,189 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	RESULT_EXTRA_FIELD_TRUNCATED = "truncated"
+	RESULT_EXTRA_FIELD_LIMITS    = "limits"
+)
+
+// ErrResultLimitReached is returned by the RowEmitter when the row exceeds the result limits,
+// the connector should stop fetching and finish the run as succeeded.
+var ErrResultLimitReached = errors.New("result limit reached")
+
+// ResultLimits is the max rows and bytes (of serialized rows) of a run result, the zero value means unlimited.
+type ResultLimits struct {
+	MaxRows  int   `json:"maxRows"`
+	MaxBytes int64 `json:"maxBytes"`
+}
+
+func NewResultLimits(maxRows int, maxBytes int64) *ResultLimits {
+	return &ResultLimits{
+		MaxRows:  maxRows,
+		MaxBytes: maxBytes,
+	}
+}
+
+// Cap limit the limits by the upper limits, the unlimited fields are replaced by the upper ones.
+func (limits *ResultLimits) Cap(upper *ResultLimits) *ResultLimits {
+	capped := NewResultLimits(limits.MaxRows, limits.MaxBytes)
+	if upper.MaxRows > 0 && (capped.MaxRows <= 0 || capped.MaxRows > upper.MaxRows) {
+		capped.MaxRows = upper.MaxRows
+	}
+	if upper.MaxBytes > 0 && (capped.MaxBytes <= 0 || capped.MaxBytes > upper.MaxBytes) {
+		capped.MaxBytes = upper.MaxBytes
+	}
+	return capped
+}
+
+type resultLimitsContextKey struct{}
+
+// ContextWithResultLimits attach the result limits of resource to the action run context.
+func ContextWithResultLimits(ctx context.Context, limits *ResultLimits) context.Context {
+	return context.WithValue(ctx, resultLimitsContextKey{}, limits)
+}
+
+// ResultLimitsFromContext return the attached result limits, nil means unlimited.
+func ResultLimitsFromContext(ctx context.Context) *ResultLimits {
+	limits, _ := ctx.Value(resultLimitsContextKey{}).(*ResultLimits)
+	return limits
+}
+
+// RowEmitter receive the result rows one by one.
+type RowEmitter func(row map[string]interface{}) error
+
+// StreamingDataConnector is implemented by the connectors which can emit rows while fetching,
+// so the large result need not to be materialised in memory.
+// the rows are emitted by emit, the rows returned in result (if any) should be emitted by caller after the run.
+type StreamingDataConnector interface {
+	DataConnector
+	RunStream(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}, emit RowEmitter) (RuntimeResult, error)
+}
+
+// RowLimiter count the serialized size of rows and reject the rows over the limits.
+type RowLimiter struct {
+	limits    *ResultLimits
+	rows      int
+	bytes     int64
+	truncated bool
+}
+
+func NewRowLimiter(limits *ResultLimits) *RowLimiter {
+	return &RowLimiter{
+		limits: limits,
+	}
+}
+
+// Accept serialize and count the row, ErrResultLimitReached is returned when the row does not fit in the limits.
+func (limiter *RowLimiter) Accept(row map[string]interface{}) ([]byte, error) {
+	if limiter.truncated {
+		return nil, ErrResultLimitReached
+	}
+	if limiter.limits != nil && limiter.limits.MaxRows > 0 && limiter.rows >= limiter.limits.MaxRows {
+		limiter.truncated = true
+		return nil, ErrResultLimitReached
+	}
+	rowInJSON, errInMarshal := json.Marshal(row)
+	if errInMarshal != nil {
+		return nil, errInMarshal
+	}
+	if limiter.limits != nil && limiter.limits.MaxBytes > 0 && limiter.bytes+int64(len(rowInJSON)) > limiter.limits.MaxBytes {
+		limiter.truncated = true
+		return nil, ErrResultLimitReached
+	}
+	limiter.rows++
+	limiter.bytes += int64(len(rowInJSON))
+	return rowInJSON, nil
+}
+
+func (limiter *RowLimiter) IsTruncated() bool {
+	return limiter.truncated
+}
+
+// MarkResult set the truncated marker and the limits to result extra when rows were dropped.
+func (limiter *RowLimiter) MarkResult(result *RuntimeResult) {
+	if !limiter.truncated {
+		return
+	}
+	if result.Extra == nil {
+		result.Extra = map[string]interface{}{}
+	}
+	result.Extra[RESULT_EXTRA_FIELD_TRUNCATED] = true
+	result.Extra[RESULT_EXTRA_FIELD_LIMITS] = limiter.limits
+}
+
+// RowCollector collect the emitted rows within the limits, for running the streaming connector in normal mode.
+type RowCollector struct {
+	limiter *RowLimiter
+	rows    []map[string]interface{}
+}
+
+// NewRowCollector build collector with the result limits attached to ctx.
+func NewRowCollector(ctx context.Context) *RowCollector {
+	return &RowCollector{
+		limiter: NewRowLimiter(ResultLimitsFromContext(ctx)),
+		rows:    []map[string]interface{}{},
+	}
+}
+
+func (collector *RowCollector) Emit(row map[string]interface{}) error {
+	if _, errInAccept := collector.limiter.Accept(row); errInAccept != nil {
+		return errInAccept
+	}
+	collector.rows = append(collector.rows, row)
+	return nil
+}
+
+func (collector *RowCollector) ExportRows() []map[string]interface{} {
+	return collector.rows
+}
+
+func (collector *RowCollector) MarkResult(result *RuntimeResult) {
+	collector.limiter.MarkResult(result)
+}
+
+// CollectStream run the streaming connector and collect the emitted rows as the result rows.
+func CollectStream(ctx context.Context, run func(emit RowEmitter) (RuntimeResult, error)) (RuntimeResult, error) {
+	collector := NewRowCollector(ctx)
+	result, err := run(collector.Emit)
+	if err != nil {
+		return result, err
+	}
+	if err := EmitEachRow(result.Rows, collector.Emit); err != nil {
+		return result, err
+	}
+	result.Rows = collector.ExportRows()
+	collector.MarkResult(&result)
+	return result, nil
+}
+
+// EmitEachRow emit rows from a slice, it is the streaming fallback of the connectors which only return materialised rows.
+func EmitEachRow(rows []map[string]interface{}, emit RowEmitter) error {
+	for _, row := range rows {
+		if err := emit(row); err != nil {
+			if errors.Is(err, ErrResultLimitReached) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// every test row is serialized to 10 bytes: {"id":"x"}
+func newTestRows(count int) []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0, count)
+	for i := 0; i < count; i++ {
+		rows = append(rows, map[string]interface{}{"id": string(rune('a' + i))})
+	}
+	return rows
+}
+
+func TestRowLimiterAccept(t *testing.T) {
+	testCases := []struct {
+		name      string
+		limits    *ResultLimits
+		rows      []map[string]interface{}
+		accepted  int
+		truncated bool
+	}{
+		{name: "unlimited", limits: nil, rows: newTestRows(5), accepted: 5, truncated: false},
+		{name: "zero limits", limits: NewResultLimits(0, 0), rows: newTestRows(5), accepted: 5, truncated: false},
+		{name: "rows under cap", limits: NewResultLimits(5, 0), rows: newTestRows(5), accepted: 5, truncated: false},
+		{name: "rows over cap", limits: NewResultLimits(3, 0), rows: newTestRows(5), accepted: 3, truncated: true},
+		{name: "bytes exactly cap", limits: NewResultLimits(0, 30), rows: newTestRows(3), accepted: 3, truncated: false},
+		{name: "bytes over cap", limits: NewResultLimits(0, 25), rows: newTestRows(5), accepted: 2, truncated: true},
+		{name: "single row larger than cap", limits: NewResultLimits(0, 5), rows: newTestRows(1), accepted: 0, truncated: true},
+		{name: "rows cap reached first", limits: NewResultLimits(2, 1000), rows: newTestRows(5), accepted: 2, truncated: true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			limiter := NewRowLimiter(testCase.limits)
+			accepted := 0
+			for _, row := range testCase.rows {
+				rowInJSON, err := limiter.Accept(row)
+				if err != nil {
+					assert.True(t, errors.Is(err, ErrResultLimitReached))
+					break
+				}
+				assert.Equal(t, 10, len(rowInJSON))
+				accepted++
+			}
+			assert.Equal(t, testCase.accepted, accepted)
+			assert.Equal(t, testCase.truncated, limiter.IsTruncated())
+
+			// the truncated marker is set only when rows were dropped
+			result := RuntimeResult{}
+			limiter.MarkResult(&result)
+			_, hit := result.Extra[RESULT_EXTRA_FIELD_TRUNCATED]
+			assert.Equal(t, testCase.truncated, hit)
+			if testCase.truncated {
+				assert.Equal(t, testCase.limits, result.Extra[RESULT_EXTRA_FIELD_LIMITS])
+			}
+		})
+	}
+}
+
+func TestRowLimiterRejectAfterTruncation(t *testing.T) {
+	limiter := NewRowLimiter(NewResultLimits(0, 15))
+	_, err := limiter.Accept(map[string]interface{}{"id": "a"})
+	assert.Nil(t, err)
+	_, err = limiter.Accept(map[string]interface{}{"id": strings.Repeat("a", 10)})
+	assert.Equal(t, ErrResultLimitReached, err)
+
+	// the smaller row fitting in the remaining bytes is still rejected, so the result keeps the row order
+	_, err = limiter.Accept(map[string]interface{}{})
+	assert.Equal(t, ErrResultLimitReached, err)
+}
+
+func TestCollectStream(t *testing.T) {
+	testCases := []struct {
+		name         string
+		limits       *ResultLimits
+		emitted      []map[string]interface{}
+		returned     []map[string]interface{}
+		expectedRows int
+		truncated    bool
+	}{
+		{name: "unlimited", limits: nil, emitted: newTestRows(3), returned: newTestRows(2), expectedRows: 5, truncated: false},
+		{name: "emitted rows over cap", limits: NewResultLimits(2, 0), emitted: newTestRows(3), expectedRows: 2, truncated: true},
+		{name: "returned rows over cap", limits: NewResultLimits(4, 0), emitted: newTestRows(3), returned: newTestRows(2), expectedRows: 4, truncated: true},
+		{name: "bytes over cap", limits: NewResultLimits(0, 25), returned: newTestRows(3), expectedRows: 2, truncated: true},
+		{name: "within limits", limits: NewResultLimits(5, 50), emitted: newTestRows(2), returned: newTestRows(3), expectedRows: 5, truncated: false},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := ContextWithResultLimits(context.Background(), testCase.limits)
+			result, err := CollectStream(ctx, func(emit RowEmitter) (RuntimeResult, error) {
+				for _, row := range testCase.emitted {
+					if errInEmit := emit(row); errInEmit != nil {
+						// the connector stops fetching on the limits
+						assert.True(t, errors.Is(errInEmit, ErrResultLimitReached))
+						break
+					}
+				}
+				return RuntimeResult{Success: true, Rows: testCase.returned}, nil
+			})
+			assert.Nil(t, err)
+			assert.True(t, result.Success)
+			assert.Equal(t, testCase.expectedRows, len(result.Rows))
+			_, hit := result.Extra[RESULT_EXTRA_FIELD_TRUNCATED]
+			assert.Equal(t, testCase.truncated, hit)
+		})
+	}
+}
+
+func TestCollectStreamRunError(t *testing.T) {
+	errInRun := errors.New("connection refused")
+	_, err := CollectStream(context.Background(), func(emit RowEmitter) (RuntimeResult, error) {
+		return RuntimeResult{}, errInRun
+	})
+	assert.Equal(t, errInRun, err)
+}
+
+func TestEmitEachRow(t *testing.T) {
+	// the ErrResultLimitReached stops emitting and is swallowed
+	emitted := 0
+	err := EmitEachRow(newTestRows(5), func(row map[string]interface{}) error {
+		if emitted == 2 {
+			return ErrResultLimitReached
+		}
+		emitted++
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, emitted)
+
+	// the other errors are returned
+	errInEmit := errors.New("broken pipe")
+	err = EmitEachRow(newTestRows(5), func(row map[string]interface{}) error {
+		return errInEmit
+	})
+	assert.Equal(t, errInEmit, err)
+
+	assert.Nil(t, EmitEachRow(nil, func(row map[string]interface{}) error {
+		return errInEmit
+	}))
+}
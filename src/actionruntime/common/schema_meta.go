@@ -0,0 +1,196 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+const (
+	TABLE_KIND_TABLE             = "table"
+	TABLE_KIND_VIEW              = "view"
+	TABLE_KIND_MATERIALIZED_VIEW = "materializedView"
+	TABLE_KIND_FOREIGN_TABLE     = "foreignTable"
+)
+
+// SCHEMA_META_MAX_TABLES limit the tables fetched by meta info, avoid the huge warehouse blow up the response and the cache.
+const SCHEMA_META_MAX_TABLES = 5000
+
+// SchemaMeta is the detailed metadata of database, the schemas are the databases for mysql.
+type SchemaMeta struct {
+	Schemas   []*SchemaMetaSchema `json:"schemas"`
+	Truncated bool                `json:"truncated"`
+}
+
+type SchemaMetaSchema struct {
+	Name   string       `json:"name"`
+	Tables []*TableMeta `json:"tables"`
+}
+
+type TableMeta struct {
+	Name        string            `json:"name"`
+	Kind        string            `json:"kind"`
+	Columns     []*ColumnMeta     `json:"columns"`
+	PrimaryKey  []string          `json:"primaryKey"`
+	ForeignKeys []*ForeignKeyMeta `json:"foreignKeys"`
+	Indexes     []*IndexMeta      `json:"indexes"`
+}
+
+type ColumnMeta struct {
+	Name     string  `json:"name"`
+	DataType string  `json:"dataType"`
+	Nullable bool    `json:"nullable"`
+	Default  *string `json:"default"`
+}
+
+type ForeignKeyMeta struct {
+	Name              string   `json:"name"`
+	Columns           []string `json:"columns"`
+	ReferencedSchema  string   `json:"referencedSchema"`
+	ReferencedTable   string   `json:"referencedTable"`
+	ReferencedColumns []string `json:"referencedColumns"`
+}
+
+type IndexMeta struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique"`
+	Primary bool     `json:"primary"`
+}
+
+// ExportLegacySchema export the schema in the legacy format {"table": {"column": {"data_type": "type"}}} for the query editor autocomplete,
+// the tables in default schema are keyed by table name, and the others are keyed by "schema.table".
+// all tables are keyed by "schema.table" when the default schema is empty.
+func (schemaMeta *SchemaMeta) ExportLegacySchema(defaultSchema string) map[string]interface{} {
+	legacySchema := make(map[string]interface{})
+	for _, schema := range schemaMeta.Schemas {
+		for _, table := range schema.Tables {
+			columns := make(map[string]interface{}, len(table.Columns))
+			for _, column := range table.Columns {
+				columns[column.Name] = map[string]string{"data_type": column.DataType}
+			}
+			tableKey := schema.Name + "." + table.Name
+			if defaultSchema != "" && schema.Name == defaultSchema {
+				tableKey = table.Name
+			}
+			legacySchema[tableKey] = columns
+		}
+	}
+	return legacySchema
+}
+
+// SchemaMetaBuilder assemble the SchemaMeta from the rows of the catalog queries.
+// the tables should be added first, the columns, keys and indexes of the unknown table are ignored,
+// so the tables which are skipped by the limit or not accessible are not returned partially.
+type SchemaMetaBuilder struct {
+	schemaMeta  *SchemaMeta
+	schemas     map[string]*SchemaMetaSchema
+	tables      map[string]*TableMeta
+	foreignKeys map[string]*ForeignKeyMeta
+	indexes     map[string]*IndexMeta
+}
+
+func NewSchemaMetaBuilder() *SchemaMetaBuilder {
+	return &SchemaMetaBuilder{
+		schemaMeta:  &SchemaMeta{Schemas: make([]*SchemaMetaSchema, 0)},
+		schemas:     make(map[string]*SchemaMetaSchema),
+		tables:      make(map[string]*TableMeta),
+		foreignKeys: make(map[string]*ForeignKeyMeta),
+		indexes:     make(map[string]*IndexMeta),
+	}
+}
+
+func schemaMetaTableKey(schemaName string, tableName string) string {
+	return schemaName + "\x00" + tableName
+}
+
+// AddTable add the table, return false when the table limit reached.
+func (builder *SchemaMetaBuilder) AddTable(schemaName string, tableName string, kind string) bool {
+	if len(builder.tables) >= SCHEMA_META_MAX_TABLES {
+		builder.schemaMeta.Truncated = true
+		return false
+	}
+	schema, hit := builder.schemas[schemaName]
+	if !hit {
+		schema = &SchemaMetaSchema{Name: schemaName, Tables: make([]*TableMeta, 0)}
+		builder.schemas[schemaName] = schema
+		builder.schemaMeta.Schemas = append(builder.schemaMeta.Schemas, schema)
+	}
+	table := &TableMeta{
+		Name:        tableName,
+		Kind:        kind,
+		Columns:     make([]*ColumnMeta, 0),
+		PrimaryKey:  make([]string, 0),
+		ForeignKeys: make([]*ForeignKeyMeta, 0),
+		Indexes:     make([]*IndexMeta, 0),
+	}
+	schema.Tables = append(schema.Tables, table)
+	builder.tables[schemaMetaTableKey(schemaName, tableName)] = table
+	return true
+}
+
+// AddColumn add the column, the columns should be added in ordinal position.
+func (builder *SchemaMetaBuilder) AddColumn(schemaName string, tableName string, column *ColumnMeta) {
+	if table, hit := builder.tables[schemaMetaTableKey(schemaName, tableName)]; hit {
+		table.Columns = append(table.Columns, column)
+	}
+}
+
+// AddPrimaryKeyColumn add the primary key column, the columns should be added in key order.
+func (builder *SchemaMetaBuilder) AddPrimaryKeyColumn(schemaName string, tableName string, columnName string) {
+	if table, hit := builder.tables[schemaMetaTableKey(schemaName, tableName)]; hit {
+		table.PrimaryKey = append(table.PrimaryKey, columnName)
+	}
+}
+
+// AddForeignKeyColumn add the column pair of foreign key, the composite foreign key is added column by column in key order.
+func (builder *SchemaMetaBuilder) AddForeignKeyColumn(schemaName string, tableName string, foreignKeyName string, columnName string, referencedSchema string, referencedTable string, referencedColumn string) {
+	table, hit := builder.tables[schemaMetaTableKey(schemaName, tableName)]
+	if !hit {
+		return
+	}
+	key := schemaMetaTableKey(schemaName, tableName) + "\x00" + foreignKeyName
+	foreignKey, hit := builder.foreignKeys[key]
+	if !hit {
+		foreignKey = &ForeignKeyMeta{
+			Name:              foreignKeyName,
+			Columns:           make([]string, 0),
+			ReferencedSchema:  referencedSchema,
+			ReferencedTable:   referencedTable,
+			ReferencedColumns: make([]string, 0),
+		}
+		builder.foreignKeys[key] = foreignKey
+		table.ForeignKeys = append(table.ForeignKeys, foreignKey)
+	}
+	foreignKey.Columns = append(foreignKey.Columns, columnName)
+	foreignKey.ReferencedColumns = append(foreignKey.ReferencedColumns, referencedColumn)
+}
+
+// AddIndexColumn add the column of index, the columns should be added in key order.
+// the column is the expression for the functional index.
+func (builder *SchemaMetaBuilder) AddIndexColumn(schemaName string, tableName string, indexName string, unique bool, primary bool, columnName string) {
+	table, hit := builder.tables[schemaMetaTableKey(schemaName, tableName)]
+	if !hit {
+		return
+	}
+	key := schemaMetaTableKey(schemaName, tableName) + "\x00" + indexName
+	index, hit := builder.indexes[key]
+	if !hit {
+		index = &IndexMeta{Name: indexName, Columns: make([]string, 0), Unique: unique, Primary: primary}
+		builder.indexes[key] = index
+		table.Indexes = append(table.Indexes, index)
+	}
+	index.Columns = append(index.Columns, columnName)
+}
+
+func (builder *SchemaMetaBuilder) Build() *SchemaMeta {
+	return builder.schemaMeta
+}
@@ -0,0 +1,87 @@
+package common
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaMetaBuilderCompositeForeignKey(t *testing.T) {
+	builder := NewSchemaMetaBuilder()
+	assert.True(t, builder.AddTable("public", "orders", TABLE_KIND_TABLE))
+	builder.AddColumn("public", "orders", &ColumnMeta{Name: "tenant_id", DataType: "integer"})
+	builder.AddColumn("public", "orders", &ColumnMeta{Name: "user_id", DataType: "integer"})
+	builder.AddPrimaryKeyColumn("public", "orders", "tenant_id")
+	builder.AddForeignKeyColumn("public", "orders", "orders_user_fk", "tenant_id", "public", "users", "tenant_id")
+	builder.AddForeignKeyColumn("public", "orders", "orders_user_fk", "user_id", "public", "users", "id")
+	builder.AddIndexColumn("public", "orders", "orders_pkey", true, true, "tenant_id")
+	schemaMeta := builder.Build()
+
+	assert.Equal(t, 1, len(schemaMeta.Schemas))
+	table := schemaMeta.Schemas[0].Tables[0]
+	assert.Equal(t, []string{"tenant_id"}, table.PrimaryKey)
+	assert.Equal(t, 1, len(table.ForeignKeys), "the columns of composite foreign key should be grouped in one key")
+	assert.Equal(t, []string{"tenant_id", "user_id"}, table.ForeignKeys[0].Columns)
+	assert.Equal(t, []string{"tenant_id", "id"}, table.ForeignKeys[0].ReferencedColumns)
+	assert.Equal(t, "users", table.ForeignKeys[0].ReferencedTable)
+	assert.True(t, table.Indexes[0].Primary)
+	assert.False(t, schemaMeta.Truncated)
+}
+
+func TestSchemaMetaBuilderUnknownTable(t *testing.T) {
+	builder := NewSchemaMetaBuilder()
+	builder.AddTable("public", "users", TABLE_KIND_TABLE)
+	builder.AddColumn("public", "orders", &ColumnMeta{Name: "id", DataType: "integer"})
+	builder.AddPrimaryKeyColumn("public", "orders", "id")
+	builder.AddForeignKeyColumn("public", "orders", "orders_user_fk", "user_id", "public", "users", "id")
+	builder.AddIndexColumn("other", "users", "users_pkey", true, true, "id")
+	schemaMeta := builder.Build()
+
+	assert.Equal(t, 1, len(schemaMeta.Schemas))
+	assert.Equal(t, 1, len(schemaMeta.Schemas[0].Tables), "the unknown table should not be added partially")
+	table := schemaMeta.Schemas[0].Tables[0]
+	assert.Equal(t, 0, len(table.Columns))
+	assert.Equal(t, 0, len(table.Indexes), "the table with same name in other schema is not the same table")
+}
+
+func TestSchemaMetaBuilderTruncation(t *testing.T) {
+	builder := NewSchemaMetaBuilder()
+	for i := 0; i < SCHEMA_META_MAX_TABLES; i++ {
+		assert.True(t, builder.AddTable("public", "table_"+strconv.Itoa(i), TABLE_KIND_TABLE))
+	}
+	assert.False(t, builder.Build().Truncated)
+
+	// the one more table detects the truncation, and its columns are ignored
+	assert.False(t, builder.AddTable("public", "overflow", TABLE_KIND_TABLE))
+	builder.AddColumn("public", "overflow", &ColumnMeta{Name: "id", DataType: "integer"})
+	schemaMeta := builder.Build()
+	assert.True(t, schemaMeta.Truncated)
+	assert.Equal(t, SCHEMA_META_MAX_TABLES, len(schemaMeta.Schemas[0].Tables))
+	_, hit := schemaMeta.ExportLegacySchema("public")["overflow"]
+	assert.False(t, hit)
+}
+
+func TestSchemaMetaExportLegacySchema(t *testing.T) {
+	builder := NewSchemaMetaBuilder()
+	builder.AddTable("public", "users", TABLE_KIND_TABLE)
+	builder.AddTable("sales", "orders", TABLE_KIND_VIEW)
+	builder.AddColumn("public", "users", &ColumnMeta{Name: "id", DataType: "integer"})
+	builder.AddColumn("sales", "orders", &ColumnMeta{Name: "amount", DataType: "numeric"})
+	schemaMeta := builder.Build()
+
+	// the tables in default schema are keyed by table name
+	legacySchema := schemaMeta.ExportLegacySchema("public")
+	assert.Equal(t, map[string]interface{}{"id": map[string]string{"data_type": "integer"}}, legacySchema["users"])
+	assert.Equal(t, map[string]interface{}{"amount": map[string]string{"data_type": "numeric"}}, legacySchema["sales.orders"])
+	_, hit := legacySchema["public.users"]
+	assert.False(t, hit)
+
+	// all tables are keyed by "schema.table" without default schema
+	legacySchema = schemaMeta.ExportLegacySchema("")
+	assert.Equal(t, 2, len(legacySchema))
+	_, hit = legacySchema["public.users"]
+	assert.True(t, hit)
+	_, hit = legacySchema["sales.orders"]
+	assert.True(t, hit)
+}
@@ -14,10 +14,17 @@
 
 package common
 
+import (
+	"context"
+)
+
+// DataConnector is the interface every action runtime implemented.
+// the ctx passed to TestConnection, GetMetaInfo and Run carries the caller's cancellation and deadline,
+// connectors should pass it to the underlying database or http client, so abandoned queries are cancelled on target.
 type DataConnector interface {
 	ValidateResourceOptions(resourceOptions map[string]interface{}) (ValidateResult, error)
 	ValidateActionTemplate(actionOptions map[string]interface{}) (ValidateResult, error)
-	TestConnection(resourceOptions map[string]interface{}) (ConnectionResult, error)
-	GetMetaInfo(resourceOptions map[string]interface{}) (MetaInfoResult, error)
-	Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (RuntimeResult, error)
+	TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (ConnectionResult, error)
+	GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (MetaInfoResult, error)
+	Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (RuntimeResult, error)
 }
@@ -0,0 +1,378 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	SSH_TUNNEL_AUTH_METHOD_PASSWORD    = "password"
+	SSH_TUNNEL_AUTH_METHOD_PRIVATE_KEY = "privateKey"
+	DEFAULT_SSH_TUNNEL_PORT            = "22"
+	DEFAULT_SSH_TUNNEL_DIAL_TIMEOUT    = 10 * time.Second
+	// the tunnel outlives the pooled connections dialed through it, so it must not be evicted before the connection pool.
+	DEFAULT_SSH_TUNNEL_IDLE_TIMEOUT    = 2 * DEFAULT_CONNECTION_POOL_IDLE_TIMEOUT
+	DEFAULT_SSH_TUNNEL_EVICTION_PERIOD = DEFAULT_CONNECTION_POOL_EVICTION_PERIOD
+)
+
+// SSHTunnelOptions is the "sshTunnel" option block of TCP based resources,
+// the connector dials the database through the bastion host when it is enabled.
+type SSHTunnelOptions struct {
+	Enabled                 bool
+	Host                    string `validate:"required_if=Enabled true"`
+	Port                    string `validate:"omitempty,numeric"`
+	Username                string `validate:"required_if=Enabled true"`
+	AuthMethod              string `validate:"required_if=Enabled true,omitempty,oneof=password privateKey"`
+	Password                string `validate:"required_if=Enabled true AuthMethod password"`
+	PrivateKey              string `validate:"required_if=Enabled true AuthMethod privateKey"`
+	Passphrase              string
+	KnownHosts              string `validate:"required_if=Enabled true SkipHostKeyVerification false"`
+	SkipHostKeyVerification bool
+}
+
+// WithSSHTunnelSecretFields append the secret fields of "sshTunnel" option block to the connector own secret fields.
+func WithSSHTunnelSecretFields(fields ...string) []string {
+	return append(fields, "sshTunnel.password", "sshTunnel.privateKey", "sshTunnel.passphrase")
+}
+
+func (o *SSHTunnelOptions) ExportAddress() string {
+	port := o.Port
+	if port == "" {
+		port = DEFAULT_SSH_TUNNEL_PORT
+	}
+	return net.JoinHostPort(o.Host, port)
+}
+
+func (o *SSHTunnelOptions) ExportClientConfig() (*ssh.ClientConfig, error) {
+	var authMethod ssh.AuthMethod
+	switch o.AuthMethod {
+	case SSH_TUNNEL_AUTH_METHOD_PASSWORD:
+		authMethod = ssh.Password(o.Password)
+	case SSH_TUNNEL_AUTH_METHOD_PRIVATE_KEY:
+		var signer ssh.Signer
+		var errInParse error
+		if o.Passphrase != "" {
+			signer, errInParse = ssh.ParsePrivateKeyWithPassphrase([]byte(o.PrivateKey), []byte(o.Passphrase))
+		} else {
+			signer, errInParse = ssh.ParsePrivateKey([]byte(o.PrivateKey))
+		}
+		if errInParse != nil {
+			return nil, errors.New("parse ssh tunnel private key failed: " + errInParse.Error())
+		}
+		authMethod = ssh.PublicKeys(signer)
+	default:
+		return nil, errors.New("unsupported ssh tunnel auth method: " + o.AuthMethod)
+	}
+	hostKeyCallback, errInBuildCallback := o.exportHostKeyCallback()
+	if errInBuildCallback != nil {
+		return nil, errInBuildCallback
+	}
+	return &ssh.ClientConfig{
+		User:            o.Username,
+		Auth:            []ssh.AuthMethod{authMethod},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         DEFAULT_SSH_TUNNEL_DIAL_TIMEOUT,
+	}, nil
+}
+
+// exportHostKeyCallback verify the bastion host key by the known_hosts lines in options.
+func (o *SSHTunnelOptions) exportHostKeyCallback() (ssh.HostKeyCallback, error) {
+	if o.SkipHostKeyVerification {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+	if o.KnownHosts == "" {
+		return nil, errors.New("ssh tunnel known hosts is required for host key verification")
+	}
+	// knownhosts only reads files, it loads the file eagerly so the temp file can be removed at once
+	knownHostsFile, errInCreate := os.CreateTemp("", "illa-known-hosts-*")
+	if errInCreate != nil {
+		return nil, errInCreate
+	}
+	defer os.Remove(knownHostsFile.Name())
+	_, errInWrite := knownHostsFile.WriteString(o.KnownHosts + "\n")
+	knownHostsFile.Close()
+	if errInWrite != nil {
+		return nil, errInWrite
+	}
+	hostKeyCallback, errInParse := knownhosts.New(knownHostsFile.Name())
+	if errInParse != nil {
+		return nil, errors.New("parse ssh tunnel known hosts failed: " + errInParse.Error())
+	}
+	return hostKeyCallback, nil
+}
+
+// SSHTunnelDialFunc dial the address through the bastion host, it is passed to the dialer hook of database driver,
+// so the tunneled connections are only reachable by the connector, no local port is opened.
+type SSHTunnelDialFunc func(ctx context.Context, network string, address string) (net.Conn, error)
+
+// DialContext make SSHTunnelDialFunc a context dialer, like the dialer of mongo and mssql drivers.
+func (dial SSHTunnelDialFunc) DialContext(ctx context.Context, network string, address string) (net.Conn, error) {
+	return dial(ctx, network, address)
+}
+
+// sshTunnel dial the connections through the ssh client of bastion host.
+// the ssh client is redialed when it was broken, the dialed connections are counted for the idle eviction.
+type sshTunnel struct {
+	mutex        sync.Mutex
+	options      SSHTunnelOptions
+	clientConfig *ssh.ClientConfig
+	client       *ssh.Client
+	activeConns  int
+	lastUsedAt   time.Time
+}
+
+func (t *sshTunnel) getClient() (*ssh.Client, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.client != nil {
+		return t.client, nil
+	}
+	client, errInDial := ssh.Dial("tcp", t.options.ExportAddress(), t.clientConfig)
+	if errInDial != nil {
+		return nil, errors.New("ssh tunnel dial bastion host failed: " + errInDial.Error())
+	}
+	t.client = client
+	go func() {
+		client.Wait()
+		t.resetClient(client)
+	}()
+	return client, nil
+}
+
+func (t *sshTunnel) resetClient(client *ssh.Client) {
+	t.mutex.Lock()
+	if t.client == client {
+		t.client = nil
+	}
+	t.mutex.Unlock()
+	client.Close()
+}
+
+func (t *sshTunnel) dialAddress(address string) (net.Conn, error) {
+	client, errInGetClient := t.getClient()
+	if errInGetClient != nil {
+		return nil, errInGetClient
+	}
+	remoteConn, errInDial := client.Dial("tcp", address)
+	if errInDial == nil {
+		return remoteConn, nil
+	}
+	// the ssh connection may be broken silently, redial the bastion host once
+	t.resetClient(client)
+	client, errInGetClient = t.getClient()
+	if errInGetClient != nil {
+		return nil, errInGetClient
+	}
+	return client.Dial("tcp", address)
+}
+
+// dial the address through the bastion host, the ssh client does not support context, so the dialing is abandoned when ctx done.
+func (t *sshTunnel) dial(ctx context.Context, network string, address string) (net.Conn, error) {
+	if network != "tcp" && network != "tcp4" && network != "tcp6" {
+		return nil, errors.New("ssh tunnel does not support network: " + network)
+	}
+	type dialResult struct {
+		conn net.Conn
+		err  error
+	}
+	dialed := make(chan dialResult, 1)
+	go func() {
+		conn, errInDial := t.dialAddress(address)
+		dialed <- dialResult{conn: conn, err: errInDial}
+	}()
+	select {
+	case result := <-dialed:
+		if result.err != nil {
+			return nil, errors.New("ssh tunnel dial " + address + " failed: " + result.err.Error())
+		}
+		t.touch(1)
+		return &sshTunnelConn{Conn: result.conn, tunnel: t}, nil
+	case <-ctx.Done():
+		go func() {
+			if result := <-dialed; result.conn != nil {
+				result.conn.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+func (t *sshTunnel) touch(activeConnsDelta int) {
+	t.mutex.Lock()
+	t.activeConns += activeConnsDelta
+	t.lastUsedAt = time.Now()
+	t.mutex.Unlock()
+}
+
+func (t *sshTunnel) isIdleSince(deadline time.Time) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.activeConns == 0 && t.lastUsedAt.Before(deadline)
+}
+
+func (t *sshTunnel) close() {
+	t.mutex.Lock()
+	client := t.client
+	t.client = nil
+	t.mutex.Unlock()
+	if client != nil {
+		client.Close()
+	}
+}
+
+// sshTunnelConn is the connection dialed through tunnel, which is counted as active until closed.
+type sshTunnelConn struct {
+	net.Conn
+	tunnel    *sshTunnel
+	closeOnce sync.Once
+}
+
+func (conn *sshTunnelConn) Close() error {
+	conn.closeOnce.Do(func() { conn.tunnel.touch(-1) })
+	return conn.Conn.Close()
+}
+
+// SSHTunnelManager keep the opened ssh tunnels, keyed by the tunnel options.
+// the tunnels are shared by all connections through the same bastion host, and evicted when no connection used them since idleTimeout.
+type SSHTunnelManager struct {
+	mutex       sync.Mutex
+	tunnels     map[string]*sshTunnel
+	idleTimeout time.Duration
+}
+
+var sshTunnelManager *SSHTunnelManager
+var sshTunnelManagerOnce sync.Once
+
+func GetSSHTunnelManager() *SSHTunnelManager {
+	sshTunnelManagerOnce.Do(func() {
+		sshTunnelManager = NewSSHTunnelManager(DEFAULT_SSH_TUNNEL_IDLE_TIMEOUT)
+		go sshTunnelManager.runEviction(DEFAULT_SSH_TUNNEL_EVICTION_PERIOD)
+	})
+	return sshTunnelManager
+}
+
+func NewSSHTunnelManager(idleTimeout time.Duration) *SSHTunnelManager {
+	return &SSHTunnelManager{
+		tunnels:     make(map[string]*sshTunnel),
+		idleTimeout: idleTimeout,
+	}
+}
+
+// NewDialFunc return the dial func through the bastion host when ssh tunnel enabled, otherwise nil (dial directly).
+// the bastion host is connected eagerly, so the ssh errors are reported instead of the database driver errors.
+// the dial func outlives the tunnel, it reopens the tunnel after the idle eviction.
+func (m *SSHTunnelManager) NewDialFunc(options SSHTunnelOptions) (SSHTunnelDialFunc, error) {
+	if !options.Enabled {
+		return nil, nil
+	}
+	key, errInBuildKey := options.ExportKey()
+	if errInBuildKey != nil {
+		return nil, errInBuildKey
+	}
+	tunnel, errInAcquire := m.acquire(key, options)
+	if errInAcquire != nil {
+		return nil, errInAcquire
+	}
+	tunnel.touch(0)
+	if _, errInGetClient := tunnel.getClient(); errInGetClient != nil {
+		return nil, errInGetClient
+	}
+	return func(ctx context.Context, network string, address string) (net.Conn, error) {
+		tunnel, errInAcquire := m.acquire(key, options)
+		if errInAcquire != nil {
+			return nil, errInAcquire
+		}
+		return tunnel.dial(ctx, network, address)
+	}, nil
+}
+
+func (m *SSHTunnelManager) acquire(key string, options SSHTunnelOptions) (*sshTunnel, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if tunnel, hit := m.tunnels[key]; hit {
+		return tunnel, nil
+	}
+	tunnel, errInOpen := openSSHTunnel(options)
+	if errInOpen != nil {
+		return nil, errInOpen
+	}
+	m.tunnels[key] = tunnel
+	return tunnel, nil
+}
+
+// EvictIdle close the tunnels which have no active connection since idleTimeout.
+func (m *SSHTunnelManager) EvictIdle() {
+	m.mutex.Lock()
+	idleTunnels := make([]*sshTunnel, 0)
+	deadline := time.Now().Add(-m.idleTimeout)
+	for key, tunnel := range m.tunnels {
+		if !tunnel.isIdleSince(deadline) {
+			continue
+		}
+		delete(m.tunnels, key)
+		idleTunnels = append(idleTunnels, tunnel)
+	}
+	m.mutex.Unlock()
+	for _, tunnel := range idleTunnels {
+		tunnel.close()
+	}
+}
+
+func (m *SSHTunnelManager) runEviction(period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for range ticker.C {
+		m.EvictIdle()
+	}
+}
+
+func openSSHTunnel(options SSHTunnelOptions) (*sshTunnel, error) {
+	clientConfig, errInBuildConfig := options.ExportClientConfig()
+	if errInBuildConfig != nil {
+		return nil, errInBuildConfig
+	}
+	return &sshTunnel{
+		options:      options,
+		clientConfig: clientConfig,
+		lastUsedAt:   time.Now(),
+	}, nil
+}
+
+// ExportKey return the hash of options, which identifies the tunnel.
+func (o *SSHTunnelOptions) ExportKey() (string, error) {
+	optionsInJSON, errInMarshal := json.Marshal(o)
+	if errInMarshal != nil {
+		return "", errInMarshal
+	}
+	optionsHash := sha256.Sum256(optionsInJSON)
+	return hex.EncodeToString(optionsHash[:]), nil
+}
+
+// NewSSHTunnelDialFunc is the NewDialFunc of the shared SSHTunnelManager.
+func NewSSHTunnelDialFunc(options SSHTunnelOptions) (SSHTunnelDialFunc, error) {
+	return GetSSHTunnelManager().NewDialFunc(options)
+}
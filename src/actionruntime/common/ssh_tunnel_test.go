@@ -0,0 +1,191 @@
+package common
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// startTestSSHServer start a bastion host which forwards the direct-tcpip channels, it returns the address and the host key.
+func startTestSSHServer(t *testing.T) (string, ssh.PublicKey) {
+	_, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	assert.Nil(t, err)
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == "illa" && string(password) == "secret" {
+				return nil, nil
+			}
+			return nil, io.EOF
+		},
+	}
+	config.AddHostKey(signer)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestSSHConn(conn, config)
+		}
+	}()
+	return listener.Addr().String(), signer.PublicKey()
+}
+
+func serveTestSSHConn(conn net.Conn, config *ssh.ServerConfig) {
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+	for newChannel := range channels {
+		var target struct {
+			DestAddr string
+			DestPort uint32
+			OrigAddr string
+			OrigPort uint32
+		}
+		if newChannel.ChannelType() != "direct-tcpip" || ssh.Unmarshal(newChannel.ExtraData(), &target) != nil {
+			newChannel.Reject(ssh.UnknownChannelType, "unsupported channel")
+			continue
+		}
+		targetConn, err := net.Dial("tcp", net.JoinHostPort(target.DestAddr, strconv.Itoa(int(target.DestPort))))
+		if err != nil {
+			newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			targetConn.Close()
+			continue
+		}
+		go ssh.DiscardRequests(channelRequests)
+		go func() {
+			io.Copy(targetConn, channel)
+			targetConn.Close()
+		}()
+		go func() {
+			io.Copy(channel, targetConn)
+			channel.Close()
+		}()
+	}
+}
+
+// startTestEchoServer start the database stand-in, which echoes everything back.
+func startTestEchoServer(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(conn, conn)
+				conn.Close()
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func newTestSSHTunnelOptions(address string, hostKey ssh.PublicKey) SSHTunnelOptions {
+	host, port, _ := net.SplitHostPort(address)
+	return SSHTunnelOptions{
+		Enabled:    true,
+		Host:       host,
+		Port:       port,
+		Username:   "illa",
+		AuthMethod: SSH_TUNNEL_AUTH_METHOD_PASSWORD,
+		Password:   "secret",
+		KnownHosts: knownhosts.Line([]string{address}, hostKey),
+	}
+}
+
+func assertEcho(t *testing.T, conn net.Conn) {
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	_, err := conn.Write([]byte("ping"))
+	assert.Nil(t, err)
+	reply := make([]byte, 4)
+	_, err = io.ReadFull(conn, reply)
+	assert.Nil(t, err)
+	assert.Equal(t, "ping", string(reply))
+}
+
+func TestSSHTunnelDial(t *testing.T) {
+	sshAddress, hostKey := startTestSSHServer(t)
+	echoAddress := startTestEchoServer(t)
+	manager := NewSSHTunnelManager(time.Hour)
+
+	// no dial func when the tunnel disabled
+	dial, err := manager.NewDialFunc(SSHTunnelOptions{})
+	assert.Nil(t, err)
+	assert.Nil(t, dial)
+
+	dial, err = manager.NewDialFunc(newTestSSHTunnelOptions(sshAddress, hostKey))
+	assert.Nil(t, err)
+	conn, err := dial(context.Background(), "tcp", echoAddress)
+	assert.Nil(t, err)
+	assertEcho(t, conn)
+	conn.Close()
+
+	_, err = dial(context.Background(), "unix", "/tmp/illa.sock")
+	assert.NotNil(t, err)
+}
+
+func TestSSHTunnelAuthentication(t *testing.T) {
+	sshAddress, hostKey := startTestSSHServer(t)
+	manager := NewSSHTunnelManager(time.Hour)
+
+	// the bastion host key is not the known one
+	_, otherPrivateKey, _ := ed25519.GenerateKey(rand.Reader)
+	otherSigner, _ := ssh.NewSignerFromKey(otherPrivateKey)
+	options := newTestSSHTunnelOptions(sshAddress, otherSigner.PublicKey())
+	_, err := manager.NewDialFunc(options)
+	assert.NotNil(t, err)
+
+	// wrong password
+	options = newTestSSHTunnelOptions(sshAddress, hostKey)
+	options.Password = "wrong"
+	_, err = manager.NewDialFunc(options)
+	assert.NotNil(t, err)
+}
+
+func TestSSHTunnelEviction(t *testing.T) {
+	sshAddress, hostKey := startTestSSHServer(t)
+	echoAddress := startTestEchoServer(t)
+	manager := NewSSHTunnelManager(0)
+	dial, err := manager.NewDialFunc(newTestSSHTunnelOptions(sshAddress, hostKey))
+	assert.Nil(t, err)
+
+	// the tunnel with active connection is kept
+	conn, err := dial(context.Background(), "tcp", echoAddress)
+	assert.Nil(t, err)
+	manager.EvictIdle()
+	assert.Equal(t, 1, len(manager.tunnels))
+	assertEcho(t, conn)
+
+	// the idle tunnel is evicted, and reopened by the dial func
+	conn.Close()
+	time.Sleep(time.Millisecond)
+	manager.EvictIdle()
+	assert.Equal(t, 0, len(manager.tunnels))
+	conn, err = dial(context.Background(), "tcp", echoAddress)
+	assert.Nil(t, err)
+	assertEcho(t, conn)
+	conn.Close()
+}
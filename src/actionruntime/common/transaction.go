@@ -0,0 +1,160 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	parser_sql "github.com/illacloud/builder-backend/src/utils/parser/sql"
+)
+
+// ActionTransaction is the database transaction opened on a resource, the actions run in it share the same connection.
+// the transaction must end with Commit or Rollback, which release the connection, calling Rollback after Commit is a no-op.
+type ActionTransaction interface {
+	Run(ctx context.Context, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (RuntimeResult, error)
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
+
+// TransactionalDataConnector is the connector which can run a group of actions in one database transaction.
+type TransactionalDataConnector interface {
+	DataConnector
+	BeginTransaction(ctx context.Context, resourceOptions map[string]interface{}) (ActionTransaction, error)
+}
+
+// PreparedQuery is the escaped SQL of an action with its args,
+// the IsReturningRows tells if the SQL should be queried (read statements and DML with returning clause) or executed.
+type PreparedQuery struct {
+	SQL             string
+	Args            []interface{}
+	IsReturningRows bool
+}
+
+func NewPreparedQuery(resourceType int, sql string, args []interface{}) (*PreparedQuery, error) {
+	statements, errInClassify := parser_sql.NewSQLClassifier(resourceType).Classify(sql)
+	if errInClassify != nil {
+		return nil, errInClassify
+	}
+	isReturningRows := len(statements) > 0
+	for _, statement := range statements {
+		if !statement.IsRead() && !(statement.IsDML() && statement.Returning) {
+			isReturningRows = false
+		}
+	}
+	return &PreparedQuery{
+		SQL:             sql,
+		Args:            args,
+		IsReturningRows: isReturningRows,
+	}, nil
+}
+
+// SQLActionTransaction is the ActionTransaction of the database/sql connectors.
+type SQLActionTransaction struct {
+	tx           *sql.Tx
+	release      func()
+	prepare      func(actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (*PreparedQuery, error)
+	convertError func(err error) error
+	done         bool
+}
+
+// BeginSQLActionTransaction begin the transaction on db with txOptions (nil for default), the release is called when transaction ended,
+// the prepare build the query of action, and the driver errors are converted by convertError.
+func BeginSQLActionTransaction(ctx context.Context, db *sql.DB, txOptions *sql.TxOptions, release func(), prepare func(actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (*PreparedQuery, error), convertError func(err error) error) (*SQLActionTransaction, error) {
+	tx, errInBegin := db.BeginTx(ctx, txOptions)
+	if errInBegin != nil {
+		release()
+		return nil, convertError(errInBegin)
+	}
+	return &SQLActionTransaction{
+		tx:           tx,
+		release:      release,
+		prepare:      prepare,
+		convertError: convertError,
+	}, nil
+}
+
+func (transaction *SQLActionTransaction) Run(ctx context.Context, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (RuntimeResult, error) {
+	result, err := transaction.run(ctx, actionOptions, rawActionOptions)
+	return result, transaction.convertError(err)
+}
+
+func (transaction *SQLActionTransaction) run(ctx context.Context, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (RuntimeResult, error) {
+	if transaction.done {
+		return RuntimeResult{Success: false}, sql.ErrTxDone
+	}
+	query, errInPrepare := transaction.prepare(actionOptions, rawActionOptions)
+	if errInPrepare != nil {
+		return RuntimeResult{Success: false}, errInPrepare
+	}
+	result := RuntimeResult{
+		Success: false,
+		Rows:    []map[string]interface{}{},
+		Extra:   map[string]interface{}{},
+	}
+	if query.IsReturningRows {
+		rows, errInQuery := transaction.tx.QueryContext(ctx, query.SQL, query.Args...)
+		if errInQuery != nil {
+			return result, errInQuery
+		}
+		defer rows.Close()
+		collector := NewRowCollector(ctx)
+		columns, errInEmit := EmitRows(rows, collector.Emit)
+		if errInEmit != nil {
+			return result, errInEmit
+		}
+		result.Rows = collector.ExportRows()
+		result.Columns = columns
+		result.Success = true
+		collector.MarkResult(&result)
+		return result, nil
+	}
+	execResult, errInExec := transaction.tx.ExecContext(ctx, query.SQL, query.Args...)
+	if errInExec != nil {
+		return result, errInExec
+	}
+	affectedRows, errInGetAffectedRows := execResult.RowsAffected()
+	if errInGetAffectedRows != nil {
+		return result, errInGetAffectedRows
+	}
+	result.Success = true
+	result.Extra[RESULT_EXTRA_FIELD_AFFECTED_ROWS] = affectedRows
+	result.Extra["message"] = fmt.Sprintf("Affeted %d rows.", affectedRows)
+	return result, nil
+}
+
+func (transaction *SQLActionTransaction) Commit(ctx context.Context) error {
+	if transaction.done {
+		return sql.ErrTxDone
+	}
+	transaction.done = true
+	defer transaction.release()
+	return transaction.convertError(transaction.tx.Commit())
+}
+
+func (transaction *SQLActionTransaction) Rollback(ctx context.Context) error {
+	if transaction.done {
+		return nil
+	}
+	transaction.done = true
+	defer transaction.release()
+	// the transaction was rolled back by driver when ctx cancelled
+	if errInRollback := transaction.tx.Rollback(); errInRollback != nil && !errors.Is(errInRollback, sql.ErrTxDone) {
+		return transaction.convertError(errInRollback)
+	}
+	return nil
+}
@@ -29,9 +29,11 @@ type ConnectionResult struct {
 	Success bool
 }
 
+// RuntimeResult is the action run result, the Columns is the ordered column schema of rows (for sql connectors).
 type RuntimeResult struct {
 	Success bool
 	Rows    []map[string]interface{}
+	Columns []*ColumnSchema `json:"Columns,omitempty"`
 	Extra   map[string]interface{}
 }
 
@@ -39,9 +41,12 @@ func (i *RuntimeResult) SetSuccess() {
 	i.Success = true
 }
 
+// MetaInfoResult is the meta info of resource, the Schema is the legacy table-columns map for the query editor autocomplete,
+// and the Detail is the detailed metadata returned by the sql connectors which support it.
 type MetaInfoResult struct {
 	Success bool
 	Schema  map[string]interface{}
+	Detail  *SchemaMeta `json:"Detail,omitempty"`
 }
 
 func (metaInfoResult *MetaInfoResult) ExportSchema() map[string]interface{} {
@@ -17,16 +17,38 @@ package common
 import (
 	"database/sql"
 	"database/sql/driver"
+	"errors"
+	"io"
 )
 
 func RetrieveToMap(rows *sql.Rows) ([]map[string]interface{}, error) {
-	columns, err := rows.Columns()
+	mapData := make([]map[string]interface{}, 0)
+	_, errInEmit := EmitRows(rows, func(row map[string]interface{}) error {
+		mapData = append(mapData, row)
+		return nil
+	})
+	return mapData, errInEmit
+}
+
+func RetrieveToMapByDriverRows(rows driver.Rows) ([]map[string]interface{}, error) {
+	mapData := make([]map[string]interface{}, 0)
+	_, errInEmit := EmitDriverRows(rows, func(row map[string]interface{}) error {
+		mapData = append(mapData, row)
+		return nil
+	})
+	return mapData, errInEmit
+}
+
+// EmitRows emit the query result row by row, the fetching stops quietly when the emitter reached the result limits.
+// the ordered column schemas are returned, and the rows are keyed by the disambiguated column names.
+func EmitRows(rows *sql.Rows, emit RowEmitter) ([]*ColumnSchema, error) {
+	columnTypes, err := rows.ColumnTypes()
 	if err != nil {
 		return nil, err
 	}
+	columns := NewColumnSchemasBySQLColumnTypes(columnTypes)
 	// count of columns
 	count := len(columns)
-	mapData := make([]map[string]interface{}, 0)
 
 	// value of every row
 	values := make([]interface{}, count)
@@ -40,61 +62,62 @@ func RetrieveToMap(rows *sql.Rows) ([]map[string]interface{}, error) {
 		}
 
 		// get query result
-		rows.Scan(valPointers...)
+		if err := rows.Scan(valPointers...); err != nil {
+			return columns, err
+		}
 
 		// value for every single row
-		entry := make(map[string]interface{})
-
-		for i, col := range columns {
-			var v interface{}
-
-			val := values[i]
-			b, ok := val.([]byte)
-			if ok {
-				// []byte to string
-				v = string(b)
-			} else {
-				v = val
+		entry := make(map[string]interface{}, count)
+
+		for i, column := range columns {
+			entry[column.Name] = convertBytesToString(values[i])
+		}
+		if err := emit(entry); err != nil {
+			if errors.Is(err, ErrResultLimitReached) {
+				return columns, nil
 			}
-			entry[col] = v
+			return columns, err
 		}
-		mapData = append(mapData, entry)
 	}
 
-	return mapData, nil
+	return columns, rows.Err()
 }
 
-func RetrieveToMapByDriverRows(rows driver.Rows) ([]map[string]interface{}, error) {
-	columns := rows.Columns()
-	mapData := make([]map[string]interface{}, 0)
+// EmitDriverRows is the EmitRows for the driver rows.
+func EmitDriverRows(rows driver.Rows, emit RowEmitter) ([]*ColumnSchema, error) {
+	columns := NewColumnSchemasByDriverRows(rows)
 
 	// value of every row
 	values := make([]driver.Value, len(columns))
 	// get all values
 	for {
 		errInFetchNextRows := rows.Next(values)
+		if errInFetchNextRows == io.EOF {
+			return columns, nil
+		}
 		if errInFetchNextRows != nil {
-			break
+			return columns, errInFetchNextRows
 		}
 
 		// value for every single row
-		entry := make(map[string]interface{})
-
-		for i, col := range columns {
-			var v interface{}
-
-			val := values[i]
-			b, ok := val.([]byte)
-			if ok {
-				// []byte to string
-				v = string(b)
-			} else {
-				v = val
+		entry := make(map[string]interface{}, len(columns))
+
+		for i, column := range columns {
+			entry[column.Name] = convertBytesToString(values[i])
+		}
+		if err := emit(entry); err != nil {
+			if errors.Is(err, ErrResultLimitReached) {
+				return columns, nil
 			}
-			entry[col] = v
+			return columns, err
 		}
-		mapData = append(mapData, entry)
 	}
+}
 
-	return mapData, nil
+// []byte to string
+func convertBytesToString(val interface{}) interface{} {
+	if b, ok := val.([]byte); ok {
+		return string(b)
+	}
+	return val
 }
@@ -0,0 +1,46 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package connectors import all built-in connectors, so they are registered to the connector registry.
+// an in-house connector registers itself by common.RegisterConnector in its init(), and is enabled by importing it here.
+package connectors
+
+import (
+	_ "github.com/illacloud/builder-backend/src/actionruntime/aiagent"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/airtable"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/appwrite"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/clickhouse"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/couchdb"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/dynamodb"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/elasticsearch"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/firebase"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/googlesheets"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/graphql"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/hfendpoint"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/huggingface"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/illadrive"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/mongodb"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/mssql"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/mysql"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/oracle"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/oracle9i"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/postgresql"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/redis"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/restapi"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/s3"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/serversidetransformer"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/smtp"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/snowflake"
+	_ "github.com/illacloud/builder-backend/src/actionruntime/trigger"
+)
@@ -63,7 +63,7 @@ func (c *Connector) ValidateActionTemplate(actionOptions map[string]interface{})
 	return common.ValidateResult{Valid: true}, nil
 }
 
-func (c *Connector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (c *Connector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	// get couchdb client
 	client, err := c.getClient(resourceOptions)
 	if err != nil {
@@ -71,14 +71,14 @@ func (c *Connector) TestConnection(resourceOptions map[string]interface{}) (comm
 	}
 
 	// test couchdb connection
-	if _, err := client.Version(context.Background()); err != nil {
+	if _, err := client.Version(ctx); err != nil {
 		return common.ConnectionResult{Success: false}, err
 	}
 
 	return common.ConnectionResult{Success: true}, nil
 }
 
-func (c *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (c *Connector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 	// get couchdb client
 	client, err := c.getClient(resourceOptions)
 	if err != nil {
@@ -86,7 +86,7 @@ func (c *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.
 	}
 
 	// get all databases
-	dbs, err := client.AllDBs(context.Background())
+	dbs, err := client.AllDBs(ctx)
 	if err != nil {
 		return common.MetaInfoResult{Success: false}, err
 	}
@@ -97,7 +97,7 @@ func (c *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.
 	}, nil
 }
 
-func (c *Connector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+func (c *Connector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
 	// get couchdb client
 	client, err := c.getClient(resourceOptions)
 	if err != nil {
@@ -138,7 +138,7 @@ func (c *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 		delete(c.actionOptions.Opts, "includeDocs")
 		c.actionOptions.Opts["descending_order"] = c.actionOptions.Opts["descendingOrder"]
 		delete(c.actionOptions.Opts, "descending_order")
-		resSet := db.AllDocs(context.TODO(), c.actionOptions.Opts)
+		resSet := db.AllDocs(ctx, c.actionOptions.Opts)
 		rows := make([]map[string]interface{}, 0)
 		for resSet.Next() {
 			item := make(map[string]interface{}, 3)
@@ -169,7 +169,7 @@ func (c *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 		if !ok {
 			return res, errors.New("doc id is required")
 		}
-		resSet := db.Get(context.TODO(), docID)
+		resSet := db.Get(ctx, docID)
 		var doc map[string]interface{}
 		resSet.ScanDoc(&doc)
 		resSet.Close()
@@ -180,7 +180,7 @@ func (c *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 		res.Rows = append(res.Rows, doc)
 		res.Success = true
 	case CREATE_METHOD:
-		docID, rev, err := db.CreateDoc(context.TODO(), c.actionOptions.Opts["record"])
+		docID, rev, err := db.CreateDoc(ctx, c.actionOptions.Opts["record"])
 		if err != nil {
 			return res, err
 		}
@@ -198,7 +198,7 @@ func (c *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 		}
 		opts.Record["_rev"] = opts.Rev
 
-		newRev, err := db.Put(context.TODO(), opts.ID, opts.Record)
+		newRev, err := db.Put(ctx, opts.ID, opts.Record)
 		if err != nil {
 			return res, err
 		}
@@ -213,13 +213,13 @@ func (c *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 		if !ok {
 			return res, errors.New("revision id is required")
 		}
-		if _, err := db.Delete(context.TODO(), docID, rev); err != nil {
+		if _, err := db.Delete(ctx, docID, rev); err != nil {
 			return res, err
 		}
 		res.Rows = append(res.Rows, map[string]interface{}{"message": fmt.Sprintf("deleted %s document", docID)})
 		res.Success = true
 	case FIND_METHOD:
-		resSet := db.Find(context.TODO(), c.actionOptions.Opts["mangoQuery"])
+		resSet := db.Find(ctx, c.actionOptions.Opts["mangoQuery"])
 		rows := make([]map[string]interface{}, 0)
 		for resSet.Next() {
 			var doc map[string]interface{}
@@ -272,7 +272,7 @@ func (c *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 			floatTmp := opts.Skip.(float64)
 			kOpts["skip"] = int(floatTmp)
 		}
-		resSet := db.Query(context.TODO(), "_design/"+viewURLSlice[1], "_view/"+viewURLSlice[3], kOpts)
+		resSet := db.Query(ctx, "_design/"+viewURLSlice[1], "_view/"+viewURLSlice[3], kOpts)
 		rows := make([]map[string]interface{}, 0)
 		for resSet.Next() {
 			item := make(map[string]interface{}, 3)
@@ -62,7 +62,7 @@ func (d *Connector) ValidateActionTemplate(actionOptions map[string]interface{})
 	return common.ValidateResult{Valid: true}, nil
 }
 
-func (d *Connector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (d *Connector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	// get dynamodb client
 	svc, err := d.getClientWithOptions(resourceOptions)
 	if err != nil {
@@ -70,14 +70,14 @@ func (d *Connector) TestConnection(resourceOptions map[string]interface{}) (comm
 	}
 
 	// test dynamodb client connection
-	if _, err := svc.ListTables(context.TODO(), nil); err != nil {
+	if _, err := svc.ListTables(ctx, nil); err != nil {
 		return common.ConnectionResult{Success: false}, err
 	}
 
 	return common.ConnectionResult{Success: true}, nil
 }
 
-func (d *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (d *Connector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 	// get dynamodb client
 	svc, err := d.getClientWithOptions(resourceOptions)
 	if err != nil {
@@ -85,7 +85,7 @@ func (d *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.
 	}
 
 	// get dynamodb tables
-	resp, err := svc.ListTables(context.TODO(), nil)
+	resp, err := svc.ListTables(ctx, nil)
 	if err != nil {
 		return common.MetaInfoResult{Success: false}, err
 	}
@@ -96,7 +96,7 @@ func (d *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.
 	}, nil
 }
 
-func (d *Connector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+func (d *Connector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
 	// get dynamodb client
 	svc, err := d.getClientWithOptions(resourceOptions)
 	if err != nil {
@@ -127,7 +127,7 @@ func (d *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 		if err != nil {
 			return res, err
 		}
-		out, err := svc.Query(context.TODO(), in)
+		out, err := svc.Query(ctx, in)
 		if err != nil {
 			return res, err
 		}
@@ -142,7 +142,7 @@ func (d *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 		if err != nil {
 			return res, err
 		}
-		out, err := svc.Scan(context.TODO(), in)
+		out, err := svc.Scan(ctx, in)
 		if err != nil {
 			return res, err
 		}
@@ -157,7 +157,7 @@ func (d *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 		if err != nil {
 			return res, err
 		}
-		if _, err := svc.PutItem(context.TODO(), in); err != nil {
+		if _, err := svc.PutItem(ctx, in); err != nil {
 			return res, err
 		}
 		res.Success = true
@@ -167,7 +167,7 @@ func (d *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 		if err != nil {
 			return res, err
 		}
-		out, err := svc.GetItem(context.TODO(), in)
+		out, err := svc.GetItem(ctx, in)
 		if err != nil {
 			return res, err
 		}
@@ -182,7 +182,7 @@ func (d *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 		if err != nil {
 			return res, err
 		}
-		if _, err := svc.UpdateItem(context.TODO(), in); err != nil {
+		if _, err := svc.UpdateItem(ctx, in); err != nil {
 			return res, err
 		}
 		res.Success = true
@@ -192,7 +192,7 @@ func (d *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 		if err != nil {
 			return res, err
 		}
-		if _, err := svc.DeleteItem(context.TODO(), in); err != nil {
+		if _, err := svc.DeleteItem(ctx, in); err != nil {
 			return res, err
 		}
 		res.Success = true
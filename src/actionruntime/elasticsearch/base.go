@@ -15,10 +15,36 @@
 package elasticsearch
 
 import (
+	"context"
+
 	es "github.com/elastic/go-elasticsearch/v8"
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
 	"github.com/mitchellh/mapstructure"
 )
 
+const (
+	CONNECTION_POOL_TYPE = "elasticsearch"
+)
+
+// getPooledConnectionWithOptions return the pooled elasticsearch client for resource, call release after the operation finished.
+func (e *Connector) getPooledConnectionWithOptions(ctx context.Context, resourceOptions map[string]interface{}) (*es.Client, func(), error) {
+	if err := mapstructure.Decode(resourceOptions, &e.ResourceOpts); err != nil {
+		return nil, nil, err
+	}
+	connection, release, err := common.GetConnectionPoolManager().Acquire(ctx, CONNECTION_POOL_TYPE, resourceOptions, func() (interface{}, func(), error) {
+		esClient, err := e.getConnectionWithOptions(resourceOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+		// the client keeps no connection besides the idle http keep-alive ones, nothing to close
+		return esClient, func() {}, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return connection.(*es.Client), release, nil
+}
+
 func (e *Connector) getConnectionWithOptions(resourceOptions map[string]interface{}) (*es.Client, error) {
 	if err := mapstructure.Decode(resourceOptions, &e.ResourceOpts); err != nil {
 		return nil, err
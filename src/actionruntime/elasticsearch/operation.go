@@ -18,15 +18,61 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 
 	"github.com/illacloud/builder-backend/src/actionruntime/common"
 
 	es "github.com/elastic/go-elasticsearch/v8"
 )
 
+const (
+	SEARCH_RESULT_FIELD_HITS  = "hits"
+	SEARCH_RESULT_FIELD_TOTAL = "total"
+)
+
 type OperationRunner struct {
+	ctx       context.Context
 	client    *es.Client
 	operation Action
+	emit      common.RowEmitter // emit the search hits in stream mode
+}
+
+// limitSearchHits keep the search hits within the result limits,
+// the hits are emitted one by one in stream mode, and only the total is kept in extra.
+func (o *OperationRunner) limitSearchHits(result map[string]interface{}) (common.RuntimeResult, error) {
+	hitsInfo, _ := result[SEARCH_RESULT_FIELD_HITS].(map[string]interface{})
+	hits, _ := hitsInfo[SEARCH_RESULT_FIELD_HITS].([]interface{})
+	emit := o.emit
+	var collector *common.RowCollector
+	if emit == nil {
+		collector = common.NewRowCollector(o.ctx)
+		emit = collector.Emit
+	}
+	for _, hit := range hits {
+		hitInMap, ok := hit.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if err := emit(hitInMap); err != nil {
+			if errors.Is(err, common.ErrResultLimitReached) {
+				break
+			}
+			return common.RuntimeResult{Success: false}, err
+		}
+	}
+	if collector == nil {
+		return common.RuntimeResult{Success: true, Rows: []map[string]interface{}{}, Extra: map[string]interface{}{SEARCH_RESULT_FIELD_TOTAL: hitsInfo[SEARCH_RESULT_FIELD_TOTAL]}}, nil
+	}
+	limitedHits := make([]interface{}, 0, len(collector.ExportRows()))
+	for _, hit := range collector.ExportRows() {
+		limitedHits = append(limitedHits, hit)
+	}
+	if hitsInfo != nil {
+		hitsInfo[SEARCH_RESULT_FIELD_HITS] = limitedHits
+	}
+	runtimeResult := common.RuntimeResult{Success: true, Rows: []map[string]interface{}{result}}
+	collector.MarkResult(&runtimeResult)
+	return runtimeResult, nil
 }
 
 func (o *OperationRunner) search() (common.RuntimeResult, error) {
@@ -42,7 +88,7 @@ func (o *OperationRunner) search() (common.RuntimeResult, error) {
 
 	// Perform the search request.
 	res, err := o.client.Search(
-		o.client.Search.WithContext(context.Background()),
+		o.client.Search.WithContext(o.ctx),
 		o.client.Search.WithIndex(o.operation.Index),
 		o.client.Search.WithBody(&buf),
 		o.client.Search.WithTrackTotalHits(true),
@@ -59,7 +105,7 @@ func (o *OperationRunner) search() (common.RuntimeResult, error) {
 		return common.RuntimeResult{Success: false}, err
 	}
 
-	return common.RuntimeResult{Success: true, Rows: []map[string]interface{}{result}}, nil
+	return o.limitSearchHits(result)
 }
 
 func (o *OperationRunner) insert() (common.RuntimeResult, error) {
@@ -78,7 +124,7 @@ func (o *OperationRunner) insert() (common.RuntimeResult, error) {
 		o.operation.Index,
 		"",
 		&buf,
-		o.client.Create.WithContext(context.Background()),
+		o.client.Create.WithContext(o.ctx),
 		o.client.Create.WithPretty(),
 	)
 	defer res.Body.Close()
@@ -101,7 +147,7 @@ func (o *OperationRunner) get() (common.RuntimeResult, error) {
 	res, err := o.client.Get(
 		o.operation.Index,
 		o.operation.ID,
-		o.client.Get.WithContext(context.Background()),
+		o.client.Get.WithContext(o.ctx),
 		o.client.Get.WithPretty(),
 	)
 	defer res.Body.Close()
@@ -134,7 +180,7 @@ func (o *OperationRunner) update() (common.RuntimeResult, error) {
 		o.operation.Index,
 		o.operation.ID,
 		&buf,
-		o.client.Update.WithContext(context.Background()),
+		o.client.Update.WithContext(o.ctx),
 		o.client.Update.WithPretty(),
 	)
 	defer res.Body.Close()
@@ -157,7 +203,7 @@ func (o *OperationRunner) delete() (common.RuntimeResult, error) {
 	res, err := o.client.Delete(
 		o.operation.Index,
 		o.operation.ID,
-		o.client.Delete.WithContext(context.Background()),
+		o.client.Delete.WithContext(o.ctx),
 		o.client.Delete.WithPretty(),
 	)
 	defer res.Body.Close()
@@ -58,7 +58,7 @@ func (e *Connector) ValidateActionTemplate(actionOptions map[string]interface{})
 	return common.ValidateResult{Valid: true}, nil
 }
 
-func (e *Connector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (e *Connector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	// get es connection
 	esClient, err := e.getConnectionWithOptions(resourceOptions)
 	if err != nil {
@@ -70,7 +70,7 @@ func (e *Connector) TestConnection(resourceOptions map[string]interface{}) (comm
 		Pretty: true,
 		Human:  true,
 	}
-	pingRes, err := pingReq.Do(context.TODO(), esClient)
+	pingRes, err := pingReq.Do(ctx, esClient)
 	if err != nil {
 		return common.ConnectionResult{Success: false}, err
 	}
@@ -79,19 +79,29 @@ func (e *Connector) TestConnection(resourceOptions map[string]interface{}) (comm
 	return common.ConnectionResult{Success: true}, nil
 }
 
-func (e *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (e *Connector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 	return common.MetaInfoResult{
 		Success: true,
 		Schema:  nil,
 	}, nil
 }
 
-func (e *Connector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+func (e *Connector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+	return e.run(ctx, resourceOptions, actionOptions, nil)
+}
+
+// RunStream run the action and emit the search hits one by one.
+func (e *Connector) RunStream(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}, emit common.RowEmitter) (common.RuntimeResult, error) {
+	return e.run(ctx, resourceOptions, actionOptions, emit)
+}
+
+func (e *Connector) run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, emit common.RowEmitter) (common.RuntimeResult, error) {
 	// get mysql connection
-	esClient, err := e.getConnectionWithOptions(resourceOptions)
+	esClient, releaseConnection, err := e.getPooledConnectionWithOptions(ctx, resourceOptions)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, errors.New("failed to get elasticsearch connection")
 	}
+	defer releaseConnection()
 
 	// format es operation
 	if err := mapstructure.Decode(actionOptions, &e.ActionOpts); err != nil {
@@ -99,7 +109,7 @@ func (e *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 	}
 
 	var result common.RuntimeResult
-	operationRunner := OperationRunner{client: esClient, operation: e.ActionOpts}
+	operationRunner := OperationRunner{ctx: ctx, client: esClient, operation: e.ActionOpts, emit: emit}
 	switch e.ActionOpts.Operation {
 	case SEARCH_OPERATION:
 		result, err = operationRunner.search()
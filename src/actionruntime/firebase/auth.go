@@ -38,6 +38,7 @@ const (
 )
 
 type AuthOperationRunner struct {
+	ctx       context.Context
 	client    *firebase.App
 	operation string
 	options   map[string]interface{}
@@ -105,7 +106,7 @@ func (a *AuthOperationRunner) query() (common.RuntimeResult, error) {
 	}
 
 	// build query action
-	ctx := context.TODO()
+	ctx := a.ctx
 	client, err := a.client.Auth(ctx)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
@@ -139,7 +140,7 @@ func (a *AuthOperationRunner) create() (common.RuntimeResult, error) {
 	}
 
 	// build create action
-	ctx := context.TODO()
+	ctx := a.ctx
 	client, err := a.client.Auth(ctx)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
@@ -174,7 +175,7 @@ func (a *AuthOperationRunner) update() (common.RuntimeResult, error) {
 	}
 
 	// build update action
-	ctx := context.TODO()
+	ctx := a.ctx
 	client, err := a.client.Auth(ctx)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
@@ -206,7 +207,7 @@ func (a *AuthOperationRunner) delete() (common.RuntimeResult, error) {
 	}
 
 	// build delete action
-	ctx := context.TODO()
+	ctx := a.ctx
 	client, err := a.client.Auth(ctx)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
@@ -229,7 +230,7 @@ func (a *AuthOperationRunner) list() (common.RuntimeResult, error) {
 	}
 
 	// build list action
-	ctx := context.TODO()
+	ctx := a.ctx
 	client, err := a.client.Auth(ctx)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
@@ -33,6 +33,7 @@ const (
 )
 
 type DBOperationRunner struct {
+	ctx       context.Context
 	client    *firebase.App
 	operation string
 	options   map[string]interface{}
@@ -74,7 +75,7 @@ func (d *DBOperationRunner) query() (common.RuntimeResult, error) {
 	}
 
 	// build query action
-	ctx := context.TODO()
+	ctx := d.ctx
 	client, err := d.client.Database(ctx)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
@@ -101,7 +102,7 @@ func (d *DBOperationRunner) set() (common.RuntimeResult, error) {
 	}
 
 	// build set action
-	ctx := context.TODO()
+	ctx := d.ctx
 	client, err := d.client.Database(ctx)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
@@ -127,7 +128,7 @@ func (d *DBOperationRunner) update() (common.RuntimeResult, error) {
 	}
 
 	// build update action
-	ctx := context.TODO()
+	ctx := d.ctx
 	client, err := d.client.Database(ctx)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
@@ -153,7 +154,7 @@ func (d *DBOperationRunner) append() (common.RuntimeResult, error) {
 	}
 
 	// build append action
-	ctx := context.TODO()
+	ctx := d.ctx
 	client, err := d.client.Database(ctx)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
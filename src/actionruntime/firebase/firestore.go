@@ -38,6 +38,7 @@ const (
 )
 
 type FirestoreOperationRunner struct {
+	ctx       context.Context
 	client    *firebase.App
 	operation string
 	options   map[string]interface{}
@@ -117,7 +118,7 @@ func (f *FirestoreOperationRunner) queryFirestore() (common.RuntimeResult, error
 	}
 
 	// build query firestore action
-	ctx := context.TODO()
+	ctx := f.ctx
 	client, err := f.client.Firestore(ctx)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
@@ -177,7 +178,7 @@ func (f *FirestoreOperationRunner) insertDoc() (common.RuntimeResult, error) {
 	}
 
 	// build insert document action
-	ctx := context.TODO()
+	ctx := f.ctx
 	client, err := f.client.Firestore(ctx)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
@@ -204,7 +205,7 @@ func (f *FirestoreOperationRunner) updateDoc() (common.RuntimeResult, error) {
 	}
 
 	// build update document action
-	ctx := context.TODO()
+	ctx := f.ctx
 	client, err := f.client.Firestore(ctx)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
@@ -231,7 +232,7 @@ func (f *FirestoreOperationRunner) getDocByID() (common.RuntimeResult, error) {
 	}
 
 	// build get document by id action
-	ctx := context.TODO()
+	ctx := f.ctx
 	client, err := f.client.Firestore(ctx)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
@@ -257,7 +258,7 @@ func (f *FirestoreOperationRunner) deleteDoc() (common.RuntimeResult, error) {
 	}
 
 	// build delete document action
-	ctx := context.TODO()
+	ctx := f.ctx
 	client, err := f.client.Firestore(ctx)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
@@ -280,7 +281,7 @@ func (f *FirestoreOperationRunner) getCollections() (common.RuntimeResult, error
 	}
 
 	// build get collections action
-	ctx := context.TODO()
+	ctx := f.ctx
 	client, err := f.client.Firestore(ctx)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
@@ -325,7 +326,7 @@ func (f *FirestoreOperationRunner) queryCollectionGroup() (common.RuntimeResult,
 	}
 
 	// build query collection group action
-	ctx := context.TODO()
+	ctx := f.ctx
 	client, err := f.client.Firestore(ctx)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
@@ -57,7 +57,7 @@ func (f *Connector) ValidateActionTemplate(actionOptions map[string]interface{})
 	return common.ValidateResult{Valid: true}, nil
 }
 
-func (f *Connector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (f *Connector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	// get firebase app
 	app, err := f.getConnectionWithOptions(resourceOptions)
 	if err != nil {
@@ -65,7 +65,6 @@ func (f *Connector) TestConnection(resourceOptions map[string]interface{}) (comm
 	}
 
 	// test connection
-	ctx := context.TODO()
 	firestoreClient, errF := app.Firestore(ctx)
 	_, errA := app.Auth(ctx)
 	_, errD := app.Database(ctx)
@@ -78,7 +77,7 @@ func (f *Connector) TestConnection(resourceOptions map[string]interface{}) (comm
 }
 
 // GetMetaInfo get the collections in firestore
-func (f *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (f *Connector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 	// get firebase app
 	app, err := f.getConnectionWithOptions(resourceOptions)
 	if err != nil {
@@ -86,7 +85,6 @@ func (f *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.
 	}
 
 	// get firestore client
-	ctx := context.TODO()
 	firestoreClient, err := app.Firestore(ctx)
 	if err != nil {
 		return common.MetaInfoResult{Success: false}, err
@@ -110,7 +108,7 @@ func (f *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.
 	}, nil
 }
 
-func (f *Connector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+func (f *Connector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
 	// get firebase app
 	app, err := f.getConnectionWithOptions(resourceOptions)
 	if err != nil {
@@ -125,13 +123,13 @@ func (f *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 	var result common.RuntimeResult
 	switch f.ActionOpts.Service {
 	case AUTH_SERVICE:
-		operationRunner := &AuthOperationRunner{client: app, operation: f.ActionOpts.Operation, options: f.ActionOpts.Options}
+		operationRunner := &AuthOperationRunner{ctx: ctx, client: app, operation: f.ActionOpts.Operation, options: f.ActionOpts.Options}
 		result, err = operationRunner.run()
 	case DATABASE_SERVICE:
-		operationRunner := &DBOperationRunner{client: app, operation: f.ActionOpts.Operation, options: f.ActionOpts.Options}
+		operationRunner := &DBOperationRunner{ctx: ctx, client: app, operation: f.ActionOpts.Operation, options: f.ActionOpts.Options}
 		result, err = operationRunner.run()
 	case FIRESTORE_SERVICE:
-		operationRunner := &FirestoreOperationRunner{client: app, operation: f.ActionOpts.Operation, options: f.ActionOpts.Options}
+		operationRunner := &FirestoreOperationRunner{ctx: ctx, client: app, operation: f.ActionOpts.Operation, options: f.ActionOpts.Options}
 		result, err = operationRunner.run()
 	default:
 		result.Success = false
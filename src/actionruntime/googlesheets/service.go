@@ -15,6 +15,7 @@
 package googlesheets
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sort"
@@ -31,6 +32,7 @@ type Connector struct {
 }
 
 type ActionRunner struct {
+	ctx     context.Context
 	opts    map[string]interface{}
 	service *sheets.Service
 }
@@ -63,11 +65,11 @@ func (g *Connector) ValidateActionTemplate(actionOptions map[string]interface{})
 	return common.ValidateResult{Valid: true}, nil
 }
 
-func (g *Connector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (g *Connector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	return common.ConnectionResult{Success: true}, nil
 }
 
-func (g *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (g *Connector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 	// get Google Drive service instance
 	driveService, err := g.getDriveWithOpts(resourceOptions)
 	if err != nil {
@@ -76,7 +78,7 @@ func (g *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.
 
 	// get all spreadsheet information
 	query := "mimeType='application/vnd.google-apps.spreadsheet'"
-	files, err := driveService.Files.List().Q(query).Do()
+	files, err := driveService.Files.List().Q(query).Context(ctx).Do()
 	if err != nil {
 		return common.MetaInfoResult{Success: false}, err
 	}
@@ -93,7 +95,7 @@ func (g *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.
 	}, nil
 }
 
-func (g *Connector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+func (g *Connector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
 	// get Google Sheets service instance
 	svc, err := g.getSheetsWithOpts(resourceOptions)
 	if err != nil {
@@ -113,6 +115,7 @@ func (g *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 
 	// build ActionRunner
 	actionRunner := &ActionRunner{
+		ctx:     ctx,
 		service: svc,
 		opts:    g.actionOptions.Opts,
 	}
@@ -169,7 +172,7 @@ func (g *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 		}
 		// get all spreadsheet information
 		query := "mimeType='application/vnd.google-apps.spreadsheet'"
-		files, err := driveService.Files.List().Q(query).Do()
+		files, err := driveService.Files.List().Q(query).Context(ctx).Do()
 		if err != nil {
 			res.Success = false
 			return res, err
@@ -213,7 +216,7 @@ func (r *ActionRunner) Read() (common.RuntimeResult, error) {
 		}
 
 		// get the total number of rows of a spreadsheet
-		resp, err := r.service.Spreadsheets.Get(readOpts.Spreadsheet).Do()
+		resp, err := r.service.Spreadsheets.Get(readOpts.Spreadsheet).Context(r.ctx).Do()
 		if err != nil {
 			return common.RuntimeResult{Success: false, Rows: []map[string]interface{}{0: {"message": err.Error()}}}, nil
 		}
@@ -246,7 +249,7 @@ func (r *ActionRunner) Read() (common.RuntimeResult, error) {
 
 	}
 
-	valuesResp, err := r.service.Spreadsheets.Values.Get(readOpts.Spreadsheet, readRange).Do()
+	valuesResp, err := r.service.Spreadsheets.Values.Get(readOpts.Spreadsheet, readRange).Context(r.ctx).Do()
 	if err != nil {
 		return common.RuntimeResult{Success: false, Rows: []map[string]interface{}{0: {"message": err.Error()}}}, nil
 	}
@@ -293,7 +296,7 @@ func (r *ActionRunner) Append() (common.RuntimeResult, error) {
 		sheet = appendOpts.SheetName
 	}
 	// get the last non-empty row in the sheet
-	resp, err := r.service.Spreadsheets.Values.Get(appendOpts.Spreadsheet, sheet).Do()
+	resp, err := r.service.Spreadsheets.Values.Get(appendOpts.Spreadsheet, sheet).Context(r.ctx).Do()
 	if err != nil {
 		return common.RuntimeResult{Success: false, Rows: []map[string]interface{}{0: {"message": err.Error()}}}, nil
 	}
@@ -342,7 +345,7 @@ func (r *ActionRunner) Append() (common.RuntimeResult, error) {
 		Values:         valuesToAppend,
 	}
 
-	appendResp, err := r.service.Spreadsheets.Values.Append(appendOpts.Spreadsheet, rangeToAppend, rb).ValueInputOption("RAW").Do()
+	appendResp, err := r.service.Spreadsheets.Values.Append(appendOpts.Spreadsheet, rangeToAppend, rb).ValueInputOption("RAW").Context(r.ctx).Do()
 	if err != nil {
 		return common.RuntimeResult{Success: false, Rows: []map[string]interface{}{0: {"message": err.Error()}}}, nil
 	}
@@ -380,7 +383,7 @@ func (r *ActionRunner) Update() (common.RuntimeResult, error) {
 
 	// get the header row in the sheet
 	readRange := fmt.Sprintf("%s!A1:Z1", updateOpts.SheetName)
-	resp, err := r.service.Spreadsheets.Values.Get(updateOpts.Spreadsheet, readRange).Do()
+	resp, err := r.service.Spreadsheets.Values.Get(updateOpts.Spreadsheet, readRange).Context(r.ctx).Do()
 	if err != nil {
 		return common.RuntimeResult{Success: false, Rows: []map[string]interface{}{0: {"message": err.Error()}}}, nil
 	}
@@ -410,7 +413,7 @@ func (r *ActionRunner) Update() (common.RuntimeResult, error) {
 			Values:         valuesToUpdate,
 		}
 
-		resp, err := r.service.Spreadsheets.Values.Update(updateOpts.Spreadsheet, updateOpts.A1Notation, rb).ValueInputOption("RAW").Do()
+		resp, err := r.service.Spreadsheets.Values.Update(updateOpts.Spreadsheet, updateOpts.A1Notation, rb).ValueInputOption("RAW").Context(r.ctx).Do()
 		res[0] = map[string]interface{}{
 			"spreadsheetId": resp.SpreadsheetId,
 			"updates": map[string]interface{}{
@@ -425,7 +428,7 @@ func (r *ActionRunner) Update() (common.RuntimeResult, error) {
 			return common.RuntimeResult{Success: false, Rows: []map[string]interface{}{0: {"message": err.Error()}}}, nil
 		}
 	} else if updateOpts.FilterType == "filter" {
-		return updateSpreadsheetByFilters(r.service, updateOpts.Spreadsheet, updateOpts.SheetName, updateOpts.Filters, updateOpts.Values)
+		return updateSpreadsheetByFilters(r.ctx, r.service, updateOpts.Spreadsheet, updateOpts.SheetName, updateOpts.Filters, updateOpts.Values)
 	}
 
 	return common.RuntimeResult{Success: true, Rows: res}, nil
@@ -449,7 +452,7 @@ func (r *ActionRunner) BulkUpdate() (common.RuntimeResult, error) {
 
 	// read the data from the sheet
 	readRange := fmt.Sprintf("%s!A1:Z", bulkUpdateOpts.SheetName)
-	resp, err := r.service.Spreadsheets.Values.Get(bulkUpdateOpts.Spreadsheet, readRange).Do()
+	resp, err := r.service.Spreadsheets.Values.Get(bulkUpdateOpts.Spreadsheet, readRange).Context(r.ctx).Do()
 	if err != nil {
 		return common.RuntimeResult{Success: false, Rows: []map[string]interface{}{0: {"message": err.Error()}}}, nil
 	}
@@ -533,7 +536,7 @@ func (r *ActionRunner) BulkUpdate() (common.RuntimeResult, error) {
 		Requests: updateRequests,
 	}
 
-	batchUpdateResp, err := r.service.Spreadsheets.BatchUpdate(bulkUpdateOpts.Spreadsheet, batchUpdate).Do()
+	batchUpdateResp, err := r.service.Spreadsheets.BatchUpdate(bulkUpdateOpts.Spreadsheet, batchUpdate).Context(r.ctx).Do()
 	if err != nil {
 		return common.RuntimeResult{Success: false, Rows: []map[string]interface{}{0: {"message": err.Error()}}}, nil
 	}
@@ -565,7 +568,7 @@ func (r *ActionRunner) DeleteSingleRow() (common.RuntimeResult, error) {
 
 	// get sheet id
 	var sheetID int64
-	spreadsheet, err := r.service.Spreadsheets.Get(deleteOpts.Spreadsheet).Do()
+	spreadsheet, err := r.service.Spreadsheets.Get(deleteOpts.Spreadsheet).Context(r.ctx).Do()
 	if err != nil {
 		return common.RuntimeResult{Success: false, Rows: []map[string]interface{}{0: {"message": err.Error()}}}, nil
 	}
@@ -595,7 +598,7 @@ func (r *ActionRunner) DeleteSingleRow() (common.RuntimeResult, error) {
 		Requests: requests,
 	}
 
-	batchUpdateResp, err := r.service.Spreadsheets.BatchUpdate(deleteOpts.Spreadsheet, batchUpdateRequest).Do()
+	batchUpdateResp, err := r.service.Spreadsheets.BatchUpdate(deleteOpts.Spreadsheet, batchUpdateRequest).Context(r.ctx).Do()
 	if err != nil {
 		return common.RuntimeResult{Success: false, Rows: []map[string]interface{}{0: {"message": err.Error()}}}, nil
 	}
@@ -627,7 +630,7 @@ func (r *ActionRunner) CreateASpreadsheet() (common.RuntimeResult, error) {
 		},
 	}
 
-	createdSpreadsheet, err := r.service.Spreadsheets.Create(newSpreadsheet).Do()
+	createdSpreadsheet, err := r.service.Spreadsheets.Create(newSpreadsheet).Context(r.ctx).Do()
 	if err != nil {
 		return common.RuntimeResult{Success: false, Rows: []map[string]interface{}{0: {"message": err.Error()}}}, nil
 	}
@@ -664,7 +667,7 @@ func (r *ActionRunner) CopyFromAToB() (common.RuntimeResult, error) {
 
 	// get sheet id
 	var sheetID int64
-	spreadsheet, err := r.service.Spreadsheets.Get(copyOpts.Spreadsheet).Do()
+	spreadsheet, err := r.service.Spreadsheets.Get(copyOpts.Spreadsheet).Context(r.ctx).Do()
 	if err != nil {
 		return common.RuntimeResult{Success: false, Rows: []map[string]interface{}{0: {"message": err.Error()}}}, nil
 	}
@@ -679,7 +682,7 @@ func (r *ActionRunner) CopyFromAToB() (common.RuntimeResult, error) {
 		DestinationSpreadsheetId: copyOpts.ToSpreadsheet,
 	}
 
-	copyResp, err := r.service.Spreadsheets.Sheets.CopyTo(copyOpts.Spreadsheet, sheetID, copySheetRequest).Do()
+	copyResp, err := r.service.Spreadsheets.Sheets.CopyTo(copyOpts.Spreadsheet, sheetID, copySheetRequest).Context(r.ctx).Do()
 	if err != nil {
 		return common.RuntimeResult{Success: false, Rows: []map[string]interface{}{0: {"message": err.Error()}}}, nil
 	}
@@ -702,7 +705,7 @@ func (r *ActionRunner) CopyFromAToB() (common.RuntimeResult, error) {
 		Requests: requests,
 	}
 
-	batchUpdateResp, err := r.service.Spreadsheets.BatchUpdate(copyOpts.ToSpreadsheet, batchUpdateRequest).Do()
+	batchUpdateResp, err := r.service.Spreadsheets.BatchUpdate(copyOpts.ToSpreadsheet, batchUpdateRequest).Context(r.ctx).Do()
 	if err != nil {
 		return common.RuntimeResult{Success: false, Rows: []map[string]interface{}{0: {"message": err.Error()}}}, nil
 	}
@@ -728,7 +731,7 @@ func (r *ActionRunner) GetSpreadsheetInfo() (common.RuntimeResult, error) {
 		return common.RuntimeResult{Success: false, Rows: []map[string]interface{}{0: {"message": err.Error()}}}, nil
 	}
 
-	spreadsheet, err := r.service.Spreadsheets.Get(getOpts.Spreadsheet).IncludeGridData(false).Do()
+	spreadsheet, err := r.service.Spreadsheets.Get(getOpts.Spreadsheet).IncludeGridData(false).Context(r.ctx).Do()
 	if err != nil {
 		return common.RuntimeResult{Success: false, Rows: []map[string]interface{}{0: {"message": err.Error()}}}, nil
 	}
@@ -744,10 +747,10 @@ func (r *ActionRunner) GetSpreadsheetInfo() (common.RuntimeResult, error) {
 	return common.RuntimeResult{Success: true, Rows: res}, nil
 }
 
-func updateSpreadsheetByFilters(srv *sheets.Service, spreadsheetID, sheetName string, filters []Filter, values []map[string]interface{}) (common.RuntimeResult, error) {
+func updateSpreadsheetByFilters(ctx context.Context, srv *sheets.Service, spreadsheetID, sheetName string, filters []Filter, values []map[string]interface{}) (common.RuntimeResult, error) {
 	// get the sheet data
 	readRange := fmt.Sprintf("%s!A1:Z", sheetName)
-	response, err := srv.Spreadsheets.Values.Get(spreadsheetID, readRange).Do()
+	response, err := srv.Spreadsheets.Values.Get(spreadsheetID, readRange).Context(ctx).Do()
 	if err != nil {
 		return common.RuntimeResult{Success: false, Rows: []map[string]interface{}{0: {"message": err.Error()}}}, nil
 	}
@@ -774,7 +777,7 @@ func updateSpreadsheetByFilters(srv *sheets.Service, spreadsheetID, sheetName st
 		Data:             updateRows,
 	}
 
-	resp, err := srv.Spreadsheets.Values.BatchUpdate(spreadsheetID, batchUpdate).Do()
+	resp, err := srv.Spreadsheets.Values.BatchUpdate(spreadsheetID, batchUpdate).Context(ctx).Do()
 	res := make([]map[string]interface{}, 1, 1)
 	res[0] = map[string]interface{}{
 		"spreadsheetId": resp.SpreadsheetId,
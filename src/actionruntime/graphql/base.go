@@ -15,6 +15,7 @@
 package graphql
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 
@@ -28,7 +29,7 @@ const (
 	AUTH_APIKEY = "apiKey"
 )
 
-func (g *Connector) doQuery(baseURL string, queryParams, headers, cookies map[string]string, authentication string,
+func (g *Connector) doQuery(ctx context.Context, baseURL string, queryParams, headers, cookies map[string]string, authentication string,
 	authContent map[string]string, query string, vars map[string]interface{}) (*resty.Response, error) {
 
 	client := resty.New()
@@ -69,7 +70,7 @@ func (g *Connector) doQuery(baseURL string, queryParams, headers, cookies map[st
 		break
 	}
 
-	queryClient := client.R()
+	queryClient := client.R().SetContext(ctx)
 
 	// set headers
 	queryClient.SetHeaders(headers)
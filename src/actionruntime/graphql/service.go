@@ -15,6 +15,7 @@
 package graphql
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 
@@ -59,7 +60,7 @@ func (g *Connector) ValidateActionTemplate(actionOptions map[string]interface{})
 	return common.ValidateResult{Valid: true}, nil
 }
 
-func (g *Connector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (g *Connector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	// format resource options
 	if err := mapstructure.Decode(resourceOptions, &g.ResourceOpts); err != nil {
 		return common.ConnectionResult{Success: false}, err
@@ -86,7 +87,7 @@ func (g *Connector) TestConnection(resourceOptions map[string]interface{}) (comm
 		}
 	}
 
-	resp, err := g.doQuery(g.ResourceOpts.BaseURL, queryParams, headers, cookies, g.ResourceOpts.Authentication,
+	resp, err := g.doQuery(ctx, g.ResourceOpts.BaseURL, queryParams, headers, cookies, g.ResourceOpts.Authentication,
 		g.ResourceOpts.AuthContent, "{__typename}", nil)
 	if err != nil {
 		return common.ConnectionResult{Success: false}, err
@@ -99,14 +100,14 @@ func (g *Connector) TestConnection(resourceOptions map[string]interface{}) (comm
 	return common.ConnectionResult{Success: true}, nil
 }
 
-func (g *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (g *Connector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 	return common.MetaInfoResult{
 		Success: true,
 		Schema:  nil,
 	}, nil
 }
 
-func (g *Connector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+func (g *Connector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
 	// format resource options
 	if err := mapstructure.Decode(resourceOptions, &g.ResourceOpts); err != nil {
 		return common.RuntimeResult{Success: false}, err
@@ -150,7 +151,7 @@ func (g *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 		}
 	}
 
-	resp, err := g.doQuery(g.ResourceOpts.BaseURL, queryParams, headers, cookies, g.ResourceOpts.Authentication,
+	resp, err := g.doQuery(ctx, g.ResourceOpts.BaseURL, queryParams, headers, cookies, g.ResourceOpts.Authentication,
 		g.ResourceOpts.AuthContent, g.ActionOpts.Query, vars)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
@@ -15,6 +15,7 @@
 package hfendpoint
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -61,15 +62,15 @@ func (h *Connector) ValidateActionTemplate(actionOptions map[string]interface{})
 	return common.ValidateResult{Valid: true}, nil
 }
 
-func (h *Connector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (h *Connector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	return common.ConnectionResult{Success: false}, errors.New("unsupported type: Hugging Face")
 }
 
-func (h *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (h *Connector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 	return common.MetaInfoResult{Success: false}, errors.New("unsupported type: Hugging Face")
 }
 
-func (h *Connector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+func (h *Connector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
 	// format resource options
 	if err := mapstructure.Decode(resourceOptions, &h.ResourceOpts); err != nil {
 		return common.RuntimeResult{Success: false}, err
@@ -81,7 +82,7 @@ func (h *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 	}
 
 	// Create a Resty Client
-	client := resty.New().R()
+	client := resty.New().R().SetContext(ctx)
 	// set Hugging Face token
 	client.SetAuthToken(h.ResourceOpts.Token)
 	// build Hugging Face request
@@ -15,6 +15,7 @@
 package illadrive
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -40,7 +41,6 @@ func (r *IllaDriveConnector) ValidateResourceOptions(resourceOptions map[string]
 }
 
 func (r *IllaDriveConnector) ValidateActionTemplate(actionOptions map[string]interface{}) (common.ValidateResult, error) {
-	fmt.Printf("[DUMP] actionOptions: %+v \n", actionOptions)
 	// check action options common field
 	_, hitOperation := actionOptions[DRIVE_ACTION_OPTIONS_FIELD_OPERATION]
 	if !hitOperation {
@@ -51,24 +51,22 @@ func (r *IllaDriveConnector) ValidateActionTemplate(actionOptions map[string]int
 }
 
 // AI Agent have no test connection method
-func (r *IllaDriveConnector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (r *IllaDriveConnector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	return common.ConnectionResult{Success: false}, errors.New("unsupported type: AI Agent")
 }
 
 // AI Agent have no meta info
-func (r *IllaDriveConnector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (r *IllaDriveConnector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 	return common.MetaInfoResult{Success: false}, errors.New("unsupported type: AI Agent")
 }
 
-func (r *IllaDriveConnector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+func (r *IllaDriveConnector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
 	res := common.RuntimeResult{
 		Success: false,
 		Rows:    []map[string]interface{}{},
 		Extra:   map[string]interface{}{},
 	}
 
-	fmt.Printf("[DUMP] illadrive.Run() actionOptions: %+v\n", actionOptions)
-
 	// resolve actionOptions
 	teamID, _ := resolveIntFieldsFromActionOptions(actionOptions, DRIVE_ACTION_OPTIONS_FIELD_TEAM_ID)
 	userID, _ := resolveIntFieldsFromActionOptions(actionOptions, DRIVE_ACTION_OPTIONS_FIELD_USER_ID)
@@ -25,12 +25,51 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
 	"github.com/mitchellh/mapstructure"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func (m *Connector) getConnectionWithOptions(resourceOptions map[string]interface{}) (*mongo.Client, error) {
+const (
+	CONNECTION_POOL_TYPE = "mongodb"
+)
+
+// checkSSHTunnelOptions check the connection options can be tunneled, the guiOptions is nil for the uri options.
+// the tunnel dials the single host in options, so the uri and the dns seed list which may resolve to many hosts are refused.
+func checkSSHTunnelOptions(resource Options, guiOptions *GUIOptions) error {
+	if !resource.SSHTunnel.Enabled {
+		return nil
+	}
+	if guiOptions == nil {
+		return errors.New("ssh tunnel only supports the gui options of mongodb")
+	}
+	if guiOptions.ConnectionFormat != STANDARD_FORMAT {
+		return errors.New("ssh tunnel only supports the standard connection format of mongodb")
+	}
+	return nil
+}
+
+// getPooledConnectionWithOptions return the pooled mongo client for resource, call release after the query finished.
+func (m *Connector) getPooledConnectionWithOptions(ctx context.Context, resourceOptions map[string]interface{}) (*mongo.Client, func(), error) {
+	if err := mapstructure.Decode(resourceOptions, &m.Resource); err != nil {
+		return nil, nil, err
+	}
+	connection, release, err := common.GetConnectionPoolManager().Acquire(ctx, CONNECTION_POOL_TYPE, resourceOptions, func() (interface{}, func(), error) {
+		// the client outlives current request, so do not bind it to request context
+		client, err := m.getConnectionWithOptions(context.Background(), resourceOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+		return client, func() { client.Disconnect(context.Background()) }, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return connection.(*mongo.Client), release, nil
+}
+
+func (m *Connector) getConnectionWithOptions(ctx context.Context, resourceOptions map[string]interface{}) (*mongo.Client, error) {
 	if err := mapstructure.Decode(resourceOptions, &m.Resource); err != nil {
 		return nil, err
 	}
@@ -42,6 +81,9 @@ func (m *Connector) getConnectionWithOptions(resourceOptions map[string]interfac
 		if err := mapstructure.Decode(m.Resource.ConfigContent, &mOptions); err != nil {
 			return nil, err
 		}
+		if err := checkSSHTunnelOptions(m.Resource, &mOptions); err != nil {
+			return nil, err
+		}
 		if mOptions.DatabaseUsername != "" && mOptions.DatabasePassword != "" {
 			escapedPassword := url.QueryEscape(mOptions.DatabasePassword)
 			uri = fmt.Sprintf("%s://%s:%s@%s", CONNECTION_FORMAT[mOptions.ConnectionFormat],
@@ -52,13 +94,27 @@ func (m *Connector) getConnectionWithOptions(resourceOptions map[string]interfac
 		if mOptions.ConnectionFormat == STANDARD_FORMAT {
 			uri = uri + ":" + mOptions.Port
 		}
+		query := url.Values{}
 		if mOptions.DatabaseName != "" {
 			uri = uri + "/" + mOptions.DatabaseName
 			if mOptions.DatabaseName != "admin" {
-				uri += "?authSource=admin"
+				query.Set("authSource", "admin")
 			}
 		}
+		// the replica set members may not be reachable from the bastion host, so only talk to the host in options
+		if m.Resource.SSHTunnel.Enabled {
+			if mOptions.DatabaseName == "" {
+				uri += "/"
+			}
+			query.Set("directConnection", "true")
+		}
+		if len(query) > 0 {
+			uri += "?" + query.Encode()
+		}
 	} else if m.Resource.ConfigType == URI_OPTIONS {
+		if err := checkSSHTunnelOptions(m.Resource, nil); err != nil {
+			return nil, err
+		}
 		mOptions := URIOptions{}
 		if err := mapstructure.Decode(m.Resource.ConfigContent, &mOptions); err != nil {
 			return nil, err
@@ -113,7 +169,15 @@ func (m *Connector) getConnectionWithOptions(resourceOptions map[string]interfac
 	if m.Resource.SSL.Open == true && m.Resource.SSL.CA != "" {
 		clientOptions = clientOptions.SetTLSConfig(&tlsConfig).SetAuth(credential)
 	}
-	client, err = mongo.Connect(context.Background(), clientOptions)
+	// dial through the bastion host when the ssh tunnel enabled
+	dial, err := common.NewSSHTunnelDialFunc(m.Resource.SSHTunnel)
+	if err != nil {
+		return nil, err
+	}
+	if dial != nil {
+		clientOptions = clientOptions.SetDialer(dial)
+	}
+	client, err = mongo.Connect(ctx, clientOptions)
 	if err != nil {
 		return nil, err
 	}
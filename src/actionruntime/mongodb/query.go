@@ -17,6 +17,7 @@ package mongodb
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -29,9 +30,44 @@ import (
 )
 
 type QueryRunner struct {
+	ctx    context.Context
 	client *mongo.Client
 	query  Query
 	db     string
+	emit   common.RowEmitter // emit the cursor documents in stream mode
+}
+
+// retrieveCursor read the cursor documents within the result limits,
+// the documents are emitted one by one in stream mode, otherwise they are wrapped in the "result" row.
+func (q *QueryRunner) retrieveCursor(cursor *mongo.Cursor) (common.RuntimeResult, error) {
+	defer cursor.Close(q.ctx)
+	emit := q.emit
+	var collector *common.RowCollector
+	if emit == nil {
+		collector = common.NewRowCollector(q.ctx)
+		emit = collector.Emit
+	}
+	for cursor.Next(q.ctx) {
+		var document bson.M
+		if err := cursor.Decode(&document); err != nil {
+			return common.RuntimeResult{Success: false}, err
+		}
+		if err := emit(document); err != nil {
+			if errors.Is(err, common.ErrResultLimitReached) {
+				break
+			}
+			return common.RuntimeResult{Success: false}, err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return common.RuntimeResult{Success: false}, err
+	}
+	if collector == nil {
+		return common.RuntimeResult{Success: true, Rows: []map[string]interface{}{}}, nil
+	}
+	result := common.RuntimeResult{Success: true, Rows: []map[string]interface{}{{"result": collector.ExportRows()}}}
+	collector.MarkResult(&result)
+	return result, nil
 }
 
 func (q *QueryRunner) aggregate() (common.RuntimeResult, error) {
@@ -70,16 +106,12 @@ func (q *QueryRunner) aggregate() (common.RuntimeResult, error) {
 		opts = opts.SetBatchSize(parsedAggregateOptions.BatchSize)
 	}
 
-	cursor, err := coll.Aggregate(context.TODO(), aggregateStage, opts)
+	cursor, err := coll.Aggregate(q.ctx, aggregateStage, opts)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
 	}
 
-	var results []bson.M
-	if err = cursor.All(context.TODO(), &results); err != nil {
-		return common.RuntimeResult{Success: false}, err
-	}
-	return common.RuntimeResult{Success: true, Rows: []map[string]interface{}{{"result": results}}}, nil
+	return q.retrieveCursor(cursor)
 }
 
 func (q *QueryRunner) bulkWrite() (common.RuntimeResult, error) {
@@ -144,7 +176,7 @@ func (q *QueryRunner) bulkWrite() (common.RuntimeResult, error) {
 			break
 		}
 	}
-	results, err := coll.BulkWrite(context.TODO(), models)
+	results, err := coll.BulkWrite(q.ctx, models)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
 	}
@@ -164,7 +196,7 @@ func (q *QueryRunner) count() (common.RuntimeResult, error) {
 		}
 	}
 
-	count, err := coll.CountDocuments(context.TODO(), filter)
+	count, err := coll.CountDocuments(q.ctx, filter)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
 	}
@@ -184,7 +216,7 @@ func (q *QueryRunner) deleteMany() (common.RuntimeResult, error) {
 		}
 	}
 
-	results, err := coll.DeleteMany(context.TODO(), filter)
+	results, err := coll.DeleteMany(q.ctx, filter)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
 	}
@@ -204,7 +236,7 @@ func (q *QueryRunner) deleteOne() (common.RuntimeResult, error) {
 		}
 	}
 
-	results, err := coll.DeleteOne(context.TODO(), filter)
+	results, err := coll.DeleteOne(q.ctx, filter)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
 	}
@@ -241,7 +273,7 @@ func (q *QueryRunner) distinct() (common.RuntimeResult, error) {
 		opts = opts.SetCollation(parsedAggregateOptions.Collation)
 	}
 
-	results, err := coll.Distinct(context.TODO(), distinctOptions.Field, filter, opts)
+	results, err := coll.Distinct(q.ctx, distinctOptions.Field, filter, opts)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
 	}
@@ -291,17 +323,12 @@ func (q *QueryRunner) find() (common.RuntimeResult, error) {
 		opts = opts.SetSkip(skip)
 	}
 
-	cursor, err := coll.Find(context.TODO(), filter, opts)
+	cursor, err := coll.Find(q.ctx, filter, opts)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
 	}
 
-	var results []bson.M
-	if err = cursor.All(context.TODO(), &results); err != nil {
-		return common.RuntimeResult{Success: false}, err
-	}
-
-	return common.RuntimeResult{Success: true, Rows: []map[string]interface{}{{"result": results}}}, nil
+	return q.retrieveCursor(cursor)
 }
 
 func (q *QueryRunner) findOne() (common.RuntimeResult, error) {
@@ -335,7 +362,7 @@ func (q *QueryRunner) findOne() (common.RuntimeResult, error) {
 	}
 
 	var results bson.M
-	err := coll.FindOne(context.TODO(), filter, opts).Decode(&results)
+	err := coll.FindOne(q.ctx, filter, opts).Decode(&results)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
 	}
@@ -403,7 +430,7 @@ func (q *QueryRunner) findOneAndUpdate() (common.RuntimeResult, error) {
 	}
 
 	var results bson.M
-	if err := coll.FindOneAndUpdate(context.TODO(), filter, update, opts).Decode(&results); err != nil {
+	if err := coll.FindOneAndUpdate(q.ctx, filter, update, opts).Decode(&results); err != nil {
 		return common.RuntimeResult{Success: false}, err
 	}
 	return common.RuntimeResult{Success: true, Rows: []map[string]interface{}{{"result": results}}}, nil
@@ -423,7 +450,7 @@ func (q *QueryRunner) insertOne() (common.RuntimeResult, error) {
 		}
 	}
 
-	results, err := coll.InsertOne(context.TODO(), doc)
+	results, err := coll.InsertOne(q.ctx, doc)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
 	}
@@ -450,7 +477,7 @@ func (q *QueryRunner) insertMany() (common.RuntimeResult, error) {
 		docs = append(docs, v)
 	}
 
-	results, err := coll.InsertMany(context.TODO(), docs)
+	results, err := coll.InsertMany(q.ctx, docs)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
 	}
@@ -471,16 +498,12 @@ func (q *QueryRunner) listCollections() (common.RuntimeResult, error) {
 		}
 	}
 
-	cursor, err := db.ListCollections(context.TODO(), filter)
+	cursor, err := db.ListCollections(q.ctx, filter)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
 	}
 
-	var results []bson.M
-	if err = cursor.All(context.TODO(), &results); err != nil {
-		return common.RuntimeResult{Success: false}, err
-	}
-	return common.RuntimeResult{Success: true, Rows: []map[string]interface{}{{"result": results}}}, nil
+	return q.retrieveCursor(cursor)
 }
 
 func (q *QueryRunner) updateMany() (common.RuntimeResult, error) {
@@ -529,7 +552,7 @@ func (q *QueryRunner) updateMany() (common.RuntimeResult, error) {
 		opts = opts.SetUpsert(parsedUpdateManyOptions.Upsert)
 	}
 
-	results, err := coll.UpdateMany(context.TODO(), filter, update, opts)
+	results, err := coll.UpdateMany(q.ctx, filter, update, opts)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
 	}
@@ -582,7 +605,7 @@ func (q *QueryRunner) updateOne() (common.RuntimeResult, error) {
 		opts = opts.SetUpsert(parsedUpdateOneOptions.Upsert)
 	}
 
-	results, err := coll.UpdateOne(context.TODO(), filter, update, opts)
+	results, err := coll.UpdateOne(q.ctx, filter, update, opts)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
 	}
@@ -602,7 +625,7 @@ func (q *QueryRunner) command() (common.RuntimeResult, error) {
 	}
 
 	var results bson.M
-	if err := db.RunCommand(context.TODO(), doc).Decode(&results); err != nil {
+	if err := db.RunCommand(q.ctx, doc).Decode(&results); err != nil {
 		return common.RuntimeResult{Success: false}, err
 	}
 
@@ -50,6 +50,9 @@ func (m *Connector) ValidateResourceOptions(resourceOptions map[string]interface
 		if err := validate.Struct(mOptions); err != nil {
 			return common.ValidateResult{Valid: false}, err
 		}
+		if err := checkSSHTunnelOptions(m.Resource, &mOptions); err != nil {
+			return common.ValidateResult{Valid: false}, err
+		}
 	} else if m.Resource.ConfigType == URI_OPTIONS {
 		var mOptions URIOptions
 		if err := mapstructure.Decode(m.Resource.ConfigContent, &mOptions); err != nil {
@@ -58,6 +61,9 @@ func (m *Connector) ValidateResourceOptions(resourceOptions map[string]interface
 		if err := validate.Struct(mOptions); err != nil {
 			return common.ValidateResult{Valid: false}, err
 		}
+		if err := checkSSHTunnelOptions(m.Resource, nil); err != nil {
+			return common.ValidateResult{Valid: false}, err
+		}
 	}
 
 	return common.ValidateResult{Valid: true}, nil
@@ -77,22 +83,22 @@ func (m *Connector) ValidateActionTemplate(actionOptions map[string]interface{})
 	return common.ValidateResult{Valid: true}, nil
 }
 
-func (m *Connector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (m *Connector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	// get mongodb connection
-	client, err := m.getConnectionWithOptions(resourceOptions)
+	client, err := m.getConnectionWithOptions(ctx, resourceOptions)
 	if err != nil {
 		return common.ConnectionResult{Success: false}, err
 	}
 	defer client.Disconnect(context.Background())
 
 	// test mongodb connection
-	if err := client.Ping(context.Background(), readpref.Primary()); err != nil {
+	if err := client.Ping(ctx, readpref.Primary()); err != nil {
 		return common.ConnectionResult{Success: false}, err
 	}
 	return common.ConnectionResult{Success: true}, nil
 }
 
-func (m *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (m *Connector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 
 	return common.MetaInfoResult{
 		Success: true,
@@ -100,13 +106,22 @@ func (m *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.
 	}, nil
 }
 
-func (m *Connector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+func (m *Connector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+	return m.run(ctx, resourceOptions, nil)
+}
+
+// RunStream run the action and emit the documents of aggregate, find and listCollections one by one.
+func (m *Connector) RunStream(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}, emit common.RowEmitter) (common.RuntimeResult, error) {
+	return m.run(ctx, resourceOptions, emit)
+}
+
+func (m *Connector) run(ctx context.Context, resourceOptions map[string]interface{}, emit common.RowEmitter) (common.RuntimeResult, error) {
 	// get mongodb connection
-	client, err := m.getConnectionWithOptions(resourceOptions)
+	client, releaseConnection, err := m.getPooledConnectionWithOptions(ctx, resourceOptions)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
 	}
-	defer client.Disconnect(context.Background())
+	defer releaseConnection()
 
 	db := ""
 	if m.Resource.ConfigType == GUI_OPTIONS {
@@ -132,7 +147,7 @@ func (m *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 	}
 
 	var result common.RuntimeResult
-	queryRunner := QueryRunner{client: client, query: m.Action, db: db}
+	queryRunner := QueryRunner{ctx: ctx, client: client, query: m.Action, db: db, emit: emit}
 	switch m.Action.ActionType {
 	case "aggregate":
 		result, err = queryRunner.aggregate()
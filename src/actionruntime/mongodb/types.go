@@ -14,7 +14,10 @@
 
 package mongodb
 
-import "go.mongodb.org/mongo-driver/mongo/options"
+import (
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
 
 const (
 	STANDARD_FORMAT    = "standard"
@@ -31,6 +34,7 @@ type Options struct {
 	ConfigType    string                 `validate:"required,oneof=gui uri"`
 	ConfigContent map[string]interface{} `validate:"required"`
 	SSL           SSLOptions
+	SSHTunnel     common.SSHTunnelOptions
 }
 
 type GUIOptions struct {
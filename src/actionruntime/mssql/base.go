@@ -15,6 +15,7 @@
 package mssql
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"database/sql"
@@ -22,21 +23,22 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"strconv"
 
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
 	mssqldb "github.com/microsoft/go-mssqldb"
 	"github.com/microsoft/go-mssqldb/msdsn"
 	"github.com/mitchellh/mapstructure"
 )
 
 const (
+	CONNECTION_POOL_TYPE = "mssql"
 	VERIFY_FULL_MODE     = "full"
 	SKIP_CA_MODE         = "skip"
 	ACTION_SQL_MODE      = "sql"
 	ACTION_SQL_SAFE_MODE = "sql-safe"
 	ACTION_GUI_MODE      = "gui"
 	ACTION_GUI_TYPE      = "bulk_insert"
-	tableSQLStr          = "SELECT TABLE_NAME tableName, TABLE_SCHEMA tableSchema FROM INFORMATION_SCHEMA.TABLES;"
-	columnSQLStr         = "SELECT COLUMN_NAME columnName, DATA_TYPE columnType FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = @p1 AND TABLE_NAME = @p2"
 )
 
 func (m *Connector) getConnectionWithOptions(resourceOptions map[string]interface{}) (*sql.DB, error) {
@@ -92,51 +94,70 @@ func (m *Connector) getConnectionWithOptions(resourceOptions map[string]interfac
 
 	// convert msdsn.Config to driver.Connector interface implemented by go-mssqldb
 	conn := mssqldb.NewConnectorConfig(cfg)
+	// dial through the bastion host when the ssh tunnel enabled
+	dial, err := common.NewSSHTunnelDialFunc(m.ResourceOpts.SSHTunnel)
+	if err != nil {
+		return nil, err
+	}
+	if dial != nil {
+		conn.Dialer = &sshTunnelHostDialer{SSHTunnelDialFunc: dial, host: m.ResourceOpts.Host}
+	}
 	// connect to db
 	db := sql.OpenDB(conn)
 
 	return db, nil
 }
 
-func tablesInfo(db *sql.DB) []map[string]string {
-	tableNames := make([]map[string]string, 0, 0)
-	tableRows, err := db.Query(tableSQLStr)
-	if err != nil {
-		return nil
-	}
-	for tableRows.Next() {
-		var tableName, tableSchema string
-		err = tableRows.Scan(&tableName, &tableSchema)
-		if err != nil {
-			return nil
-		}
-
-		tableNames = append(tableNames, map[string]string{"table": tableName, "schema": tableSchema})
+// getPooledConnectionWithOptions return the pooled *sql.DB for resource, call release after the query finished.
+func (m *Connector) getPooledConnectionWithOptions(ctx context.Context, resourceOptions map[string]interface{}) (*sql.DB, func(), error) {
+	if err := mapstructure.Decode(resourceOptions, &m.ResourceOpts); err != nil {
+		return nil, nil, err
 	}
+	return common.GetConnectionPoolManager().AcquireSQLDB(ctx, CONNECTION_POOL_TYPE, resourceOptions, func() (*sql.DB, error) {
+		return m.getConnectionWithOptions(resourceOptions)
+	})
+}
 
-	return tableNames
+// the mssql error numbers, mssql returns no SQLSTATE
+var mssqlErrorClasses = map[int32]string{
+	102:   common.QUERY_ERROR_CLASS_SYNTAX,     // incorrect syntax
+	156:   common.QUERY_ERROR_CLASS_SYNTAX,     // incorrect syntax near the keyword
+	207:   common.QUERY_ERROR_CLASS_NOT_FOUND,  // invalid column name
+	208:   common.QUERY_ERROR_CLASS_NOT_FOUND,  // invalid object name
+	2812:  common.QUERY_ERROR_CLASS_NOT_FOUND,  // could not find stored procedure
+	229:   common.QUERY_ERROR_CLASS_PERMISSION, // permission denied on object
+	230:   common.QUERY_ERROR_CLASS_PERMISSION, // permission denied on column
+	18456: common.QUERY_ERROR_CLASS_PERMISSION, // login failed
+	515:   common.QUERY_ERROR_CLASS_CONSTRAINT, // cannot insert null
+	547:   common.QUERY_ERROR_CLASS_CONSTRAINT, // constraint conflicted
+	2601:  common.QUERY_ERROR_CLASS_CONSTRAINT, // duplicate key row
+	2627:  common.QUERY_ERROR_CLASS_CONSTRAINT, // violation of unique constraint
+	245:   common.QUERY_ERROR_CLASS_DATA,       // conversion failed
+	8115:  common.QUERY_ERROR_CLASS_DATA,       // arithmetic overflow
+	1222:  common.QUERY_ERROR_CLASS_TIMEOUT,    // lock request time out
 }
 
-func fieldsInfo(db *sql.DB, tableNames []map[string]string) map[string]interface{} {
-	columns := make(map[string]interface{})
-	for _, tableName := range tableNames {
-		columnRows, err := db.Query(columnSQLStr, tableName["schema"], tableName["table"])
-		if err != nil {
-			return nil
-		}
-		tables := make(map[string]interface{})
+// convertQueryError convert the mssql server error to common.QueryError, the other errors are returned as is.
+func convertQueryError(err error) error {
+	var mssqlError mssqldb.Error
+	if !errors.As(err, &mssqlError) {
+		return err
+	}
+	class, hit := mssqlErrorClasses[mssqlError.Number]
+	if !hit {
+		class = common.QUERY_ERROR_CLASS_UNKNOWN
+	}
+	queryError := common.NewQueryError(err, class, strconv.Itoa(int(mssqlError.Number)), "", mssqlError.Message)
+	queryError.SetLocation(int(mssqlError.LineNo), 0)
+	return queryError
+}
 
-		for columnRows.Next() {
-			var columnName, columnType string
-			err = columnRows.Scan(&columnName, &columnType)
-			if err != nil {
-				return nil
-			}
-			tables[columnName] = map[string]string{"data_type": columnType}
+// sshTunnelHostDialer is the mssqldb.HostDialer, so the host name is resolved by the bastion host rather than locally.
+type sshTunnelHostDialer struct {
+	common.SSHTunnelDialFunc
+	host string
+}
 
-		}
-		tableStr := fmt.Sprintf("%s.%s", tableName["schema"], tableName["table"])
-		columns[tableStr] = tables
-	}
-	return columns
+func (dialer *sshTunnelHostDialer) HostName() string {
+	return dialer.host
 }
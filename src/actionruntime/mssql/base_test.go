@@ -0,0 +1,30 @@
+package mssql
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+	mssqldb "github.com/microsoft/go-mssqldb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertQueryError(t *testing.T) {
+	err := convertQueryError(fmt.Errorf("run query failed: %w", mssqldb.Error{Number: 208, LineNo: 2, Message: "Invalid object name 'userz'."}))
+	queryError, ok := common.AsQueryError(err)
+	assert.True(t, ok)
+	assert.Equal(t, common.QUERY_ERROR_CLASS_NOT_FOUND, queryError.Class)
+	assert.Equal(t, "208", queryError.VendorCode)
+	assert.Equal(t, 2, queryError.LineNumber)
+	assert.Equal(t, 0, queryError.Position, "mssql does not report the position in line")
+
+	// the unknown error number
+	err = convertQueryError(mssqldb.Error{Number: 50000, LineNo: 1, Message: "raised by user"})
+	queryError, _ = common.AsQueryError(err)
+	assert.Equal(t, common.QUERY_ERROR_CLASS_UNKNOWN, queryError.Class)
+	assert.Equal(t, 1, queryError.LineNumber)
+
+	errInDial := errors.New("connection refused")
+	assert.Equal(t, errInDial, convertQueryError(errInDial))
+}
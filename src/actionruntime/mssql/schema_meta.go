@@ -0,0 +1,150 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mssql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+)
+
+// the tables in the limit, the columns, keys and indexes are fetched for these tables only
+const schemaMetaLimitedTablesSQL = `SELECT TOP (@p1) lo.object_id FROM sys.objects lo
+JOIN sys.schemas ls ON ls.schema_id = lo.schema_id
+WHERE lo.type IN ('U', 'V') AND lo.is_ms_shipped = 0
+ORDER BY ls.name, lo.name`
+
+// the catalog views only list the objects which the user has any permission on, the system objects are skipped
+const (
+	schemaMetaTablesSQL = `SELECT TOP (@p1) s.name, o.name, RTRIM(o.type) FROM sys.objects o
+JOIN sys.schemas s ON s.schema_id = o.schema_id
+WHERE o.type IN ('U', 'V') AND o.is_ms_shipped = 0
+ORDER BY s.name, o.name`
+	schemaMetaColumnsSQL = `SELECT s.name, o.name, c.name, TYPE_NAME(c.user_type_id), c.is_nullable, d.definition
+FROM sys.columns c
+JOIN sys.objects o ON o.object_id = c.object_id
+JOIN sys.schemas s ON s.schema_id = o.schema_id
+LEFT JOIN sys.default_constraints d ON d.object_id = c.default_object_id
+WHERE o.object_id IN (` + schemaMetaLimitedTablesSQL + `)
+ORDER BY s.name, o.name, c.column_id`
+	schemaMetaForeignKeysSQL = `SELECT s.name, o.name, fk.name, pc.name, rs.name, ro.name, rc.name
+FROM sys.foreign_key_columns fkc
+JOIN sys.foreign_keys fk ON fk.object_id = fkc.constraint_object_id
+JOIN sys.objects o ON o.object_id = fkc.parent_object_id
+JOIN sys.schemas s ON s.schema_id = o.schema_id
+JOIN sys.columns pc ON pc.object_id = fkc.parent_object_id AND pc.column_id = fkc.parent_column_id
+JOIN sys.objects ro ON ro.object_id = fkc.referenced_object_id
+JOIN sys.schemas rs ON rs.schema_id = ro.schema_id
+JOIN sys.columns rc ON rc.object_id = fkc.referenced_object_id AND rc.column_id = fkc.referenced_column_id
+WHERE fkc.parent_object_id IN (` + schemaMetaLimitedTablesSQL + `)
+ORDER BY s.name, o.name, fk.name, fkc.constraint_column_id`
+	schemaMetaIndexesSQL = `SELECT s.name, o.name, i.name, i.is_unique, i.is_primary_key, c.name
+FROM sys.indexes i
+JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+JOIN sys.objects o ON o.object_id = i.object_id
+JOIN sys.schemas s ON s.schema_id = o.schema_id
+WHERE i.index_id > 0 AND ic.is_included_column = 0 AND o.object_id IN (` + schemaMetaLimitedTablesSQL + `)
+ORDER BY s.name, o.name, i.name, ic.key_ordinal`
+)
+
+// schemaMeta fetch the tables, views, columns, keys and indexes of all accessible schemas from the catalog views.
+func schemaMeta(ctx context.Context, db *sql.DB) (*common.SchemaMeta, error) {
+	builder := common.NewSchemaMetaBuilder()
+
+	// tables, fetch one more table for detecting the truncation
+	tableRows, err := db.QueryContext(ctx, schemaMetaTablesSQL, common.SCHEMA_META_MAX_TABLES+1)
+	if err != nil {
+		return nil, err
+	}
+	defer tableRows.Close()
+	for tableRows.Next() {
+		var schemaName, tableName, objectType string
+		if err := tableRows.Scan(&schemaName, &tableName, &objectType); err != nil {
+			return nil, err
+		}
+		kind := common.TABLE_KIND_TABLE
+		if objectType == "V" {
+			kind = common.TABLE_KIND_VIEW
+		}
+		builder.AddTable(schemaName, tableName, kind)
+	}
+	if err := tableRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// columns
+	columnRows, err := db.QueryContext(ctx, schemaMetaColumnsSQL, common.SCHEMA_META_MAX_TABLES)
+	if err != nil {
+		return nil, err
+	}
+	defer columnRows.Close()
+	for columnRows.Next() {
+		var schemaName, tableName string
+		var columnDefault sql.NullString
+		column := &common.ColumnMeta{}
+		if err := columnRows.Scan(&schemaName, &tableName, &column.Name, &column.DataType, &column.Nullable, &columnDefault); err != nil {
+			return nil, err
+		}
+		if columnDefault.Valid {
+			column.Default = &columnDefault.String
+		}
+		builder.AddColumn(schemaName, tableName, column)
+	}
+	if err := columnRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// foreign keys
+	foreignKeyRows, err := db.QueryContext(ctx, schemaMetaForeignKeysSQL, common.SCHEMA_META_MAX_TABLES)
+	if err != nil {
+		return nil, err
+	}
+	defer foreignKeyRows.Close()
+	for foreignKeyRows.Next() {
+		var schemaName, tableName, foreignKeyName, columnName, referencedSchema, referencedTable, referencedColumn string
+		if err := foreignKeyRows.Scan(&schemaName, &tableName, &foreignKeyName, &columnName, &referencedSchema, &referencedTable, &referencedColumn); err != nil {
+			return nil, err
+		}
+		builder.AddForeignKeyColumn(schemaName, tableName, foreignKeyName, columnName, referencedSchema, referencedTable, referencedColumn)
+	}
+	if err := foreignKeyRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// indexes, the primary key is the columns of the primary key index
+	indexRows, err := db.QueryContext(ctx, schemaMetaIndexesSQL, common.SCHEMA_META_MAX_TABLES)
+	if err != nil {
+		return nil, err
+	}
+	defer indexRows.Close()
+	for indexRows.Next() {
+		var schemaName, tableName, indexName, columnName string
+		var unique, primary bool
+		if err := indexRows.Scan(&schemaName, &tableName, &indexName, &unique, &primary, &columnName); err != nil {
+			return nil, err
+		}
+		builder.AddIndexColumn(schemaName, tableName, indexName, unique, primary, columnName)
+		if primary {
+			builder.AddPrimaryKeyColumn(schemaName, tableName, columnName)
+		}
+	}
+	if err := indexRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return builder.Build(), nil
+}
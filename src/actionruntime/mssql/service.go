@@ -15,6 +15,7 @@
 package mssql
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -62,7 +63,7 @@ func (m *Connector) ValidateActionTemplate(actionOptions map[string]interface{})
 	return common.ValidateResult{Valid: true}, nil
 }
 
-func (m *Connector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (m *Connector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	// get Microsoft SQL Server connection
 	db, err := m.getConnectionWithOptions(resourceOptions)
 	if err != nil {
@@ -71,42 +72,58 @@ func (m *Connector) TestConnection(resourceOptions map[string]interface{}) (comm
 	defer db.Close()
 
 	// test Microsoft SQL Server connection
-	if err := db.Ping(); err != nil {
+	if err := db.PingContext(ctx); err != nil {
 		return common.ConnectionResult{Success: false}, err
 	}
 
 	return common.ConnectionResult{Success: true}, nil
 }
 
-func (m *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (m *Connector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 	// get Microsoft SQL Server connection
-	db, err := m.getConnectionWithOptions(resourceOptions)
+	db, releaseConnection, err := m.getPooledConnectionWithOptions(ctx, resourceOptions)
 	if err != nil {
 		return common.MetaInfoResult{Success: false}, err
 	}
-	defer db.Close()
+	defer releaseConnection()
 
 	// test Microsoft SQL Server connection
-	if err := db.Ping(); err != nil {
+	if err := db.PingContext(ctx); err != nil {
 		return common.MetaInfoResult{Success: false}, err
 	}
 
-	// get Microsoft SQL Server tables information
-	columns := fieldsInfo(db, tablesInfo(db))
+	// get Microsoft SQL Server tables information, the tables are keyed by "schema.table" in legacy schema
+	detail, err := schemaMeta(ctx, db)
+	if err != nil {
+		return common.MetaInfoResult{Success: false}, err
+	}
 
 	return common.MetaInfoResult{
 		Success: true,
-		Schema:  columns,
+		Schema:  detail.ExportLegacySchema(""),
+		Detail:  detail,
 	}, nil
 }
 
-func (m *Connector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+func (m *Connector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+	return common.CollectStream(ctx, func(emit common.RowEmitter) (common.RuntimeResult, error) {
+		return m.RunStream(ctx, resourceOptions, actionOptions, rawActionOptions, emit)
+	})
+}
+
+// RunStream run the action and emit the selected rows one by one, the driver errors are converted to common.QueryError.
+func (m *Connector) RunStream(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}, emit common.RowEmitter) (common.RuntimeResult, error) {
+	result, err := m.runStream(ctx, resourceOptions, actionOptions, rawActionOptions, emit)
+	return result, convertQueryError(err)
+}
+
+func (m *Connector) runStream(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}, emit common.RowEmitter) (common.RuntimeResult, error) {
 	// get Microsoft SQL Server connection
-	db, err := m.getConnectionWithOptions(resourceOptions)
+	db, releaseConnection, err := m.getPooledConnectionWithOptions(ctx, resourceOptions)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, errors.New("failed to get mssql connection")
 	}
-	defer db.Close()
+	defer releaseConnection()
 	// format query
 	if err := mapstructure.Decode(actionOptions, &m.ActionOpts); err != nil {
 		return common.RuntimeResult{Success: false}, err
@@ -134,6 +151,9 @@ func (m *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 		if errInEscapeSQL != nil {
 			return queryResult, errInEscapeSQL
 		}
+		if errInCheckReadOnly := common.CheckReadOnlySQL(resourceOptions, resourcelist.TYPE_MSSQL_ID, escapedSQL); errInCheckReadOnly != nil {
+			return queryResult, errInCheckReadOnly
+		}
 		// check if m.Action.Query["sql"] is select query
 		isSelectQuery := false
 
@@ -149,31 +169,31 @@ func (m *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 
 		// fetch data
 		if isSelectQuery && m.ActionOpts.IsSafeMode() {
-			rows, err := db.Query(escapedSQL, sqlArgs...)
+			rows, err := db.QueryContext(ctx, escapedSQL, sqlArgs...)
 			if err != nil {
 				return queryResult, err
 			}
-			mapRes, err := common.RetrieveToMap(rows)
+			defer rows.Close()
+			columns, err := common.EmitRows(rows, emit)
 			if err != nil {
 				return queryResult, err
 			}
-			defer rows.Close()
+			queryResult.Columns = columns
 			queryResult.Success = true
-			queryResult.Rows = mapRes
 		} else if isSelectQuery && !m.ActionOpts.IsSafeMode() {
-			rows, err := db.Query(escapedSQL)
+			rows, err := db.QueryContext(ctx, escapedSQL)
 			if err != nil {
 				return queryResult, err
 			}
-			mapRes, err := common.RetrieveToMap(rows)
+			defer rows.Close()
+			columns, err := common.EmitRows(rows, emit)
 			if err != nil {
 				return queryResult, err
 			}
-			defer rows.Close()
+			queryResult.Columns = columns
 			queryResult.Success = true
-			queryResult.Rows = mapRes
 		} else if !isSelectQuery && m.ActionOpts.IsSafeMode() {
-			execResult, err := db.Exec(escapedSQL, sqlArgs...)
+			execResult, err := db.ExecContext(ctx, escapedSQL, sqlArgs...)
 			if err != nil {
 				return queryResult, err
 			}
@@ -184,7 +204,7 @@ func (m *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 			queryResult.Success = true
 			queryResult.Extra["message"] = fmt.Sprintf("Affeted %d rows.", affectedRows)
 		} else if !isSelectQuery && !m.ActionOpts.IsSafeMode() {
-			execResult, err := db.Exec(escapedSQL)
+			execResult, err := db.ExecContext(ctx, escapedSQL)
 			if err != nil {
 				return queryResult, err
 			}
@@ -196,6 +216,9 @@ func (m *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 			queryResult.Extra["message"] = fmt.Sprintf("Affeted %d rows.", affectedRows)
 		}
 	case ACTION_GUI_MODE:
+		if errInCheckReadOnly := common.CheckReadOnlyGUI(resourceOptions); errInCheckReadOnly != nil {
+			return queryResult, errInCheckReadOnly
+		}
 		// format data
 		var guiQuery GUIQuery
 		if err := mapstructure.Decode(m.ActionOpts.Query, &guiQuery); err != nil {
@@ -219,12 +242,12 @@ func (m *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 		}
 
 		// begin transaction
-		txn, err := db.Begin()
+		txn, err := db.BeginTx(ctx, nil)
 		if err != nil {
 			return queryResult, err
 		}
 		// prepare statement
-		stmt, err := txn.Prepare(mssql.CopyIn(tableName, mssql.BulkOptions{}, tableColumns...))
+		stmt, err := txn.PrepareContext(ctx, mssql.CopyIn(tableName, mssql.BulkOptions{}, tableColumns...))
 		if err != nil {
 			return queryResult, err
 		}
@@ -234,7 +257,7 @@ func (m *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 			for _, tableColumn := range tableColumns {
 				tableValues = append(tableValues, records[i][tableColumn])
 			}
-			_, err = stmt.Exec(tableValues...)
+			_, err = stmt.ExecContext(ctx, tableValues...)
 			if err != nil {
 				stmt.Close()
 				txn.Rollback()
@@ -242,7 +265,7 @@ func (m *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 			}
 		}
 		// exec prepared statement with given batch data
-		result, err := stmt.Exec()
+		result, err := stmt.ExecContext(ctx)
 		if err != nil {
 			stmt.Close()
 			txn.Rollback()
@@ -270,3 +293,66 @@ func (m *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 
 	return queryResult, err
 }
+
+// RunPreview run the single DML statement of sql mode in a transaction then roll it back,
+// the "OUTPUT" rows are returned when the statement has it. the driver errors are converted to common.QueryError.
+func (m *Connector) RunPreview(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+	result, err := m.runPreview(ctx, resourceOptions, actionOptions, rawActionOptions)
+	return result, convertQueryError(err)
+}
+
+func (m *Connector) runPreview(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+	// get Microsoft SQL Server connection
+	db, releaseConnection, err := m.getPooledConnectionWithOptions(ctx, resourceOptions)
+	if err != nil {
+		return common.RuntimeResult{Success: false}, errors.New("failed to get mssql connection")
+	}
+	defer releaseConnection()
+
+	query, errInPrepare := m.prepareQuery(resourceOptions, actionOptions, rawActionOptions)
+	if errInPrepare != nil {
+		return common.RuntimeResult{Success: false}, errInPrepare
+	}
+	statement, errInCheckPreview := common.CheckPreviewSQL(resourcelist.TYPE_MSSQL_ID, query.SQL)
+	if errInCheckPreview != nil {
+		return common.RuntimeResult{Success: false}, errInCheckPreview
+	}
+	return common.RunPreviewInSQLTx(ctx, db, statement, query.SQL, query.Args...)
+}
+
+// BeginTransaction begin a transaction on the resource, the sql mode actions run in it are committed or rolled back together.
+func (m *Connector) BeginTransaction(ctx context.Context, resourceOptions map[string]interface{}) (common.ActionTransaction, error) {
+	db, releaseConnection, err := m.getPooledConnectionWithOptions(ctx, resourceOptions)
+	if err != nil {
+		return nil, errors.New("failed to get mssql connection")
+	}
+	return common.BeginSQLActionTransaction(ctx, db, nil, releaseConnection, func(actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (*common.PreparedQuery, error) {
+		return m.prepareQuery(resourceOptions, actionOptions, rawActionOptions)
+	}, convertQueryError)
+}
+
+// prepareQuery decode the sql mode action and escape the sql, the read-only resource is checked.
+func (m *Connector) prepareQuery(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (*common.PreparedQuery, error) {
+	m.ActionOpts = Action{}
+	if err := mapstructure.Decode(actionOptions, &m.ActionOpts); err != nil {
+		return nil, err
+	}
+	if m.ActionOpts.Mode != ACTION_SQL_MODE && m.ActionOpts.Mode != ACTION_SQL_SAFE_MODE {
+		return nil, errors.New("only sql mode action is supported")
+	}
+	if errInSetRawQuery := m.ActionOpts.SetRawQueryAndContext(rawActionOptions); errInSetRawQuery != nil {
+		return nil, errInSetRawQuery
+	}
+	sqlEscaper := parser_sql.NewSQLEscaper(resourcelist.TYPE_MSSQL_ID)
+	escapedSQL, sqlArgs, errInEscapeSQL := sqlEscaper.EscapeSQLActionTemplate(m.ActionOpts.RawQuery, m.ActionOpts.Context, m.ActionOpts.IsSafeMode())
+	if errInEscapeSQL != nil {
+		return nil, errInEscapeSQL
+	}
+	if errInCheckReadOnly := common.CheckReadOnlySQL(resourceOptions, resourcelist.TYPE_MSSQL_ID, escapedSQL); errInCheckReadOnly != nil {
+		return nil, errInCheckReadOnly
+	}
+	if !m.ActionOpts.IsSafeMode() {
+		sqlArgs = nil
+	}
+	return common.NewPreparedQuery(resourcelist.TYPE_MSSQL_ID, escapedSQL, sqlArgs)
+}
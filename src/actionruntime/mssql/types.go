@@ -34,6 +34,7 @@ type Resource struct {
 	Password       string
 	ConnectionOpts []map[string]string `validate:"required"`
 	SSL            SSLOptions
+	SSHTunnel      common.SSHTunnelOptions
 }
 
 type SSLOptions struct {
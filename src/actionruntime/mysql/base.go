@@ -15,22 +15,26 @@
 package mysql
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"database/sql"
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
+	"regexp"
+	"strconv"
 
 	"github.com/go-sql-driver/mysql"
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
 	"github.com/mitchellh/mapstructure"
 )
 
 const (
-	tableSQLStr  = "SELECT TABLE_NAME tableName FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ?"
-	columnSQLStr = "SELECT COLUMN_NAME columnName, DATA_TYPE columnType FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?"
+	CONNECTION_POOL_TYPE = "mysql"
 )
 
 func (m *MySQLConnector) getConnectionWithOptions(resourceOptions map[string]interface{}) (*sql.DB, error) {
@@ -47,10 +51,41 @@ func (m *MySQLConnector) getConnectionWithOptions(resourceOptions map[string]int
 	return db, err
 }
 
+// getPooledConnectionWithOptions return the pooled *sql.DB for resource, call release after the query finished.
+func (m *MySQLConnector) getPooledConnectionWithOptions(ctx context.Context, resourceOptions map[string]interface{}) (*sql.DB, func(), error) {
+	if err := mapstructure.Decode(resourceOptions, &m.Resource); err != nil {
+		return nil, nil, err
+	}
+	return common.GetConnectionPoolManager().AcquireSQLDB(ctx, CONNECTION_POOL_TYPE, resourceOptions, func() (*sql.DB, error) {
+		return m.getConnectionWithOptions(resourceOptions)
+	})
+}
+
+// dialNetwork return the network in dsn, it is the network registered with the ssh tunnel dial func when the tunnel enabled.
+func (m *MySQLConnector) dialNetwork() (string, error) {
+	dial, err := common.NewSSHTunnelDialFunc(m.Resource.SSHTunnel)
+	if err != nil || dial == nil {
+		return "tcp", err
+	}
+	key, err := m.Resource.SSHTunnel.ExportKey()
+	if err != nil {
+		return "", err
+	}
+	network := "ssh-tunnel-" + key
+	mysql.RegisterDialContext(network, func(ctx context.Context, addr string) (net.Conn, error) {
+		return dial(ctx, "tcp", addr)
+	})
+	return network, nil
+}
+
 func (m *MySQLConnector) connectPure() (db *sql.DB, err error) {
+	network, err := m.dialNetwork()
+	if err != nil {
+		return nil, err
+	}
 	escapedPassword := url.QueryEscape(m.Resource.DatabasePassword)
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", m.Resource.DatabaseUsername,
-		escapedPassword, m.Resource.Host, m.Resource.Port, m.Resource.DatabaseName)
+	dsn := fmt.Sprintf("%s:%s@%s(%s)/%s", m.Resource.DatabaseUsername,
+		escapedPassword, network, net.JoinHostPort(m.Resource.Host, m.Resource.Port), m.Resource.DatabaseName)
 	db, err = sql.Open("mysql", dsn+"?timeout=30s")
 	if err != nil {
 		return nil, err
@@ -59,14 +94,18 @@ func (m *MySQLConnector) connectPure() (db *sql.DB, err error) {
 }
 
 func (m *MySQLConnector) connectViaSSL() (db *sql.DB, err error) {
+	network, err := m.dialNetwork()
+	if err != nil {
+		return nil, err
+	}
 	escapedPassword := url.QueryEscape(m.Resource.DatabasePassword)
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", m.Resource.DatabaseUsername,
-		escapedPassword, m.Resource.Host, m.Resource.Port, m.Resource.DatabaseName)
+	dsn := fmt.Sprintf("%s:%s@%s(%s)/%s", m.Resource.DatabaseUsername,
+		escapedPassword, network, net.JoinHostPort(m.Resource.Host, m.Resource.Port), m.Resource.DatabaseName)
 	pool := x509.NewCertPool()
 	if ok := pool.AppendCertsFromPEM([]byte(m.Resource.SSL.ServerCert)); !ok {
 		return nil, errors.New("MySQL SSL/TLS Connection failed")
 	}
-	config := tls.Config{RootCAs: pool}
+	config := tls.Config{RootCAs: pool, ServerName: m.Resource.Host}
 	ccBlock, _ := pem.Decode([]byte(m.Resource.SSL.ClientCert))
 	ckBlock, _ := pem.Decode([]byte(m.Resource.SSL.ClientKey))
 	if (ccBlock != nil && ccBlock.Type == "CERTIFICATE") && (ckBlock != nil || ckBlock.Type == "PRIVATE KEY") {
@@ -85,44 +124,38 @@ func (m *MySQLConnector) connectViaSSL() (db *sql.DB, err error) {
 	return db, nil
 }
 
-func tablesInfo(db *sql.DB, dbName string) []string {
-	tableNames := make([]string, 0, 0)
-	tableRows, err := db.Query(tableSQLStr, dbName)
-	if err != nil {
-		return nil
-	}
-	for tableRows.Next() {
-		var tableName string
-		err = tableRows.Scan(&tableName)
-		if err != nil {
-			return nil
-		}
-
-		tableNames = append(tableNames, tableName)
-	}
-
-	return tableNames
+// the mysql error numbers which SQLSTATE is too general to classify
+var mysqlErrorClasses = map[uint16]string{
+	1064: common.QUERY_ERROR_CLASS_SYNTAX,     // ER_PARSE_ERROR
+	1149: common.QUERY_ERROR_CLASS_SYNTAX,     // ER_SYNTAX_ERROR
+	1049: common.QUERY_ERROR_CLASS_NOT_FOUND,  // ER_BAD_DB_ERROR
+	1054: common.QUERY_ERROR_CLASS_NOT_FOUND,  // ER_BAD_FIELD_ERROR
+	1146: common.QUERY_ERROR_CLASS_NOT_FOUND,  // ER_NO_SUCH_TABLE
+	1305: common.QUERY_ERROR_CLASS_NOT_FOUND,  // ER_SP_DOES_NOT_EXIST
+	1044: common.QUERY_ERROR_CLASS_PERMISSION, // ER_DBACCESS_DENIED_ERROR
+	1045: common.QUERY_ERROR_CLASS_PERMISSION, // ER_ACCESS_DENIED_ERROR
+	1142: common.QUERY_ERROR_CLASS_PERMISSION, // ER_TABLEACCESS_DENIED_ERROR
+	1143: common.QUERY_ERROR_CLASS_PERMISSION, // ER_COLUMNACCESS_DENIED_ERROR
+	1205: common.QUERY_ERROR_CLASS_TIMEOUT,    // ER_LOCK_WAIT_TIMEOUT
+	3024: common.QUERY_ERROR_CLASS_TIMEOUT,    // ER_QUERY_TIMEOUT
 }
 
-func fieldsInfo(db *sql.DB, dbName string, tableNames []string) map[string]interface{} {
-	columns := make(map[string]interface{})
-	for _, tableName := range tableNames {
-		columnRows, err := db.Query(columnSQLStr, dbName, tableName)
-		if err != nil {
-			return nil
-		}
-		tables := make(map[string]interface{})
-
-		for columnRows.Next() {
-			var columnName, columnType string
-			err = columnRows.Scan(&columnName, &columnType)
-			if err != nil {
-				return nil
-			}
-			tables[columnName] = map[string]string{"data_type": columnType}
+var mysqlErrorLinePattern = regexp.MustCompile(`at line (\d+)`)
 
-		}
-		columns[tableName] = tables
+// convertQueryError convert the mysql server error to common.QueryError, the other errors are returned as is.
+func convertQueryError(err error) error {
+	var mysqlError *mysql.MySQLError
+	if !errors.As(err, &mysqlError) {
+		return err
+	}
+	sqlState := ""
+	if mysqlError.SQLState != [5]byte{} {
+		sqlState = string(mysqlError.SQLState[:])
+	}
+	queryError := common.NewQueryError(err, mysqlErrorClasses[mysqlError.Number], strconv.Itoa(int(mysqlError.Number)), sqlState, mysqlError.Message)
+	if matched := mysqlErrorLinePattern.FindStringSubmatch(mysqlError.Message); len(matched) == 2 {
+		lineNumber, _ := strconv.Atoi(matched[1])
+		queryError.SetLocation(lineNumber, 0)
 	}
-	return columns
+	return queryError
 }
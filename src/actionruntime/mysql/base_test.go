@@ -0,0 +1,34 @@
+package mysql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertQueryError(t *testing.T) {
+	err := convertQueryError(&mysql.MySQLError{
+		Number:   1064,
+		SQLState: [5]byte{'4', '2', '0', '0', '0'},
+		Message:  "You have an error in your SQL syntax; check the manual that corresponds to your MySQL server version for the right syntax to use near 'FORM users' at line 3",
+	})
+	queryError, ok := common.AsQueryError(err)
+	assert.True(t, ok)
+	assert.Equal(t, common.QUERY_ERROR_CLASS_SYNTAX, queryError.Class)
+	assert.Equal(t, "1064", queryError.VendorCode)
+	assert.Equal(t, "42000", queryError.SQLState)
+	assert.Equal(t, 3, queryError.LineNumber)
+	assert.Equal(t, 0, queryError.Position, "mysql does not report the position in line")
+
+	// the error number classifies the error when the SQLSTATE is general, the error without line has no location
+	err = convertQueryError(&mysql.MySQLError{Number: 1146, SQLState: [5]byte{'4', '2', 'S', '0', '2'}, Message: "Table 'illa.userz' doesn't exist"})
+	queryError, _ = common.AsQueryError(err)
+	assert.Equal(t, common.QUERY_ERROR_CLASS_NOT_FOUND, queryError.Class)
+	assert.Equal(t, 0, queryError.LineNumber)
+
+	errInDial := errors.New("connection refused")
+	assert.Equal(t, errInDial, convertQueryError(errInDial))
+}
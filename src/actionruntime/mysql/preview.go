@@ -0,0 +1,165 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// the non-transactional tables (like MyISAM, MEMORY) which names are in the candidates,
+// the engine is non-transactional when it is not reported to support transactions.
+const nonTransactionalTablesSQL = `SELECT t.TABLE_SCHEMA, t.TABLE_NAME, t.ENGINE, COALESCE(DATABASE(), '') FROM INFORMATION_SCHEMA.TABLES t
+JOIN INFORMATION_SCHEMA.ENGINES e ON e.ENGINE = t.ENGINE
+WHERE t.TABLE_TYPE = 'BASE TABLE' AND COALESCE(e.TRANSACTIONS, 'NO') <> 'YES' AND LOWER(t.TABLE_NAME) IN (%s)`
+
+// tableReference is a possible table reference in query, the schema is empty when the name is not qualified.
+type tableReference struct {
+	schema string
+	name   string
+}
+
+// matches check if the reference is the table, the unqualified name refers to the table in current database.
+// the names are compared case-insensitively, since it depends on the lower_case_table_names of server.
+func (reference *tableReference) matches(schema string, name string, currentSchema string) bool {
+	if !strings.EqualFold(reference.name, name) {
+		return false
+	}
+	if reference.schema == "" {
+		return strings.EqualFold(schema, currentSchema)
+	}
+	return strings.EqualFold(reference.schema, schema)
+}
+
+// extractTableReferences collect the identifiers and the qualified identifiers outside of the literals and comments,
+// they are the candidates of the tables referenced by query, so every table in query is in them.
+func extractTableReferences(query string) []*tableReference {
+	references := []*tableReference{}
+	previousIdentifier, qualified := "", false
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"':
+			i = skipQuoted(query, i, c)
+			previousIdentifier, qualified = "", false
+		case c == '#' || strings.HasPrefix(query[i:], "-- "):
+			i = skipTo(query, i, "\n")
+			previousIdentifier, qualified = "", false
+		case strings.HasPrefix(query[i:], "/*"):
+			i = skipTo(query, i+2, "*/")
+			previousIdentifier, qualified = "", false
+		case c == '.':
+			qualified = previousIdentifier != ""
+			i++
+		case c == '`' || c == '_' || c == '$' || isLetter(c):
+			var identifier string
+			if c == '`' {
+				end := skipQuoted(query, i, c)
+				identifier = strings.ReplaceAll(strings.TrimSuffix(query[i+1:end], "`"), "``", "`")
+				i = end
+			} else {
+				end := i
+				for end < len(query) && isIdentifierPart(query[end]) {
+					end++
+				}
+				identifier = query[i:end]
+				i = end
+			}
+			references = append(references, &tableReference{name: identifier})
+			if qualified {
+				references = append(references, &tableReference{schema: previousIdentifier, name: identifier})
+			}
+			previousIdentifier, qualified = identifier, false
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		default:
+			i++
+			previousIdentifier, qualified = "", false
+		}
+	}
+	return references
+}
+
+// skipQuoted return the index after the closing quote, the doubled quote and the backslash (except in identifier) are escapes.
+func skipQuoted(query string, start int, quote byte) int {
+	for i := start + 1; i < len(query); i++ {
+		switch {
+		case query[i] == '\\' && quote != '`':
+			i++
+		case query[i] == quote && i+1 < len(query) && query[i+1] == quote:
+			i++
+		case query[i] == quote:
+			return i + 1
+		}
+	}
+	return len(query)
+}
+
+// skipTo return the index after the end mark, or the end of query.
+func skipTo(query string, start int, end string) int {
+	index := strings.Index(query[start:], end)
+	if index < 0 {
+		return len(query)
+	}
+	return start + index + len(end)
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isIdentifierPart(c byte) bool {
+	return isLetter(c) || (c >= '0' && c <= '9') || c == '_' || c == '$'
+}
+
+// checkPreviewTables refuse previewing the query on the non-transactional tables, their changes can not be rolled back.
+// the query is matched with the table names conservatively, a column or alias named as the non-transactional table refuses it too.
+func checkPreviewTables(ctx context.Context, db *sql.DB, query string) error {
+	references := extractTableReferences(query)
+	if len(references) == 0 {
+		return nil
+	}
+	names := map[string]bool{}
+	placeholders := []string{}
+	args := []interface{}{}
+	for _, reference := range references {
+		name := strings.ToLower(reference.name)
+		if names[name] {
+			continue
+		}
+		names[name] = true
+		placeholders = append(placeholders, "?")
+		args = append(args, name)
+	}
+	rows, errInQuery := db.QueryContext(ctx, fmt.Sprintf(nonTransactionalTablesSQL, strings.Join(placeholders, ", ")), args...)
+	if errInQuery != nil {
+		return errInQuery
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var schema, name, engine, currentSchema string
+		if errInScan := rows.Scan(&schema, &name, &engine, &currentSchema); errInScan != nil {
+			return errInScan
+		}
+		for _, reference := range references {
+			if reference.matches(schema, name, currentSchema) {
+				return fmt.Errorf("preview does not support the table %s.%s of non-transactional engine %s, its changes can not be rolled back", schema, name, engine)
+			}
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,45 @@
+package mysql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func exportTableReferences(query string) []tableReference {
+	references := []tableReference{}
+	for _, reference := range extractTableReferences(query) {
+		references = append(references, *reference)
+	}
+	return references
+}
+
+func TestExtractTableReferences(t *testing.T) {
+	testCases := []struct {
+		name     string
+		query    string
+		expected []tableReference
+	}{
+		{name: "bare name", query: "DELETE FROM logs", expected: []tableReference{{name: "DELETE"}, {name: "FROM"}, {name: "logs"}}},
+		{name: "qualified name", query: "UPDATE shop.orders SET a=1", expected: []tableReference{{name: "UPDATE"}, {name: "shop"}, {name: "orders"}, {schema: "shop", name: "orders"}, {name: "SET"}, {name: "a"}}},
+		{name: "quoted identifier", query: "INSERT INTO `shop` . `my``logs` VALUES (1)", expected: []tableReference{{name: "INSERT"}, {name: "INTO"}, {name: "shop"}, {name: "my`logs"}, {schema: "shop", name: "my`logs"}, {name: "VALUES"}}},
+		{name: "literals and comments are skipped", query: "DELETE /* logs */ FROM t -- logs\nWHERE a = 'lo''gs' AND b = \"l\\\"ogs\" # logs", expected: []tableReference{{name: "DELETE"}, {name: "FROM"}, {name: "t"}, {name: "WHERE"}, {name: "a"}, {name: "AND"}, {name: "b"}}},
+		{name: "number is not qualifier", query: "UPDATE t SET a = 1.5", expected: []tableReference{{name: "UPDATE"}, {name: "t"}, {name: "SET"}, {name: "a"}}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, exportTableReferences(testCase.query))
+		})
+	}
+}
+
+func TestTableReferenceMatches(t *testing.T) {
+	unqualified := &tableReference{name: "Logs"}
+	assert.True(t, unqualified.matches("shop", "logs", "shop"), "the unqualified name should match the table in current database")
+	assert.False(t, unqualified.matches("other", "logs", "shop"))
+	assert.False(t, unqualified.matches("shop", "orders", "shop"))
+
+	qualified := &tableReference{schema: "other", name: "logs"}
+	assert.True(t, qualified.matches("other", "logs", "shop"))
+	assert.False(t, qualified.matches("shop", "logs", "shop"))
+}
@@ -0,0 +1,51 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+	"github.com/illacloud/builder-backend/src/utils/resourcelist"
+)
+
+func init() {
+	newConnector := func(resourceType int) func() common.DataConnector {
+		return func() common.DataConnector {
+			return &MySQLConnector{ResourceType: resourceType}
+		}
+	}
+	secretFields := common.WithSSHTunnelSecretFields("databasePassword", "ssl.clientKey")
+	capabilities := common.ConnectorCapabilities{MetaInfo: true, TestConnection: true, GUIMode: true}
+	common.RegisterConnector(common.ConnectorRegistration{
+		Type:         resourcelist.TYPE_MYSQL,
+		ID:           resourcelist.TYPE_MYSQL_ID,
+		Capabilities: capabilities,
+		SecretFields: secretFields,
+		New:          newConnector(resourcelist.TYPE_MYSQL_ID),
+	})
+	common.RegisterConnector(common.ConnectorRegistration{
+		Type:         resourcelist.TYPE_MARIADB,
+		ID:           resourcelist.TYPE_MARIADB_ID,
+		Capabilities: capabilities,
+		SecretFields: secretFields,
+		New:          newConnector(resourcelist.TYPE_MARIADB_ID),
+	})
+	common.RegisterConnector(common.ConnectorRegistration{
+		Type:         resourcelist.TYPE_TIDB,
+		ID:           resourcelist.TYPE_TIDB_ID,
+		Capabilities: capabilities,
+		SecretFields: secretFields,
+		New:          newConnector(resourcelist.TYPE_TIDB_ID),
+	})
+}
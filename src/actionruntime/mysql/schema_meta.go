@@ -0,0 +1,139 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+)
+
+// the information schema only lists the databases which the user has any privilege on, the system databases are skipped
+const accessibleSchemaCondition = `TABLE_SCHEMA NOT IN ('mysql', 'information_schema', 'performance_schema', 'sys')`
+
+const schemaMetaTableCondition = `TABLE_TYPE <> 'SYSTEM VIEW' AND ` + accessibleSchemaCondition
+
+// the tables in the limit, the columns, keys and indexes are fetched for these tables only.
+// it is joined as derived table, mysql does not support LIMIT in IN subquery.
+const schemaMetaLimitedTablesSQL = `SELECT TABLE_SCHEMA, TABLE_NAME FROM INFORMATION_SCHEMA.TABLES
+WHERE ` + schemaMetaTableCondition + `
+ORDER BY TABLE_SCHEMA, TABLE_NAME LIMIT ?`
+
+const (
+	schemaMetaTablesSQL = `SELECT TABLE_SCHEMA, TABLE_NAME, TABLE_TYPE FROM INFORMATION_SCHEMA.TABLES
+WHERE ` + schemaMetaTableCondition + `
+ORDER BY TABLE_SCHEMA, TABLE_NAME LIMIT ?`
+	schemaMetaColumnsSQL = `SELECT c.TABLE_SCHEMA, c.TABLE_NAME, c.COLUMN_NAME, c.COLUMN_TYPE, c.IS_NULLABLE = 'YES', c.COLUMN_DEFAULT FROM INFORMATION_SCHEMA.COLUMNS c
+JOIN (` + schemaMetaLimitedTablesSQL + `) t ON t.TABLE_SCHEMA = c.TABLE_SCHEMA AND t.TABLE_NAME = c.TABLE_NAME
+ORDER BY c.TABLE_SCHEMA, c.TABLE_NAME, c.ORDINAL_POSITION`
+	schemaMetaKeysSQL = `SELECT k.TABLE_SCHEMA, k.TABLE_NAME, k.CONSTRAINT_NAME, k.COLUMN_NAME, COALESCE(k.REFERENCED_TABLE_SCHEMA, ''), COALESCE(k.REFERENCED_TABLE_NAME, ''), COALESCE(k.REFERENCED_COLUMN_NAME, '')
+FROM INFORMATION_SCHEMA.KEY_COLUMN_USAGE k
+JOIN (` + schemaMetaLimitedTablesSQL + `) t ON t.TABLE_SCHEMA = k.TABLE_SCHEMA AND t.TABLE_NAME = k.TABLE_NAME
+WHERE k.CONSTRAINT_NAME = 'PRIMARY' OR k.REFERENCED_TABLE_NAME IS NOT NULL
+ORDER BY k.TABLE_SCHEMA, k.TABLE_NAME, k.CONSTRAINT_NAME, k.ORDINAL_POSITION`
+	schemaMetaIndexesSQL = `SELECT s.TABLE_SCHEMA, s.TABLE_NAME, s.INDEX_NAME, s.NON_UNIQUE = 0, COALESCE(s.COLUMN_NAME, '') FROM INFORMATION_SCHEMA.STATISTICS s
+JOIN (` + schemaMetaLimitedTablesSQL + `) t ON t.TABLE_SCHEMA = s.TABLE_SCHEMA AND t.TABLE_NAME = s.TABLE_NAME
+ORDER BY s.TABLE_SCHEMA, s.TABLE_NAME, s.INDEX_NAME, s.SEQ_IN_INDEX`
+)
+
+// schemaMeta fetch the tables, views, columns, keys and indexes of all accessible databases from the information schema.
+func schemaMeta(ctx context.Context, db *sql.DB) (*common.SchemaMeta, error) {
+	builder := common.NewSchemaMetaBuilder()
+
+	// tables, fetch one more table for detecting the truncation
+	tableRows, err := db.QueryContext(ctx, schemaMetaTablesSQL, common.SCHEMA_META_MAX_TABLES+1)
+	if err != nil {
+		return nil, err
+	}
+	defer tableRows.Close()
+	for tableRows.Next() {
+		var schemaName, tableName, tableType string
+		if err := tableRows.Scan(&schemaName, &tableName, &tableType); err != nil {
+			return nil, err
+		}
+		kind := common.TABLE_KIND_TABLE
+		if tableType == "VIEW" {
+			kind = common.TABLE_KIND_VIEW
+		}
+		builder.AddTable(schemaName, tableName, kind)
+	}
+	if err := tableRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// columns
+	columnRows, err := db.QueryContext(ctx, schemaMetaColumnsSQL, common.SCHEMA_META_MAX_TABLES)
+	if err != nil {
+		return nil, err
+	}
+	defer columnRows.Close()
+	for columnRows.Next() {
+		var schemaName, tableName string
+		var columnDefault sql.NullString
+		column := &common.ColumnMeta{}
+		if err := columnRows.Scan(&schemaName, &tableName, &column.Name, &column.DataType, &column.Nullable, &columnDefault); err != nil {
+			return nil, err
+		}
+		if columnDefault.Valid {
+			column.Default = &columnDefault.String
+		}
+		builder.AddColumn(schemaName, tableName, column)
+	}
+	if err := columnRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// primary keys and foreign keys
+	keyRows, err := db.QueryContext(ctx, schemaMetaKeysSQL, common.SCHEMA_META_MAX_TABLES)
+	if err != nil {
+		return nil, err
+	}
+	defer keyRows.Close()
+	for keyRows.Next() {
+		var schemaName, tableName, constraintName, columnName, referencedSchema, referencedTable, referencedColumn string
+		if err := keyRows.Scan(&schemaName, &tableName, &constraintName, &columnName, &referencedSchema, &referencedTable, &referencedColumn); err != nil {
+			return nil, err
+		}
+		if constraintName == "PRIMARY" {
+			builder.AddPrimaryKeyColumn(schemaName, tableName, columnName)
+		} else {
+			builder.AddForeignKeyColumn(schemaName, tableName, constraintName, columnName, referencedSchema, referencedTable, referencedColumn)
+		}
+	}
+	if err := keyRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// indexes, the column name is empty for the functional key part
+	indexRows, err := db.QueryContext(ctx, schemaMetaIndexesSQL, common.SCHEMA_META_MAX_TABLES)
+	if err != nil {
+		return nil, err
+	}
+	defer indexRows.Close()
+	for indexRows.Next() {
+		var schemaName, tableName, indexName, columnName string
+		var unique bool
+		if err := indexRows.Scan(&schemaName, &tableName, &indexName, &unique, &columnName); err != nil {
+			return nil, err
+		}
+		builder.AddIndexColumn(schemaName, tableName, indexName, unique, indexName == "PRIMARY", columnName)
+	}
+	if err := indexRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return builder.Build(), nil
+}
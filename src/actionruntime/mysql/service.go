@@ -15,6 +15,8 @@
 package mysql
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 
@@ -25,9 +27,11 @@ import (
 	"github.com/mitchellh/mapstructure"
 )
 
+// MySQLConnector serves the mysql, mariadb and tidb resources, the ResourceType tells which one.
 type MySQLConnector struct {
-	Resource MySQLOptions
-	Action   MySQLQuery
+	ResourceType int
+	Resource     MySQLOptions
+	Action       MySQLQuery
 }
 
 func (m *MySQLConnector) ValidateResourceOptions(resourceOptions map[string]interface{}) (common.ValidateResult, error) {
@@ -58,7 +62,7 @@ func (m *MySQLConnector) ValidateActionTemplate(actionOptions map[string]interfa
 	return common.ValidateResult{Valid: true}, nil
 }
 
-func (m *MySQLConnector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (m *MySQLConnector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	// get mysql connection
 	db, err := m.getConnectionWithOptions(resourceOptions)
 	if err != nil {
@@ -67,40 +71,56 @@ func (m *MySQLConnector) TestConnection(resourceOptions map[string]interface{})
 	defer db.Close()
 
 	// test mysql connection
-	if err := db.Ping(); err != nil {
+	if err := db.PingContext(ctx); err != nil {
 		return common.ConnectionResult{Success: false}, err
 	}
 	return common.ConnectionResult{Success: true}, nil
 }
 
-func (m *MySQLConnector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (m *MySQLConnector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 	// get mysql connection
-	db, err := m.getConnectionWithOptions(resourceOptions)
+	db, releaseConnection, err := m.getPooledConnectionWithOptions(ctx, resourceOptions)
 	if err != nil {
 		return common.MetaInfoResult{Success: false}, err
 	}
-	defer db.Close()
+	defer releaseConnection()
 
 	// test mysql connection
-	if err := db.Ping(); err != nil {
+	if err := db.PingContext(ctx); err != nil {
 		return common.MetaInfoResult{Success: false}, err
 	}
 
-	columns := fieldsInfo(db, m.Resource.DatabaseName, tablesInfo(db, m.Resource.DatabaseName))
+	detail, err := schemaMeta(ctx, db)
+	if err != nil {
+		return common.MetaInfoResult{Success: false}, err
+	}
 
 	return common.MetaInfoResult{
 		Success: true,
-		Schema:  columns,
+		Schema:  detail.ExportLegacySchema(m.Resource.DatabaseName),
+		Detail:  detail,
 	}, nil
 }
 
-func (m *MySQLConnector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+func (m *MySQLConnector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+	return common.CollectStream(ctx, func(emit common.RowEmitter) (common.RuntimeResult, error) {
+		return m.RunStream(ctx, resourceOptions, actionOptions, rawActionOptions, emit)
+	})
+}
+
+// RunStream run the action and emit the selected rows one by one, the driver errors are converted to common.QueryError.
+func (m *MySQLConnector) RunStream(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}, emit common.RowEmitter) (common.RuntimeResult, error) {
+	result, err := m.runStream(ctx, resourceOptions, actionOptions, rawActionOptions, emit)
+	return result, convertQueryError(err)
+}
+
+func (m *MySQLConnector) runStream(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}, emit common.RowEmitter) (common.RuntimeResult, error) {
 	// get mysql connection
-	db, err := m.getConnectionWithOptions(resourceOptions)
+	db, releaseConnection, err := m.getPooledConnectionWithOptions(ctx, resourceOptions)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, errors.New("failed to get mysql connection")
 	}
-	defer db.Close()
+	defer releaseConnection()
 
 	// format query
 	if err := mapstructure.Decode(actionOptions, &m.Action); err != nil {
@@ -125,6 +145,9 @@ func (m *MySQLConnector) Run(resourceOptions map[string]interface{}, actionOptio
 	if errInEscapeSQL != nil {
 		return queryResult, errInEscapeSQL
 	}
+	if errInCheckReadOnly := common.CheckReadOnlySQL(resourceOptions, resourcelist.TYPE_MYSQL_ID, escapedSQL); errInCheckReadOnly != nil {
+		return queryResult, errInCheckReadOnly
+	}
 	isSelectQuery := false
 	lexer := parser_sql.NewLexer(m.Action.Query)
 	isSelectQuery, err = parser_sql.IsSelectSQL(lexer)
@@ -132,33 +155,40 @@ func (m *MySQLConnector) Run(resourceOptions map[string]interface{}, actionOptio
 		return common.RuntimeResult{Success: false}, err
 	}
 
+	// run in read only transaction on read-only resource
+	queryer, endReadOnlyTx, err := m.beginReadOnlyTx(ctx, db, resourceOptions)
+	if err != nil {
+		return queryResult, err
+	}
+	defer endReadOnlyTx()
+
 	// fetch data
 	if isSelectQuery && m.Action.IsSafeMode() {
-		rows, err := db.Query(escapedSQL, sqlArgs...)
+		rows, err := queryer.QueryContext(ctx, escapedSQL, sqlArgs...)
 		if err != nil {
 			return queryResult, err
 		}
-		mapRes, err := common.RetrieveToMap(rows)
+		defer rows.Close()
+		columns, err := common.EmitRows(rows, emit)
 		if err != nil {
 			return queryResult, err
 		}
-		defer rows.Close()
+		queryResult.Columns = columns
 		queryResult.Success = true
-		queryResult.Rows = mapRes
 	} else if isSelectQuery && !m.Action.IsSafeMode() {
-		rows, err := db.Query(escapedSQL)
+		rows, err := queryer.QueryContext(ctx, escapedSQL)
 		if err != nil {
 			return queryResult, err
 		}
-		mapRes, err := common.RetrieveToMap(rows)
+		defer rows.Close()
+		columns, err := common.EmitRows(rows, emit)
 		if err != nil {
 			return queryResult, err
 		}
-		defer rows.Close()
+		queryResult.Columns = columns
 		queryResult.Success = true
-		queryResult.Rows = mapRes
 	} else if !isSelectQuery && m.Action.IsSafeMode() {
-		execResult, err := db.Exec(escapedSQL, sqlArgs...)
+		execResult, err := queryer.ExecContext(ctx, escapedSQL, sqlArgs...)
 		if err != nil {
 			return queryResult, err
 		}
@@ -169,7 +199,7 @@ func (m *MySQLConnector) Run(resourceOptions map[string]interface{}, actionOptio
 		queryResult.Success = true
 		queryResult.Extra["message"] = fmt.Sprintf("Affeted %d rows.", affectedRows)
 	} else if !isSelectQuery && !m.Action.IsSafeMode() {
-		execResult, err := db.Exec(escapedSQL)
+		execResult, err := queryer.ExecContext(ctx, escapedSQL)
 		if err != nil {
 			return queryResult, err
 		}
@@ -183,3 +213,85 @@ func (m *MySQLConnector) Run(resourceOptions map[string]interface{}, actionOptio
 
 	return queryResult, nil
 }
+
+// RunPreview run the single DML statement in a transaction then roll it back, the driver errors are converted to common.QueryError.
+// the query on non-transactional tables (like MyISAM) is refused, since its changes can not be rolled back.
+func (m *MySQLConnector) RunPreview(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+	result, err := m.runPreview(ctx, resourceOptions, actionOptions, rawActionOptions)
+	return result, convertQueryError(err)
+}
+
+func (m *MySQLConnector) runPreview(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+	// get mysql connection
+	db, releaseConnection, err := m.getPooledConnectionWithOptions(ctx, resourceOptions)
+	if err != nil {
+		return common.RuntimeResult{Success: false}, errors.New("failed to get mysql connection")
+	}
+	defer releaseConnection()
+
+	query, errInPrepare := m.prepareQuery(resourceOptions, actionOptions, rawActionOptions)
+	if errInPrepare != nil {
+		return common.RuntimeResult{Success: false}, errInPrepare
+	}
+	statement, errInCheckPreview := common.CheckPreviewSQL(resourcelist.TYPE_MYSQL_ID, query.SQL)
+	if errInCheckPreview != nil {
+		return common.RuntimeResult{Success: false}, errInCheckPreview
+	}
+	// all tables of tidb are transactional
+	if m.ResourceType != resourcelist.TYPE_TIDB_ID {
+		if errInCheckTables := checkPreviewTables(ctx, db, query.SQL); errInCheckTables != nil {
+			return common.RuntimeResult{Success: false}, errInCheckTables
+		}
+	}
+	return common.RunPreviewInSQLTx(ctx, db, statement, query.SQL, query.Args...)
+}
+
+// BeginTransaction begin a transaction on the resource, the actions run in it are committed or rolled back together.
+func (m *MySQLConnector) BeginTransaction(ctx context.Context, resourceOptions map[string]interface{}) (common.ActionTransaction, error) {
+	db, releaseConnection, err := m.getPooledConnectionWithOptions(ctx, resourceOptions)
+	if err != nil {
+		return nil, errors.New("failed to get mysql connection")
+	}
+	return common.BeginSQLActionTransaction(ctx, db, m.exportTxOptions(resourceOptions), releaseConnection, func(actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (*common.PreparedQuery, error) {
+		return m.prepareQuery(resourceOptions, actionOptions, rawActionOptions)
+	}, convertQueryError)
+}
+
+// prepareQuery decode the action and escape the sql, the read-only resource is checked.
+func (m *MySQLConnector) prepareQuery(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (*common.PreparedQuery, error) {
+	m.Action = MySQLQuery{}
+	if err := mapstructure.Decode(actionOptions, &m.Action); err != nil {
+		return nil, err
+	}
+	if errInSetRawQuery := m.Action.SetRawQueryAndContext(rawActionOptions); errInSetRawQuery != nil {
+		return nil, errInSetRawQuery
+	}
+	sqlEscaper := parser_sql.NewSQLEscaper(resourcelist.TYPE_MYSQL_ID)
+	escapedSQL, sqlArgs, errInEscapeSQL := sqlEscaper.EscapeSQLActionTemplate(m.Action.RawQuery, m.Action.Context, m.Action.IsSafeMode())
+	if errInEscapeSQL != nil {
+		return nil, errInEscapeSQL
+	}
+	if errInCheckReadOnly := common.CheckReadOnlySQL(resourceOptions, resourcelist.TYPE_MYSQL_ID, escapedSQL); errInCheckReadOnly != nil {
+		return nil, errInCheckReadOnly
+	}
+	if !m.Action.IsSafeMode() {
+		sqlArgs = nil
+	}
+	return common.NewPreparedQuery(resourcelist.TYPE_MYSQL_ID, escapedSQL, sqlArgs)
+}
+
+// beginReadOnlyTx begin the read only transaction for read-only resource, see common.BeginReadOnlySQLTx.
+// tidb is skipped, since it refuses the read only transaction unless the noop functions enabled.
+func (m *MySQLConnector) beginReadOnlyTx(ctx context.Context, db *sql.DB, resourceOptions map[string]interface{}) (common.SQLQueryer, func(), error) {
+	if m.ResourceType == resourcelist.TYPE_TIDB_ID {
+		return db, func() {}, nil
+	}
+	return common.BeginReadOnlySQLTx(ctx, db, resourceOptions)
+}
+
+func (m *MySQLConnector) exportTxOptions(resourceOptions map[string]interface{}) *sql.TxOptions {
+	if m.ResourceType == resourcelist.TYPE_TIDB_ID {
+		return nil
+	}
+	return common.ExportSQLTxOptions(resourceOptions)
+}
@@ -32,6 +32,7 @@ type MySQLOptions struct {
 	DatabaseUsername string `validate:"required"`
 	DatabasePassword string `validate:"required"`
 	SSL              SSLOptions
+	SSHTunnel        common.SSHTunnelOptions
 }
 
 type SSLOptions struct {
@@ -15,25 +15,27 @@
 package oracle
 
 import (
+	"context"
 	"database/sql"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
 	"github.com/mitchellh/mapstructure"
 	_ "github.com/sijms/go-ora/v2"
 	go_ora "github.com/sijms/go-ora/v2"
+	"github.com/sijms/go-ora/v2/network"
 )
 
 const (
+	CONNECTION_POOL_TYPE = "oracle"
 	CONNECTION_SID       = "SID"
 	CONNECTION_SERVICE   = "Service"
 	ACTION_SQL_MODE      = "sql"
 	ACTION_SQL_SAFE_MODE = "sql-safe"
 	ACTION_GUI_MODE      = "gui"
 	ACTION_GUI_TYPE      = "bulk_insert"
-
-	columnsSQL = "SELECT tabs.table_name, tabs.tablespace_name, cols.column_name, cols.data_type FROM user_tables tabs JOIN user_tab_columns cols ON tabs.table_name = cols.table_name LEFT JOIN user_cons_columns col_cons ON cols.column_name = col_cons.column_name AND cols.table_name = col_cons.table_name WHERE tabs.tablespace_name IS NOT NULL"
 )
 
 func (o *Connector) getConnectionWithOptions(resourceOptions map[string]interface{}) (*sql.DB, error) {
@@ -65,39 +67,67 @@ func (o *Connector) getConnectionWithOptions(resourceOptions map[string]interfac
 		return nil, err
 	}
 
+	// dial through the bastion host when the ssh tunnel enabled, the dialer can only be set on the connector of driver
+	dial, err := common.NewSSHTunnelDialFunc(o.resourceOptions.SSHTunnel)
+	if err != nil || dial == nil {
+		return db, err
+	}
+	connector, err := db.Driver().(*go_ora.OracleDriver).OpenConnector(databaseURL)
+	db.Close()
+	if err != nil {
+		return nil, err
+	}
+	connector.(*go_ora.OracleConnector).Dialer(dial)
+	db = sql.OpenDB(connector)
+
 	return db, nil
 }
 
-func mapColumns(db *sql.DB) map[string]interface{} {
-	columnRows, err := db.Query(columnsSQL)
-	if err != nil {
-		return nil
+// getPooledConnectionWithOptions return the pooled *sql.DB for resource, call release after the query finished.
+func (o *Connector) getPooledConnectionWithOptions(ctx context.Context, resourceOptions map[string]interface{}) (*sql.DB, func(), error) {
+	if err := mapstructure.Decode(resourceOptions, &o.resourceOptions); err != nil {
+		return nil, nil, err
 	}
+	return common.GetConnectionPoolManager().AcquireSQLDB(ctx, CONNECTION_POOL_TYPE, resourceOptions, func() (*sql.DB, error) {
+		return o.getConnectionWithOptions(resourceOptions)
+	})
+}
 
-	tables := make(map[string]map[string]map[string]string)
+// the oracle error codes (ORA-xxxxx), oracle returns no SQLSTATE
+var oracleErrorClasses = map[int]string{
+	900:   common.QUERY_ERROR_CLASS_SYNTAX,     // invalid SQL statement
+	903:   common.QUERY_ERROR_CLASS_SYNTAX,     // invalid table name
+	905:   common.QUERY_ERROR_CLASS_SYNTAX,     // missing keyword
+	907:   common.QUERY_ERROR_CLASS_SYNTAX,     // missing right parenthesis
+	911:   common.QUERY_ERROR_CLASS_SYNTAX,     // invalid character
+	917:   common.QUERY_ERROR_CLASS_SYNTAX,     // missing comma
+	923:   common.QUERY_ERROR_CLASS_SYNTAX,     // FROM keyword not found where expected
+	933:   common.QUERY_ERROR_CLASS_SYNTAX,     // SQL command not properly ended
+	936:   common.QUERY_ERROR_CLASS_SYNTAX,     // missing expression
+	904:   common.QUERY_ERROR_CLASS_NOT_FOUND,  // invalid identifier
+	942:   common.QUERY_ERROR_CLASS_NOT_FOUND,  // table or view does not exist
+	1:     common.QUERY_ERROR_CLASS_CONSTRAINT, // unique constraint violated
+	1400:  common.QUERY_ERROR_CLASS_CONSTRAINT, // cannot insert NULL
+	2291:  common.QUERY_ERROR_CLASS_CONSTRAINT, // parent key not found
+	2292:  common.QUERY_ERROR_CLASS_CONSTRAINT, // child record found
+	1017:  common.QUERY_ERROR_CLASS_PERMISSION, // invalid username/password
+	1031:  common.QUERY_ERROR_CLASS_PERMISSION, // insufficient privileges
+	1722:  common.QUERY_ERROR_CLASS_DATA,       // invalid number
+	1861:  common.QUERY_ERROR_CLASS_DATA,       // literal does not match format string
+	12899: common.QUERY_ERROR_CLASS_DATA,       // value too large for column
+	1013:  common.QUERY_ERROR_CLASS_TIMEOUT,    // user requested cancel of current operation
+}
 
-	for columnRows.Next() {
-		var table, tablespace, column, columnType string
-		err = columnRows.Scan(&table, &tablespace, &column, &columnType)
-		if err != nil {
-			return nil
-		}
-		tableStr := fmt.Sprintf("%s.%s", tablespace, table)
-		columnMap := map[string]string{"data_type": columnType}
-		if _, ok := tables[tableStr]; ok {
-			tables[tableStr][column] = columnMap
-			continue
-		} else {
-			tables[tableStr] = map[string]map[string]string{column: columnMap}
-			continue
-		}
+// convertQueryError convert the oracle server error to common.QueryError, the other errors are returned as is.
+// oracle does not return the error position with the message, so the location is unknown.
+func convertQueryError(err error) error {
+	var oracleError *network.OracleError
+	if !errors.As(err, &oracleError) {
+		return err
 	}
-
-	res := make(map[string]interface{})
-	b, _ := json.Marshal(&tables)
-	if err := json.Unmarshal(b, &res); err != nil {
-		return nil
+	class, hit := oracleErrorClasses[oracleError.ErrCode]
+	if !hit {
+		class = common.QUERY_ERROR_CLASS_UNKNOWN
 	}
-
-	return res
+	return common.NewQueryError(err, class, fmt.Sprintf("ORA-%05d", oracleError.ErrCode), "", oracleError.Error())
 }
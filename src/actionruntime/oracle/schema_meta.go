@@ -0,0 +1,158 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oracle
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+)
+
+// the tables, views and materialized views of the connected user, the recycle bin, IOT overflow, nested and domain index tables are skipped.
+// the container table of materialized view is listed as the materialized view only.
+const schemaMetaObjectsSQL = `SELECT table_name object_name, 'TABLE' object_kind FROM user_tables
+WHERE table_name NOT LIKE 'BIN$%' AND NVL(iot_type, 'IOT') = 'IOT' AND nested = 'NO' AND secondary = 'N'
+AND table_name NOT IN (SELECT mview_name FROM user_mviews)
+UNION ALL SELECT view_name, 'VIEW' FROM user_views
+UNION ALL SELECT mview_name, 'MATERIALIZED VIEW' FROM user_mviews
+ORDER BY object_name`
+
+// the tables in the limit, the columns, keys and indexes are fetched for these tables only
+const schemaMetaLimitedTablesSQL = `SELECT object_name FROM (` + schemaMetaObjectsSQL + `) WHERE ROWNUM <= :1`
+
+// the schema of user objects is the connected user
+const (
+	schemaMetaTablesSQL  = `SELECT USER, object_name, object_kind FROM (` + schemaMetaObjectsSQL + `) WHERE ROWNUM <= :1`
+	schemaMetaColumnsSQL = `SELECT USER, table_name, column_name, data_type, nullable FROM user_tab_columns
+WHERE table_name IN (` + schemaMetaLimitedTablesSQL + `)
+ORDER BY table_name, column_id`
+	schemaMetaPrimaryKeysSQL = `SELECT USER, c.table_name, cc.column_name FROM user_constraints c
+JOIN user_cons_columns cc ON cc.constraint_name = c.constraint_name
+WHERE c.constraint_type = 'P' AND c.table_name IN (` + schemaMetaLimitedTablesSQL + `)
+ORDER BY c.table_name, cc.position`
+	schemaMetaForeignKeysSQL = `SELECT USER, c.table_name, c.constraint_name, cc.column_name, rc.owner, rc.table_name, rcc.column_name FROM user_constraints c
+JOIN user_cons_columns cc ON cc.constraint_name = c.constraint_name
+JOIN all_constraints rc ON rc.owner = c.r_owner AND rc.constraint_name = c.r_constraint_name
+JOIN all_cons_columns rcc ON rcc.owner = rc.owner AND rcc.constraint_name = rc.constraint_name AND rcc.position = cc.position
+WHERE c.constraint_type = 'R' AND c.table_name IN (` + schemaMetaLimitedTablesSQL + `)
+ORDER BY c.table_name, c.constraint_name, cc.position`
+	schemaMetaIndexesSQL = `SELECT USER, i.table_name, i.index_name, i.uniqueness, CASE WHEN pk.constraint_name IS NULL THEN 'N' ELSE 'Y' END, ic.column_name FROM user_indexes i
+JOIN user_ind_columns ic ON ic.index_name = i.index_name
+LEFT JOIN user_constraints pk ON pk.constraint_type = 'P' AND pk.table_name = i.table_name AND pk.index_name = i.index_name
+WHERE i.table_owner = USER AND i.table_name IN (` + schemaMetaLimitedTablesSQL + `)
+ORDER BY i.table_name, i.index_name, ic.column_position`
+)
+
+var schemaMetaTableKinds = map[string]string{
+	"TABLE":             common.TABLE_KIND_TABLE,
+	"VIEW":              common.TABLE_KIND_VIEW,
+	"MATERIALIZED VIEW": common.TABLE_KIND_MATERIALIZED_VIEW,
+}
+
+// schemaMeta fetch the tables, views, columns, keys and indexes of the connected user from the data dictionary views.
+// the column default is not fetched, since it is in LONG column.
+func schemaMeta(ctx context.Context, db *sql.DB) (*common.SchemaMeta, error) {
+	builder := common.NewSchemaMetaBuilder()
+
+	// tables, fetch one more table for detecting the truncation
+	tableRows, err := db.QueryContext(ctx, schemaMetaTablesSQL, common.SCHEMA_META_MAX_TABLES+1)
+	if err != nil {
+		return nil, err
+	}
+	defer tableRows.Close()
+	for tableRows.Next() {
+		var schemaName, tableName, objectKind string
+		if err := tableRows.Scan(&schemaName, &tableName, &objectKind); err != nil {
+			return nil, err
+		}
+		builder.AddTable(schemaName, tableName, schemaMetaTableKinds[objectKind])
+	}
+	if err := tableRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// columns
+	columnRows, err := db.QueryContext(ctx, schemaMetaColumnsSQL, common.SCHEMA_META_MAX_TABLES)
+	if err != nil {
+		return nil, err
+	}
+	defer columnRows.Close()
+	for columnRows.Next() {
+		var schemaName, tableName, nullable string
+		column := &common.ColumnMeta{}
+		if err := columnRows.Scan(&schemaName, &tableName, &column.Name, &column.DataType, &nullable); err != nil {
+			return nil, err
+		}
+		column.Nullable = nullable == "Y"
+		builder.AddColumn(schemaName, tableName, column)
+	}
+	if err := columnRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// primary keys
+	primaryKeyRows, err := db.QueryContext(ctx, schemaMetaPrimaryKeysSQL, common.SCHEMA_META_MAX_TABLES)
+	if err != nil {
+		return nil, err
+	}
+	defer primaryKeyRows.Close()
+	for primaryKeyRows.Next() {
+		var schemaName, tableName, columnName string
+		if err := primaryKeyRows.Scan(&schemaName, &tableName, &columnName); err != nil {
+			return nil, err
+		}
+		builder.AddPrimaryKeyColumn(schemaName, tableName, columnName)
+	}
+	if err := primaryKeyRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// foreign keys
+	foreignKeyRows, err := db.QueryContext(ctx, schemaMetaForeignKeysSQL, common.SCHEMA_META_MAX_TABLES)
+	if err != nil {
+		return nil, err
+	}
+	defer foreignKeyRows.Close()
+	for foreignKeyRows.Next() {
+		var schemaName, tableName, foreignKeyName, columnName, referencedSchema, referencedTable, referencedColumn string
+		if err := foreignKeyRows.Scan(&schemaName, &tableName, &foreignKeyName, &columnName, &referencedSchema, &referencedTable, &referencedColumn); err != nil {
+			return nil, err
+		}
+		builder.AddForeignKeyColumn(schemaName, tableName, foreignKeyName, columnName, referencedSchema, referencedTable, referencedColumn)
+	}
+	if err := foreignKeyRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// indexes, the primary index is the index of primary key constraint
+	indexRows, err := db.QueryContext(ctx, schemaMetaIndexesSQL, common.SCHEMA_META_MAX_TABLES)
+	if err != nil {
+		return nil, err
+	}
+	defer indexRows.Close()
+	for indexRows.Next() {
+		var schemaName, tableName, indexName, uniqueness, primary, columnName string
+		if err := indexRows.Scan(&schemaName, &tableName, &indexName, &uniqueness, &primary, &columnName); err != nil {
+			return nil, err
+		}
+		builder.AddIndexColumn(schemaName, tableName, indexName, uniqueness == "UNIQUE", primary == "Y", columnName)
+	}
+	if err := indexRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return builder.Build(), nil
+}
@@ -15,6 +15,7 @@
 package oracle
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -60,7 +61,7 @@ func (o *Connector) ValidateActionTemplate(actionOptions map[string]interface{})
 	return common.ValidateResult{Valid: true}, nil
 }
 
-func (o *Connector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (o *Connector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	// get oracle connection
 	db, err := o.getConnectionWithOptions(resourceOptions)
 	if err != nil {
@@ -69,41 +70,58 @@ func (o *Connector) TestConnection(resourceOptions map[string]interface{}) (comm
 	defer db.Close()
 
 	// test oracle connection
-	if err := db.Ping(); err != nil {
+	if err := db.PingContext(ctx); err != nil {
 		return common.ConnectionResult{Success: false}, err
 	}
 
 	return common.ConnectionResult{Success: true}, nil
 }
 
-func (o *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (o *Connector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 	// get oracle connection
-	db, err := o.getConnectionWithOptions(resourceOptions)
+	db, releaseConnection, err := o.getPooledConnectionWithOptions(ctx, resourceOptions)
 	if err != nil {
 		return common.MetaInfoResult{Success: false}, err
 	}
-	defer db.Close()
+	defer releaseConnection()
 
 	// test oracle connection
-	if err := db.Ping(); err != nil {
+	if err := db.PingContext(ctx); err != nil {
 		return common.MetaInfoResult{Success: false}, err
 	}
 
-	columns := mapColumns(db)
+	// get oracle tables information of the connected user, the tables are keyed by "schema.table" in legacy schema
+	detail, err := schemaMeta(ctx, db)
+	if err != nil {
+		return common.MetaInfoResult{Success: false}, err
+	}
 
 	return common.MetaInfoResult{
 		Success: true,
-		Schema:  columns,
+		Schema:  detail.ExportLegacySchema(""),
+		Detail:  detail,
 	}, nil
 }
 
-func (o *Connector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+func (o *Connector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+	return common.CollectStream(ctx, func(emit common.RowEmitter) (common.RuntimeResult, error) {
+		return o.RunStream(ctx, resourceOptions, actionOptions, rawActionOptions, emit)
+	})
+}
+
+// RunStream run the action and emit the selected rows one by one, the driver errors are converted to common.QueryError.
+func (o *Connector) RunStream(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}, emit common.RowEmitter) (common.RuntimeResult, error) {
+	result, err := o.runStream(ctx, resourceOptions, actionOptions, rawActionOptions, emit)
+	return result, convertQueryError(err)
+}
+
+func (o *Connector) runStream(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}, emit common.RowEmitter) (common.RuntimeResult, error) {
 	// get Oracle connection
-	db, err := o.getConnectionWithOptions(resourceOptions)
+	db, releaseConnection, err := o.getPooledConnectionWithOptions(ctx, resourceOptions)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, errors.New("failed to get oracle connection")
 	}
-	defer db.Close()
+	defer releaseConnection()
 	// format query
 	if err := mapstructure.Decode(actionOptions, &o.actionOptions); err != nil {
 		return common.RuntimeResult{Success: false}, err
@@ -129,6 +147,9 @@ func (o *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 		if errInEscapeSQL != nil {
 			return queryResult, errInEscapeSQL
 		}
+		if errInCheckReadOnly := common.CheckReadOnlySQL(resourceOptions, resourcelist.TYPE_ORACLE_ID, escapedSQL); errInCheckReadOnly != nil {
+			return queryResult, errInCheckReadOnly
+		}
 		// check if o.actionOptions.Opts.Raw is select query
 		isSelectQuery := false
 
@@ -142,36 +163,43 @@ func (o *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 			return common.RuntimeResult{Success: false}, err
 		}
 
+		// run in read only transaction on read-only resource
+		queryer, endReadOnlyTx, errInBegin := common.BeginReadOnlySQLTxByStatement(ctx, db, resourceOptions, common.ORACLE_SET_TRANSACTION_READ_ONLY)
+		if errInBegin != nil {
+			return queryResult, errInBegin
+		}
+		defer endReadOnlyTx()
+
 		// fetch data
 		if isSelectQuery && o.actionOptions.IsSafeMode() {
 			fmt.Printf("[oracle] [RUN] isSelectQuery, IsSafeMode, escapedSQL: %s\n", escapedSQL)
-			rows, err := db.Query(escapedSQL, sqlArgs...)
+			rows, err := queryer.QueryContext(ctx, escapedSQL, sqlArgs...)
 			if err != nil {
 				return queryResult, err
 			}
-			mapRes, err := common.RetrieveToMap(rows)
+			defer rows.Close()
+			columns, err := common.EmitRows(rows, emit)
 			if err != nil {
 				return queryResult, err
 			}
-			defer rows.Close()
+			queryResult.Columns = columns
 			queryResult.Success = true
-			queryResult.Rows = mapRes
 		} else if isSelectQuery && !o.actionOptions.IsSafeMode() {
 			fmt.Printf("[oracle] [RUN] isSelectQuery, !IsSafeMode, query.Raw: %s\n", query.Raw)
-			rows, err := db.Query(escapedSQL)
+			rows, err := queryer.QueryContext(ctx, escapedSQL)
 			if err != nil {
 				return queryResult, err
 			}
-			mapRes, err := common.RetrieveToMap(rows)
+			defer rows.Close()
+			columns, err := common.EmitRows(rows, emit)
 			if err != nil {
 				return queryResult, err
 			}
-			defer rows.Close()
+			queryResult.Columns = columns
 			queryResult.Success = true
-			queryResult.Rows = mapRes
 		} else if !isSelectQuery && o.actionOptions.IsSafeMode() {
 			fmt.Printf("[oracle] [RUN] !isSelectQuery, IsSafeMode, escapedSQL: %s\n", escapedSQL)
-			execResult, err := db.Exec(escapedSQL, sqlArgs...)
+			execResult, err := queryer.ExecContext(ctx, escapedSQL, sqlArgs...)
 			if err != nil {
 				return queryResult, err
 			}
@@ -183,7 +211,7 @@ func (o *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 			queryResult.Extra["message"] = fmt.Sprintf("Affeted %d rows.", affectedRows)
 		} else if !isSelectQuery && !o.actionOptions.IsSafeMode() {
 			fmt.Printf("[oracle] [RUN] !isSelectQuery, !IsSafeMode, query.Raw: %s\n", query.Raw)
-			execResult, err := db.Exec(escapedSQL)
+			execResult, err := queryer.ExecContext(ctx, escapedSQL)
 			if err != nil {
 				return queryResult, err
 			}
@@ -15,12 +15,13 @@
 package oracle9i
 
 import (
-	"database/sql/driver"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
 	go_ora_v1 "github.com/illacloud/go-ora-v1"
+	"github.com/illacloud/go-ora-v1/network"
 	"github.com/mitchellh/mapstructure"
 )
 
@@ -31,8 +32,6 @@ const (
 	ACTION_SQL_SAFE_MODE = "sql-safe"
 	ACTION_GUI_MODE      = "gui"
 	ACTION_GUI_TYPE      = "bulk_insert"
-
-	columnsSQL = "SELECT tabs.table_name, tabs.tablespace_name, cols.column_name, cols.data_type FROM user_tables tabs JOIN user_tab_columns cols ON tabs.table_name = cols.table_name LEFT JOIN user_cons_columns col_cons ON cols.column_name = col_cons.column_name AND cols.table_name = col_cons.table_name WHERE tabs.tablespace_name IS NOT NULL"
 )
 
 func (o *Connector) getConnectionWithOptions(resourceOptions map[string]interface{}) (*go_ora_v1.Connection, error) {
@@ -53,6 +52,10 @@ func (o *Connector) getConnectionWithOptions(resourceOptions map[string]interfac
 	} else if o.resourceOptions.Type == CONNECTION_SERVICE {
 		serviceName = o.resourceOptions.Name
 	}
+	// the driver has no dialer hook, so the connection can not be dialed through the bastion host
+	if o.resourceOptions.SSHTunnel.Enabled {
+		return nil, errors.New("ssh tunnel is not supported by oracle 9i resource")
+	}
 	port, err := strconv.Atoi(o.resourceOptions.Port)
 	if err != nil {
 		return nil, err
@@ -69,57 +72,41 @@ func (o *Connector) getConnectionWithOptions(resourceOptions map[string]interfac
 	return db, nil
 }
 
-func mapColumns(db *go_ora_v1.Connection) map[string]interface{} {
-	stmt := go_ora_v1.NewStmt(columnsSQL, db)
-	defer stmt.Close()
-
-	columnRows, err := stmt.Query(nil)
-	if err != nil {
-		return nil
-	}
-	defer columnRows.Close()
-
-	columns := columnRows.Columns()
-	values := make([]driver.Value, len(columns))
-
-	tables := make(map[string]map[string]map[string]string)
+// the oracle error codes (ORA-xxxxx), oracle returns no SQLSTATE
+var oracleErrorClasses = map[int]string{
+	900:   common.QUERY_ERROR_CLASS_SYNTAX,     // invalid SQL statement
+	903:   common.QUERY_ERROR_CLASS_SYNTAX,     // invalid table name
+	905:   common.QUERY_ERROR_CLASS_SYNTAX,     // missing keyword
+	907:   common.QUERY_ERROR_CLASS_SYNTAX,     // missing right parenthesis
+	911:   common.QUERY_ERROR_CLASS_SYNTAX,     // invalid character
+	917:   common.QUERY_ERROR_CLASS_SYNTAX,     // missing comma
+	923:   common.QUERY_ERROR_CLASS_SYNTAX,     // FROM keyword not found where expected
+	933:   common.QUERY_ERROR_CLASS_SYNTAX,     // SQL command not properly ended
+	936:   common.QUERY_ERROR_CLASS_SYNTAX,     // missing expression
+	904:   common.QUERY_ERROR_CLASS_NOT_FOUND,  // invalid identifier
+	942:   common.QUERY_ERROR_CLASS_NOT_FOUND,  // table or view does not exist
+	1:     common.QUERY_ERROR_CLASS_CONSTRAINT, // unique constraint violated
+	1400:  common.QUERY_ERROR_CLASS_CONSTRAINT, // cannot insert NULL
+	2291:  common.QUERY_ERROR_CLASS_CONSTRAINT, // parent key not found
+	2292:  common.QUERY_ERROR_CLASS_CONSTRAINT, // child record found
+	1017:  common.QUERY_ERROR_CLASS_PERMISSION, // invalid username/password
+	1031:  common.QUERY_ERROR_CLASS_PERMISSION, // insufficient privileges
+	1722:  common.QUERY_ERROR_CLASS_DATA,       // invalid number
+	1861:  common.QUERY_ERROR_CLASS_DATA,       // literal does not match format string
+	12899: common.QUERY_ERROR_CLASS_DATA,       // value too large for column
+	1013:  common.QUERY_ERROR_CLASS_TIMEOUT,    // user requested cancel of current operation
+}
 
-	for {
-		errInFetchNextRows := columnRows.Next(values)
-		if errInFetchNextRows != nil {
-			break
-		}
-		var table, tablespace, column, columnType string
-		for serial, value := range values {
-			field := columns[serial]
-			valueInString := value.(string)
-			switch field {
-			case "TABLE_NAME", "table_name":
-				table = valueInString
-			case "TABLESPACE_NAME", "tablespace_name":
-				tablespace = valueInString
-			case "COLUMN_NAME", "column_name":
-				column = valueInString
-			case "DATA_TYPE", "data_type":
-				columnType = valueInString
-			}
-			tableStr := fmt.Sprintf("%s.%s", tablespace, table)
-			columnMap := map[string]string{"data_type": columnType}
-			if _, ok := tables[tableStr]; ok {
-				tables[tableStr][column] = columnMap
-				continue
-			} else {
-				tables[tableStr] = map[string]map[string]string{column: columnMap}
-				continue
-			}
-		}
+// convertQueryError convert the oracle server error to common.QueryError, the other errors are returned as is.
+// oracle does not return the error position with the message, so the location is unknown.
+func convertQueryError(err error) error {
+	var oracleError *network.OracleError
+	if !errors.As(err, &oracleError) {
+		return err
 	}
-
-	res := make(map[string]interface{})
-	b, _ := json.Marshal(&tables)
-	if err := json.Unmarshal(b, &res); err != nil {
-		return nil
+	class, hit := oracleErrorClasses[oracleError.ErrCode]
+	if !hit {
+		class = common.QUERY_ERROR_CLASS_UNKNOWN
 	}
-
-	return res
+	return common.NewQueryError(err, class, fmt.Sprintf("ORA-%05d", oracleError.ErrCode), "", oracleError.Error())
 }
@@ -0,0 +1,144 @@
+// Copyright 2023 Illa Soft, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oracle9i
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"io"
+
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+	go_ora_v1 "github.com/illacloud/go-ora-v1"
+)
+
+// the tables, views and materialized views of the connected user, the recycle bin, IOT overflow, nested and domain index tables are skipped.
+// the container table of materialized view is listed as the materialized view only.
+const schemaMetaObjectsSQL = `SELECT table_name object_name, 'TABLE' object_kind FROM user_tables
+WHERE table_name NOT LIKE 'BIN$%' AND NVL(iot_type, 'IOT') = 'IOT' AND nested = 'NO' AND secondary = 'N'
+AND table_name NOT IN (SELECT mview_name FROM user_mviews)
+UNION ALL SELECT view_name, 'VIEW' FROM user_views
+UNION ALL SELECT mview_name, 'MATERIALIZED VIEW' FROM user_mviews
+ORDER BY object_name`
+
+// the tables in the limit, the columns, keys and indexes are fetched for these tables only
+const schemaMetaLimitedTablesSQL = `SELECT object_name FROM (` + schemaMetaObjectsSQL + `) WHERE ROWNUM <= :1`
+
+// the schema of user objects is the connected user
+const (
+	schemaMetaTablesSQL  = `SELECT USER, object_name, object_kind FROM (` + schemaMetaObjectsSQL + `) WHERE ROWNUM <= :1`
+	schemaMetaColumnsSQL = `SELECT USER, table_name, column_name, data_type, nullable FROM user_tab_columns
+WHERE table_name IN (` + schemaMetaLimitedTablesSQL + `)
+ORDER BY table_name, column_id`
+	schemaMetaPrimaryKeysSQL = `SELECT USER, c.table_name, cc.column_name FROM user_constraints c
+JOIN user_cons_columns cc ON cc.constraint_name = c.constraint_name
+WHERE c.constraint_type = 'P' AND c.table_name IN (` + schemaMetaLimitedTablesSQL + `)
+ORDER BY c.table_name, cc.position`
+	schemaMetaForeignKeysSQL = `SELECT USER, c.table_name, c.constraint_name, cc.column_name, rc.owner, rc.table_name, rcc.column_name FROM user_constraints c
+JOIN user_cons_columns cc ON cc.constraint_name = c.constraint_name
+JOIN all_constraints rc ON rc.owner = c.r_owner AND rc.constraint_name = c.r_constraint_name
+JOIN all_cons_columns rcc ON rcc.owner = rc.owner AND rcc.constraint_name = rc.constraint_name AND rcc.position = cc.position
+WHERE c.constraint_type = 'R' AND c.table_name IN (` + schemaMetaLimitedTablesSQL + `)
+ORDER BY c.table_name, c.constraint_name, cc.position`
+	schemaMetaIndexesSQL = `SELECT USER, i.table_name, i.index_name, i.uniqueness, CASE WHEN pk.constraint_name IS NULL THEN 'N' ELSE 'Y' END, ic.column_name FROM user_indexes i
+JOIN user_ind_columns ic ON ic.index_name = i.index_name
+LEFT JOIN user_constraints pk ON pk.constraint_type = 'P' AND pk.table_name = i.table_name AND pk.index_name = i.index_name
+WHERE i.table_owner = USER AND i.table_name IN (` + schemaMetaLimitedTablesSQL + `)
+ORDER BY i.table_name, i.index_name, ic.column_position`
+)
+
+var schemaMetaTableKinds = map[string]string{
+	"TABLE":             common.TABLE_KIND_TABLE,
+	"VIEW":              common.TABLE_KIND_VIEW,
+	"MATERIALIZED VIEW": common.TABLE_KIND_MATERIALIZED_VIEW,
+}
+
+// queryCatalog run the catalog query and pass the values of every row in string to the scan, the NULL is empty string.
+func queryCatalog(db *go_ora_v1.Connection, query string, args []driver.Value, scan func(values []string)) error {
+	stmt := go_ora_v1.NewStmt(query, db)
+	defer stmt.Close()
+
+	rows, err := stmt.Query(args)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	values := make([]driver.Value, len(rows.Columns()))
+	valuesInString := make([]string, len(values))
+	for {
+		if err := rows.Next(values); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		for i, value := range values {
+			valuesInString[i] = ""
+			if value != nil {
+				valuesInString[i] = fmt.Sprint(value)
+			}
+		}
+		scan(valuesInString)
+	}
+}
+
+// schemaMeta fetch the tables, views, columns, keys and indexes of the connected user from the data dictionary views.
+// the column default is not fetched, since it is in LONG column.
+func schemaMeta(db *go_ora_v1.Connection) (*common.SchemaMeta, error) {
+	builder := common.NewSchemaMetaBuilder()
+	limit := []driver.Value{common.SCHEMA_META_MAX_TABLES}
+
+	// tables, fetch one more table for detecting the truncation
+	err := queryCatalog(db, schemaMetaTablesSQL, []driver.Value{common.SCHEMA_META_MAX_TABLES + 1}, func(values []string) {
+		builder.AddTable(values[0], values[1], schemaMetaTableKinds[values[2]])
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// columns
+	err = queryCatalog(db, schemaMetaColumnsSQL, limit, func(values []string) {
+		builder.AddColumn(values[0], values[1], &common.ColumnMeta{Name: values[2], DataType: values[3], Nullable: values[4] == "Y"})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// primary keys
+	err = queryCatalog(db, schemaMetaPrimaryKeysSQL, limit, func(values []string) {
+		builder.AddPrimaryKeyColumn(values[0], values[1], values[2])
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// foreign keys
+	err = queryCatalog(db, schemaMetaForeignKeysSQL, limit, func(values []string) {
+		builder.AddForeignKeyColumn(values[0], values[1], values[2], values[3], values[4], values[5], values[6])
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// indexes, the primary index is the index of primary key constraint
+	err = queryCatalog(db, schemaMetaIndexesSQL, limit, func(values []string) {
+		builder.AddIndexColumn(values[0], values[1], values[2], values[3] == "UNIQUE", values[4] == "Y", values[5])
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return builder.Build(), nil
+}
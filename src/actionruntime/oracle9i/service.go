@@ -68,7 +68,7 @@ func (o *Connector) ValidateActionTemplate(actionOptions map[string]interface{})
 	return common.ValidateResult{Valid: true}, nil
 }
 
-func (o *Connector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (o *Connector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	// get oracle connection
 	db, err := o.getConnectionWithOptions(resourceOptions)
 	if err != nil {
@@ -77,7 +77,7 @@ func (o *Connector) TestConnection(resourceOptions map[string]interface{}) (comm
 	defer db.Close()
 
 	// test oracle connection
-	connectCtx, connectCancel := context.WithTimeout(context.Background(), DEFAULT_CONNECTION_TIMEOUT)
+	connectCtx, connectCancel := context.WithTimeout(ctx, DEFAULT_CONNECTION_TIMEOUT)
 	defer connectCancel()
 	if err := db.Ping(connectCtx); err != nil {
 		return common.ConnectionResult{Success: false}, err
@@ -86,7 +86,7 @@ func (o *Connector) TestConnection(resourceOptions map[string]interface{}) (comm
 	return common.ConnectionResult{Success: true}, nil
 }
 
-func (o *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (o *Connector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 	// get oracle connection
 	db, err := o.getConnectionWithOptions(resourceOptions)
 	if err != nil {
@@ -95,21 +95,38 @@ func (o *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.
 	defer db.Close()
 
 	// test oracle connection
-	connectCtx, connectCancel := context.WithTimeout(context.Background(), DEFAULT_CONNECTION_TIMEOUT)
+	connectCtx, connectCancel := context.WithTimeout(ctx, DEFAULT_CONNECTION_TIMEOUT)
 	defer connectCancel()
 	if err := db.Ping(connectCtx); err != nil {
 		return common.MetaInfoResult{Success: false}, err
 	}
 
-	columns := mapColumns(db)
+	// get oracle tables information of the connected user, the tables are keyed by "schema.table" in legacy schema
+	detail, err := schemaMeta(db)
+	if err != nil {
+		return common.MetaInfoResult{Success: false}, err
+	}
 
 	return common.MetaInfoResult{
 		Success: true,
-		Schema:  columns,
+		Schema:  detail.ExportLegacySchema(""),
+		Detail:  detail,
 	}, nil
 }
 
-func (o *Connector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+func (o *Connector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+	return common.CollectStream(ctx, func(emit common.RowEmitter) (common.RuntimeResult, error) {
+		return o.RunStream(ctx, resourceOptions, actionOptions, rawActionOptions, emit)
+	})
+}
+
+// RunStream run the action and emit the selected rows one by one, the driver errors are converted to common.QueryError.
+func (o *Connector) RunStream(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}, emit common.RowEmitter) (common.RuntimeResult, error) {
+	result, err := o.runStream(ctx, resourceOptions, actionOptions, rawActionOptions, emit)
+	return result, convertQueryError(err)
+}
+
+func (o *Connector) runStream(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}, emit common.RowEmitter) (common.RuntimeResult, error) {
 	// get Oracle connection
 	db, err := o.getConnectionWithOptions(resourceOptions)
 	if err != nil {
@@ -141,6 +158,9 @@ func (o *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 		if errInEscapeSQL != nil {
 			return queryResult, errInEscapeSQL
 		}
+		if errInCheckReadOnly := common.CheckReadOnlySQL(resourceOptions, resourcelist.TYPE_ORACLE_9I_ID, escapedSQL); errInCheckReadOnly != nil {
+			return queryResult, errInCheckReadOnly
+		}
 		// check if o.actionOptions.Opts.Raw is select query
 		isSelectQuery := false
 
@@ -154,6 +174,13 @@ func (o *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 			return common.RuntimeResult{Success: false}, err
 		}
 
+		// run in read only transaction on read-only resource
+		endReadOnlyTx, errInBegin := beginReadOnlyTx(ctx, db, resourceOptions)
+		if errInBegin != nil {
+			return queryResult, errInBegin
+		}
+		defer endReadOnlyTx()
+
 		// fetch data
 		if isSelectQuery && o.actionOptions.IsSafeMode() {
 			stmt, errInPrepare := db.Prepare(escapedSQL)
@@ -167,12 +194,12 @@ func (o *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 				return queryResult, err
 			}
 			defer rows.Close()
-			mapRes, err := common.RetrieveToMapByDriverRows(rows)
+			columns, err := common.EmitDriverRows(rows, emit)
 			if err != nil {
 				return queryResult, err
 			}
+			queryResult.Columns = columns
 			queryResult.Success = true
-			queryResult.Rows = mapRes
 		} else if isSelectQuery && !o.actionOptions.IsSafeMode() {
 			stmt := go_ora_v1.NewStmt(escapedSQL, db)
 			defer stmt.Close()
@@ -181,12 +208,12 @@ func (o *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 				return queryResult, err
 			}
 			defer rows.Close()
-			mapRes, err := common.RetrieveToMapByDriverRows(rows)
+			columns, err := common.EmitDriverRows(rows, emit)
 			if err != nil {
 				return queryResult, err
 			}
+			queryResult.Columns = columns
 			queryResult.Success = true
-			queryResult.Rows = mapRes
 		} else if !isSelectQuery && o.actionOptions.IsSafeMode() {
 			stmt, errInPrepare := db.Prepare(escapedSQL)
 			defer stmt.Close()
@@ -226,6 +253,25 @@ func (o *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 	return queryResult, err
 }
 
+// beginReadOnlyTx begin the read only transaction on read-only resource, see common.BeginReadOnlySQLTxByStatement.
+// the statements run on the connection are in the transaction until the returned end func rolls it back.
+func beginReadOnlyTx(ctx context.Context, db *go_ora_v1.Connection, resourceOptions map[string]interface{}) (func(), error) {
+	if !common.IsReadOnlyResource(resourceOptions) {
+		return func() {}, nil
+	}
+	tx, errInBegin := db.BeginTx(ctx, driver.TxOptions{})
+	if errInBegin != nil {
+		return nil, errInBegin
+	}
+	stmt := go_ora_v1.NewStmt(common.ORACLE_SET_TRANSACTION_READ_ONLY, db)
+	defer stmt.Close()
+	if _, errInSet := stmt.Exec(nil); errInSet != nil {
+		tx.Rollback()
+		return nil, errInSet
+	}
+	return func() { tx.Rollback() }, nil
+}
+
 func ConvertSQlArgsToDriverValues(sqlArgs []interface{}) []driver.Value {
 	ret := make([]driver.Value, 0)
 	for _, value := range sqlArgs {
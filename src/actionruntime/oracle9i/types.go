@@ -28,13 +28,14 @@ const (
 )
 
 type Resource struct {
-	Host     string `mapstructure:"host" validate:"required"`
-	Port     string `mapstructure:"port" validate:"required"`
-	Type     string `mapstructure:"connectionType" validate:"oneof=SID Service"`
-	Name     string `mapstructure:"name"`
-	SSL      bool   `mapstructure:"ssl"`
-	Username string `mapstructure:"username"`
-	Password string `mapstructure:"password"`
+	Host      string                  `mapstructure:"host" validate:"required"`
+	Port      string                  `mapstructure:"port" validate:"required"`
+	Type      string                  `mapstructure:"connectionType" validate:"oneof=SID Service"`
+	Name      string                  `mapstructure:"name"`
+	SSL       bool                    `mapstructure:"ssl"`
+	Username  string                  `mapstructure:"username"`
+	Password  string                  `mapstructure:"password"`
+	SSHTunnel common.SSHTunnelOptions `mapstructure:"sshTunnel"`
 }
 
 type Action struct {
@@ -21,59 +21,101 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
 	"reflect"
 
 	"github.com/google/uuid"
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mitchellh/mapstructure"
 )
 
 const (
-	tableSQLStr  = "SELECT TABLE_NAME tableName FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = $1;"
-	columnSQLStr = "SELECT COLUMN_NAME columnName, DATA_TYPE columnType FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = $1 AND TABLE_NAME = $2;"
+	CONNECTION_POOL_TYPE = "postgresql"
 )
 
-func (p *Connector) getConnectionWithOptions(resourceOptions map[string]interface{}) (*pgx.Conn, error) {
+func (p *Connector) getConnectionWithOptions(ctx context.Context, resourceOptions map[string]interface{}) (*pgx.Conn, error) {
 	if err := mapstructure.Decode(resourceOptions, &p.Resource); err != nil {
 		return nil, err
 	}
-	var db *pgx.Conn
-	var err error
-	if p.Resource.SSL.SSL == true {
-		db, err = p.connectViaSSL()
-	} else {
-		db, err = p.connectPure()
+	pgCfg, err := pgx.ParseConfig(p.buildDSN())
+	if err != nil {
+		return nil, err
+	}
+	if err := p.applySSLConfig(pgCfg); err != nil {
+		return nil, err
 	}
-	return db, err
+	if err := p.applySSHTunnelConfig(pgCfg); err != nil {
+		return nil, err
+	}
+	return pgx.ConnectConfig(ctx, pgCfg)
 }
 
-func (p *Connector) connectPure() (db *pgx.Conn, err error) {
-	escapedPassword := url.QueryEscape(p.Resource.DatabasePassword)
-	dsn := fmt.Sprintf("postgresql://%s:%s@%s:%s/%s", p.Resource.DatabaseUsername,
-		escapedPassword, p.Resource.Host, p.Resource.Port, p.Resource.DatabaseName)
-	pgCfg, err := pgx.ParseConfig(dsn)
-	if err != nil {
-		return nil, err
+// getPooledConnectionWithOptions return the pooled pgxpool for resource, call release after the query finished.
+func (p *Connector) getPooledConnectionWithOptions(ctx context.Context, resourceOptions map[string]interface{}) (*pgxpool.Pool, func(), error) {
+	if err := mapstructure.Decode(resourceOptions, &p.Resource); err != nil {
+		return nil, nil, err
 	}
-	db, err = pgx.ConnectConfig(context.Background(), pgCfg)
+	connection, release, err := common.GetConnectionPoolManager().Acquire(ctx, CONNECTION_POOL_TYPE, resourceOptions, func() (interface{}, func(), error) {
+		poolCfg, err := pgxpool.ParseConfig(p.buildDSN())
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := p.applySSLConfig(poolCfg.ConnConfig); err != nil {
+			return nil, nil, err
+		}
+		if err := p.applySSHTunnelConfig(poolCfg.ConnConfig); err != nil {
+			return nil, nil, err
+		}
+		// the read-only resource runs every transaction read only, which refuses the writes the classifier can not see,
+		// like the writes in user defined functions
+		if common.IsReadOnlyResource(resourceOptions) {
+			poolCfg.ConnConfig.RuntimeParams["default_transaction_read_only"] = "on"
+		}
+		// the pool outlives current request, so do not bind it to request context
+		pool, err := pgxpool.NewWithConfig(context.Background(), poolCfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		return pool, pool.Close, nil
+	})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return db, nil
+	return connection.(*pgxpool.Pool), release, nil
 }
 
-func (p *Connector) connectViaSSL() (db *pgx.Conn, err error) {
+func (p *Connector) buildDSN() string {
 	escapedPassword := url.QueryEscape(p.Resource.DatabasePassword)
-	dsn := fmt.Sprintf("postgresql://%s:%s@%s:%s/%s", p.Resource.DatabaseUsername,
-		escapedPassword, p.Resource.Host, p.Resource.Port, p.Resource.DatabaseName)
-	pgCfg, err := pgx.ParseConfig(dsn)
-	if err != nil {
-		return nil, err
+	return fmt.Sprintf("postgresql://%s:%s@%s/%s", p.Resource.DatabaseUsername,
+		escapedPassword, net.JoinHostPort(p.Resource.Host, p.Resource.Port), p.Resource.DatabaseName)
+}
+
+// applySSHTunnelConfig dial through the bastion host when the ssh tunnel enabled,
+// the host name is resolved by the bastion host, since it may be only resolvable in the private network.
+func (p *Connector) applySSHTunnelConfig(pgCfg *pgx.ConnConfig) error {
+	dial, err := common.NewSSHTunnelDialFunc(p.Resource.SSHTunnel)
+	if err != nil || dial == nil {
+		return err
+	}
+	pgCfg.DialFunc = pgconn.DialFunc(dial)
+	pgCfg.LookupFunc = func(ctx context.Context, host string) ([]string, error) {
+		return []string{host}, nil
+	}
+	return nil
+}
+
+func (p *Connector) applySSLConfig(pgCfg *pgx.ConnConfig) error {
+	if !p.Resource.SSL.SSL {
+		return nil
 	}
 	pool := x509.NewCertPool()
 	if ok := pool.AppendCertsFromPEM([]byte(p.Resource.SSL.ServerCert)); !ok {
-		return nil, errors.New("PostgreSQL SSL/TLS Connection failed")
+		return errors.New("PostgreSQL SSL/TLS Connection failed")
 	}
 	tlsConfig := tls.Config{RootCAs: pool, ServerName: p.Resource.Host}
 	ccBlock, _ := pem.Decode([]byte(p.Resource.SSL.ClientCert))
@@ -81,69 +123,28 @@ func (p *Connector) connectViaSSL() (db *pgx.Conn, err error) {
 	if (ccBlock != nil && ccBlock.Type == "CERTIFICATE") && (ckBlock != nil || ckBlock.Type == "PRIVATE KEY") {
 		cert, err := tls.X509KeyPair([]byte(p.Resource.SSL.ClientCert), []byte(p.Resource.SSL.ClientKey))
 		if err != nil {
-			return nil, err
+			return err
 		}
 		tlsConfig.Certificates = []tls.Certificate{cert}
 	}
 	pgCfg.Config.TLSConfig = &tlsConfig
-
-	db, err = pgx.ConnectConfig(context.Background(), pgCfg)
-	if err != nil {
-		return nil, err
-	}
-	return db, nil
+	return nil
 }
 
-func tablesInfo(db *pgx.Conn, tableSchema string) []string {
-	tableNames := make([]string, 0, 0)
-	tableRows, err := db.Query(context.Background(), tableSQLStr, tableSchema)
-	if err != nil {
-		return nil
-	}
-	for tableRows.Next() {
-		var tableName string
-		err = tableRows.Scan(&tableName)
-		if err != nil {
-			return nil
-		}
-
-		tableNames = append(tableNames, tableName)
-	}
-
-	return tableNames
-}
-
-func fieldsInfo(db *pgx.Conn, tableSchema string, tableNames []string) map[string]interface{} {
-	columns := make(map[string]interface{})
-	for _, tableName := range tableNames {
-		columnRows, err := db.Query(context.Background(), columnSQLStr, tableSchema, tableName)
-		if err != nil {
-			return nil
-		}
-		tables := make(map[string]interface{})
-
-		for columnRows.Next() {
-			var columnName, columnType string
-			err = columnRows.Scan(&columnName, &columnType)
-			if err != nil {
-				return nil
-			}
-			tables[columnName] = map[string]string{"data_type": columnType}
-
-		}
-		columns[tableName] = tables
-	}
-	return columns
+// queryer is implemented by both *pgx.Conn and *pgxpool.Pool
+type queryer interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
 }
 
-func RetrieveToMap(rows pgx.Rows) ([]map[string]interface{}, error) {
-	fieldDescriptions := rows.FieldDescriptions()
+// EmitRows emit the query result row by row, the fetching stops quietly when the emitter reached the result limits.
+// the ordered column schemas are returned, and the rows are keyed by the disambiguated column names.
+func EmitRows(rows pgx.Rows, emit common.RowEmitter) ([]*common.ColumnSchema, error) {
+	columnSchemas := NewColumnSchemasByFieldDescriptions(rows)
 	var columns []string
-	for _, col := range fieldDescriptions {
-		columns = append(columns, col.Name)
+	for _, columnSchema := range columnSchemas {
+		columns = append(columns, columnSchema.Name)
 	}
 	count := len(columns)
-	tableData := make([]map[string]interface{}, 0)
 	values := make([]interface{}, count)
 	valuePtrs := make([]interface{}, count)
 
@@ -165,7 +166,49 @@ func RetrieveToMap(rows pgx.Rows) ([]map[string]interface{}, error) {
 			val := values[i]
 			entry[col] = val
 		}
-		tableData = append(tableData, entry)
+		if err := emit(entry); err != nil {
+			if errors.Is(err, common.ErrResultLimitReached) {
+				return columnSchemas, nil
+			}
+			return columnSchemas, err
+		}
+	}
+	return columnSchemas, rows.Err()
+}
+
+// NewColumnSchemasByFieldDescriptions build the column schemas by the field descriptions, the type name is resolved by the type OID.
+// the nullable is unknown since postgresql does not return it with the result.
+func NewColumnSchemasByFieldDescriptions(rows pgx.Rows) []*common.ColumnSchema {
+	var typeMap *pgtype.Map
+	if conn := rows.Conn(); conn != nil {
+		typeMap = conn.TypeMap()
+	}
+	columnSchemas := make([]*common.ColumnSchema, 0, len(rows.FieldDescriptions()))
+	for _, fieldDescription := range rows.FieldDescriptions() {
+		databaseType := ""
+		if typeMap != nil {
+			if dataType, hit := typeMap.TypeForOID(fieldDescription.DataTypeOID); hit {
+				databaseType = dataType.Name
+			}
+		}
+		columnSchemas = append(columnSchemas, common.NewColumnSchema(fieldDescription.Name, databaseType, nil))
+	}
+	return common.DisambiguateColumnSchemas(columnSchemas)
+}
+
+// convertQueryError convert the postgresql server error to common.QueryError, the other errors are returned as is.
+// the error position is the character offset in query, which is converted to line number and position.
+func convertQueryError(err error, query string) error {
+	var pgError *pgconn.PgError
+	if !errors.As(err, &pgError) {
+		return err
+	}
+	queryError := common.NewQueryError(err, "", pgError.Code, pgError.Code, pgError.Message)
+	queryError.SetLocationByOffset(query, int(pgError.Position))
+	hint := pgError.Hint
+	if hint == "" {
+		hint = pgError.Detail
 	}
-	return tableData, nil
+	queryError.SetHint(hint)
+	return queryError
 }
@@ -0,0 +1,33 @@
+package postgresql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertQueryError(t *testing.T) {
+	query := "SELECT id\nFROM userz"
+	err := convertQueryError(&pgconn.PgError{Code: "42P01", Message: `relation "userz" does not exist`, Position: 16, Detail: "the detail"}, query)
+	queryError, ok := common.AsQueryError(err)
+	assert.True(t, ok)
+	assert.Equal(t, common.QUERY_ERROR_CLASS_NOT_FOUND, queryError.Class)
+	assert.Equal(t, "42P01", queryError.SQLState)
+	assert.Equal(t, 2, queryError.LineNumber)
+	assert.Equal(t, 6, queryError.Position)
+	assert.Equal(t, "the detail", queryError.Hint, "the detail should be the hint when no hint given")
+
+	// the error without position has no location
+	err = convertQueryError(&pgconn.PgError{Code: "42601", Message: "syntax error", Hint: "the hint"}, query)
+	queryError, _ = common.AsQueryError(err)
+	assert.Equal(t, common.QUERY_ERROR_CLASS_SYNTAX, queryError.Class)
+	assert.Equal(t, 0, queryError.LineNumber)
+	assert.Equal(t, "the hint", queryError.Hint)
+
+	// the other errors are returned as is
+	errInDial := errors.New("connection refused")
+	assert.Equal(t, errInDial, convertQueryError(errInDial, query))
+}
@@ -0,0 +1,162 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresql
+
+import (
+	"context"
+
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+)
+
+const DEFAULT_SCHEMA = "public"
+
+// the system schemas and the schemas without usage privilege are skipped
+const accessibleSchemaCondition = `n.nspname NOT IN ('pg_catalog', 'information_schema') AND n.nspname NOT LIKE 'pg\_toast%' AND n.nspname NOT LIKE 'pg\_temp\_%' AND has_schema_privilege(n.oid, 'USAGE')`
+
+const schemaMetaTableCondition = `c.relkind IN ('r', 'p', 'v', 'm', 'f') AND NOT c.relispartition AND ` + accessibleSchemaCondition
+
+// the tables in the limit, the columns, keys and indexes are fetched for these tables only
+const schemaMetaLimitedTablesSQL = `SELECT c.oid FROM pg_catalog.pg_class c
+JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+WHERE ` + schemaMetaTableCondition + `
+ORDER BY n.nspname, c.relname LIMIT $1`
+
+const (
+	schemaMetaTablesSQL = `SELECT n.nspname, c.relname, c.relkind::text FROM pg_catalog.pg_class c
+JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+WHERE ` + schemaMetaTableCondition + `
+ORDER BY n.nspname, c.relname LIMIT $1`
+	schemaMetaColumnsSQL = `SELECT n.nspname, c.relname, a.attname, pg_catalog.format_type(a.atttypid, a.atttypmod), NOT a.attnotnull, pg_catalog.pg_get_expr(d.adbin, d.adrelid)
+FROM pg_catalog.pg_attribute a
+JOIN pg_catalog.pg_class c ON c.oid = a.attrelid
+JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+LEFT JOIN pg_catalog.pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+WHERE a.attnum > 0 AND NOT a.attisdropped AND c.oid IN (` + schemaMetaLimitedTablesSQL + `)
+ORDER BY n.nspname, c.relname, a.attnum`
+	schemaMetaConstraintsSQL = `SELECT n.nspname, c.relname, con.conname, con.contype::text,
+ARRAY(SELECT a.attname::text FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord) JOIN pg_catalog.pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum ORDER BY k.ord),
+COALESCE(fn.nspname, ''), COALESCE(fc.relname, ''),
+ARRAY(SELECT a.attname::text FROM unnest(con.confkey) WITH ORDINALITY AS k(attnum, ord) JOIN pg_catalog.pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.attnum ORDER BY k.ord)
+FROM pg_catalog.pg_constraint con
+JOIN pg_catalog.pg_class c ON c.oid = con.conrelid
+JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+LEFT JOIN pg_catalog.pg_class fc ON fc.oid = con.confrelid
+LEFT JOIN pg_catalog.pg_namespace fn ON fn.oid = fc.relnamespace
+WHERE con.contype IN ('p', 'f') AND c.oid IN (` + schemaMetaLimitedTablesSQL + `)
+ORDER BY n.nspname, c.relname, con.conname`
+	schemaMetaIndexesSQL = `SELECT n.nspname, c.relname, i.relname, ix.indisunique, ix.indisprimary,
+ARRAY(SELECT pg_catalog.pg_get_indexdef(ix.indexrelid, k, true) FROM generate_series(1, ix.indnatts) AS k ORDER BY k)
+FROM pg_catalog.pg_index ix
+JOIN pg_catalog.pg_class i ON i.oid = ix.indexrelid
+JOIN pg_catalog.pg_class c ON c.oid = ix.indrelid
+JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+WHERE c.oid IN (` + schemaMetaLimitedTablesSQL + `)
+ORDER BY n.nspname, c.relname, i.relname`
+)
+
+var tableKinds = map[string]string{
+	"r": common.TABLE_KIND_TABLE,
+	"p": common.TABLE_KIND_TABLE,
+	"v": common.TABLE_KIND_VIEW,
+	"m": common.TABLE_KIND_MATERIALIZED_VIEW,
+	"f": common.TABLE_KIND_FOREIGN_TABLE,
+}
+
+// schemaMeta fetch the tables, views, columns, keys and indexes of all accessible schemas from the system catalog.
+func schemaMeta(ctx context.Context, db queryer) (*common.SchemaMeta, error) {
+	builder := common.NewSchemaMetaBuilder()
+
+	// tables, fetch one more table for detecting the truncation
+	tableRows, err := db.Query(ctx, schemaMetaTablesSQL, common.SCHEMA_META_MAX_TABLES+1)
+	if err != nil {
+		return nil, err
+	}
+	for tableRows.Next() {
+		var schemaName, tableName, relkind string
+		if err := tableRows.Scan(&schemaName, &tableName, &relkind); err != nil {
+			tableRows.Close()
+			return nil, err
+		}
+		builder.AddTable(schemaName, tableName, tableKinds[relkind])
+	}
+	if err := tableRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// columns
+	columnRows, err := db.Query(ctx, schemaMetaColumnsSQL, common.SCHEMA_META_MAX_TABLES)
+	if err != nil {
+		return nil, err
+	}
+	for columnRows.Next() {
+		var schemaName, tableName string
+		column := &common.ColumnMeta{}
+		if err := columnRows.Scan(&schemaName, &tableName, &column.Name, &column.DataType, &column.Nullable, &column.Default); err != nil {
+			columnRows.Close()
+			return nil, err
+		}
+		builder.AddColumn(schemaName, tableName, column)
+	}
+	if err := columnRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// primary keys and foreign keys
+	constraintRows, err := db.Query(ctx, schemaMetaConstraintsSQL, common.SCHEMA_META_MAX_TABLES)
+	if err != nil {
+		return nil, err
+	}
+	for constraintRows.Next() {
+		var schemaName, tableName, constraintName, constraintType, referencedSchema, referencedTable string
+		var columns, referencedColumns []string
+		if err := constraintRows.Scan(&schemaName, &tableName, &constraintName, &constraintType, &columns, &referencedSchema, &referencedTable, &referencedColumns); err != nil {
+			constraintRows.Close()
+			return nil, err
+		}
+		for i, column := range columns {
+			if constraintType == "p" {
+				builder.AddPrimaryKeyColumn(schemaName, tableName, column)
+			} else if i < len(referencedColumns) {
+				builder.AddForeignKeyColumn(schemaName, tableName, constraintName, column, referencedSchema, referencedTable, referencedColumns[i])
+			}
+		}
+	}
+	if err := constraintRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// indexes
+	indexRows, err := db.Query(ctx, schemaMetaIndexesSQL, common.SCHEMA_META_MAX_TABLES)
+	if err != nil {
+		return nil, err
+	}
+	for indexRows.Next() {
+		var schemaName, tableName, indexName string
+		var unique, primary bool
+		var columns []string
+		if err := indexRows.Scan(&schemaName, &tableName, &indexName, &unique, &primary, &columns); err != nil {
+			indexRows.Close()
+			return nil, err
+		}
+		for _, column := range columns {
+			builder.AddIndexColumn(schemaName, tableName, indexName, unique, primary, column)
+		}
+	}
+	if err := indexRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return builder.Build(), nil
+}
@@ -60,52 +60,65 @@ func (p *Connector) ValidateActionTemplate(actionOptions map[string]interface{})
 	return common.ValidateResult{Valid: true}, nil
 }
 
-func (p *Connector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (p *Connector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	// get postgresql connection
-	db, err := p.getConnectionWithOptions(resourceOptions)
+	db, err := p.getConnectionWithOptions(ctx, resourceOptions)
 	if err != nil {
 		return common.ConnectionResult{Success: false}, err
 	}
 	defer db.Close(context.Background())
 
 	// test postgresql connection
-	if err := db.Ping(context.Background()); err != nil {
+	if err := db.Ping(ctx); err != nil {
 		return common.ConnectionResult{Success: false}, err
 	}
 	return common.ConnectionResult{Success: true}, nil
 }
 
-func (p *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (p *Connector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 	// get postgresql connection
-	db, err := p.getConnectionWithOptions(resourceOptions)
+	db, releaseConnection, err := p.getPooledConnectionWithOptions(ctx, resourceOptions)
 	if err != nil {
 		return common.MetaInfoResult{Success: false}, err
 	}
-	defer db.Close(context.Background())
+	defer releaseConnection()
 
 	// test postgresql connection
-	if err := db.Ping(context.Background()); err != nil {
+	if err := db.Ping(ctx); err != nil {
 		return common.MetaInfoResult{Success: false}, err
 	}
 
-	columns := fieldsInfo(db, "public", tablesInfo(db, "public"))
+	detail, err := schemaMeta(ctx, db)
+	if err != nil {
+		return common.MetaInfoResult{Success: false}, err
+	}
 
 	return common.MetaInfoResult{
 		Success: true,
-		Schema:  columns,
+		Schema:  detail.ExportLegacySchema(DEFAULT_SCHEMA),
+		Detail:  detail,
 	}, nil
 }
 
-func (p *Connector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+func (p *Connector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+	return common.CollectStream(ctx, func(emit common.RowEmitter) (common.RuntimeResult, error) {
+		return p.RunStream(ctx, resourceOptions, actionOptions, rawActionOptions, emit)
+	})
+}
+
+// RunStream run the action and emit the selected rows one by one, the driver errors are converted to common.QueryError.
+func (p *Connector) RunStream(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}, emit common.RowEmitter) (common.RuntimeResult, error) {
+	result, err := p.runStream(ctx, resourceOptions, actionOptions, rawActionOptions, emit)
+	return result, convertQueryError(err, p.Action.EscapedQuery)
+}
+
+func (p *Connector) runStream(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}, emit common.RowEmitter) (common.RuntimeResult, error) {
 	// get postgresql connection
-	db, err := p.getConnectionWithOptions(resourceOptions)
+	db, releaseConnection, err := p.getPooledConnectionWithOptions(ctx, resourceOptions)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, errors.New("failed to get postgresql connection")
 	}
-	defer db.Close(context.Background())
-
-	fmt.Printf("[DUMP] Run.actionOptions: %+v\n", actionOptions)
-	fmt.Printf("[DUMP] Run.rawActionOptions: %+v\n", rawActionOptions)
+	defer releaseConnection()
 
 	// format query
 	if err := mapstructure.Decode(actionOptions, &p.Action); err != nil {
@@ -129,6 +142,10 @@ func (p *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 	if errInEscapeSQL != nil {
 		return queryResult, errInEscapeSQL
 	}
+	p.Action.EscapedQuery = escapedSQL
+	if errInCheckReadOnly := common.CheckReadOnlySQL(resourceOptions, resourcelist.TYPE_POSTGRESQL_ID, escapedSQL); errInCheckReadOnly != nil {
+		return queryResult, errInCheckReadOnly
+	}
 	isSelectQuery := false
 
 	lexer := parser_sql.NewLexer(escapedSQL)
@@ -137,35 +154,33 @@ func (p *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 		return common.RuntimeResult{Success: false}, err
 	}
 
-	fmt.Printf("[DUMP] escapedSQL: %s\n", escapedSQL)
-
 	// fetch data
 	if isSelectQuery && p.Action.IsSafeMode() {
-		rows, err := db.Query(context.Background(), escapedSQL, sqlArgs...)
+		rows, err := db.Query(ctx, escapedSQL, sqlArgs...)
 		if err != nil {
 			return queryResult, err
 		}
-		mapRes, err := RetrieveToMap(rows)
+		defer rows.Close()
+		columns, err := EmitRows(rows, emit)
 		if err != nil {
 			return queryResult, err
 		}
-		defer rows.Close()
+		queryResult.Columns = columns
 		queryResult.Success = true
-		queryResult.Rows = mapRes
 	} else if isSelectQuery && !p.Action.IsSafeMode() {
-		rows, err := db.Query(context.Background(), escapedSQL)
+		rows, err := db.Query(ctx, escapedSQL)
 		if err != nil {
 			return queryResult, err
 		}
-		mapRes, err := RetrieveToMap(rows)
+		defer rows.Close()
+		columns, err := EmitRows(rows, emit)
 		if err != nil {
 			return queryResult, err
 		}
-		defer rows.Close()
+		queryResult.Columns = columns
 		queryResult.Success = true
-		queryResult.Rows = mapRes
 	} else if !isSelectQuery && p.Action.IsSafeMode() { // update, insert, delete data
-		execResult, err := db.Exec(context.Background(), escapedSQL, sqlArgs...)
+		execResult, err := db.Exec(ctx, escapedSQL, sqlArgs...)
 		if err != nil {
 			return queryResult, err
 		}
@@ -173,7 +188,7 @@ func (p *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 		queryResult.Success = true
 		queryResult.Extra["message"] = fmt.Sprintf("Affeted %d rows.", affectedRows)
 	} else if !isSelectQuery && !p.Action.IsSafeMode() {
-		execResult, err := db.Exec(context.Background(), escapedSQL)
+		execResult, err := db.Exec(ctx, escapedSQL)
 		if err != nil {
 			return queryResult, err
 		}
@@ -184,3 +199,96 @@ func (p *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 
 	return queryResult, nil
 }
+
+// RunPreview run the single DML statement in a transaction then roll it back,
+// the "RETURNING" rows are returned when the statement has it. the driver errors are converted to common.QueryError.
+func (p *Connector) RunPreview(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+	result, err := p.runPreview(ctx, resourceOptions, actionOptions, rawActionOptions)
+	return result, convertQueryError(err, p.Action.EscapedQuery)
+}
+
+func (p *Connector) runPreview(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+	// get postgresql connection
+	db, releaseConnection, err := p.getPooledConnectionWithOptions(ctx, resourceOptions)
+	if err != nil {
+		return common.RuntimeResult{Success: false}, errors.New("failed to get postgresql connection")
+	}
+	defer releaseConnection()
+
+	query, errInPrepare := p.prepareQuery(resourceOptions, actionOptions, rawActionOptions)
+	if errInPrepare != nil {
+		return common.RuntimeResult{Success: false}, errInPrepare
+	}
+	if _, errInCheckPreview := common.CheckPreviewSQL(resourcelist.TYPE_POSTGRESQL_ID, query.SQL); errInCheckPreview != nil {
+		return common.RuntimeResult{Success: false}, errInCheckPreview
+	}
+
+	// run in transaction and roll back
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return common.RuntimeResult{Success: false}, err
+	}
+	defer tx.Rollback(ctx)
+
+	// the rows are empty unless the statement has "RETURNING", and the command tag is available after rows closed
+	rows, err := tx.Query(ctx, query.SQL, query.Args...)
+	if err != nil {
+		return common.RuntimeResult{Success: false}, err
+	}
+	counter := common.NewPreviewRowCounter(ctx)
+	columns, err := EmitRows(rows, counter.Emit)
+	rows.Close()
+	if err != nil {
+		return common.RuntimeResult{Success: false}, err
+	}
+	if err := rows.Err(); err != nil {
+		return common.RuntimeResult{Success: false}, err
+	}
+	if len(columns) == 0 {
+		columns = nil
+	}
+	return common.NewPreviewResult(counter, columns, rows.CommandTag().RowsAffected()), nil
+}
+
+// BeginTransaction begin a transaction on the resource, the actions run in it are committed or rolled back together.
+func (p *Connector) BeginTransaction(ctx context.Context, resourceOptions map[string]interface{}) (common.ActionTransaction, error) {
+	db, releaseConnection, err := p.getPooledConnectionWithOptions(ctx, resourceOptions)
+	if err != nil {
+		return nil, errors.New("failed to get postgresql connection")
+	}
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		releaseConnection()
+		return nil, convertQueryError(err, "")
+	}
+	return &ActionTransaction{
+		connector:       p,
+		resourceOptions: resourceOptions,
+		tx:              tx,
+		release:         releaseConnection,
+	}, nil
+}
+
+// prepareQuery decode the action and escape the sql, the read-only resource is checked.
+func (p *Connector) prepareQuery(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (*common.PreparedQuery, error) {
+	p.Action = Query{}
+	if err := mapstructure.Decode(actionOptions, &p.Action); err != nil {
+		return nil, err
+	}
+	if errInSetRawQuery := p.Action.SetRawQueryAndContext(rawActionOptions); errInSetRawQuery != nil {
+		return nil, errInSetRawQuery
+	}
+	sqlEscaper := parser_sql.NewSQLEscaper(resourcelist.TYPE_POSTGRESQL_ID)
+	escapedSQL, sqlArgs, errInEscapeSQL := sqlEscaper.EscapeSQLActionTemplate(p.Action.RawQuery, p.Action.Context, p.Action.IsSafeMode())
+	if errInEscapeSQL != nil {
+		return nil, errInEscapeSQL
+	}
+	p.Action.EscapedQuery = escapedSQL
+	if errInCheckReadOnly := common.CheckReadOnlySQL(resourceOptions, resourcelist.TYPE_POSTGRESQL_ID, escapedSQL); errInCheckReadOnly != nil {
+		return nil, errInCheckReadOnly
+	}
+	if !p.Action.IsSafeMode() {
+		sqlArgs = nil
+	}
+	return common.NewPreparedQuery(resourcelist.TYPE_POSTGRESQL_ID, escapedSQL, sqlArgs)
+}
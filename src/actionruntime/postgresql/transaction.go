@@ -0,0 +1,105 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+	"github.com/jackc/pgx/v5"
+)
+
+// ActionTransaction is the common.ActionTransaction on the pgx transaction.
+type ActionTransaction struct {
+	connector       *Connector
+	resourceOptions map[string]interface{}
+	tx              pgx.Tx
+	release         func()
+	done            bool
+}
+
+func (transaction *ActionTransaction) Run(ctx context.Context, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+	result, err := transaction.run(ctx, actionOptions, rawActionOptions)
+	return result, convertQueryError(err, transaction.connector.Action.EscapedQuery)
+}
+
+func (transaction *ActionTransaction) run(ctx context.Context, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+	if transaction.done {
+		return common.RuntimeResult{Success: false}, pgx.ErrTxClosed
+	}
+	query, errInPrepare := transaction.connector.prepareQuery(transaction.resourceOptions, actionOptions, rawActionOptions)
+	if errInPrepare != nil {
+		return common.RuntimeResult{Success: false}, errInPrepare
+	}
+	result := common.RuntimeResult{
+		Success: false,
+		Rows:    []map[string]interface{}{},
+		Extra:   map[string]interface{}{},
+	}
+	if query.IsReturningRows {
+		rows, errInQuery := transaction.tx.Query(ctx, query.SQL, query.Args...)
+		if errInQuery != nil {
+			return result, errInQuery
+		}
+		defer rows.Close()
+		collector := common.NewRowCollector(ctx)
+		columns, errInEmit := EmitRows(rows, collector.Emit)
+		if errInEmit != nil {
+			return result, errInEmit
+		}
+		// the rows are not drained when the result limits reached, close it before the next statement of transaction
+		rows.Close()
+		if errInRows := rows.Err(); errInRows != nil {
+			return result, errInRows
+		}
+		result.Rows = collector.ExportRows()
+		result.Columns = columns
+		result.Success = true
+		collector.MarkResult(&result)
+		return result, nil
+	}
+	execResult, errInExec := transaction.tx.Exec(ctx, query.SQL, query.Args...)
+	if errInExec != nil {
+		return result, errInExec
+	}
+	affectedRows := execResult.RowsAffected()
+	result.Success = true
+	result.Extra[common.RESULT_EXTRA_FIELD_AFFECTED_ROWS] = affectedRows
+	result.Extra["message"] = fmt.Sprintf("Affeted %d rows.", affectedRows)
+	return result, nil
+}
+
+func (transaction *ActionTransaction) Commit(ctx context.Context) error {
+	if transaction.done {
+		return pgx.ErrTxClosed
+	}
+	transaction.done = true
+	defer transaction.release()
+	return convertQueryError(transaction.tx.Commit(ctx), "")
+}
+
+func (transaction *ActionTransaction) Rollback(ctx context.Context) error {
+	if transaction.done {
+		return nil
+	}
+	transaction.done = true
+	defer transaction.release()
+	if errInRollback := transaction.tx.Rollback(ctx); errInRollback != nil && !errors.Is(errInRollback, pgx.ErrTxClosed) {
+		return convertQueryError(errInRollback, "")
+	}
+	return nil
+}
@@ -32,6 +32,7 @@ type Options struct {
 	DatabaseUsername string `validate:"required"`
 	DatabasePassword string `validate:"required"`
 	SSL              SSLOptions
+	SSHTunnel        common.SSHTunnelOptions
 }
 
 type SSLOptions struct {
@@ -46,6 +47,8 @@ type Query struct {
 	Query    string
 	RawQuery string
 	Context  map[string]interface{}
+	// the sql actually sent to the server, the error position is the offset in it
+	EscapedQuery string `mapstructure:"-"`
 }
 
 func (q *Query) IsSafeMode() bool {
@@ -15,23 +15,56 @@
 package redis
 
 import (
+	"context"
 	"crypto/tls"
+	"net"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
 	"github.com/mitchellh/mapstructure"
 )
 
+const (
+	CONNECTION_POOL_TYPE = "redis"
+)
+
+// getPooledConnectionWithOptions return the pooled redis client for resource, call release after the command finished.
+func (r *Connector) getPooledConnectionWithOptions(ctx context.Context, resourceOptions map[string]interface{}) (*redis.Client, func(), error) {
+	if err := mapstructure.Decode(resourceOptions, &r.Resource); err != nil {
+		return nil, nil, err
+	}
+	connection, release, err := common.GetConnectionPoolManager().Acquire(ctx, CONNECTION_POOL_TYPE, resourceOptions, func() (interface{}, func(), error) {
+		rdb, err := r.getConnectionWithOptions(resourceOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+		return rdb, func() { rdb.Close() }, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return connection.(*redis.Client), release, nil
+}
+
 func (r *Connector) getConnectionWithOptions(resourceOptions map[string]interface{}) (*redis.Client, error) {
 	if err := mapstructure.Decode(resourceOptions, &r.Resource); err != nil {
 		return nil, err
 	}
 
+	// dial through the bastion host when the ssh tunnel enabled
+	dial, err := common.NewSSHTunnelDialFunc(r.Resource.SSHTunnel)
+	if err != nil {
+		return nil, err
+	}
 	options := redis.Options{
-		Addr:     r.Resource.Host + ":" + r.Resource.Port,
+		Addr:     net.JoinHostPort(r.Resource.Host, r.Resource.Port),
 		Username: r.Resource.DatabaseUsername,
 		Password: r.Resource.DatabasePassword,
 		DB:       r.Resource.DatabaseIndex,
 	}
+	if dial != nil {
+		options.Dialer = dial
+	}
 	if r.Resource.SSL {
 		tlsConfig := tls.Config{
 			MinVersion: tls.VersionTLS12,
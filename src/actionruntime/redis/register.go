@@ -0,0 +1,42 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+	"github.com/illacloud/builder-backend/src/utils/resourcelist"
+)
+
+func init() {
+	newConnector := func() common.DataConnector {
+		return &Connector{}
+	}
+	secretFields := common.WithSSHTunnelSecretFields("databasePassword")
+	capabilities := common.ConnectorCapabilities{MetaInfo: true, TestConnection: true}
+	common.RegisterConnector(common.ConnectorRegistration{
+		Type:         resourcelist.TYPE_REDIS,
+		ID:           resourcelist.TYPE_REDIS_ID,
+		Capabilities: capabilities,
+		SecretFields: secretFields,
+		New:          newConnector,
+	})
+	common.RegisterConnector(common.ConnectorRegistration{
+		Type:         resourcelist.TYPE_UPSTASH,
+		ID:           resourcelist.TYPE_UPSTASH_ID,
+		Capabilities: capabilities,
+		SecretFields: secretFields,
+		New:          newConnector,
+	})
+}
@@ -57,7 +57,7 @@ func (r *Connector) ValidateActionTemplate(actionOptions map[string]interface{})
 	return common.ValidateResult{Valid: true}, nil
 }
 
-func (r *Connector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (r *Connector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	// get redis client
 	rdb, err := r.getConnectionWithOptions(resourceOptions)
 	if err != nil {
@@ -66,14 +66,14 @@ func (r *Connector) TestConnection(resourceOptions map[string]interface{}) (comm
 	defer rdb.Close()
 
 	// test redis connection
-	if _, err := rdb.Ping(context.Background()).Result(); err != nil {
+	if _, err := rdb.Ping(ctx).Result(); err != nil {
 		return common.ConnectionResult{Success: false}, err
 	}
 
 	return common.ConnectionResult{Success: true}, nil
 }
 
-func (r *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (r *Connector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 
 	return common.MetaInfoResult{
 		Success: true,
@@ -81,16 +81,16 @@ func (r *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.
 	}, nil
 }
 
-func (r *Connector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+func (r *Connector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
 	// get redis connection
-	rdb, err := r.getConnectionWithOptions(resourceOptions)
+	rdb, releaseConnection, err := r.getPooledConnectionWithOptions(ctx, resourceOptions)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
 	}
-	defer rdb.Close()
+	defer releaseConnection()
 
 	// test redis connection
-	if _, err := rdb.Ping(context.Background()).Result(); err != nil {
+	if _, err := rdb.Ping(ctx).Result(); err != nil {
 		return common.RuntimeResult{Success: false}, err
 	}
 
@@ -106,7 +106,7 @@ func (r *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 	}
 
 	// run redis command
-	val, err := rdb.Do(context.Background(), inputRedisCMDSlice...).Result()
+	val, err := rdb.Do(ctx, inputRedisCMDSlice...).Result()
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
 	}
@@ -14,6 +14,8 @@
 
 package redis
 
+import "github.com/illacloud/builder-backend/src/actionruntime/common"
+
 type Options struct {
 	Host             string `validate:"required"`
 	Port             string `validate:"required"`
@@ -21,6 +23,7 @@ type Options struct {
 	DatabaseUsername string
 	DatabasePassword string
 	SSL              bool
+	SSHTunnel        common.SSHTunnelOptions
 }
 
 type Command struct {
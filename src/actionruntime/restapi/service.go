@@ -15,6 +15,7 @@
 package restapi
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -84,15 +85,15 @@ func (r *RESTAPIConnector) ValidateActionTemplate(actionOptions map[string]inter
 	return common.ValidateResult{Valid: true}, nil
 }
 
-func (r *RESTAPIConnector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (r *RESTAPIConnector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	return common.ConnectionResult{Success: false}, errors.New("unsupported type: REST API")
 }
 
-func (r *RESTAPIConnector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (r *RESTAPIConnector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 	return common.MetaInfoResult{Success: false}, errors.New("unsupported type: REST API")
 }
 
-func (r *RESTAPIConnector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+func (r *RESTAPIConnector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
 	res := common.RuntimeResult{
 		Success: false,
 		Rows:    []map[string]interface{}{},
@@ -100,9 +101,7 @@ func (r *RESTAPIConnector) Run(resourceOptions map[string]interface{}, actionOpt
 	}
 	var err error
 
-	fmt.Printf("[DUMP] RESTAPIConnector.Resource: %+v, r.Resource.BaseURL: %+v\n", r.Resource, r.Resource.BaseURL)
 	uriParsed, err := url.ParseRequestURI(r.Resource.BaseURL)
-	fmt.Printf("[DUMP] ParseRequestURI: uriParsed:%+v, err: %+v\n", uriParsed, err)
 
 	if err != nil {
 		res.Success = false
@@ -189,7 +188,7 @@ func (r *RESTAPIConnector) Run(resourceOptions map[string]interface{}, actionOpt
 	}
 
 	// resty client instance set `action` options
-	actionClient := client.R()
+	actionClient := client.R().SetContext(ctx)
 	// set headers, will override `resource` headers
 	actionClient.SetHeaders(headers)
 	// set cookies, will override `resource` cookies
@@ -202,11 +201,7 @@ func (r *RESTAPIConnector) Run(resourceOptions map[string]interface{}, actionOpt
 	// set body for action client
 	switch r.Action.BodyType {
 	case BODY_RAW:
-		fmt.Printf("[DUMP] restapi r.Action: %+v\n", r.Action)
-
 		b := r.Action.ReflectBodyToRaw()
-		fmt.Printf("[DUMP] b := r.Action.ReflectBodyToRaw(): %+v\n", b)
-
 		rawBody, contentType := b.UnmarshalRawBody()
 		fmt.Printf("[DUMP] restapi request contentType: %+v\n", contentType)
 		client.OnBeforeRequest(
@@ -214,7 +209,6 @@ func (r *RESTAPIConnector) Run(resourceOptions map[string]interface{}, actionOpt
 				req.Header.Add("Content-Type", contentType)
 				return nil
 			})
-		fmt.Printf("[DUMP] restapi request body: %+v\n", rawBody)
 		actionClient.SetBody(rawBody)
 	case BODY_BINARY:
 		b := r.Action.ReflectBodyToBinary()
@@ -234,10 +228,6 @@ func (r *RESTAPIConnector) Run(resourceOptions map[string]interface{}, actionOpt
 		break
 	}
 
-	fmt.Printf("[DUMP] r.Action: %+v\n", r.Action)
-	fmt.Printf("[DUMP] actionOptions: %+v\n", actionOptions)
-	fmt.Printf("[DUMP] rawActionOptions: %+v\n", rawActionOptions)
-
 	switch r.Action.Method {
 	case METHOD_GET:
 		actionClient.SetBody(nil)
@@ -262,7 +252,6 @@ func (r *RESTAPIConnector) Run(resourceOptions map[string]interface{}, actionOpt
 		res.Extra["statusText"] = resp.Status()
 	case METHOD_POST:
 		resp, errInPost := actionClient.SetQueryParams(actionURLParams).Post(baseURL + r.Action.URL)
-		fmt.Printf("[DUMP] restapi POST resp.Body(): %+v\n", string(resp.Body()))
 		if errInPost != nil && (resp == nil || resp.RawResponse == nil) {
 			return res, errInPost
 		}
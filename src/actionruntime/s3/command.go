@@ -28,6 +28,7 @@ import (
 )
 
 type CommandExecutor struct {
+	ctx     context.Context
 	client  *s3.Client
 	command Action
 	bucket  string
@@ -62,7 +63,7 @@ func (c *CommandExecutor) listObjects(region string) (common.RuntimeResult, erro
 		MaxKeys:   listCommandArgs.MaxKeys,
 	}
 
-	res, err := c.client.ListObjectsV2(context.TODO(), &params)
+	res, err := c.client.ListObjectsV2(c.ctx, &params)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
 	}
@@ -202,7 +203,7 @@ func (c *CommandExecutor) deleteAnObject() (common.RuntimeResult, error) {
 		Key:    &delete1CommandArgs.ObjectKey,
 	}
 
-	res, err := c.client.DeleteObject(context.TODO(), &params)
+	res, err := c.client.DeleteObject(c.ctx, &params)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, err
 	}
@@ -243,7 +244,7 @@ func (c *CommandExecutor) deleteMultipleObjects() (common.RuntimeResult, error)
 			Key:    &batchDeleteCommandArgs.ObjectKeyList[i],
 		}
 
-		_, err := c.client.DeleteObject(context.TODO(), &params)
+		_, err := c.client.DeleteObject(c.ctx, &params)
 		if err != nil {
 			failedKeys = append(failedKeys, batchDeleteCommandArgs.ObjectKeyList[i])
 			continue
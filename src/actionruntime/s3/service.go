@@ -65,7 +65,7 @@ func (s *Connector) ValidateActionTemplate(actionOptions map[string]interface{})
 	return common.ValidateResult{Valid: true}, nil
 }
 
-func (s *Connector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (s *Connector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	// get s3 client
 	s3Client, err := s.getConnectionWithOptions(resourceOptions)
 	if err != nil {
@@ -73,14 +73,14 @@ func (s *Connector) TestConnection(resourceOptions map[string]interface{}) (comm
 	}
 
 	// test s3 client
-	if _, err := s3Client.ListBuckets(context.TODO(), nil); err != nil {
+	if _, err := s3Client.ListBuckets(ctx, nil); err != nil {
 		return common.ConnectionResult{Success: false}, err
 	}
 
 	return common.ConnectionResult{Success: true}, nil
 }
 
-func (s *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (s *Connector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 	// get s3 client
 	s3Client, err := s.getConnectionWithOptions(resourceOptions)
 	if err != nil {
@@ -88,7 +88,7 @@ func (s *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.
 	}
 
 	// get s3 bucket
-	buckets, err := s3Client.ListBuckets(context.TODO(), nil)
+	buckets, err := s3Client.ListBuckets(ctx, nil)
 	if err != nil {
 		return common.MetaInfoResult{Success: false}, err
 	}
@@ -99,7 +99,7 @@ func (s *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.
 	}, nil
 }
 
-func (s *Connector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+func (s *Connector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
 	// get s3 client
 	s3Client, err := s.getConnectionWithOptions(resourceOptions)
 	if err != nil {
@@ -112,7 +112,7 @@ func (s *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 	}
 
 	var result common.RuntimeResult
-	commandExecutor := CommandExecutor{client: s3Client, command: s.ActionOpts, bucket: s.ResourceOpts.BucketName}
+	commandExecutor := CommandExecutor{ctx: ctx, client: s3Client, command: s.ActionOpts, bucket: s.ResourceOpts.BucketName}
 	switch s.ActionOpts.Commands {
 	case LIST_COMMAND:
 		result, err = commandExecutor.listObjects(s.ResourceOpts.Region)
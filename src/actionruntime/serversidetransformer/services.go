@@ -15,10 +15,13 @@
 package serversidetransformer
 
 import (
+	"context"
 	"errors"
-	"fmt"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/illacloud/builder-backend/src/actionruntime/common"
+	"github.com/illacloud/builder-backend/src/utils/jssandbox"
+	"github.com/mitchellh/mapstructure"
 )
 
 type ServerSideTransformerConnector struct {
@@ -31,30 +34,54 @@ func (r *ServerSideTransformerConnector) ValidateResourceOptions(resourceOptions
 }
 
 func (r *ServerSideTransformerConnector) ValidateActionTemplate(actionOptions map[string]interface{}) (common.ValidateResult, error) {
-	fmt.Printf("[DUMP] actionOptions: %+v \n", actionOptions)
-	// @todo: check action needed field
+	if err := mapstructure.Decode(actionOptions, &r.Action); err != nil {
+		return common.ValidateResult{Valid: false}, err
+	}
+
+	// validate server side transformer template
+	validate := validator.New()
+	if err := validate.Struct(r.Action); err != nil {
+		return common.ValidateResult{Valid: false}, err
+	}
 	return common.ValidateResult{Valid: true}, nil
 }
 
 // server side transformer have no test connection method
-func (r *ServerSideTransformerConnector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (r *ServerSideTransformerConnector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	return common.ConnectionResult{Success: false}, errors.New("unsupported type: server side transformer")
 }
 
 // server side transformer have no meta info
-func (r *ServerSideTransformerConnector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (r *ServerSideTransformerConnector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 	return common.MetaInfoResult{Success: false}, errors.New("unsupported type: server side transformer")
 }
 
-func (r *ServerSideTransformerConnector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+// Run execute the transformer code in sandbox, "data" and "context" are passed as the function arguments.
+func (r *ServerSideTransformerConnector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
 	res := common.RuntimeResult{
 		Success: false,
 		Rows:    []map[string]interface{}{},
 		Extra:   map[string]interface{}{},
 	}
+	if err := mapstructure.Decode(actionOptions, &r.Action); err != nil {
+		return res, err
+	}
 
-	fmt.Printf("[DUMP] ServerSideTransformerConnector.Run() actionOptions: %+v\n", actionOptions)
+	runContext, _ := rawActionOptions[FIELD_CONTEXT].(map[string]interface{})
+	if runContext == nil {
+		runContext = map[string]interface{}{}
+	}
+	inputs := map[string]interface{}{
+		INPUT_DATA:    r.Action.ExportData(runContext),
+		INPUT_CONTEXT: runContext,
+	}
+	result, errInRun := jssandbox.Run(ctx, r.Action.TransformerCode, inputs, jssandbox.NewDefaultLimits())
+	if errInRun != nil {
+		return res, errInRun
+	}
 
-	fmt.Printf("[DUMP] res: %+v\n", res)
+	res.Rows = ConvertValueToRows(result.Value)
+	res.Extra["logs"] = result.Logs
+	res.Success = true
 	return res, nil
 }
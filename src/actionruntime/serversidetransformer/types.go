@@ -16,33 +16,27 @@ package serversidetransformer
 
 import "errors"
 
-type ServerSideTransformerTemplate struct {
-	URL       string
-	Method    string `validate:"oneof=GET POST PUT PATCH DELETE HEAD OPTIONS"`
-	BodyType  string `validate:"oneof=none form-data x-www-form-urlencoded raw json binary"`
-	UrlParams []map[string]string
-	Headers   []map[string]string
-	Body      interface{} `validate:"required_unless=BodyType none"`
-	Cookies   []map[string]string
-}
+const (
+	FIELD_CONTEXT = "context"
+	// the workflow runner put the rows of previous flow action into context by this key
+	CONTEXT_FIELD_PREVIOUS_ACTION_DATA = "previousAction.data"
+	// the data source action rows is referenced as "<actionName>.data" in context
+	CONTEXT_DATA_FIELD_SUFFIX = ".data"
+)
 
-type RawBody struct {
-	Type    string `json:"type"`
-	Content string `json:"content"`
-}
+const (
+	INPUT_DATA    = "data"
+	INPUT_CONTEXT = "context"
+)
 
-func (t *ServerSideTransformerTemplate) ReflectBodyToRaw() *RawBody {
-	rbd := &RawBody{}
-	rb, _ := t.Body.(map[string]interface{})
-	for k, v := range rb {
-		switch k {
-		case "type":
-			rbd.Type, _ = v.(string)
-		case "content":
-			rbd.Content, _ = v.(string)
-		}
-	}
-	return rbd
+const RESULT_VALUE_FIELD = "value"
+
+// ServerSideTransformerTemplate, the transformer code is a javascript function body, like "return data.filter(row => row.enabled)".
+// the data is resolved by DataSource (action name) in run context first, then the Data field, then the previous flow action rows.
+type ServerSideTransformerTemplate struct {
+	TransformerCode string `validate:"required"`
+	DataSource      string
+	Data            interface{}
 }
 
 func resolveIntFieldsFromActionOptions(actionOptions map[string]interface{}, fieldName string) (int, error) {
@@ -59,3 +53,42 @@ func resolveIntFieldsFromActionOptions(actionOptions map[string]interface{}, fie
 	}
 	return number, nil
 }
+
+// ExportData resolve the input data of transformer from the run context.
+func (t *ServerSideTransformerTemplate) ExportData(runContext map[string]interface{}) interface{} {
+	if t.DataSource != "" {
+		if data, hit := runContext[t.DataSource+CONTEXT_DATA_FIELD_SUFFIX]; hit {
+			return data
+		}
+	}
+	if t.Data != nil {
+		return t.Data
+	}
+	if data, hit := runContext[CONTEXT_FIELD_PREVIOUS_ACTION_DATA]; hit {
+		return data
+	}
+	return []interface{}{}
+}
+
+// ConvertValueToRows convert the transformer return value to result rows,
+// the object is one row, the array items are rows, and other values are wrapped as {"value": value}.
+func ConvertValueToRows(value interface{}) []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0)
+	switch valueAsserted := value.(type) {
+	case nil:
+		return rows
+	case map[string]interface{}:
+		return append(rows, valueAsserted)
+	case []interface{}:
+		for _, item := range valueAsserted {
+			if row, assertPass := item.(map[string]interface{}); assertPass {
+				rows = append(rows, row)
+				continue
+			}
+			rows = append(rows, map[string]interface{}{RESULT_VALUE_FIELD: item})
+		}
+		return rows
+	default:
+		return append(rows, map[string]interface{}{RESULT_VALUE_FIELD: valueAsserted})
+	}
+}
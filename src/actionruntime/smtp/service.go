@@ -15,6 +15,7 @@
 package smtp
 
 import (
+	"context"
 	"encoding/base64"
 	"errors"
 	"io"
@@ -49,7 +50,7 @@ func (s *Connector) ValidateActionTemplate(actionOptions map[string]interface{})
 	return common.ValidateResult{Valid: true}, nil
 }
 
-func (s *Connector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (s *Connector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	// get smtp dialer
 	smtpDialer, err := s.getConnectionWithOptions(resourceOptions)
 	if err != nil {
@@ -66,14 +67,14 @@ func (s *Connector) TestConnection(resourceOptions map[string]interface{}) (comm
 	return common.ConnectionResult{Success: true}, nil
 }
 
-func (s *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (s *Connector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 	return common.MetaInfoResult{
 		Success: true,
 		Schema:  nil,
 	}, nil
 }
 
-func (s *Connector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+func (s *Connector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
 	// get smtp dialer
 	smtpDialer, err := s.getConnectionWithOptions(resourceOptions)
 	if err != nil {
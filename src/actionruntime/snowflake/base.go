@@ -15,23 +15,24 @@
 package snowflake
 
 import (
+	"context"
 	"crypto/rsa"
 	"crypto/x509"
 	"database/sql"
 	"encoding/pem"
 	"errors"
-	"fmt"
+	"regexp"
+	"strconv"
 
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
 	"github.com/mitchellh/mapstructure"
 	sf "github.com/snowflakedb/gosnowflake"
 )
 
 const (
-	BASIC_AUTH    = "basic"
-	KEY_PAIR_AUTH = "key"
-
-	tableSQLStr  = "SHOW TERSE TABLES IN SCHEMA "
-	columnSQLStr = "DESCRIBE TABLE "
+	CONNECTION_POOL_TYPE = "snowflake"
+	BASIC_AUTH           = "basic"
+	KEY_PAIR_AUTH        = "key"
 )
 
 func (s *Connector) getConnectionWithOptions(resourceOptions map[string]interface{}) (*sql.DB, error) {
@@ -84,51 +85,38 @@ func (s *Connector) getConnectionWithOptions(resourceOptions map[string]interfac
 	return db, nil
 }
 
-func tablesInfo(db *sql.DB, dbName string) []map[string]string {
-	tableNames := make([]map[string]string, 0, 0)
-	queryStr := tableSQLStr + dbName
-	tableRows, err := db.Query(queryStr)
-	defer tableRows.Close()
-	if err != nil {
-		return nil
-	}
-	for tableRows.Next() {
-		var createdOn interface{}
-		var name, kind, databaseName, schemaName string
-		err = tableRows.Scan(&createdOn, &name, &kind, &databaseName, &schemaName)
-		if err != nil {
-			return nil
-		}
-
-		tableNames = append(tableNames, map[string]string{"table": name, "schema": schemaName})
+// getPooledConnectionWithOptions return the pooled *sql.DB for resource, call release after the query finished.
+func (s *Connector) getPooledConnectionWithOptions(ctx context.Context, resourceOptions map[string]interface{}) (*sql.DB, func(), error) {
+	if err := mapstructure.Decode(resourceOptions, &s.resourceOptions); err != nil {
+		return nil, nil, err
 	}
-
-	return tableNames
+	return common.GetConnectionPoolManager().AcquireSQLDB(ctx, CONNECTION_POOL_TYPE, resourceOptions, func() (*sql.DB, error) {
+		return s.getConnectionWithOptions(resourceOptions)
+	})
 }
 
-func fieldsInfo(db *sql.DB, tableNames []map[string]string) map[string]interface{} {
-	columns := make(map[string]interface{})
-	for _, tableName := range tableNames {
-		queryStr := columnSQLStr + fmt.Sprintf("%s.%s", tableName["schema"], tableName["table"])
-		columnRows, err := db.Query(queryStr)
-		if err != nil {
-			return nil
-		}
-		tables := make(map[string]interface{})
+// the snowflake error numbers which SQLSTATE is too general to classify
+var snowflakeErrorClasses = map[int]string{
+	1003: common.QUERY_ERROR_CLASS_SYNTAX,     // syntax error
+	2003: common.QUERY_ERROR_CLASS_NOT_FOUND,  // object does not exist or not authorized
+	904:  common.QUERY_ERROR_CLASS_NOT_FOUND,  // invalid identifier
+	3001: common.QUERY_ERROR_CLASS_PERMISSION, // insufficient privileges
+}
 
-		for columnRows.Next() {
-			var columnName, columnType string
-			var c3, c4, c5, c6, c7, c8, c9, c10, c11 interface{}
-			err = columnRows.Scan(&columnName, &columnType, &c3, &c4, &c5, &c6, &c7, &c8, &c9, &c10, &c11)
-			if err != nil {
-				return nil
-			}
-			tables[columnName] = map[string]string{"data_type": columnType}
+var snowflakeErrorLocationPattern = regexp.MustCompile(`line (\d+) at position (\d+)`)
 
-		}
-		tableStr := fmt.Sprintf("%s.%s", tableName["schema"], tableName["table"])
-		columns[tableStr] = tables
+// convertQueryError convert the snowflake server error to common.QueryError, the other errors are returned as is.
+// the snowflake position is 0-based in line.
+func convertQueryError(err error) error {
+	var snowflakeError *sf.SnowflakeError
+	if !errors.As(err, &snowflakeError) {
+		return err
 	}
-
-	return columns
+	queryError := common.NewQueryError(err, snowflakeErrorClasses[snowflakeError.Number], strconv.Itoa(snowflakeError.Number), snowflakeError.SQLState, snowflakeError.Message)
+	if matched := snowflakeErrorLocationPattern.FindStringSubmatch(snowflakeError.Message); len(matched) == 3 {
+		lineNumber, _ := strconv.Atoi(matched[1])
+		position, _ := strconv.Atoi(matched[2])
+		queryError.SetLocation(lineNumber, position+1)
+	}
+	return queryError
 }
@@ -0,0 +1,178 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snowflake
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strconv"
+
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+)
+
+// the tables of current schema when the schema is configured, or the tables of current database,
+// the bind is true when all schemas are listed.
+const schemaMetaScopeCondition = `TABLE_SCHEMA <> 'INFORMATION_SCHEMA' AND (? OR TABLE_SCHEMA = CURRENT_SCHEMA())`
+
+// the tables in the limit, the columns are fetched for these tables only.
+// the limit is a literal, since the snowflake LIMIT takes constant only.
+var schemaMetaLimitedTablesSQL = `SELECT TABLE_SCHEMA, TABLE_NAME FROM INFORMATION_SCHEMA.TABLES
+WHERE ` + schemaMetaScopeCondition + `
+ORDER BY TABLE_SCHEMA, TABLE_NAME LIMIT ` + strconv.Itoa(common.SCHEMA_META_MAX_TABLES)
+
+// the information schema only lists the objects which the role has any privilege on
+var (
+	schemaMetaTablesSQL = `SELECT TABLE_SCHEMA, TABLE_NAME, TABLE_TYPE FROM INFORMATION_SCHEMA.TABLES
+WHERE ` + schemaMetaScopeCondition + `
+ORDER BY TABLE_SCHEMA, TABLE_NAME LIMIT ` + strconv.Itoa(common.SCHEMA_META_MAX_TABLES+1)
+	schemaMetaColumnsSQL = `SELECT c.TABLE_SCHEMA, c.TABLE_NAME, c.COLUMN_NAME, c.DATA_TYPE, c.IS_NULLABLE, c.COLUMN_DEFAULT FROM INFORMATION_SCHEMA.COLUMNS c
+JOIN (` + schemaMetaLimitedTablesSQL + `) t ON t.TABLE_SCHEMA = c.TABLE_SCHEMA AND t.TABLE_NAME = c.TABLE_NAME
+ORDER BY c.TABLE_SCHEMA, c.TABLE_NAME, c.ORDINAL_POSITION`
+)
+
+// the keys are not in information schema, the SHOW commands list them in current schema or database
+const (
+	schemaMetaPrimaryKeysInSchemaSQL   = "SHOW PRIMARY KEYS IN SCHEMA"
+	schemaMetaPrimaryKeysInDatabaseSQL = "SHOW PRIMARY KEYS IN DATABASE"
+	schemaMetaForeignKeysInSchemaSQL   = "SHOW IMPORTED KEYS IN SCHEMA"
+	schemaMetaForeignKeysInDatabaseSQL = "SHOW IMPORTED KEYS IN DATABASE"
+)
+
+var schemaMetaTableKinds = map[string]string{
+	"VIEW":              common.TABLE_KIND_VIEW,
+	"MATERIALIZED VIEW": common.TABLE_KIND_MATERIALIZED_VIEW,
+	"EXTERNAL TABLE":    common.TABLE_KIND_FOREIGN_TABLE,
+}
+
+// schemaMeta fetch the tables, views, columns and keys of current schema, or of current database when no schema is configured.
+// snowflake has no index, and the keys are not enforced but declared only.
+func schemaMeta(ctx context.Context, db *sql.DB, allSchemas bool) (*common.SchemaMeta, error) {
+	builder := common.NewSchemaMetaBuilder()
+
+	// tables, fetch one more table for detecting the truncation
+	tableRows, err := db.QueryContext(ctx, schemaMetaTablesSQL, allSchemas)
+	if err != nil {
+		return nil, err
+	}
+	defer tableRows.Close()
+	for tableRows.Next() {
+		var schemaName, tableName, tableType string
+		if err := tableRows.Scan(&schemaName, &tableName, &tableType); err != nil {
+			return nil, err
+		}
+		kind, hit := schemaMetaTableKinds[tableType]
+		if !hit {
+			kind = common.TABLE_KIND_TABLE
+		}
+		builder.AddTable(schemaName, tableName, kind)
+	}
+	if err := tableRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// columns
+	columnRows, err := db.QueryContext(ctx, schemaMetaColumnsSQL, allSchemas)
+	if err != nil {
+		return nil, err
+	}
+	defer columnRows.Close()
+	for columnRows.Next() {
+		var schemaName, tableName, nullable string
+		var columnDefault sql.NullString
+		column := &common.ColumnMeta{}
+		if err := columnRows.Scan(&schemaName, &tableName, &column.Name, &column.DataType, &nullable, &columnDefault); err != nil {
+			return nil, err
+		}
+		column.Nullable = nullable == "YES"
+		if columnDefault.Valid {
+			column.Default = &columnDefault.String
+		}
+		builder.AddColumn(schemaName, tableName, column)
+	}
+	if err := columnRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// primary keys
+	primaryKeysSQL, foreignKeysSQL := schemaMetaPrimaryKeysInSchemaSQL, schemaMetaForeignKeysInSchemaSQL
+	if allSchemas {
+		primaryKeysSQL, foreignKeysSQL = schemaMetaPrimaryKeysInDatabaseSQL, schemaMetaForeignKeysInDatabaseSQL
+	}
+	primaryKeys, err := queryShowRows(ctx, db, primaryKeysSQL)
+	if err != nil {
+		return nil, err
+	}
+	sortShowRows(primaryKeys, "schema_name", "table_name")
+	for _, primaryKey := range primaryKeys {
+		builder.AddPrimaryKeyColumn(primaryKey["schema_name"], primaryKey["table_name"], primaryKey["column_name"])
+	}
+
+	// foreign keys
+	foreignKeys, err := queryShowRows(ctx, db, foreignKeysSQL)
+	if err != nil {
+		return nil, err
+	}
+	sortShowRows(foreignKeys, "fk_schema_name", "fk_table_name", "fk_name")
+	for _, foreignKey := range foreignKeys {
+		builder.AddForeignKeyColumn(foreignKey["fk_schema_name"], foreignKey["fk_table_name"], foreignKey["fk_name"], foreignKey["fk_column_name"], foreignKey["pk_schema_name"], foreignKey["pk_table_name"], foreignKey["pk_column_name"])
+	}
+
+	return builder.Build(), nil
+}
+
+// queryShowRows run the SHOW command and return the rows keyed by column name, the columns of SHOW output vary between versions.
+func queryShowRows(ctx context.Context, db *sql.DB, query string) ([]map[string]string, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]sql.NullString, len(columns))
+	valuePointers := make([]interface{}, len(columns))
+	for i := range values {
+		valuePointers[i] = &values[i]
+	}
+	showRows := []map[string]string{}
+	for rows.Next() {
+		if err := rows.Scan(valuePointers...); err != nil {
+			return nil, err
+		}
+		showRow := make(map[string]string, len(columns))
+		for i, column := range columns {
+			showRow[column] = values[i].String
+		}
+		showRows = append(showRows, showRow)
+	}
+	return showRows, rows.Err()
+}
+
+// sortShowRows sort the key rows by the fields and the key sequence, so the key columns are added in order.
+func sortShowRows(showRows []map[string]string, fields ...string) {
+	sort.SliceStable(showRows, func(i, j int) bool {
+		for _, field := range fields {
+			if showRows[i][field] != showRows[j][field] {
+				return showRows[i][field] < showRows[j][field]
+			}
+		}
+		sequenceI, _ := strconv.Atoi(showRows[i]["key_sequence"])
+		sequenceJ, _ := strconv.Atoi(showRows[j]["key_sequence"])
+		return sequenceI < sequenceJ
+	})
+}
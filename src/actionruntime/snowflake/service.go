@@ -15,6 +15,7 @@
 package snowflake
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -60,7 +61,7 @@ func (s *Connector) ValidateActionTemplate(actionOptions map[string]interface{})
 	return common.ValidateResult{Valid: true}, nil
 }
 
-func (s *Connector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+func (s *Connector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
 	// get snowflake connection
 	db, err := s.getConnectionWithOptions(resourceOptions)
 	if err != nil {
@@ -69,41 +70,58 @@ func (s *Connector) TestConnection(resourceOptions map[string]interface{}) (comm
 	defer db.Close()
 
 	// test snowflake connection
-	if err := db.Ping(); err != nil {
+	if err := db.PingContext(ctx); err != nil {
 		return common.ConnectionResult{Success: false}, err
 	}
 
 	return common.ConnectionResult{Success: true}, nil
 }
 
-func (s *Connector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+func (s *Connector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
 	// get snowflake connection
-	db, err := s.getConnectionWithOptions(resourceOptions)
+	db, releaseConnection, err := s.getPooledConnectionWithOptions(ctx, resourceOptions)
 	if err != nil {
 		return common.MetaInfoResult{Success: false}, err
 	}
-	defer db.Close()
+	defer releaseConnection()
 
 	// test snowflake connection
-	if err := db.Ping(); err != nil {
+	if err := db.PingContext(ctx); err != nil {
 		return common.MetaInfoResult{Success: false}, err
 	}
 
-	columns := fieldsInfo(db, tablesInfo(db, fmt.Sprintf("%s.%s", s.resourceOptions.Database, s.resourceOptions.Schema)))
+	// get snowflake tables information, the tables are keyed by "schema.table" in legacy schema
+	detail, err := schemaMeta(ctx, db, s.resourceOptions.Schema == "")
+	if err != nil {
+		return common.MetaInfoResult{Success: false}, err
+	}
 
 	return common.MetaInfoResult{
 		Success: true,
-		Schema:  columns,
+		Schema:  detail.ExportLegacySchema(""),
+		Detail:  detail,
 	}, nil
 }
 
-func (s *Connector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+func (s *Connector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+	return common.CollectStream(ctx, func(emit common.RowEmitter) (common.RuntimeResult, error) {
+		return s.RunStream(ctx, resourceOptions, actionOptions, rawActionOptions, emit)
+	})
+}
+
+// RunStream run the action and emit the selected rows one by one, the driver errors are converted to common.QueryError.
+func (s *Connector) RunStream(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}, emit common.RowEmitter) (common.RuntimeResult, error) {
+	result, err := s.runStream(ctx, resourceOptions, actionOptions, rawActionOptions, emit)
+	return result, convertQueryError(err)
+}
+
+func (s *Connector) runStream(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}, emit common.RowEmitter) (common.RuntimeResult, error) {
 	// get snowflake connection
-	db, err := s.getConnectionWithOptions(resourceOptions)
+	db, releaseConnection, err := s.getPooledConnectionWithOptions(ctx, resourceOptions)
 	if err != nil {
 		return common.RuntimeResult{Success: false}, errors.New("failed to get snowflake connection")
 	}
-	defer db.Close()
+	defer releaseConnection()
 
 	// format query
 	if err := mapstructure.Decode(actionOptions, &s.actionOptions); err != nil {
@@ -129,6 +147,10 @@ func (s *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 	if errInEscapeSQL != nil {
 		return queryResult, errInEscapeSQL
 	}
+	// snowflake has no read only transaction, so the read-only resource is guarded by the classifier only
+	if errInCheckReadOnly := common.CheckReadOnlySQL(resourceOptions, resourcelist.TYPE_SNOWFLAKE_ID, escapedSQL); errInCheckReadOnly != nil {
+		return queryResult, errInCheckReadOnly
+	}
 
 	// check if m.Action.Query is select query
 	isSelectQuery := false
@@ -141,31 +163,31 @@ func (s *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 
 	// fetch data
 	if isSelectQuery && s.actionOptions.IsSafeMode() {
-		rows, err := db.Query(escapedSQL, sqlArgs...)
+		rows, err := db.QueryContext(ctx, escapedSQL, sqlArgs...)
 		if err != nil {
 			return queryResult, err
 		}
-		mapRes, err := common.RetrieveToMap(rows)
+		defer rows.Close()
+		columns, err := common.EmitRows(rows, emit)
 		if err != nil {
 			return queryResult, err
 		}
-		defer rows.Close()
+		queryResult.Columns = columns
 		queryResult.Success = true
-		queryResult.Rows = mapRes
 	} else if isSelectQuery && !s.actionOptions.IsSafeMode() {
-		rows, err := db.Query(escapedSQL)
+		rows, err := db.QueryContext(ctx, escapedSQL)
 		if err != nil {
 			return queryResult, err
 		}
-		mapRes, err := common.RetrieveToMap(rows)
+		defer rows.Close()
+		columns, err := common.EmitRows(rows, emit)
 		if err != nil {
 			return queryResult, err
 		}
-		defer rows.Close()
+		queryResult.Columns = columns
 		queryResult.Success = true
-		queryResult.Rows = mapRes
 	} else if !isSelectQuery && s.actionOptions.IsSafeMode() {
-		execResult, err := db.Exec(escapedSQL, sqlArgs...)
+		execResult, err := db.ExecContext(ctx, escapedSQL, sqlArgs...)
 		if err != nil {
 			return queryResult, err
 		}
@@ -176,7 +198,7 @@ func (s *Connector) Run(resourceOptions map[string]interface{}, actionOptions ma
 		queryResult.Success = true
 		queryResult.Extra["message"] = fmt.Sprintf("Affeted %d rows.", affectedRows)
 	} else if !isSelectQuery && !s.actionOptions.IsSafeMode() {
-		execResult, err := db.Exec(escapedSQL)
+		execResult, err := db.ExecContext(ctx, escapedSQL)
 		if err != nil {
 			return queryResult, err
 		}
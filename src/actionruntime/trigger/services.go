@@ -15,46 +15,59 @@
 package trigger
 
 import (
+	"context"
 	"errors"
-	"fmt"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/illacloud/builder-backend/src/actionruntime/common"
+	"github.com/mitchellh/mapstructure"
 )
 
 type TriggerConnector struct {
 	Action TriggerTemplate
 }
 
-// AI Agent have no validate resource options method
+// trigger have no resource options to validate
 func (r *TriggerConnector) ValidateResourceOptions(resourceOptions map[string]interface{}) (common.ValidateResult, error) {
 	return common.ValidateResult{Valid: true}, nil
 }
 
 func (r *TriggerConnector) ValidateActionTemplate(actionOptions map[string]interface{}) (common.ValidateResult, error) {
-	fmt.Printf("[DUMP] actionOptions: %+v \n", actionOptions)
-	// @todo: check action needed field
+	if err := mapstructure.Decode(actionOptions, &r.Action); err != nil {
+		return common.ValidateResult{Valid: false}, err
+	}
+	r.Action.SetDefaults()
+
+	// validate trigger template
+	validate := validator.New()
+	if err := validate.Struct(r.Action); err != nil {
+		return common.ValidateResult{Valid: false}, err
+	}
 	return common.ValidateResult{Valid: true}, nil
 }
 
-// AI Agent have no test connection method
-func (r *TriggerConnector) TestConnection(resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
-	return common.ConnectionResult{Success: false}, errors.New("unsupported type: AI Agent")
+// trigger is an inbound webhook, there is no remote server to connect
+func (r *TriggerConnector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+	return common.ConnectionResult{Success: true}, nil
 }
 
-// AI Agent have no meta info
-func (r *TriggerConnector) GetMetaInfo(resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
-	return common.MetaInfoResult{Success: false}, errors.New("unsupported type: AI Agent")
+// trigger have no meta info
+func (r *TriggerConnector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+	return common.MetaInfoResult{Success: false}, errors.New("unsupported type: trigger")
 }
 
-func (r *TriggerConnector) Run(resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+// Run return the inbound webhook payload (passed by run context) as result, so the following flow actions can reference it.
+func (r *TriggerConnector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
 	res := common.RuntimeResult{
 		Success: false,
 		Rows:    []map[string]interface{}{},
 		Extra:   map[string]interface{}{},
 	}
-
-	fmt.Printf("[DUMP] illadrive.Run() actionOptions: %+v\n", actionOptions)
-
-	fmt.Printf("[DUMP] res: %+v\n", res)
+	runContext, _ := rawActionOptions[FIELD_CONTEXT].(map[string]interface{})
+	payload, hitPayload := runContext[FIELD_WEBHOOK_PAYLOAD].(map[string]interface{})
+	if hitPayload {
+		res.Rows = append(res.Rows, payload)
+	}
+	res.Success = true
 	return res, nil
 }
@@ -14,7 +14,21 @@
 
 package trigger
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+const (
+	FIELD_CONTEXT         = "context"
+	FIELD_WEBHOOK_PAYLOAD = "webhookPayload"
+)
+
+const (
+	DEFAULT_METHOD    = "POST"
+	DEFAULT_BODY_TYPE = BODY_TYPE_JSON
+)
 
 type TriggerTemplate struct {
 	URL       string
@@ -22,10 +36,29 @@ type TriggerTemplate struct {
 	BodyType  string `validate:"oneof=none form-data x-www-form-urlencoded raw json binary"`
 	UrlParams []map[string]string
 	Headers   []map[string]string
-	Body      interface{} `validate:"required_unless=BodyType none"`
+	Body      interface{} // sample body for editor, the inbound request body is not checked against it
 	Cookies   []map[string]string
 }
 
+func NewTriggerTemplateByActionOptions(actionOptions map[string]interface{}) (*TriggerTemplate, error) {
+	template := &TriggerTemplate{}
+	if err := mapstructure.Decode(actionOptions, template); err != nil {
+		return nil, err
+	}
+	template.SetDefaults()
+	return template, nil
+}
+
+// SetDefaults fill the method and body type for the trigger created before webhook supported.
+func (t *TriggerTemplate) SetDefaults() {
+	if t.Method == "" {
+		t.Method = DEFAULT_METHOD
+	}
+	if t.BodyType == "" {
+		t.BodyType = DEFAULT_BODY_TYPE
+	}
+}
+
 type RawBody struct {
 	Type    string `json:"type"`
 	Content string `json:"content"`
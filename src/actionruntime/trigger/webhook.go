@@ -0,0 +1,182 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trigger
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	WEBHOOK_SIGNATURE_HEADER    = "X-Illa-Signature"
+	WEBHOOK_TIMESTAMP_HEADER    = "X-Illa-Timestamp"
+	WEBHOOK_SIGNATURE_PREFIX    = "sha256="
+	WEBHOOK_TIMESTAMP_TOLERANCE = 5 * time.Minute
+	WEBHOOK_MAX_BODY_SIZE       = 1 << 20 // 1MB
+	// the delivery is remembered until its timestamp is out of tolerance, the later replay is rejected by timestamp
+	WEBHOOK_DELIVERY_TTL = 2 * WEBHOOK_TIMESTAMP_TOLERANCE
+)
+
+const (
+	BODY_TYPE_NONE                  = "none"
+	BODY_TYPE_FORM_DATA             = "form-data"
+	BODY_TYPE_X_WWW_FORM_URLENCODED = "x-www-form-urlencoded"
+	BODY_TYPE_RAW                   = "raw"
+	BODY_TYPE_JSON                  = "json"
+	BODY_TYPE_BINARY                = "binary"
+)
+
+const (
+	WEBHOOK_PAYLOAD_FIELD_METHOD  = "method"
+	WEBHOOK_PAYLOAD_FIELD_HEADERS = "headers"
+	WEBHOOK_PAYLOAD_FIELD_QUERY   = "query"
+	WEBHOOK_PAYLOAD_FIELD_BODY    = "body"
+)
+
+// SignWebhookPayload sign the "{timestamp}.{body}" with the webhook secret, the output is "sha256={hex digest}".
+func SignWebhookPayload(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return WEBHOOK_SIGNATURE_PREFIX + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature check the signature and reject the request which timestamp is too old (or too new) for avoiding replay.
+func VerifyWebhookSignature(secret string, timestamp string, signature string, body []byte, now time.Time) error {
+	if timestamp == "" || signature == "" {
+		return errors.New("missing " + WEBHOOK_TIMESTAMP_HEADER + " or " + WEBHOOK_SIGNATURE_HEADER + " header")
+	}
+	timestampInUnix, errInParse := strconv.ParseInt(timestamp, 10, 64)
+	if errInParse != nil {
+		return errors.New("invalid timestamp")
+	}
+	skew := now.Sub(time.Unix(timestampInUnix, 0))
+	if skew > WEBHOOK_TIMESTAMP_TOLERANCE || skew < -WEBHOOK_TIMESTAMP_TOLERANCE {
+		return errors.New("timestamp out of tolerance")
+	}
+	expected := SignWebhookPayload(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+// NewWebhookPayload check the inbound request matches the trigger template, and build the payload for workflow context.
+func NewWebhookPayload(req *http.Request, body []byte, template *TriggerTemplate) (map[string]interface{}, error) {
+	if template.Method != "" && !strings.EqualFold(template.Method, req.Method) {
+		return nil, fmt.Errorf("method %s not allowed, expect %s", req.Method, template.Method)
+	}
+	headers := make(map[string]interface{}, len(req.Header))
+	for key := range req.Header {
+		headers[key] = req.Header.Get(key)
+	}
+	query := make(map[string]interface{})
+	for key := range req.URL.Query() {
+		query[key] = req.URL.Query().Get(key)
+	}
+	payload := map[string]interface{}{
+		WEBHOOK_PAYLOAD_FIELD_METHOD:  req.Method,
+		WEBHOOK_PAYLOAD_FIELD_HEADERS: headers,
+		WEBHOOK_PAYLOAD_FIELD_QUERY:   query,
+		WEBHOOK_PAYLOAD_FIELD_BODY:    nil,
+	}
+
+	// decode body by body type
+	switch template.BodyType {
+	case BODY_TYPE_NONE:
+	case BODY_TYPE_JSON:
+		if len(body) == 0 {
+			break
+		}
+		var bodyInJSON interface{}
+		if errInUnmarshal := json.Unmarshal(body, &bodyInJSON); errInUnmarshal != nil {
+			return nil, errors.New("request body is not valid json: " + errInUnmarshal.Error())
+		}
+		payload[WEBHOOK_PAYLOAD_FIELD_BODY] = bodyInJSON
+	case BODY_TYPE_X_WWW_FORM_URLENCODED:
+		form, errInParse := url.ParseQuery(string(body))
+		if errInParse != nil {
+			return nil, errors.New("request body is not valid form: " + errInParse.Error())
+		}
+		bodyInForm := make(map[string]interface{}, len(form))
+		for key := range form {
+			bodyInForm[key] = form.Get(key)
+		}
+		payload[WEBHOOK_PAYLOAD_FIELD_BODY] = bodyInForm
+	case BODY_TYPE_FORM_DATA:
+		bodyInForm, errInParse := parseMultipartForm(req.Header.Get("Content-Type"), body)
+		if errInParse != nil {
+			return nil, errors.New("request body is not valid multipart form: " + errInParse.Error())
+		}
+		payload[WEBHOOK_PAYLOAD_FIELD_BODY] = bodyInForm
+	default:
+		payload[WEBHOOK_PAYLOAD_FIELD_BODY] = string(body)
+	}
+	return payload, nil
+}
+
+// parseMultipartForm collect the value parts of multipart form, the file parts are ignored.
+func parseMultipartForm(contentType string, body []byte) (map[string]interface{}, error) {
+	_, params, errInParseMediaType := mime.ParseMediaType(contentType)
+	if errInParseMediaType != nil {
+		return nil, errInParseMediaType
+	}
+	form, errInReadForm := multipart.NewReader(bytes.NewReader(body), params["boundary"]).ReadForm(WEBHOOK_MAX_BODY_SIZE)
+	if errInReadForm != nil {
+		return nil, errInReadForm
+	}
+	defer form.RemoveAll()
+	bodyInForm := make(map[string]interface{}, len(form.Value))
+	for key, values := range form.Value {
+		if len(values) > 0 {
+			bodyInForm[key] = values[0]
+		}
+	}
+	return bodyInForm, nil
+}
+
+// FlattenWebhookContext expand the payload to "{name}.body.field" like keys,
+// which are the variable names used in action templates.
+func FlattenWebhookContext(name string, payload interface{}) map[string]interface{} {
+	flattened := make(map[string]interface{})
+	flattenValue(name, payload, flattened)
+	return flattened
+}
+
+func flattenValue(prefix string, value interface{}, flattened map[string]interface{}) {
+	flattened[prefix] = value
+	switch valueAsserted := value.(type) {
+	case map[string]interface{}:
+		for key, subValue := range valueAsserted {
+			flattenValue(prefix+"."+key, subValue, flattened)
+		}
+	case []interface{}:
+		for i, subValue := range valueAsserted {
+			flattenValue(prefix+"["+strconv.Itoa(i)+"]", subValue, flattened)
+		}
+	}
+}
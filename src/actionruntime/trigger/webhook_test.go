@@ -0,0 +1,97 @@
+package trigger
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	body := []byte(`{"event":"created"}`)
+	signature := SignWebhookPayload("secret", timestamp, body)
+
+	// valid signature
+	assert.Nil(t, VerifyWebhookSignature("secret", timestamp, signature, body, now))
+	assert.Nil(t, VerifyWebhookSignature("secret", timestamp, signature, body, now.Add(WEBHOOK_TIMESTAMP_TOLERANCE)))
+
+	// tampered body, signed by other secret, or missing headers
+	assert.NotNil(t, VerifyWebhookSignature("secret", timestamp, signature, []byte(`{"event":"deleted"}`), now))
+	assert.NotNil(t, VerifyWebhookSignature("other", timestamp, signature, body, now))
+	assert.NotNil(t, VerifyWebhookSignature("secret", "", signature, body, now))
+	assert.NotNil(t, VerifyWebhookSignature("secret", timestamp, "", body, now))
+
+	// the timestamp is signed, so it can not be refreshed for replaying
+	newTimestamp := strconv.FormatInt(now.Add(time.Hour).Unix(), 10)
+	assert.NotNil(t, VerifyWebhookSignature("secret", newTimestamp, signature, body, now.Add(time.Hour)))
+
+	// timestamp out of tolerance, in both directions
+	assert.NotNil(t, VerifyWebhookSignature("secret", timestamp, signature, body, now.Add(WEBHOOK_TIMESTAMP_TOLERANCE+time.Second)))
+	assert.NotNil(t, VerifyWebhookSignature("secret", timestamp, signature, body, now.Add(-WEBHOOK_TIMESTAMP_TOLERANCE-time.Second)))
+	assert.NotNil(t, VerifyWebhookSignature("secret", "not-a-number", signature, body, now))
+}
+
+func TestNewWebhookPayloadMethod(t *testing.T) {
+	template := &TriggerTemplate{Method: "POST", BodyType: BODY_TYPE_NONE}
+	req := httptest.NewRequest("GET", "/api/v1/webhooks/id?page=2", nil)
+	_, err := NewWebhookPayload(req, nil, template)
+	assert.NotNil(t, err, "the method not in template should be rejected")
+
+	req = httptest.NewRequest("post", "/api/v1/webhooks/id?page=2", nil)
+	req.Header.Set("X-Source", "crm")
+	payload, err := NewWebhookPayload(req, nil, template)
+	assert.Nil(t, err)
+	assert.Equal(t, "2", payload[WEBHOOK_PAYLOAD_FIELD_QUERY].(map[string]interface{})["page"])
+	assert.Equal(t, "crm", payload[WEBHOOK_PAYLOAD_FIELD_HEADERS].(map[string]interface{})["X-Source"])
+	assert.Nil(t, payload[WEBHOOK_PAYLOAD_FIELD_BODY])
+}
+
+func TestNewWebhookPayloadBodyType(t *testing.T) {
+	var multipartBody bytes.Buffer
+	multipartWriter := multipart.NewWriter(&multipartBody)
+	multipartWriter.WriteField("name", "illa")
+	fileWriter, _ := multipartWriter.CreateFormFile("file", "a.txt")
+	fileWriter.Write([]byte("content"))
+	multipartWriter.Close()
+
+	testCases := []struct {
+		name        string
+		bodyType    string
+		contentType string
+		body        []byte
+		expected    interface{}
+		failed      bool
+	}{
+		{name: "none", bodyType: BODY_TYPE_NONE, body: []byte("ignored"), expected: nil},
+		{name: "json", bodyType: BODY_TYPE_JSON, body: []byte(`{"id":1}`), expected: map[string]interface{}{"id": float64(1)}},
+		{name: "empty json", bodyType: BODY_TYPE_JSON, body: []byte{}, expected: nil},
+		{name: "invalid json", bodyType: BODY_TYPE_JSON, body: []byte(`{"id":`), failed: true},
+		{name: "urlencoded", bodyType: BODY_TYPE_X_WWW_FORM_URLENCODED, body: []byte("a=1&b=2"), expected: map[string]interface{}{"a": "1", "b": "2"}},
+		{name: "invalid urlencoded", bodyType: BODY_TYPE_X_WWW_FORM_URLENCODED, body: []byte("a=%zz"), failed: true},
+		{name: "form data", bodyType: BODY_TYPE_FORM_DATA, contentType: multipartWriter.FormDataContentType(), body: multipartBody.Bytes(), expected: map[string]interface{}{"name": "illa"}},
+		{name: "invalid form data", bodyType: BODY_TYPE_FORM_DATA, contentType: "text/plain", body: []byte("name=illa"), failed: true},
+		{name: "raw", bodyType: BODY_TYPE_RAW, body: []byte("plain text"), expected: "plain text"},
+		{name: "binary", bodyType: BODY_TYPE_BINARY, body: []byte{0x01, 0x02}, expected: string([]byte{0x01, 0x02})},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/webhooks/id", bytes.NewReader(testCase.body))
+			if testCase.contentType != "" {
+				req.Header.Set("Content-Type", testCase.contentType)
+			}
+			payload, err := NewWebhookPayload(req, testCase.body, &TriggerTemplate{BodyType: testCase.bodyType})
+			if testCase.failed {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.expected, payload[WEBHOOK_PAYLOAD_FIELD_BODY])
+		})
+	}
+}
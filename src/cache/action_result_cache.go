@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+	redis "github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	ACTION_RESULT_KEY_PREFIX            = "illa_action_result:"
+	ACTION_RESULT_GENERATION_KEY_PREFIX = "illa_action_result_generation:"
+	// the generation key lives longer than any cached result, so the expired generation never resurrects stale results
+	ACTION_RESULT_GENERATION_TTL = 48 * time.Hour
+	// the result over this size will not be cached
+	ACTION_RESULT_MAX_SIZE = 4 * 1024 * 1024
+)
+
+// ActionResultCache cache the action run results, the results are invalidated per resource by increasing the resource generation.
+type ActionResultCache struct {
+	logger  *zap.SugaredLogger
+	cache   *redis.Client
+	context context.Context
+}
+
+func NewActionResultCache(cache *redis.Client, logger *zap.SugaredLogger) *ActionResultCache {
+	return &ActionResultCache{
+		logger:  logger,
+		cache:   cache,
+		context: context.Background(),
+	}
+}
+
+func (c *ActionResultCache) generationKey(teamID int, resourceID int) string {
+	return ACTION_RESULT_GENERATION_KEY_PREFIX + strconv.Itoa(teamID) + ":" + strconv.Itoa(resourceID)
+}
+
+// GetResourceGeneration return the current generation of resource, 0 when never invalidated.
+func (c *ActionResultCache) GetResourceGeneration(teamID int, resourceID int) (int64, error) {
+	generation, errInGet := c.cache.Get(c.context, c.generationKey(teamID, resourceID)).Int64()
+	if errInGet == redis.Nil {
+		return 0, nil
+	} else if errInGet != nil {
+		return 0, errInGet
+	}
+	return generation, nil
+}
+
+// InvalidateResource increase the resource generation, all cached results of the resource are missed after it.
+func (c *ActionResultCache) InvalidateResource(teamID int, resourceID int) error {
+	key := c.generationKey(teamID, resourceID)
+	pipe := c.cache.TxPipeline()
+	pipe.Incr(c.context, key)
+	pipe.Expire(c.context, key, ACTION_RESULT_GENERATION_TTL)
+	_, errInExec := pipe.Exec(c.context)
+	return errInExec
+}
+
+// GetResult return the cached result, the second return value is false when missed.
+func (c *ActionResultCache) GetResult(key string) (*common.RuntimeResult, bool, error) {
+	resultInJSON, errInGet := c.cache.Get(c.context, ACTION_RESULT_KEY_PREFIX+key).Bytes()
+	if errInGet == redis.Nil {
+		return nil, false, nil
+	} else if errInGet != nil {
+		return nil, false, errInGet
+	}
+	result := &common.RuntimeResult{}
+	if errInUnmarshal := json.Unmarshal(resultInJSON, result); errInUnmarshal != nil {
+		return nil, false, errInUnmarshal
+	}
+	return result, true, nil
+}
+
+func (c *ActionResultCache) SetResult(key string, result common.RuntimeResult, ttl time.Duration) error {
+	resultInJSON, errInMarshal := json.Marshal(result)
+	if errInMarshal != nil {
+		return errInMarshal
+	}
+	if len(resultInJSON) > ACTION_RESULT_MAX_SIZE {
+		return nil
+	}
+	return c.cache.Set(c.context, ACTION_RESULT_KEY_PREFIX+key, resultInJSON, ttl).Err()
+}
@@ -6,12 +6,21 @@ import (
 )
 
 type Cache struct {
-	IPZoneCache *IPZoneCache
+	IPZoneCache          *IPZoneCache
+	ActionResultCache    *ActionResultCache
+	ResourceMetaCache    *ResourceMetaCache
+	WebhookDeliveryCache *WebhookDeliveryCache
 }
 
 func NewCache(redisDriver *redis.Client, logger *zap.SugaredLogger) *Cache {
 	ipZoneCache := NewIPZoneCache(redisDriver, logger)
+	actionResultCache := NewActionResultCache(redisDriver, logger)
+	resourceMetaCache := NewResourceMetaCache(redisDriver, logger)
+	webhookDeliveryCache := NewWebhookDeliveryCache(redisDriver, logger)
 	return &Cache{
-		IPZoneCache: ipZoneCache,
+		IPZoneCache:          ipZoneCache,
+		ActionResultCache:    actionResultCache,
+		ResourceMetaCache:    resourceMetaCache,
+		WebhookDeliveryCache: webhookDeliveryCache,
 	}
 }
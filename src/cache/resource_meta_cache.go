@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+	redis "github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	RESOURCE_META_KEY_PREFIX = "illa_resource_meta:"
+	// the meta info over this size will not be cached
+	RESOURCE_META_MAX_SIZE = 8 * 1024 * 1024
+)
+
+// ResourceMetaCache cache the meta info of resources, the key contains the hash of resource options,
+// so the cached meta info is missed after the resource options changed.
+type ResourceMetaCache struct {
+	logger  *zap.SugaredLogger
+	cache   *redis.Client
+	context context.Context
+}
+
+func NewResourceMetaCache(cache *redis.Client, logger *zap.SugaredLogger) *ResourceMetaCache {
+	return &ResourceMetaCache{
+		logger:  logger,
+		cache:   cache,
+		context: context.Background(),
+	}
+}
+
+// GetMetaInfo return the cached meta info, the second return value is false when missed.
+func (c *ResourceMetaCache) GetMetaInfo(key string) (*common.MetaInfoResult, bool, error) {
+	metaInfoInJSON, errInGet := c.cache.Get(c.context, RESOURCE_META_KEY_PREFIX+key).Bytes()
+	if errInGet == redis.Nil {
+		return nil, false, nil
+	} else if errInGet != nil {
+		return nil, false, errInGet
+	}
+	metaInfo := &common.MetaInfoResult{}
+	if errInUnmarshal := json.Unmarshal(metaInfoInJSON, metaInfo); errInUnmarshal != nil {
+		return nil, false, errInUnmarshal
+	}
+	return metaInfo, true, nil
+}
+
+func (c *ResourceMetaCache) SetMetaInfo(key string, metaInfo *common.MetaInfoResult, ttl time.Duration) error {
+	metaInfoInJSON, errInMarshal := json.Marshal(metaInfo)
+	if errInMarshal != nil {
+		return errInMarshal
+	}
+	if len(metaInfoInJSON) > RESOURCE_META_MAX_SIZE {
+		return nil
+	}
+	return c.cache.Set(c.context, RESOURCE_META_KEY_PREFIX+key, metaInfoInJSON, ttl).Err()
+}
@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	WEBHOOK_DELIVERY_KEY_PREFIX = "illa_webhook_delivery:"
+)
+
+// WebhookDeliveryCache remember the delivered webhook requests, so the replayed request is rejected on every replica.
+type WebhookDeliveryCache struct {
+	logger  *zap.SugaredLogger
+	cache   *redis.Client
+	context context.Context
+}
+
+func NewWebhookDeliveryCache(cache *redis.Client, logger *zap.SugaredLogger) *WebhookDeliveryCache {
+	return &WebhookDeliveryCache{
+		logger:  logger,
+		cache:   cache,
+		context: context.Background(),
+	}
+}
+
+// MarkDelivered remember the delivery for ttl, it returns false when the delivery was already marked.
+func (c *WebhookDeliveryCache) MarkDelivered(webhookID string, deliveryID string, ttl time.Duration) (bool, error) {
+	return c.cache.SetNX(c.context, WEBHOOK_DELIVERY_KEY_PREFIX+webhookID+":"+deliveryID, 1, ttl).Result()
+}
@@ -4,11 +4,13 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
 	gws "github.com/gorilla/websocket"
 	"github.com/illacloud/builder-backend/src/driver/postgres"
+	"github.com/illacloud/builder-backend/src/driver/redis"
 	"github.com/illacloud/builder-backend/src/storage"
 	"github.com/illacloud/builder-backend/src/utils/accesscontrol"
 	"github.com/illacloud/builder-backend/src/utils/builderoperation"
@@ -16,6 +18,7 @@ import (
 	"github.com/illacloud/builder-backend/src/utils/idconvertor"
 	"github.com/illacloud/builder-backend/src/utils/logger"
 	"github.com/illacloud/builder-backend/src/utils/supervisor"
+	"github.com/illacloud/builder-backend/src/utils/tokenvalidator"
 	"github.com/illacloud/builder-backend/src/websocket"
 	filter "github.com/illacloud/builder-backend/src/websocket-filter"
 	"go.uber.org/zap"
@@ -31,7 +34,7 @@ func InitStorage(globalConfig *config.Config, logger *zap.SugaredLogger) *storag
 	return storage.NewStorage(postgresDriver, logger)
 }
 
-func InitHub(s *storage.Storage) {
+func InitHub(globalConfig *config.Config, logger *zap.SugaredLogger, s *storage.Storage) {
 	// init attribute group
 	attrg, errInNewAttributeGroup := accesscontrol.NewRawAttributeGroup()
 	if errInNewAttributeGroup != nil {
@@ -41,6 +44,16 @@ func InitHub(s *storage.Storage) {
 
 	// new hub
 	hub = websocket.NewHub(s, attrg)
+
+	// enable cluster mode for multi replicas deploy
+	if globalConfig.IsWebsocketClusterEnabled() {
+		redisClient, errInNewRedisClient := redis.NewRedisConnectionByGlobalConfig(globalConfig, logger)
+		if errInNewRedisClient != nil {
+			log.Fatalf("Error in startup, websocket cluster redis init failed.")
+			return
+		}
+		hub.EnableCluster(websocket.NewCluster(redisClient))
+	}
 	go filter.Run(hub)
 }
 
@@ -89,7 +102,7 @@ func main() {
 	sugaredLogger := logger.NewSugardLogger()
 
 	storage := InitStorage(conf, sugaredLogger)
-	InitHub(storage)
+	InitHub(conf, sugaredLogger, storage)
 
 	// listen and serve
 	r := mux.NewRouter()
@@ -160,6 +173,52 @@ func main() {
 		json.NewEncoder(w).Encode(map[string]bool{"ok": true})
 	})
 
+	// handle internal scheduled action result push, the request is signed by Request-Token
+	r.HandleFunc("/api/v1/internal/teams/{teamID}/apps/{appID}/scheduledActionResult", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		// get teamID & appID
+		teamID := mux.Vars(r)["teamID"]
+		appID := mux.Vars(r)["appID"]
+		teamIDInt := idconvertor.ConvertStringToInt(teamID)
+		appIDInt := idconvertor.ConvertStringToInt(appID)
+
+		// validate request token
+		validator := tokenvalidator.NewRequestTokenValidator()
+		if r.Header.Get("Request-Token") != validator.GenerateValidateToken(strconv.Itoa(teamIDInt), strconv.Itoa(appIDInt)) {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]bool{"ok": false})
+			return
+		}
+
+		// parse result
+		var result interface{}
+		if errInDecode := json.NewDecoder(r.Body).Decode(&result); errInDecode != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]bool{"ok": false})
+			return
+		}
+
+		// broadcast result to room all client
+		serverSideClientID := websocket.GetMessageClientIDForWebsocketServer()
+		message, errInNewWebSocketMessage := websocket.NewEmptyMessage(appIDInt, serverSideClientID, builderoperation.SIGNAL_BROADCAST_ONLY, builderoperation.TARGET_ACTION, true)
+		if errInNewWebSocketMessage != nil {
+			json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+			return
+		}
+		message.SetBroadcastType(websocket.BROADCAST_TYPE_SCHEDULED_ACTION_RESULT)
+		message.SetBroadcastPayload(result)
+		message.RewriteBroadcast()
+		hub.SendFeedbackToTargetRoomAllClients(websocket.ERROR_CODE_BROADCAST, message, teamIDInt, appIDInt)
+
+		// done
+		json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+	})
+
 	// handle ws://{ip:port}/teams/{teamID}/room/websocketConnection/dashboard
 	r.HandleFunc("/teams/{teamID}/room/websocketConnection/dashboard", func(w http.ResponseWriter, r *http.Request) {
 		teamID := mux.Vars(r)["teamID"]
@@ -10,6 +10,7 @@ import (
 	"github.com/illacloud/builder-backend/src/driver/postgres"
 	"github.com/illacloud/builder-backend/src/driver/redis"
 	"github.com/illacloud/builder-backend/src/router"
+	"github.com/illacloud/builder-backend/src/scheduler"
 	"github.com/illacloud/builder-backend/src/storage"
 	"github.com/illacloud/builder-backend/src/utils/accesscontrol"
 	"github.com/illacloud/builder-backend/src/utils/config"
@@ -88,6 +89,14 @@ func initServer() (*Server, error) {
 	c := controller.NewControllerForBackend(storage, cache, drive, validator, attrg)
 	router := router.NewRouter(c)
 	server := NewServer(globalConfig, engine, router, sugaredLogger)
+
+	// encrypt the plaintext secrets of existing resources
+	scheduler.NewResourceSecretMigration(storage, sugaredLogger).Start()
+
+	// start action scheduler
+	if globalConfig.IsActionSchedulerEnabled() {
+		scheduler.NewActionScheduler(storage, cache, sugaredLogger).Start()
+	}
 	return server, nil
 
 }
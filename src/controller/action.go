@@ -2,18 +2,14 @@ package controller
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
-	"log"
 	"net/http"
-	"regexp"
-	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/illacloud/builder-backend/src/model"
 	"github.com/illacloud/builder-backend/src/request"
 	"github.com/illacloud/builder-backend/src/response"
+	"github.com/illacloud/builder-backend/src/storage"
 	"github.com/illacloud/builder-backend/src/utils/accesscontrol"
 	"github.com/illacloud/builder-backend/src/utils/illaresourcemanagersdk"
 )
@@ -90,8 +86,14 @@ func (controller *Controller) CreateAction(c *gin.Context) {
 		return
 	}
 
-	// create action
-	_, errInCreateAction := controller.Storage.ActionStorage.Create(action)
+	// create action, the resource is locked so it will not be deleted meanwhile
+	errInCreateAction := controller.Storage.Transaction(func(txStorage *storage.Storage) error {
+		if errInLock := lockActionResource(txStorage, teamID, action.ExportType(), action.ExportResourceID()); errInLock != nil {
+			return errInLock
+		}
+		_, errInCreate := txStorage.ActionStorage.Create(action)
+		return errInCreate
+	})
 	if errInCreateAction != nil {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_ACTION, "create action error: "+errInCreateAction.Error())
 		return
@@ -185,8 +187,13 @@ func (controller *Controller) UpdateAction(c *gin.Context) {
 		return
 	}
 
-	// update action
-	errInUpdateAction := controller.Storage.ActionStorage.UpdateWholeAction(inDatabaseAction)
+	// update action, the resource is locked so it will not be deleted meanwhile
+	errInUpdateAction := controller.Storage.Transaction(func(txStorage *storage.Storage) error {
+		if errInLock := lockActionResource(txStorage, teamID, inDatabaseAction.ExportType(), inDatabaseAction.ExportResourceID()); errInLock != nil {
+			return errInLock
+		}
+		return txStorage.ActionStorage.UpdateWholeAction(inDatabaseAction)
+	})
 	if errInUpdateAction != nil {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_ACTION, "update action error: "+errInUpdateAction.Error())
 		return
@@ -362,9 +369,25 @@ func (controller *Controller) RunAction(c *gin.Context) {
 		}
 	}
 
+	// the template saved by editor authorizes the team variable placeholders, the onboarding action has no saved template
+	persistedTemplate := ""
+	if model.DoesActionHasBeenCreated(actionID) {
+		persistedTemplate = action.Template
+	}
+
 	// update action data with run action reqeust
 	action.UpdateWithRunActionRequest(runActionRequest, userID)
-	fmt.Printf("[DUMP] action: %+v\n", action)
+
+	// return mock data instead of calling the real connector when mock enabled
+	if action.IsMockEnabled() {
+		mockResult, errInExportMockResult := action.ExportMockResult()
+		if errInExportMockResult != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_EXECUTE_ACTION_FAILED, "run action error: "+errInExportMockResult.Error())
+			return
+		}
+		c.JSON(http.StatusOK, mockResult)
+		return
+	}
 
 	// assembly action
 	actionFactory := model.NewActionFactoryByAction(action)
@@ -376,6 +399,7 @@ func (controller *Controller) RunAction(c *gin.Context) {
 
 	// get resource
 	resource := model.NewResource()
+	var resourceOptions map[string]interface{}
 	if !action.IsVirtualAction() {
 		// process normal resource action
 		var errInRetrieveResource error
@@ -384,9 +408,29 @@ func (controller *Controller) RunAction(c *gin.Context) {
 			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_RESOURCE, "get resource failed: "+errInRetrieveResource.Error())
 			return
 		}
+		// run with the resource environment which the app binds to the action version
+		var errInSwitchResourceEnvironment error
+		resource, errInSwitchResourceEnvironment = controller.switchResourceEnvironment(action, resource)
+		if errInSwitchResourceEnvironment != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_RESOURCE, "get resource environment failed: "+errInSwitchResourceEnvironment.Error())
+			return
+		}
+		// resolve the team variables referenced by resource options and action template
+		var errInResolveTeamVariables error
+		resource, errInResolveTeamVariables = controller.resolveTeamVariables(teamID, resource, false, model.NewTeamVariableRunTemplate(&action.Template, persistedTemplate, runActionRequest.ExportContext()))
+		if errInResolveTeamVariables != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_RESOURCE_FAILED, "resolve team variables failed: "+errInResolveTeamVariables.Error())
+			return
+		}
+		var errInExportResourceOptions error
+		resourceOptions, errInExportResourceOptions = resource.ExportOptionsInMap()
+		if errInExportResourceOptions != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_RESOURCE_FAILED, "get resource options failed: "+errInExportResourceOptions.Error())
+			return
+		}
 		// resource option validate only happend in create or update phrase
 		// note that validate will set resprce options to actionAssemblyLine
-		_, errInValidateResourceOptions := actionAssemblyLine.ValidateResourceOptions(resource.ExportOptionsInMap())
+		_, errInValidateResourceOptions := actionAssemblyLine.ValidateResourceOptions(resourceOptions)
 		if errInValidateResourceOptions != nil {
 			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_RESOURCE_FAILED, "validate resource failed: "+errInValidateResourceOptions.Error())
 			return
@@ -397,8 +441,6 @@ func (controller *Controller) RunAction(c *gin.Context) {
 	}
 
 	// check action template
-	fmt.Printf("[DUMP] action.ExportTemplateInMap(): %+v\n", action.ExportTemplateInMap())
-	fmt.Printf("[DUMP] action.ExportRawTemplateInMap(): %+v\n", action.ExportRawTemplateInMap())
 	_, errInValidate := actionAssemblyLine.ValidateActionTemplate(action.ExportTemplateInMap())
 	if errInValidate != nil {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate action template error: "+errInValidate.Error())
@@ -406,30 +448,55 @@ func (controller *Controller) RunAction(c *gin.Context) {
 	}
 
 	// run
-	log.Printf("[DUMP]action: %+v\n", action)
-	log.Printf("[DUMP] resource.ExportOptionsInMap(): %+v, action.ExportTemplateInMap(): %+v\n", resource.ExportOptionsInMap(), action.ExportTemplateInMap())
-	actionRunResult, errInRunAction := actionAssemblyLine.Run(resource.ExportOptionsInMap(), action.ExportTemplateInMap(), action.ExportRawTemplateInMap())
-	if errInRunAction != nil {
-		if strings.HasPrefix(errInRunAction.Error(), "Error 1064:") {
-			lineNumber, _ := strconv.Atoi(errInRunAction.Error()[len(errInRunAction.Error())-1:])
-			message := ""
-			regexp, _ := regexp.Compile(`to use`)
-			match := regexp.FindStringIndex(errInRunAction.Error())
-			if len(match) == 2 {
-				message = errInRunAction.Error()[match[1]:]
-			}
-			c.JSON(http.StatusBadRequest, gin.H{
-				"errorCode":    400,
-				"errorFlag":    ERROR_FLAG_EXECUTE_ACTION_FAILED,
-				"errorMessage": errors.New("SQL syntax error").Error(),
-				"errorData": map[string]interface{}{
-					"lineNumber": lineNumber,
-					"message":    "SQL syntax error" + message,
-				},
-			})
+	// run in preview mode, the changes are rolled back so the result cache is neither served nor invalidated
+	if IsActionRunInPreview(c) {
+		actionRunContext, cancelActionRun := NewActionRunContext(c, resource, action.ExportRunTimeout())
+		defer cancelActionRun()
+		actionRunLog := model.NewActionRunLogByAction(action, model.ACTION_RUN_LOG_SOURCE_EDITOR, userID)
+		actionRunResult, errInRunAction := runActionInPreview(actionRunContext, actionAssemblyLine, resourceOptions, action.ExportTemplateInMap(), action.ExportRawTemplateInMap())
+		actionRunLog.Finish(actionRunResult, errInRunAction)
+		controller.recordActionRun(actionRunLog)
+		if errInRunAction != nil {
+			controller.feedbackRunActionError(c, ERROR_FLAG_EXECUTE_ACTION_FAILED, "preview action error: ", errInRunAction)
 			return
 		}
-		controller.FeedbackBadRequest(c, ERROR_FLAG_EXECUTE_ACTION_FAILED, "run action error: "+errInRunAction.Error())
+		c.JSON(http.StatusOK, actionRunResult)
+		return
+	}
+
+	// run in NDJSON stream mode, the result cache is bypassed
+	if IsActionRunInStream(c) {
+		actionRunContext, cancelActionRun := NewActionRunContext(c, resource, action.ExportRunTimeout())
+		defer cancelActionRun()
+		actionRunLog := model.NewActionRunLogByAction(action, model.ACTION_RUN_LOG_SOURCE_EDITOR, userID)
+		actionRunResult, rowCount, errInRunAction := runActionInStream(c, actionRunContext, actionAssemblyLine, resourceOptions, action.ExportTemplateInMap(), action.ExportRawTemplateInMap())
+		actionRunLog.Finish(actionRunResult, errInRunAction)
+		actionRunLog.SetRowCount(rowCount)
+		controller.recordActionRun(actionRunLog)
+		if errInRunAction == nil {
+			controller.cacheActionResult(action, "", actionRunResult)
+		}
+		return
+	}
+
+	// serve from result cache when the action opts in
+	actionCacheKey := controller.newActionCacheKey(action)
+	if cachedResult, hit := controller.getCachedActionResult(c, actionCacheKey); hit {
+		c.JSON(http.StatusOK, cachedResult)
+		return
+	}
+
+	actionRunContext, cancelActionRun := NewActionRunContext(c, resource, action.ExportRunTimeout())
+	defer cancelActionRun()
+	actionRunLog := model.NewActionRunLogByAction(action, model.ACTION_RUN_LOG_SOURCE_EDITOR, userID)
+	actionRunResult, errInRunAction := actionAssemblyLine.Run(actionRunContext, resourceOptions, action.ExportTemplateInMap(), action.ExportRawTemplateInMap())
+	actionRunLog.Finish(actionRunResult, errInRunAction)
+	controller.recordActionRun(actionRunLog)
+	if errInRunAction == nil {
+		controller.cacheActionResult(action, actionCacheKey, actionRunResult)
+	}
+	if errInRunAction != nil {
+		controller.feedbackRunActionError(c, ERROR_FLAG_EXECUTE_ACTION_FAILED, "run action error: ", errInRunAction)
 		return
 	}
 
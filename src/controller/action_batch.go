@@ -0,0 +1,230 @@
+package controller
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+	"github.com/illacloud/builder-backend/src/model"
+	"github.com/illacloud/builder-backend/src/request"
+	"github.com/illacloud/builder-backend/src/response"
+	"github.com/illacloud/builder-backend/src/utils/accesscontrol"
+	"github.com/illacloud/builder-backend/src/utils/config"
+)
+
+// batchRun is a prepared run of batch run request.
+type batchRun struct {
+	action          *model.Action
+	connector       common.DataConnector
+	resource        *model.Resource
+	resourceOptions map[string]interface{}
+}
+
+// BatchRunActions run multiple actions of app in one request, the run permission is checked for every action like running it alone,
+// the actions and resources are retrieved once, and the independent runs are executed concurrently in a bounded worker pool.
+// a run starts after the runs in its runAfter succeeded, and it is skipped when any of them failed.
+// the results are returned in the order of request runs, a failed run does not fail the request.
+func (controller *Controller) BatchRunActions(c *gin.Context) {
+	// fetch needed param
+	teamID, errInGetTeamID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_ID)
+	appID, errInGetAppID := controller.GetMagicIntParamFromRequest(c, PARAM_APP_ID)
+	userAuthToken, errInGetAuthToken := controller.GetUserAuthTokenFromHeader(c)
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetTeamID != nil || errInGetAppID != nil || errInGetAuthToken != nil || errInGetUserID != nil {
+		return
+	}
+
+	// set resource timing header
+	c.Header("Timing-Allow-Origin", "*")
+
+	// parse request body
+	req := request.NewBatchRunActionsRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate request body
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+	runAfterIndexes, errInResolveRunAfter := req.ExportRunAfterIndexes()
+	if errInResolveRunAfter != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate runAfter error: "+errInResolveRunAfter.Error())
+		return
+	}
+
+	// get actions
+	actionIDs := make([]int, 0, len(req.Runs))
+	for _, item := range req.Runs {
+		actionIDs = append(actionIDs, item.ExportActionIDInInt())
+	}
+	actions, errInRetrieveActions := controller.Storage.ActionStorage.RetrieveActionsByTeamIDAppIDAndIDs(teamID, appID, actionIDs)
+	if errInRetrieveActions != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ACTION, "get actions failed: "+errInRetrieveActions.Error())
+		return
+	}
+	actionMap := make(map[int]*model.Action, len(actions))
+	for _, action := range actions {
+		actionMap[action.ExportID()] = action
+	}
+
+	// prepare runs, the runs failed in preparing and the mocked runs have results already
+	runs := make([]*batchRun, len(req.Runs))
+	results := make([]*response.BatchRunActionResult, len(req.Runs))
+	resources := make(map[int]*model.Resource)
+	for i, item := range req.Runs {
+		runs[i], results[i] = controller.prepareBatchRun(teamID, userID, userAuthToken, item, actionMap[item.ExportActionIDInInt()], resources)
+	}
+
+	// execute
+	done := make([]chan struct{}, len(runs))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	slots := make(chan struct{}, config.GetInstance().GetActionBatchRunConcurrency())
+	var wg sync.WaitGroup
+	for i := range runs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer close(done[i])
+			for _, dependencyIndex := range runAfterIndexes[i] {
+				<-done[dependencyIndex]
+			}
+			if results[i] != nil {
+				return
+			}
+			for _, dependencyIndex := range runAfterIndexes[i] {
+				if !results[dependencyIndex].IsSucceeded() {
+					results[i] = response.NewBatchRunActionSkippedResult(req.Runs[i].ActionID, "skipped since action "+req.Runs[dependencyIndex].ActionID+" in runAfter did not succeed")
+					return
+				}
+			}
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			results[i] = controller.runBatchRun(c, userID, req.Runs[i].ActionID, runs[i])
+		}(i)
+	}
+	wg.Wait()
+
+	// feedback
+	controller.FeedbackOK(c, response.NewBatchRunActionsResponse(results))
+}
+
+// prepareBatchRun check the run permission of action and build the run of batch item, the failed result is returned when the run can not be prepared,
+// and the mock result is returned when the action mock enabled. the resources are shared between runs by the resources map.
+func (controller *Controller) prepareBatchRun(teamID int, userID int, userAuthToken string, item *request.BatchRunActionItem, action *model.Action, resources map[int]*model.Resource) (*batchRun, *response.BatchRunActionResult) {
+	if action == nil {
+		return nil, response.NewBatchRunActionFailedResult(item.ActionID, ERROR_FLAG_CAN_NOT_GET_ACTION, "get action failed: action not found in app", nil)
+	}
+
+	// validate
+	canManage, errInCheckAttr := controller.AttributeGroup.CanManage(
+		teamID,
+		userAuthToken,
+		accesscontrol.UNIT_TYPE_ACTION,
+		action.ExportID(),
+		accesscontrol.ACTION_MANAGE_RUN_ACTION,
+	)
+	if errInCheckAttr != nil {
+		return nil, response.NewBatchRunActionFailedResult(item.ActionID, ERROR_FLAG_ACCESS_DENIED, "error in check attribute: "+errInCheckAttr.Error(), errInCheckAttr)
+	}
+	if !canManage {
+		return nil, response.NewBatchRunActionFailedResult(item.ActionID, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.", nil)
+	}
+
+	// update action data with run action request, the template saved by editor authorizes the team variable placeholders
+	persistedTemplate := action.Template
+	runActionRequest := item.ExportRunActionRequest(action.ExportTemplateInMap())
+	action.UpdateWithRunActionRequest(runActionRequest, userID)
+
+	// return mock data instead of calling the real connector when mock enabled
+	if action.IsMockEnabled() {
+		mockResult, errInExportMockResult := action.ExportMockResult()
+		if errInExportMockResult != nil {
+			return nil, response.NewBatchRunActionFailedResult(item.ActionID, ERROR_FLAG_EXECUTE_ACTION_FAILED, "run action error: "+errInExportMockResult.Error(), errInExportMockResult)
+		}
+		return nil, response.NewBatchRunActionSucceededResult(item.ActionID, "", &mockResult)
+	}
+
+	// assembly action
+	actionAssemblyLine, errInBuild := model.NewActionFactoryByAction(action).Build()
+	if errInBuild != nil {
+		return nil, response.NewBatchRunActionFailedResult(item.ActionID, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate action type error: "+errInBuild.Error(), errInBuild)
+	}
+
+	// get resource
+	resource := model.NewResource()
+	var resourceOptions map[string]interface{}
+	if !action.IsVirtualAction() {
+		cachedResource, hit := resources[action.ExportResourceID()]
+		if !hit {
+			var errInRetrieveResource error
+			cachedResource, errInRetrieveResource = controller.Storage.ResourceStorage.RetrieveByTeamIDAndResourceID(teamID, action.ExportResourceID())
+			if errInRetrieveResource != nil {
+				return nil, response.NewBatchRunActionFailedResult(item.ActionID, ERROR_FLAG_CAN_NOT_GET_RESOURCE, "get resource failed: "+errInRetrieveResource.Error(), errInRetrieveResource)
+			}
+			resources[action.ExportResourceID()] = cachedResource
+		}
+		// the cached resource is shared, so only the run holds the environment variant
+		var errInSwitchResourceEnvironment error
+		resource, errInSwitchResourceEnvironment = controller.switchResourceEnvironment(action, cachedResource)
+		if errInSwitchResourceEnvironment != nil {
+			return nil, response.NewBatchRunActionFailedResult(item.ActionID, ERROR_FLAG_CAN_NOT_GET_RESOURCE, "get resource environment failed: "+errInSwitchResourceEnvironment.Error(), errInSwitchResourceEnvironment)
+		}
+		var errInResolveTeamVariables error
+		resource, errInResolveTeamVariables = controller.resolveTeamVariables(teamID, resource, false, model.NewTeamVariableRunTemplate(&action.Template, persistedTemplate, runActionRequest.ExportContext()))
+		if errInResolveTeamVariables != nil {
+			return nil, response.NewBatchRunActionFailedResult(item.ActionID, ERROR_FLAG_VALIDATE_RESOURCE_FAILED, "resolve team variables failed: "+errInResolveTeamVariables.Error(), errInResolveTeamVariables)
+		}
+		var errInExportResourceOptions error
+		resourceOptions, errInExportResourceOptions = resource.ExportOptionsInMap()
+		if errInExportResourceOptions != nil {
+			return nil, response.NewBatchRunActionFailedResult(item.ActionID, ERROR_FLAG_VALIDATE_RESOURCE_FAILED, "get resource options failed: "+errInExportResourceOptions.Error(), errInExportResourceOptions)
+		}
+		_, errInValidateResourceOptions := actionAssemblyLine.ValidateResourceOptions(resourceOptions)
+		if errInValidateResourceOptions != nil {
+			return nil, response.NewBatchRunActionFailedResult(item.ActionID, ERROR_FLAG_VALIDATE_RESOURCE_FAILED, "validate resource failed: "+errInValidateResourceOptions.Error(), errInValidateResourceOptions)
+		}
+	} else {
+		action.AppendRuntimeInfoForVirtualResource(userAuthToken, teamID)
+	}
+
+	// check action template
+	_, errInValidate := actionAssemblyLine.ValidateActionTemplate(action.ExportTemplateInMap())
+	if errInValidate != nil {
+		return nil, response.NewBatchRunActionFailedResult(item.ActionID, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate action template error: "+errInValidate.Error(), errInValidate)
+	}
+	return &batchRun{
+		action:          action,
+		connector:       actionAssemblyLine,
+		resource:        resource,
+		resourceOptions: resourceOptions,
+	}, nil
+}
+
+// runBatchRun run the prepared run, the result cache is served and filled like the single run.
+func (controller *Controller) runBatchRun(c *gin.Context, userID int, actionID string, run *batchRun) *response.BatchRunActionResult {
+	actionCacheKey := controller.newActionCacheKey(run.action)
+	cachedResult, cacheStatus := controller.lookupActionResultCache(actionCacheKey)
+	if cachedResult != nil {
+		return response.NewBatchRunActionSucceededResult(actionID, cacheStatus, cachedResult)
+	}
+
+	actionRunContext, cancelActionRun := NewActionRunContext(c, run.resource, run.action.ExportRunTimeout())
+	defer cancelActionRun()
+	actionRunLog := model.NewActionRunLogByAction(run.action, model.ACTION_RUN_LOG_SOURCE_EDITOR, userID)
+	actionRunResult, errInRunAction := run.connector.Run(actionRunContext, run.resourceOptions, run.action.ExportTemplateInMap(), run.action.ExportRawTemplateInMap())
+	actionRunLog.Finish(actionRunResult, errInRunAction)
+	controller.recordActionRun(actionRunLog)
+	if errInRunAction != nil {
+		return response.NewBatchRunActionFailedResult(actionID, ERROR_FLAG_EXECUTE_ACTION_FAILED, "run action error: "+errInRunAction.Error(), errInRunAction)
+	}
+	controller.cacheActionResult(run.action, actionCacheKey, actionRunResult)
+	return response.NewBatchRunActionSucceededResult(actionID, cacheStatus, &actionRunResult)
+}
@@ -1,11 +1,119 @@
 package controller
 
 import (
+	"context"
+	"log"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
 	"github.com/illacloud/builder-backend/src/model"
+	"github.com/illacloud/builder-backend/src/utils/config"
 	"github.com/illacloud/builder-backend/src/utils/resourcelist"
 )
 
+// NewActionRunContext derive action run context from gin request context,
+// so the running action will be cancelled when client disconnected or the timeout reached.
+// the server default timeout will be used when action timeout is not set.
+// the resource ID is attached for the connection pool, and the result limits of resource are attached for the connectors.
+func NewActionRunContext(c *gin.Context, resource *model.Resource, actionTimeout time.Duration) (context.Context, context.CancelFunc) {
+	if actionTimeout <= 0 {
+		actionTimeout = config.GetInstance().GetActionRunTimeout()
+	}
+	ctx := common.ContextWithResourceID(c.Request.Context(), resource.ExportID())
+	ctx = common.ContextWithResultLimits(ctx, resource.ExportResultLimits())
+	if actionTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, actionTimeout)
+}
+
+const (
+	ACTION_CACHE_HEADER        = "Illa-Action-Cache"
+	ACTION_CACHE_STATUS_HIT    = "HIT"
+	ACTION_CACHE_STATUS_MISS   = "MISS"
+	ACTION_CACHE_STATUS_BYPASS = "BYPASS"
+)
+
+// newActionCacheKey build the result cache key of action, return empty string when the action is not cacheable.
+func (controller *Controller) newActionCacheKey(action *model.Action) string {
+	if controller.Cache == nil || !action.IsCacheable() {
+		return ""
+	}
+	generation, errInGetGeneration := controller.Cache.ActionResultCache.GetResourceGeneration(action.TeamID, action.ExportResourceID())
+	if errInGetGeneration != nil {
+		log.Printf("[ERROR] get action result cache generation failed: %s\n", errInGetGeneration.Error())
+		return ""
+	}
+	runContext := action.ExportRawTemplateInMap()[model.ACTION_RUNTIME_INFO_FIELD_CONTEXT]
+	return model.NewActionCacheKey(action.TeamID, action.ExportResourceID(), generation, action.ExportID(), action.Version, action.ExportTemplateInMap(), runContext)
+}
+
+// getCachedActionResult return the cached result and set the cache status header.
+func (controller *Controller) getCachedActionResult(c *gin.Context, cacheKey string) (*common.RuntimeResult, bool) {
+	cachedResult, cacheStatus := controller.lookupActionResultCache(cacheKey)
+	c.Header(ACTION_CACHE_HEADER, cacheStatus)
+	return cachedResult, cachedResult != nil
+}
+
+// lookupActionResultCache return the cached result (nil when missed) and the cache status.
+func (controller *Controller) lookupActionResultCache(cacheKey string) (*common.RuntimeResult, string) {
+	if cacheKey == "" {
+		return nil, ACTION_CACHE_STATUS_BYPASS
+	}
+	cachedResult, hit, errInGetResult := controller.Cache.ActionResultCache.GetResult(cacheKey)
+	if errInGetResult != nil {
+		log.Printf("[ERROR] get action result cache failed: %s\n", errInGetResult.Error())
+	}
+	if !hit {
+		return nil, ACTION_CACHE_STATUS_MISS
+	}
+	return cachedResult, ACTION_CACHE_STATUS_HIT
+}
+
+// cacheActionResult store the succeeded result when the cache key given,
+// and invalidate the cached results of the resource when the action writes.
+func (controller *Controller) cacheActionResult(action *model.Action, cacheKey string, result common.RuntimeResult) {
+	if controller.Cache == nil {
+		return
+	}
+	if action.IsInvalidatingResourceCache() {
+		controller.invalidateResourceCache(action.TeamID, action.ExportResourceID())
+	}
+	if cacheKey == "" {
+		return
+	}
+	if errInSetResult := controller.Cache.ActionResultCache.SetResult(cacheKey, result, action.ExportCacheConfig().ExportTTL()); errInSetResult != nil {
+		log.Printf("[ERROR] set action result cache failed: %s\n", errInSetResult.Error())
+	}
+}
+
+func (controller *Controller) invalidateResourceCache(teamID int, resourceID int) {
+	if controller.Cache == nil {
+		return
+	}
+	if errInInvalidate := controller.Cache.ActionResultCache.InvalidateResource(teamID, resourceID); errInInvalidate != nil {
+		log.Printf("[ERROR] invalidate action result cache failed: %s\n", errInInvalidate.Error())
+	}
+}
+
+// feedbackRunActionError feedback the run error, the database errors carry the structured query error
+// (class, vendor code, SQLSTATE, line number, position and hint) in errorData.
+func (controller *Controller) feedbackRunActionError(c *gin.Context, errorFlag string, errorMessagePrefix string, errInRun error) {
+	if queryError, ok := common.AsQueryError(errInRun); ok {
+		controller.FeedbackBadRequestWithData(c, errorFlag, errorMessagePrefix+errInRun.Error(), queryError)
+		return
+	}
+	controller.FeedbackBadRequest(c, errorFlag, errorMessagePrefix+errInRun.Error())
+}
+
+// recordActionRun store the action run log, the run result feedback is not affected when storing failed.
+func (controller *Controller) recordActionRun(runLog *model.ActionRunLog) {
+	if _, errInCreate := controller.Storage.ActionRunLogStorage.Create(runLog); errInCreate != nil {
+		log.Printf("[ERROR] record action run log failed: %s\n", errInCreate.Error())
+	}
+}
+
 func (controller *Controller) ValidateActionTemplate(c *gin.Context, action *model.Action) error {
 	if resourcelist.IsVirtualResourceHaveNoOption(action.ExportType()) {
 		return nil
@@ -49,3 +157,16 @@ func (controller *Controller) ValidateFlowActionTemplate(c *gin.Context, flowAct
 	}
 	return nil
 }
+
+// switchResourceEnvironment return the resource running with the environment which the app binds to the action version,
+// the app is retrieved only when the resource has environment variants.
+func (controller *Controller) switchResourceEnvironment(action *model.Action, resource *model.Resource) (*model.Resource, error) {
+	if !resource.HasEnvironments() {
+		return resource, nil
+	}
+	app, errInRetrieveApp := controller.Storage.AppStorage.RetrieveAppByTeamIDAndAppID(action.TeamID, action.AppRefID)
+	if errInRetrieveApp != nil {
+		return nil, errInRetrieveApp
+	}
+	return resource.ExportEnvironmentVariant(app.ExportEnvironmentByAction(action))
+}
@@ -0,0 +1,25 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+)
+
+// IsActionRunInPreview check if the client requests the preview run mode by "?preview=true".
+func IsActionRunInPreview(c *gin.Context) bool {
+	isPreview, _ := strconv.ParseBool(c.Query(PARAM_PREVIEW))
+	return isPreview
+}
+
+// runActionInPreview run the write action in a rolled back transaction, only the connectors support preview can run it.
+func runActionInPreview(ctx context.Context, connector common.DataConnector, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+	previewConnector, ok := connector.(common.PreviewDataConnector)
+	if !ok {
+		return common.RuntimeResult{Success: false}, errors.New("the action type does not support preview")
+	}
+	return previewConnector.RunPreview(ctx, resourceOptions, actionOptions, rawActionOptions)
+}
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/illacloud/builder-backend/src/response"
+	"github.com/illacloud/builder-backend/src/storage"
+	"github.com/illacloud/builder-backend/src/utils/accesscontrol"
+	"github.com/illacloud/builder-backend/src/utils/idconvertor"
+)
+
+const (
+	ACTION_RUN_LOG_DEFAULT_PAGE_LIMIT = 20
+	ACTION_RUN_LOG_MAX_PAGE_LIMIT     = 100
+)
+
+// GetActionRunLogList list the run logs of an action, the runs of the released action (which has different ID) are matched by action name.
+func (controller *Controller) GetActionRunLogList(c *gin.Context) {
+	// fetch needed param
+	teamID, errInGetTeamID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_ID)
+	appID, errInGetAPPID := controller.GetMagicIntParamFromRequest(c, PARAM_APP_ID)
+	actionID, errInGetActionID := controller.GetMagicIntParamFromRequest(c, PARAM_ACTION_ID)
+	userAuthToken, errInGetAuthToken := controller.GetUserAuthTokenFromHeader(c)
+	if errInGetTeamID != nil || errInGetAPPID != nil || errInGetActionID != nil || errInGetAuthToken != nil {
+		return
+	}
+
+	// validate
+	canAccess, errInCheckAttr := controller.AttributeGroup.CanAccess(
+		teamID,
+		userAuthToken,
+		accesscontrol.UNIT_TYPE_APP,
+		appID,
+		accesscontrol.ACTION_ACCESS_VIEW,
+	)
+	if errInCheckAttr != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "error in check attribute: "+errInCheckAttr.Error())
+		return
+	}
+	if !canAccess {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// build filter
+	filter, pagination, errInParseFilter := newActionRunLogFilterByRequest(c, teamID)
+	if errInParseFilter != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_PARAM_FAILED, "validate request param error: "+errInParseFilter.Error())
+		return
+	}
+	filter.AppID = appID
+	filter.ActionID = actionID
+	// the action may be deleted, then only the runs with same ID are listed
+	if action, errInRetrieveAction := controller.Storage.ActionStorage.RetrieveActionByTeamIDActionID(teamID, actionID); errInRetrieveAction == nil {
+		filter.ActionName = action.ExportDisplayName()
+	}
+
+	controller.feedbackActionRunLogList(c, filter, pagination)
+}
+
+// GetTeamActionRunLogList list the run logs of the whole team, filtered by the request query.
+func (controller *Controller) GetTeamActionRunLogList(c *gin.Context) {
+	// fetch needed param
+	teamID, errInGetTeamID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_ID)
+	userAuthToken, errInGetAuthToken := controller.GetUserAuthTokenFromHeader(c)
+	if errInGetTeamID != nil || errInGetAuthToken != nil {
+		return
+	}
+
+	// validate, the team-wide run logs contain all users' parameters, so it requires the audit log access
+	canAccess, errInCheckAttr := controller.AttributeGroup.CanAccess(
+		teamID,
+		userAuthToken,
+		accesscontrol.UNIT_TYPE_AUDIT_LOG,
+		accesscontrol.DEFAULT_UNIT_ID,
+		accesscontrol.ACTION_ACCESS_VIEW,
+	)
+	if errInCheckAttr != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "error in check attribute: "+errInCheckAttr.Error())
+		return
+	}
+	if !canAccess {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// build filter
+	filter, pagination, errInParseFilter := newActionRunLogFilterByRequest(c, teamID)
+	if errInParseFilter != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_PARAM_FAILED, "validate request param error: "+errInParseFilter.Error())
+		return
+	}
+	filter.Kind = c.Query(PARAM_KIND)
+	filter.AppID = convertOptionalIDQuery(c, PARAM_APP_ID)
+	filter.WorkflowID = convertOptionalIDQuery(c, PARAM_WORKFLOW_ID)
+	filter.ActionID = convertOptionalIDQuery(c, PARAM_ACTION_ID)
+
+	controller.feedbackActionRunLogList(c, filter, pagination)
+}
+
+func (controller *Controller) feedbackActionRunLogList(c *gin.Context, filter *storage.ActionRunLogFilter, pagination *storage.Pagination) {
+	totalRows, errInRetrieveCount := controller.Storage.ActionRunLogStorage.RetrieveCountByFilter(filter)
+	if errInRetrieveCount != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ACTION_RUN_LOG, "get action run logs error: "+errInRetrieveCount.Error())
+		return
+	}
+	pagination.CalculateTotalPagesByTotalRows(totalRows)
+	runLogs, errInRetrieveRunLogs := controller.Storage.ActionRunLogStorage.RetrieveByFilterAndPage(filter, pagination)
+	if errInRetrieveRunLogs != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ACTION_RUN_LOG, "get action run logs error: "+errInRetrieveRunLogs.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, response.NewGetActionRunLogListResponse(runLogs, pagination.GetTotalPages(), pagination.GetTotalRows()))
+}
+
+// newActionRunLogFilterByRequest parse the common filters from request query:
+// limit, page, status, source, userID, from and to (RFC3339).
+func newActionRunLogFilterByRequest(c *gin.Context, teamID int) (*storage.ActionRunLogFilter, *storage.Pagination, error) {
+	limit, page := ACTION_RUN_LOG_DEFAULT_PAGE_LIMIT, 1
+	if limitInString := c.Query(PARAM_LIMIT); limitInString != "" {
+		var errInConvert error
+		if limit, errInConvert = strconv.Atoi(limitInString); errInConvert != nil || limit <= 0 {
+			return nil, nil, errors.New("invalid limit")
+		}
+		if limit > ACTION_RUN_LOG_MAX_PAGE_LIMIT {
+			limit = ACTION_RUN_LOG_MAX_PAGE_LIMIT
+		}
+	}
+	if pageInString := c.Query(PARAM_PAGE); pageInString != "" {
+		var errInConvert error
+		if page, errInConvert = strconv.Atoi(pageInString); errInConvert != nil || page <= 0 {
+			return nil, nil, errors.New("invalid page")
+		}
+	}
+
+	filter := storage.NewActionRunLogFilter(teamID)
+	filter.Status = c.Query(PARAM_STATUS)
+	filter.Source = c.Query(PARAM_SOURCE)
+	filter.ExecutedBy = convertOptionalIDQuery(c, PARAM_USER_ID)
+	for paramName, target := range map[string]*time.Time{PARAM_FROM: &filter.From, PARAM_TO: &filter.To} {
+		timeInString := c.Query(paramName)
+		if timeInString == "" {
+			continue
+		}
+		parsed, errInParse := time.Parse(time.RFC3339, timeInString)
+		if errInParse != nil {
+			return nil, nil, errors.New("invalid " + paramName + " time, it should be in RFC3339 format")
+		}
+		*target = parsed.UTC()
+	}
+	return filter, storage.NewPagination(limit, page), nil
+}
+
+func convertOptionalIDQuery(c *gin.Context, paramName string) int {
+	idInString := c.Query(paramName)
+	if idInString == "" {
+		return 0
+	}
+	return idconvertor.ConvertStringToInt(idInString)
+}
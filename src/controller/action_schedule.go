@@ -0,0 +1,304 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/illacloud/builder-backend/src/model"
+	"github.com/illacloud/builder-backend/src/request"
+	"github.com/illacloud/builder-backend/src/response"
+	"github.com/illacloud/builder-backend/src/storage"
+	"github.com/illacloud/builder-backend/src/utils/accesscontrol"
+)
+
+func (controller *Controller) CreateActionSchedule(c *gin.Context) {
+	// fetch needed param
+	teamID, errInGetTeamID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_ID)
+	appID, errInGetAPPID := controller.GetMagicIntParamFromRequest(c, PARAM_APP_ID)
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	userAuthToken, errInGetAuthToken := controller.GetUserAuthTokenFromHeader(c)
+	if errInGetTeamID != nil || errInGetAPPID != nil || errInGetUserID != nil || errInGetAuthToken != nil {
+		return
+	}
+
+	// validate
+	canManage, errInCheckAttr := controller.AttributeGroup.CanManage(
+		teamID,
+		userAuthToken,
+		accesscontrol.UNIT_TYPE_APP,
+		appID,
+		accesscontrol.ACTION_MANAGE_EDIT_APP,
+	)
+	if errInCheckAttr != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "error in check attribute: "+errInCheckAttr.Error())
+		return
+	}
+	if !canManage {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// parse request body
+	req := request.NewCreateActionScheduleRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate request body
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+	if errInValidateAction := controller.validateActionScheduleTarget(teamID, appID, req.ActionName); errInValidateAction != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate action error: "+errInValidateAction.Error())
+		return
+	}
+
+	// create
+	actionSchedule, errInNewActionSchedule := model.NewActionScheduleByRequest(teamID, appID, userID, req)
+	if errInNewActionSchedule != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate schedule error: "+errInNewActionSchedule.Error())
+		return
+	}
+	_, errInCreateActionSchedule := controller.Storage.ActionScheduleStorage.Create(actionSchedule)
+	if errInCreateActionSchedule != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_ACTION_SCHEDULE, "create action schedule error: "+errInCreateActionSchedule.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewActionScheduleForExport(actionSchedule))
+	return
+}
+
+func (controller *Controller) GetAllActionSchedules(c *gin.Context) {
+	// fetch needed param
+	teamID, errInGetTeamID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_ID)
+	appID, errInGetAPPID := controller.GetMagicIntParamFromRequest(c, PARAM_APP_ID)
+	userAuthToken, errInGetAuthToken := controller.GetUserAuthTokenFromHeader(c)
+	if errInGetTeamID != nil || errInGetAPPID != nil || errInGetAuthToken != nil {
+		return
+	}
+
+	// validate
+	canAccess, errInCheckAttr := controller.AttributeGroup.CanAccess(
+		teamID,
+		userAuthToken,
+		accesscontrol.UNIT_TYPE_APP,
+		appID,
+		accesscontrol.ACTION_ACCESS_VIEW,
+	)
+	if errInCheckAttr != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "error in check attribute: "+errInCheckAttr.Error())
+		return
+	}
+	if !canAccess {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// retrieve
+	actionSchedules, errInRetrieveActionSchedules := controller.Storage.ActionScheduleStorage.RetrieveByTeamIDAndAppID(teamID, appID)
+	if errInRetrieveActionSchedules != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ACTION_SCHEDULE, "get action schedules error: "+errInRetrieveActionSchedules.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, response.NewGetActionScheduleListResponse(actionSchedules))
+	return
+}
+
+func (controller *Controller) UpdateActionSchedule(c *gin.Context) {
+	// fetch needed param
+	teamID, errInGetTeamID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_ID)
+	appID, errInGetAPPID := controller.GetMagicIntParamFromRequest(c, PARAM_APP_ID)
+	actionScheduleID, errInGetActionScheduleID := controller.GetMagicIntParamFromRequest(c, PARAM_SCHEDULE_ID)
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	userAuthToken, errInGetAuthToken := controller.GetUserAuthTokenFromHeader(c)
+	if errInGetTeamID != nil || errInGetAPPID != nil || errInGetActionScheduleID != nil || errInGetUserID != nil || errInGetAuthToken != nil {
+		return
+	}
+
+	// validate
+	canManage, errInCheckAttr := controller.AttributeGroup.CanManage(
+		teamID,
+		userAuthToken,
+		accesscontrol.UNIT_TYPE_APP,
+		appID,
+		accesscontrol.ACTION_MANAGE_EDIT_APP,
+	)
+	if errInCheckAttr != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "error in check attribute: "+errInCheckAttr.Error())
+		return
+	}
+	if !canManage {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// parse request body
+	req := request.NewUpdateActionScheduleRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate request body
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+	if errInValidateAction := controller.validateActionScheduleTarget(teamID, appID, req.ActionName); errInValidateAction != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate action error: "+errInValidateAction.Error())
+		return
+	}
+
+	// fetch action schedule
+	actionSchedule, errInRetrieveActionSchedule := controller.Storage.ActionScheduleStorage.RetrieveByTeamIDAndID(teamID, actionScheduleID)
+	if errInRetrieveActionSchedule != nil || actionSchedule.ExportAppID() != appID {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ACTION_SCHEDULE, "get action schedule error: action schedule not found")
+		return
+	}
+
+	// update
+	if errInUpdateByRequest := actionSchedule.UpdateByRequest(userID, req); errInUpdateByRequest != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate schedule error: "+errInUpdateByRequest.Error())
+		return
+	}
+	errInUpdateActionSchedule := controller.Storage.ActionScheduleStorage.UpdateWholeActionSchedule(actionSchedule)
+	if errInUpdateActionSchedule != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_ACTION_SCHEDULE, "update action schedule error: "+errInUpdateActionSchedule.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewActionScheduleForExport(actionSchedule))
+	return
+}
+
+func (controller *Controller) DeleteActionSchedule(c *gin.Context) {
+	// fetch needed param
+	teamID, errInGetTeamID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_ID)
+	appID, errInGetAPPID := controller.GetMagicIntParamFromRequest(c, PARAM_APP_ID)
+	actionScheduleID, errInGetActionScheduleID := controller.GetMagicIntParamFromRequest(c, PARAM_SCHEDULE_ID)
+	userAuthToken, errInGetAuthToken := controller.GetUserAuthTokenFromHeader(c)
+	if errInGetTeamID != nil || errInGetAPPID != nil || errInGetActionScheduleID != nil || errInGetAuthToken != nil {
+		return
+	}
+
+	// validate
+	canManage, errInCheckAttr := controller.AttributeGroup.CanManage(
+		teamID,
+		userAuthToken,
+		accesscontrol.UNIT_TYPE_APP,
+		appID,
+		accesscontrol.ACTION_MANAGE_EDIT_APP,
+	)
+	if errInCheckAttr != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "error in check attribute: "+errInCheckAttr.Error())
+		return
+	}
+	if !canManage {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// fetch action schedule
+	actionSchedule, errInRetrieveActionSchedule := controller.Storage.ActionScheduleStorage.RetrieveByTeamIDAndID(teamID, actionScheduleID)
+	if errInRetrieveActionSchedule != nil || actionSchedule.ExportAppID() != appID {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ACTION_SCHEDULE, "get action schedule error: action schedule not found")
+		return
+	}
+
+	// delete schedule and run history
+	errInDelete := controller.Storage.Transaction(func(txStorage *storage.Storage) error {
+		if errInDeleteRuns := txStorage.ActionScheduleRunStorage.DeleteByTeamIDAndScheduleID(teamID, actionScheduleID); errInDeleteRuns != nil {
+			return errInDeleteRuns
+		}
+		return txStorage.ActionScheduleStorage.DeleteByTeamIDAndID(teamID, actionScheduleID)
+	})
+	if errInDelete != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_ACTION_SCHEDULE, "delete action schedule error: "+errInDelete.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, response.NewDeleteActionScheduleResponse(actionScheduleID))
+	return
+}
+
+func (controller *Controller) GetActionScheduleRunList(c *gin.Context) {
+	// fetch needed param
+	teamID, errInGetTeamID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_ID)
+	appID, errInGetAPPID := controller.GetMagicIntParamFromRequest(c, PARAM_APP_ID)
+	actionScheduleID, errInGetActionScheduleID := controller.GetMagicIntParamFromRequest(c, PARAM_SCHEDULE_ID)
+	pageLimit, errInGetPageLimit := controller.GetIntParamFromRequest(c, PARAM_PAGE_LIMIT)
+	page, errInGetPage := controller.GetIntParamFromRequest(c, PARAM_PAGE)
+	userAuthToken, errInGetAuthToken := controller.GetUserAuthTokenFromHeader(c)
+	if errInGetTeamID != nil || errInGetAPPID != nil || errInGetActionScheduleID != nil || errInGetPageLimit != nil || errInGetPage != nil || errInGetAuthToken != nil {
+		return
+	}
+
+	// validate
+	canAccess, errInCheckAttr := controller.AttributeGroup.CanAccess(
+		teamID,
+		userAuthToken,
+		accesscontrol.UNIT_TYPE_APP,
+		appID,
+		accesscontrol.ACTION_ACCESS_VIEW,
+	)
+	if errInCheckAttr != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "error in check attribute: "+errInCheckAttr.Error())
+		return
+	}
+	if !canAccess {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// fetch action schedule
+	actionSchedule, errInRetrieveActionSchedule := controller.Storage.ActionScheduleStorage.RetrieveByTeamIDAndID(teamID, actionScheduleID)
+	if errInRetrieveActionSchedule != nil || actionSchedule.ExportAppID() != appID {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ACTION_SCHEDULE, "get action schedule error: action schedule not found")
+		return
+	}
+
+	// retrieve by page
+	pagination := storage.NewPagination(pageLimit, page)
+	runTotalRows, errInRetrieveRunCount := controller.Storage.ActionScheduleRunStorage.RetrieveCountByTeamIDAndScheduleID(teamID, actionScheduleID)
+	if errInRetrieveRunCount != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ACTION_SCHEDULE, "get action schedule runs error: "+errInRetrieveRunCount.Error())
+		return
+	}
+	pagination.CalculateTotalPagesByTotalRows(runTotalRows)
+	runs, errInRetrieveRuns := controller.Storage.ActionScheduleRunStorage.RetrieveByTeamIDScheduleIDAndPage(teamID, actionScheduleID, pagination)
+	if errInRetrieveRuns != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ACTION_SCHEDULE, "get action schedule runs error: "+errInRetrieveRuns.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, response.NewGetActionScheduleRunListResponse(runs, pagination.GetTotalPages()))
+	return
+}
+
+// validateActionScheduleTarget check the scheduled action exists in the app.
+// the action is checked in edit version, since it may not be released yet when the schedule created.
+func (controller *Controller) validateActionScheduleTarget(teamID int, appID int, actionName string) error {
+	actions, errInRetrieveActions := controller.Storage.ActionStorage.RetrieveActionsByTeamIDAppIDAndVersion(teamID, appID, model.APP_EDIT_VERSION)
+	if errInRetrieveActions != nil {
+		return errInRetrieveActions
+	}
+	for _, action := range actions {
+		if action.ExportDisplayName() == actionName {
+			return nil
+		}
+	}
+	return errors.New("action " + actionName + " not found in app")
+}
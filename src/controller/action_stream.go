@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+	"github.com/illacloud/builder-backend/src/response"
+)
+
+const (
+	ACTION_RUN_STREAM_FORMAT_NDJSON = "ndjson"
+	ACTION_RUN_STREAM_CONTENT_TYPE  = "application/x-ndjson"
+	// flush the written rows to client every this many rows
+	ACTION_RUN_STREAM_FLUSH_ROWS = 100
+)
+
+// IsActionRunInStream check if the client requests the NDJSON stream run mode by "?stream=ndjson".
+func IsActionRunInStream(c *gin.Context) bool {
+	return c.Query(PARAM_STREAM) == ACTION_RUN_STREAM_FORMAT_NDJSON
+}
+
+// runActionInStream run the action and write the result as NDJSON lines, one row line for every row,
+// then an end line (with the "truncated" extra when the result limits reached) or an error line.
+// the connectors which do not support streaming are run in normal mode and their rows are written after the run.
+// the returned result carries no rows, the written row count is returned instead.
+func runActionInStream(c *gin.Context, ctx context.Context, connector common.DataConnector, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, int, error) {
+	c.Header("Content-Type", ACTION_RUN_STREAM_CONTENT_TYPE)
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Status(http.StatusOK)
+
+	limiter := common.NewRowLimiter(common.ResultLimitsFromContext(ctx))
+	rowCount := 0
+	emit := func(row map[string]interface{}) error {
+		rowInJSON, errInAccept := limiter.Accept(row)
+		if errInAccept != nil {
+			return errInAccept
+		}
+		if _, errInWrite := c.Writer.Write(response.NewRunActionStreamRowLine(rowInJSON).ExportInLine()); errInWrite != nil {
+			return errInWrite
+		}
+		rowCount++
+		if rowCount%ACTION_RUN_STREAM_FLUSH_ROWS == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	}
+
+	var result common.RuntimeResult
+	var errInRun error
+	if streamingConnector, ok := connector.(common.StreamingDataConnector); ok {
+		result, errInRun = streamingConnector.RunStream(ctx, resourceOptions, actionOptions, rawActionOptions, emit)
+	} else {
+		result, errInRun = connector.Run(ctx, resourceOptions, actionOptions, rawActionOptions)
+	}
+	if errInRun == nil {
+		errInRun = common.EmitEachRow(result.Rows, emit)
+	}
+	result.Rows = nil
+	if errInRun != nil {
+		c.Writer.Write(response.NewRunActionStreamErrorLine(errInRun).ExportInLine())
+		c.Writer.Flush()
+		return result, rowCount, errInRun
+	}
+	limiter.MarkResult(&result)
+	c.Writer.Write(response.NewRunActionStreamEndLine(result, rowCount).ExportInLine())
+	c.Writer.Flush()
+	return result, rowCount, nil
+}
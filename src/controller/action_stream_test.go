@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+	"github.com/illacloud/builder-backend/src/response"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeConnector return the rows after the run, it does not support streaming.
+type fakeConnector struct {
+	rows []map[string]interface{}
+	err  error
+}
+
+func (connector *fakeConnector) ValidateResourceOptions(resourceOptions map[string]interface{}) (common.ValidateResult, error) {
+	return common.ValidateResult{Valid: true}, nil
+}
+
+func (connector *fakeConnector) ValidateActionTemplate(actionOptions map[string]interface{}) (common.ValidateResult, error) {
+	return common.ValidateResult{Valid: true}, nil
+}
+
+func (connector *fakeConnector) TestConnection(ctx context.Context, resourceOptions map[string]interface{}) (common.ConnectionResult, error) {
+	return common.ConnectionResult{Success: true}, nil
+}
+
+func (connector *fakeConnector) GetMetaInfo(ctx context.Context, resourceOptions map[string]interface{}) (common.MetaInfoResult, error) {
+	return common.MetaInfoResult{Success: true}, nil
+}
+
+func (connector *fakeConnector) Run(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}) (common.RuntimeResult, error) {
+	return common.RuntimeResult{Success: connector.err == nil, Rows: connector.rows}, connector.err
+}
+
+// fakeStreamingConnector emit the rows while running, and stop on the emit error.
+type fakeStreamingConnector struct {
+	fakeConnector
+}
+
+func (connector *fakeStreamingConnector) RunStream(ctx context.Context, resourceOptions map[string]interface{}, actionOptions map[string]interface{}, rawActionOptions map[string]interface{}, emit common.RowEmitter) (common.RuntimeResult, error) {
+	for _, row := range connector.rows {
+		if errInEmit := emit(row); errInEmit != nil {
+			if errors.Is(errInEmit, common.ErrResultLimitReached) {
+				break
+			}
+			return common.RuntimeResult{}, errInEmit
+		}
+	}
+	return common.RuntimeResult{Success: true}, connector.err
+}
+
+func newTestStreamRows(count int) []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0, count)
+	for i := 0; i < count; i++ {
+		rows = append(rows, map[string]interface{}{"id": float64(i)})
+	}
+	return rows
+}
+
+func runTestActionInStream(t *testing.T, connector common.DataConnector, limits *common.ResultLimits) (*httptest.ResponseRecorder, []*response.RunActionStreamLine, int, error) {
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest("POST", "/run?stream=ndjson", nil)
+	ctx := common.ContextWithResultLimits(context.Background(), limits)
+	_, rowCount, err := runActionInStream(c, ctx, connector, nil, nil, nil)
+
+	lines := []*response.RunActionStreamLine{}
+	scanner := bufio.NewScanner(recorder.Body)
+	for scanner.Scan() {
+		line := &response.RunActionStreamLine{}
+		assert.Nil(t, json.Unmarshal(scanner.Bytes(), line), "every line should be a JSON object")
+		lines = append(lines, line)
+	}
+	return recorder, lines, rowCount, err
+}
+
+func TestRunActionInStream(t *testing.T) {
+	testCases := []struct {
+		name      string
+		connector common.DataConnector
+		limits    *common.ResultLimits
+		rowCount  int
+		truncated bool
+	}{
+		{name: "streaming", connector: &fakeStreamingConnector{fakeConnector: fakeConnector{rows: newTestStreamRows(3)}}, rowCount: 3},
+		{name: "streaming over limits", connector: &fakeStreamingConnector{fakeConnector: fakeConnector{rows: newTestStreamRows(5)}}, limits: common.NewResultLimits(2, 0), rowCount: 2, truncated: true},
+		{name: "not streaming", connector: &fakeConnector{rows: newTestStreamRows(3)}, rowCount: 3},
+		{name: "not streaming over limits", connector: &fakeConnector{rows: newTestStreamRows(5)}, limits: common.NewResultLimits(4, 0), rowCount: 4, truncated: true},
+		{name: "empty result", connector: &fakeConnector{}, rowCount: 0},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			recorder, lines, rowCount, err := runTestActionInStream(t, testCase.connector, testCase.limits)
+			assert.Nil(t, err)
+			assert.Equal(t, ACTION_RUN_STREAM_CONTENT_TYPE, recorder.Header().Get("Content-Type"))
+			assert.Equal(t, testCase.rowCount, rowCount)
+			assert.Equal(t, testCase.rowCount+1, len(lines), "the rows should be followed by one end line")
+
+			for i, line := range lines[:len(lines)-1] {
+				assert.Equal(t, response.RUN_ACTION_STREAM_LINE_TYPE_ROW, line.Type)
+				assert.JSONEq(t, `{"id":`+strconv.Itoa(i)+`}`, string(line.Data))
+			}
+			endLine := lines[len(lines)-1]
+			assert.Equal(t, response.RUN_ACTION_STREAM_LINE_TYPE_END, endLine.Type)
+			assert.Equal(t, testCase.rowCount, endLine.RowCount)
+			assert.Equal(t, testCase.truncated, endLine.Extra[common.RESULT_EXTRA_FIELD_TRUNCATED] == true)
+		})
+	}
+}
+
+func TestRunActionInStreamError(t *testing.T) {
+	errInRun := errors.New("connection reset")
+	connector := &fakeStreamingConnector{fakeConnector: fakeConnector{rows: newTestStreamRows(2), err: errInRun}}
+	_, lines, rowCount, err := runTestActionInStream(t, connector, nil)
+	assert.Equal(t, errInRun, err)
+	assert.Equal(t, 2, rowCount)
+
+	// the written rows are followed by an error line instead of the end line
+	assert.Equal(t, 3, len(lines))
+	errorLine := lines[len(lines)-1]
+	assert.Equal(t, response.RUN_ACTION_STREAM_LINE_TYPE_ERROR, errorLine.Type)
+	assert.Equal(t, errInRun.Error(), errorLine.ErrorMessage)
+}
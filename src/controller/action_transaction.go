@@ -0,0 +1,180 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+	"github.com/illacloud/builder-backend/src/model"
+	"github.com/illacloud/builder-backend/src/request"
+	"github.com/illacloud/builder-backend/src/response"
+	"github.com/illacloud/builder-backend/src/utils/accesscontrol"
+)
+
+// RunActionsInTransaction run an ordered list of actions against the same SQL resource in one database transaction,
+// the results are returned in order when all actions succeeded and committed,
+// otherwise the transaction is rolled back and the position of the failed action is returned in errorData.
+func (controller *Controller) RunActionsInTransaction(c *gin.Context) {
+	// fetch needed param
+	teamID, errInGetTeamID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_ID)
+	appID, errInGetAppID := controller.GetMagicIntParamFromRequest(c, PARAM_APP_ID)
+	userAuthToken, errInGetAuthToken := controller.GetUserAuthTokenFromHeader(c)
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetTeamID != nil || errInGetAppID != nil || errInGetAuthToken != nil || errInGetUserID != nil {
+		return
+	}
+
+	// parse request body
+	req := request.NewRunActionTransactionRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate request body
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// get actions, every action should be runnable by user and belong to the app and the resource
+	actions := make([]*model.Action, 0, len(req.Actions))
+	runTemplates := make([]*model.TeamVariableRunTemplate, 0, len(req.Actions))
+	var runTimeout time.Duration
+	for _, item := range req.Actions {
+		actionID := item.ExportActionIDInInt()
+		canManage, errInCheckAttr := controller.AttributeGroup.CanManage(
+			teamID,
+			userAuthToken,
+			accesscontrol.UNIT_TYPE_ACTION,
+			actionID,
+			accesscontrol.ACTION_MANAGE_RUN_ACTION,
+		)
+		if errInCheckAttr != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "error in check attribute: "+errInCheckAttr.Error())
+			return
+		}
+		if !canManage {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+			return
+		}
+		action, errInRetrieveAction := controller.Storage.ActionStorage.RetrieveActionByTeamIDActionID(teamID, actionID)
+		if errInRetrieveAction != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ACTION, "get action failed: "+errInRetrieveAction.Error())
+			return
+		}
+		if action.AppRefID != appID || action.ExportResourceID() != req.ExportResourceIDInInt() {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, fmt.Sprintf("action %s does not belong to the app and the resource", item.ActionID))
+			return
+		}
+		// the actions run with one resource environment, which is bound to the app version
+		if len(actions) > 0 && action.Version != actions[0].Version {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, fmt.Sprintf("action %s does not belong to the same app version", item.ActionID))
+			return
+		}
+		if action.IsMockEnabled() {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, fmt.Sprintf("action %s is mocked and can not run in transaction", item.ActionID))
+			return
+		}
+		// the template saved by editor authorizes the team variable placeholders
+		persistedTemplate := action.Template
+		runActionRequest := item.ExportRunActionRequest(action.ExportTemplateInMap())
+		action.UpdateWithRunActionRequest(runActionRequest, userID)
+		if action.ExportRunTimeout() > runTimeout {
+			runTimeout = action.ExportRunTimeout()
+		}
+		actions = append(actions, action)
+		runTemplates = append(runTemplates, model.NewTeamVariableRunTemplate(&action.Template, persistedTemplate, runActionRequest.ExportContext()))
+	}
+
+	// get resource
+	resource, errInRetrieveResource := controller.Storage.ResourceStorage.RetrieveByTeamIDAndResourceID(teamID, req.ExportResourceIDInInt())
+	if errInRetrieveResource != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_RESOURCE, "get resource failed: "+errInRetrieveResource.Error())
+		return
+	}
+	resource, errInSwitchResourceEnvironment := controller.switchResourceEnvironment(actions[0], resource)
+	if errInSwitchResourceEnvironment != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_RESOURCE, "get resource environment failed: "+errInSwitchResourceEnvironment.Error())
+		return
+	}
+	resource, errInResolveTeamVariables := controller.resolveTeamVariables(teamID, resource, false, runTemplates...)
+	if errInResolveTeamVariables != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_RESOURCE_FAILED, "resolve team variables failed: "+errInResolveTeamVariables.Error())
+		return
+	}
+
+	// assembly connector, all actions share the resource type
+	actionAssemblyLine, errInBuild := model.NewActionFactoryByAction(actions[0]).Build()
+	if errInBuild != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate action type error: "+errInBuild.Error())
+		return
+	}
+	transactionalConnector, ok := actionAssemblyLine.(common.TransactionalDataConnector)
+	if !ok {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "the resource type does not support transaction")
+		return
+	}
+	resourceOptions, errInExportResourceOptions := resource.ExportOptionsInMap()
+	if errInExportResourceOptions != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_RESOURCE_FAILED, "get resource options failed: "+errInExportResourceOptions.Error())
+		return
+	}
+	_, errInValidateResourceOptions := transactionalConnector.ValidateResourceOptions(resourceOptions)
+	if errInValidateResourceOptions != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_RESOURCE_FAILED, "validate resource failed: "+errInValidateResourceOptions.Error())
+		return
+	}
+	for _, action := range actions {
+		if _, errInValidate := transactionalConnector.ValidateActionTemplate(action.ExportTemplateInMap()); errInValidate != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate action template error: "+errInValidate.Error())
+			return
+		}
+	}
+
+	// run in transaction
+	actionRunContext, cancelActionRun := NewActionRunContext(c, resource, runTimeout)
+	defer cancelActionRun()
+	transaction, errInBegin := transactionalConnector.BeginTransaction(actionRunContext, resourceOptions)
+	if errInBegin != nil {
+		controller.feedbackRunActionError(c, ERROR_FLAG_EXECUTE_ACTION_FAILED, "begin transaction error: ", errInBegin)
+		return
+	}
+	// the rollback should not be cancelled by the timeout of run context
+	defer transaction.Rollback(context.Background())
+
+	results := make([]common.RuntimeResult, 0, len(actions))
+	for i, action := range actions {
+		actionRunLog := model.NewActionRunLogByAction(action, model.ACTION_RUN_LOG_SOURCE_EDITOR, userID)
+		actionRunResult, errInRunAction := transaction.Run(actionRunContext, action.ExportTemplateInMap(), action.ExportRawTemplateInMap())
+		actionRunLog.Finish(actionRunResult, errInRunAction)
+		controller.recordActionRun(actionRunLog)
+		if errInRunAction != nil {
+			errInRollback := transaction.Rollback(context.Background())
+			if errInRollback != nil {
+				errInRunAction = errors.Join(errInRunAction, errInRollback)
+			}
+			controller.FeedbackBadRequestWithData(c, ERROR_FLAG_EXECUTE_ACTION_FAILED, fmt.Sprintf("run action %d (%s) in transaction error, transaction rolled back: %s", i, action.ExportDisplayName(), errInRunAction.Error()), response.NewRunActionTransactionErrorData(i, req.Actions[i].ActionID, errInRunAction))
+			return
+		}
+		results = append(results, actionRunResult)
+	}
+	if errInCommit := transaction.Commit(actionRunContext); errInCommit != nil {
+		controller.FeedbackBadRequestWithData(c, ERROR_FLAG_EXECUTE_ACTION_FAILED, "commit transaction error: "+errInCommit.Error(), response.NewRunActionTransactionErrorData(-1, "", errInCommit))
+		return
+	}
+
+	// the committed writes invalidate the cached results of resource
+	for i, action := range actions {
+		controller.cacheActionResult(action, "", results[i])
+	}
+
+	// feedback
+	controller.FeedbackOK(c, response.NewRunActionTransactionResponse(results))
+}
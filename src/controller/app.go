@@ -2,6 +2,7 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -178,6 +179,9 @@ func (controller *Controller) DeleteApp(c *gin.Context) {
 	_ = controller.Storage.ActionStorage.DeleteActionsByApp(teamID, appID)
 	_ = controller.Storage.SetStateStorage.DeleteAllTypeSetStatesByApp(teamID, appID)
 	_ = controller.Storage.AppSnapshotStorage.DeleteAllAppSnapshotByTeamIDAndAppID(teamID, appID)
+	_ = controller.Storage.ActionScheduleStorage.DeleteByTeamIDAndAppID(teamID, appID)
+	_ = controller.Storage.ActionScheduleRunStorage.DeleteByTeamIDAndAppID(teamID, appID)
+	_ = controller.Storage.ActionRunLogStorage.DeleteByTeamIDAndAppID(teamID, appID)
 	errInDeleteApp := controller.Storage.AppStorage.Delete(teamID, appID)
 	if errInDeleteApp != nil {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_APP, "delete app error: "+errInDeleteApp.Error())
@@ -808,41 +812,54 @@ func (controller *Controller) ReleaseApp(c *gin.Context) {
 		}
 	}
 
-	// config app & action public status
-	if req.ExportPublic() {
-		// deploy app as public
-		app.SetPublic(userID)
-		controller.Storage.ActionStorage.MakeActionPublicByTeamIDAndAppID(teamID, appID, userID)
-	} else {
-		// marketplace app can not published as private
-		if app.IsPublishedToMarketplace() {
-			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_RELEASE_APP, "this app already published to marketplace, can not make it private.")
+	// marketplace app can not published as private
+	if !req.ExportPublic() && app.IsPublishedToMarketplace() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_RELEASE_APP, "this app already published to marketplace, can not make it private.")
+		return
+	}
+
+	// bind the release to the resource environment
+	if req.IsEnvironmentSet() {
+		if req.ExportEnvironment() != model.RESOURCE_ENVIRONMENT_DEFAULT && !model.IsValidResourceEnvironmentName(req.ExportEnvironment()) {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_RELEASE_APP, "invalid release environment: "+req.ExportEnvironment())
 			return
 		}
-		// deploy app as private
-		app.SetPrivate(userID)
-		controller.Storage.ActionStorage.MakeActionPrivateByTeamIDAndAppID(teamID, appID, userID)
-	}
+		app.SetReleaseEnvironment(req.ExportEnvironment(), userID)
+	}
+
+	// release app in transaction, the version bump will rollback when copy following components & actions failed
+	errInRelease := controller.Storage.Transaction(func(txStorage *storage.Storage) error {
+		// config app & action public status
+		if req.ExportPublic() {
+			// deploy app as public
+			app.SetPublic(userID)
+			if errInMakeActionPublic := txStorage.ActionStorage.MakeActionPublicByTeamIDAndAppID(teamID, appID, userID); errInMakeActionPublic != nil {
+				return errors.New("update action failed: " + errInMakeActionPublic.Error())
+			}
+		} else {
+			// deploy app as private
+			app.SetPrivate(userID)
+			if errInMakeActionPrivate := txStorage.ActionStorage.MakeActionPrivateByTeamIDAndAppID(teamID, appID, userID); errInMakeActionPrivate != nil {
+				return errors.New("update action failed: " + errInMakeActionPrivate.Error())
+			}
+		}
 
-	// release app version
-	treeStateLatestVersion, _ := controller.Storage.TreeStateStorage.RetrieveTreeStatesLatestVersion(teamID, appID)
-	app.SyncMainlineVersionWithTreeStateLatestVersion(treeStateLatestVersion)
-	app.Release()
+		// release app version
+		treeStateLatestVersion, _ := txStorage.TreeStateStorage.RetrieveTreeStatesLatestVersion(teamID, appID)
+		app.SyncMainlineVersionWithTreeStateLatestVersion(treeStateLatestVersion)
+		app.Release()
 
-	// update app for version bump, we should update app first in case create tree state failed with mismatch release & mainline version
-	errInUpdateApp := controller.Storage.AppStorage.UpdateWholeApp(app)
-	if errInUpdateApp != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_APP, "update app failed: "+errInUpdateApp.Error())
-		return
-	}
+		// update app for version bump, we should update app first in case create tree state failed with mismatch release & mainline version
+		if errInUpdateApp := txStorage.AppStorage.UpdateWholeApp(app); errInUpdateApp != nil {
+			return errors.New("update app failed: " + errInUpdateApp.Error())
+		}
 
-	// release app following components & actions
-	// release will copy following units from edit version to app mainline version
-	errInDuplicateTreeStateByVersion := controller.DuplicateTreeStateByVersion(c, teamID, teamID, appID, appID, model.APP_EDIT_VERSION, app.ExportMainlineVersion(), userID)
-	errInDuplicateKVStateByVersion := controller.DuplicateKVStateByVersion(c, teamID, teamID, appID, appID, model.APP_EDIT_VERSION, app.ExportMainlineVersion(), userID)
-	errInDuplicateSetStateByVersion := controller.DuplicateSetStateByVersion(c, teamID, teamID, appID, appID, model.APP_EDIT_VERSION, app.ExportMainlineVersion(), userID)
-	errInDuplicateActionByVersion := controller.DuplicateActionByVersion(c, teamID, teamID, appID, appID, model.APP_EDIT_VERSION, app.ExportMainlineVersion(), req.ExportPublic(), userID, false)
-	if errInDuplicateTreeStateByVersion != nil || errInDuplicateKVStateByVersion != nil || errInDuplicateSetStateByVersion != nil || errInDuplicateActionByVersion != nil {
+		// release app following components & actions
+		// release will copy following units from edit version to app mainline version
+		return DuplicateAppUnitsByVersionWithStorage(txStorage, teamID, teamID, appID, appID, model.APP_EDIT_VERSION, app.ExportMainlineVersion(), req.ExportPublic(), userID, false)
+	})
+	if errInRelease != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_RELEASE_APP, "release app failed: "+errInRelease.Error())
 		return
 	}
 
@@ -922,28 +939,31 @@ func (controller *Controller) TakeSnapshot(c *gin.Context) {
 		return
 	}
 
-	// config app version
-	treeStateLatestVersion, _ := controller.Storage.TreeStateStorage.RetrieveTreeStatesLatestVersion(teamID, appID)
-	app.SyncMainlineVersionWithTreeStateLatestVersion(treeStateLatestVersion)
-	app.BumpMainlineVersionOverReleaseVersion()
+	// take snapshot in transaction, the version bump will rollback when copy following components & actions failed
+	errInTakeSnapshot := controller.Storage.Transaction(func(txStorage *storage.Storage) error {
+		// config app version
+		treeStateLatestVersion, _ := txStorage.TreeStateStorage.RetrieveTreeStatesLatestVersion(teamID, appID)
+		app.SyncMainlineVersionWithTreeStateLatestVersion(treeStateLatestVersion)
+		app.BumpMainlineVersionOverReleaseVersion()
 
-	// update app for version bump, we should update app first in case create tree state failed with mismatch release & mainline version
-	errInUpdateApp := controller.Storage.AppStorage.UpdateWholeApp(app)
-	if errInUpdateApp != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_APP, "update app failed: "+errInUpdateApp.Error())
-		return
-	}
+		// update app for version bump, we should update app first in case create tree state failed with mismatch release & mainline version
+		if errInUpdateApp := txStorage.AppStorage.UpdateWholeApp(app); errInUpdateApp != nil {
+			return errors.New("update app failed: " + errInUpdateApp.Error())
+		}
 
-	// do snapshot for app following components and actions
-	// do snapshot will copy following units from edit version to app mainline version
-	controller.DuplicateTreeStateByVersion(c, teamID, teamID, appID, appID, model.APP_EDIT_VERSION, app.ExportMainlineVersion(), userID)
-	controller.DuplicateKVStateByVersion(c, teamID, teamID, appID, appID, model.APP_EDIT_VERSION, app.ExportMainlineVersion(), userID)
-	controller.DuplicateSetStateByVersion(c, teamID, teamID, appID, appID, model.APP_EDIT_VERSION, app.ExportMainlineVersion(), userID)
-	controller.DuplicateActionByVersion(c, teamID, teamID, appID, appID, model.APP_EDIT_VERSION, app.ExportMainlineVersion(), app.IsPublic(), userID, false)
+		// do snapshot for app following components and actions
+		// do snapshot will copy following units from edit version to app mainline version
+		errInDuplicate := DuplicateAppUnitsByVersionWithStorage(txStorage, teamID, teamID, appID, appID, model.APP_EDIT_VERSION, app.ExportMainlineVersion(), app.IsPublic(), userID, false)
+		if errInDuplicate != nil {
+			return errInDuplicate
+		}
 
-	// save snapshot
-	_, errInTakeSnapshot := controller.SaveAppSnapshot(c, teamID, appID, userID, app.ExportMainlineVersion(), model.SNAPSHOT_TRIGGER_MODE_MANUAL)
+		// save snapshot
+		_, errInSaveSnapshot := SaveAppSnapshotByVersionWithStorage(txStorage, teamID, appID, model.APP_EDIT_VERSION, app.ExportMainlineVersion(), model.SNAPSHOT_TRIGGER_MODE_MANUAL)
+		return errInSaveSnapshot
+	})
 	if errInTakeSnapshot != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_SNAPSHOT, "take snapshot failed: "+errInTakeSnapshot.Error())
 		return
 	}
 
@@ -1110,8 +1130,6 @@ func (controller *Controller) RecoverSnapshot(c *gin.Context) {
 		return
 	}
 
-	// phrase 1: take snapshot for current edit version
-
 	// fetch app
 	app, errInRetrieveApp := controller.Storage.AppStorage.RetrieveAppByTeamIDAndAppID(teamID, appID)
 	if errInRetrieveApp != nil {
@@ -1119,37 +1137,6 @@ func (controller *Controller) RecoverSnapshot(c *gin.Context) {
 		return
 	}
 
-	// bump app mainline versoin
-	app.BumpMainlineVersion()
-
-	// update app for version bump, we should update app first in case create tree state failed with mismatch release & mainline version
-	errInUpdateApp := controller.Storage.AppStorage.UpdateWholeApp(app)
-	if errInUpdateApp != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_APP, "update app failed: "+errInUpdateApp.Error())
-		return
-	}
-
-	// do snapshot for app following components and actions
-	// do snapshot will copy following units from edit version to app mainline version
-	controller.DuplicateTreeStateByVersion(c, teamID, teamID, appID, appID, model.APP_EDIT_VERSION, app.ExportMainlineVersion(), userID)
-	controller.DuplicateKVStateByVersion(c, teamID, teamID, appID, appID, model.APP_EDIT_VERSION, app.ExportMainlineVersion(), userID)
-	controller.DuplicateSetStateByVersion(c, teamID, teamID, appID, appID, model.APP_EDIT_VERSION, app.ExportMainlineVersion(), userID)
-	controller.DuplicateActionByVersion(c, teamID, teamID, appID, appID, model.APP_EDIT_VERSION, app.ExportMainlineVersion(), app.IsPublic(), userID, false)
-
-	// save app snapshot
-	newAppSnapshot, errInTakeSnapshot := controller.SaveAppSnapshot(c, teamID, appID, userID, app.ExportMainlineVersion(), model.SNAPSHOT_TRIGGER_MODE_AUTO)
-	if errInTakeSnapshot != nil {
-		return
-	}
-
-	// phrase 2: clean edit version app following components & actions
-	controller.Storage.TreeStateStorage.DeleteAllTypeTreeStatesByTeamIDAppIDAndVersion(teamID, appID, model.APP_EDIT_VERSION)
-	controller.Storage.KVStateStorage.DeleteAllTypeKVStatesByTeamIDAppIDAndVersion(teamID, appID, model.APP_EDIT_VERSION)
-	controller.Storage.SetStateStorage.DeleteAllTypeSetStatesByTeamIDAppIDAndVersion(teamID, appID, model.APP_EDIT_VERSION)
-	controller.Storage.ActionStorage.DeleteAllActionsByTeamIDAppIDAndVersion(teamID, appID, model.APP_EDIT_VERSION)
-
-	// phrase 3: duplicate target version app data to edit version
-
 	// get target snapshot
 	targetSnapshot, errInRetrieveSnapshot := controller.Storage.AppSnapshotStorage.RetrieveByID(snapshotID)
 	if errInRetrieveSnapshot != nil {
@@ -1158,20 +1145,53 @@ func (controller *Controller) RecoverSnapshot(c *gin.Context) {
 	}
 	targetVersion := targetSnapshot.ExportTargetVersion()
 
-	// copy target version app following components & actions to edit version
-	controller.DuplicateTreeStateByVersion(c, teamID, teamID, appID, appID, targetVersion, model.APP_EDIT_VERSION, userID)
-	controller.DuplicateKVStateByVersion(c, teamID, teamID, appID, appID, targetVersion, model.APP_EDIT_VERSION, userID)
-	controller.DuplicateSetStateByVersion(c, teamID, teamID, appID, appID, targetVersion, model.APP_EDIT_VERSION, userID)
-	controller.DuplicateActionByVersion(c, teamID, teamID, appID, appID, targetVersion, model.APP_EDIT_VERSION, app.IsPublic(), userID, false)
+	// recover snapshot in transaction, all phrases will rollback when any of them failed
+	errInRecoverSnapshot := controller.Storage.Transaction(func(txStorage *storage.Storage) error {
+		// phrase 1: take snapshot for current edit version
+		// bump app mainline versoin
+		app.BumpMainlineVersion()
 
-	// create a snapshot.ModifyHistory for recover snapshot
-	modifyHistoryLog := model.NewRecoverAppSnapshotModifyHistory(userID, targetSnapshot)
-	newAppSnapshot.PushModifyHistory(modifyHistoryLog)
+		// update app for version bump, we should update app first in case create tree state failed with mismatch release & mainline version
+		if errInUpdateApp := txStorage.AppStorage.UpdateWholeApp(app); errInUpdateApp != nil {
+			return errors.New("update app failed: " + errInUpdateApp.Error())
+		}
 
-	// update app snapshot
-	errInUpdateSnapshot := controller.Storage.AppSnapshotStorage.UpdateWholeSnapshot(newAppSnapshot)
-	if errInUpdateSnapshot != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_SNAPSHOT, "update app snapshot failed: "+errInUpdateSnapshot.Error())
+		// do snapshot for app following components and actions
+		// do snapshot will copy following units from edit version to app mainline version
+		errInDuplicateEditVersion := DuplicateAppUnitsByVersionWithStorage(txStorage, teamID, teamID, appID, appID, model.APP_EDIT_VERSION, app.ExportMainlineVersion(), app.IsPublic(), userID, false)
+		if errInDuplicateEditVersion != nil {
+			return errInDuplicateEditVersion
+		}
+
+		// save app snapshot
+		newAppSnapshot, errInSaveSnapshot := SaveAppSnapshotByVersionWithStorage(txStorage, teamID, appID, model.APP_EDIT_VERSION, app.ExportMainlineVersion(), model.SNAPSHOT_TRIGGER_MODE_AUTO)
+		if errInSaveSnapshot != nil {
+			return errInSaveSnapshot
+		}
+
+		// phrase 2: clean edit version app following components & actions
+		if errInClean := CleanAppUnitsByVersionWithStorage(txStorage, teamID, appID, model.APP_EDIT_VERSION); errInClean != nil {
+			return errInClean
+		}
+
+		// phrase 3: duplicate target version app data to edit version
+		errInDuplicateTargetVersion := DuplicateAppUnitsByVersionWithStorage(txStorage, teamID, teamID, appID, appID, targetVersion, model.APP_EDIT_VERSION, app.IsPublic(), userID, false)
+		if errInDuplicateTargetVersion != nil {
+			return errInDuplicateTargetVersion
+		}
+
+		// create a snapshot.ModifyHistory for recover snapshot
+		modifyHistoryLog := model.NewRecoverAppSnapshotModifyHistory(userID, targetSnapshot)
+		newAppSnapshot.PushModifyHistory(modifyHistoryLog)
+
+		// update app snapshot
+		if errInUpdateSnapshot := txStorage.AppSnapshotStorage.UpdateWholeSnapshot(newAppSnapshot); errInUpdateSnapshot != nil {
+			return errors.New("update app snapshot failed: " + errInUpdateSnapshot.Error())
+		}
+		return nil
+	})
+	if errInRecoverSnapshot != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_SNAPSHOT, "recover snapshot failed: "+errInRecoverSnapshot.Error())
 		return
 	}
 
@@ -1277,3 +1297,194 @@ func (controller *Controller) ForkMarketplaceApp(c *gin.Context) {
 	// feedback
 	controller.FeedbackOK(c, model.NewAppForExport(duplicatedApp, usersLT))
 }
+
+// ExportApp download the app as portable bundle, the edit version is exported by default.
+// pass "?version=" to export target version, like the released one.
+func (controller *Controller) ExportApp(c *gin.Context) {
+	// fetch needed param
+	teamID, errInGetTeamID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_ID)
+	appID, errInGetAPPID := controller.GetMagicIntParamFromRequest(c, PARAM_APP_ID)
+	userAuthToken, errInGetAuthToken := controller.GetUserAuthTokenFromHeader(c)
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetTeamID != nil || errInGetAPPID != nil || errInGetAuthToken != nil || errInGetUserID != nil {
+		return
+	}
+	version := model.APP_EDIT_VERSION
+	if versionInString, errInGetVersion := controller.TestFirstStringParamValueFromURI(c, PARAM_VERSION); errInGetVersion == nil {
+		versionInInt, errInConvertVersion := strconv.Atoi(versionInString)
+		if errInConvertVersion != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_PARAM_FAILED, "please input param in int format.")
+			return
+		}
+		version = versionInInt
+	}
+
+	// validate
+	canManage, errInCheckAttr := controller.AttributeGroup.CanManage(
+		teamID,
+		userAuthToken,
+		accesscontrol.UNIT_TYPE_APP,
+		appID,
+		accesscontrol.ACTION_MANAGE_EDIT_APP,
+	)
+	if errInCheckAttr != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "error in check attribute: "+errInCheckAttr.Error())
+		return
+	}
+	if !canManage {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// fetch app
+	app, errInRetrieveApp := controller.Storage.AppStorage.RetrieveAppByTeamIDAndAppID(teamID, appID)
+	if errInRetrieveApp != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_APP, "get app failed: "+errInRetrieveApp.Error())
+		return
+	}
+	if version == model.APP_AUTO_MAINLINE_VERSION {
+		version = app.ExportMainlineVersion()
+	}
+	if version == model.APP_AUTO_RELEASE_VERSION {
+		version = app.ExportReleaseVersion()
+	}
+
+	// build bundle
+	bundle, errInBuildBundle := BuildAppBundleWithStorage(controller.Storage, app, version)
+	if errInBuildBundle != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_EXPORT_APP, "export app failed: "+errInBuildBundle.Error())
+		return
+	}
+
+	// audit log
+	auditLogger := auditlogger.GetInstance()
+	auditLogger.Log(&auditlogger.LogInfo{
+		EventType: auditlogger.AUDIT_LOG_EXPORT_APP,
+		TeamID:    teamID,
+		UserID:    userID,
+		IP:        c.ClientIP(),
+		AppID:     appID,
+		AppName:   app.ExportAppName(),
+	})
+
+	// feedback
+	c.Header("Content-Disposition", "attachment; filename=\""+strconv.Itoa(appID)+".illa-app.json\"")
+	controller.FeedbackOK(c, bundle)
+	return
+}
+
+// ImportApp create a new app by the bundle which ExportApp produced.
+// the resource placeholders in bundle should be mapped to the existing resources of current team by "resourceMapping".
+func (controller *Controller) ImportApp(c *gin.Context) {
+	// fetch needed param
+	teamID, errInGetTeamID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_ID)
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	userAuthToken, errInGetAuthToken := controller.GetUserAuthTokenFromHeader(c)
+	if errInGetTeamID != nil || errInGetUserID != nil || errInGetAuthToken != nil {
+		return
+	}
+
+	// parse request body
+	req := request.NewImportAppRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate request body
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+	bundle := &model.AppBundle{}
+	if errInUnmarshalBundle := json.Unmarshal(req.ExportBundleInByte(), bundle); errInUnmarshalBundle != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "parse app bundle error: "+errInUnmarshalBundle.Error())
+		return
+	}
+	if errInValidateBundle := bundle.Validate(); errInValidateBundle != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate app bundle error: "+errInValidateBundle.Error())
+		return
+	}
+
+	// validate
+	canManage, errInCheckAttr := controller.AttributeGroup.CanManage(
+		teamID,
+		userAuthToken,
+		accesscontrol.UNIT_TYPE_APP,
+		accesscontrol.DEFAULT_UNIT_ID,
+		accesscontrol.ACTION_MANAGE_CREATE_APP,
+	)
+	if errInCheckAttr != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "error in check attribute: "+errInCheckAttr.Error())
+		return
+	}
+	if !canManage {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// the mapped resources must belong to current team, and be the type of placeholder
+	resourceMapping := req.ExportResourceMappingInInt()
+	for placeholder, resourceID := range resourceMapping {
+		slot, hitSlot := bundle.LookupResourceSlot(placeholder)
+		if !hitSlot {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "resource placeholder "+placeholder+" is not defined in app bundle")
+			return
+		}
+		resource, errInRetrieveResource := controller.Storage.ResourceStorage.RetrieveByTeamIDAndResourceID(teamID, resourceID)
+		if errInRetrieveResource != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_RESOURCE, "get resource for placeholder "+placeholder+" failed: "+errInRetrieveResource.Error())
+			return
+		}
+		if resource.ExportTypeInString() != slot.ResourceType {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "resource type "+resource.ExportTypeInString()+" does not match placeholder "+placeholder+" type "+slot.ResourceType)
+			return
+		}
+	}
+
+	// create app and following components & actions in transaction
+	newApp := model.NewAppByAppBundle(bundle, req.ExportAppName(), teamID, userID)
+	errInImport := controller.Storage.Transaction(func(txStorage *storage.Storage) error {
+		if _, errInCreateApp := txStorage.AppStorage.Create(newApp); errInCreateApp != nil {
+			return errors.New("create app failed: " + errInCreateApp.Error())
+		}
+		if errInImportBundle := ImportAppBundleWithStorage(txStorage, newApp, bundle, userID, resourceMapping); errInImportBundle != nil {
+			return errInImportBundle
+		}
+		newAppSnapshot := model.NewAppSnapshot(teamID, newApp.ExportID(), model.APP_EDIT_VERSION, model.SNAPSHOT_TRIGGER_MODE_AUTO)
+		if _, errInCreateSnapshot := txStorage.AppSnapshotStorage.Create(newAppSnapshot); errInCreateSnapshot != nil {
+			return errors.New("create snapshot failed: " + errInCreateSnapshot.Error())
+		}
+		return nil
+	})
+	if errInImport != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_IMPORT_APP, "import app failed: "+errInImport.Error())
+		return
+	}
+
+	// audit log
+	auditLogger := auditlogger.GetInstance()
+	auditLogger.Log(&auditlogger.LogInfo{
+		EventType: auditlogger.AUDIT_LOG_CREATE_APP,
+		TeamID:    teamID,
+		UserID:    userID,
+		IP:        c.ClientIP(),
+		AppID:     newApp.ExportID(),
+		AppName:   newApp.ExportAppName(),
+	})
+
+	// get all modifier user ids from all apps
+	allUserIDs := model.ExtractAllEditorIDFromApps([]*model.App{newApp})
+
+	// fet all user id mapped user info, and build user info lookup table
+	usersLT, errInGetMultiUserInfo := datacontrol.GetMultiUserInfo(allUserIDs)
+	if errInGetMultiUserInfo != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER, "get user info failed: "+errInGetMultiUserInfo.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewAppForExport(newApp, usersLT))
+	return
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	"github.com/illacloud/builder-backend/src/model"
+	"github.com/illacloud/builder-backend/src/storage"
 	"github.com/illacloud/builder-backend/src/utils/datacontrol"
 	"github.com/illacloud/builder-backend/src/utils/illaresourcemanagersdk"
 	"github.com/illacloud/builder-backend/src/utils/resourcelist"
@@ -29,11 +30,19 @@ import (
 
 // recover edit version treeState to target version (copy target version data to edit version)
 func (controller *Controller) DuplicateTreeStateByVersion(c *gin.Context, fromTeamID int, toTeamID int, fromAppID int, toAppID int, fromVersion int, toVersion int, modifierID int) error {
+	errInDuplicate := DuplicateTreeStateByVersionWithStorage(controller.Storage, fromTeamID, toTeamID, fromAppID, toAppID, fromVersion, toVersion, modifierID)
+	if errInDuplicate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_STATE, "duplicate tree state failed: "+errInDuplicate.Error())
+		return errInDuplicate
+	}
+	return nil
+}
+
+func DuplicateTreeStateByVersionWithStorage(s *storage.Storage, fromTeamID int, toTeamID int, fromAppID int, toAppID int, fromVersion int, toVersion int, modifierID int) error {
 	// get target version tree state from database
-	treeStates, errinRetrieveTreeStates := controller.Storage.TreeStateStorage.RetrieveTreeStatesByTeamIDAppIDAndVersion(fromTeamID, fromAppID, fromVersion)
+	treeStates, errinRetrieveTreeStates := s.TreeStateStorage.RetrieveTreeStatesByTeamIDAppIDAndVersion(fromTeamID, fromAppID, fromVersion)
 	if errinRetrieveTreeStates != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_STATE, "get tree state failed: "+errinRetrieveTreeStates.Error())
-		return errinRetrieveTreeStates
+		return errors.New("get tree state failed: " + errinRetrieveTreeStates.Error())
 	}
 	indexIDMap := map[int]int{}
 	idConvertMap := map[int]int{}
@@ -46,10 +55,9 @@ func (controller *Controller) DuplicateTreeStateByVersion(c *gin.Context, fromTe
 
 	// put them to the database as duplicate, and record the old-new id map
 	for i, treeState := range treeStates {
-		treeStateID, errInCreateApp := controller.Storage.TreeStateStorage.Create(treeState)
-		if errInCreateApp != nil {
-			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_APP, "create app failed: "+errInCreateApp.Error())
-			return errInCreateApp
+		treeStateID, errInCreateTreeState := s.TreeStateStorage.Create(treeState)
+		if errInCreateTreeState != nil {
+			return errors.New("create tree state failed: " + errInCreateTreeState.Error())
 		}
 		oldID := indexIDMap[i]
 		idConvertMap[oldID] = treeStateID
@@ -59,10 +67,9 @@ func (controller *Controller) DuplicateTreeStateByVersion(c *gin.Context, fromTe
 	for _, treeState := range treeStates {
 		treeState.ResetChildrenNodeRefIDsByMap(idConvertMap)
 		treeState.ResetParentNodeRefIDByMap(idConvertMap)
-		errInUpdateTreeState := controller.Storage.TreeStateStorage.Update(treeState)
+		errInUpdateTreeState := s.TreeStateStorage.Update(treeState)
 		if errInUpdateTreeState != nil {
-			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TREE_STATE, "update tree state failed: "+errInUpdateTreeState.Error())
-			return errInUpdateTreeState
+			return errors.New("update tree state failed: " + errInUpdateTreeState.Error())
 		}
 	}
 
@@ -70,11 +77,19 @@ func (controller *Controller) DuplicateTreeStateByVersion(c *gin.Context, fromTe
 }
 
 func (controller *Controller) DuplicateKVStateByVersion(c *gin.Context, fromTeamID int, toTeamID int, fromAppID int, toAppID int, fromVersion int, toVersion int, modifierID int) error {
+	errInDuplicate := DuplicateKVStateByVersionWithStorage(controller.Storage, fromTeamID, toTeamID, fromAppID, toAppID, fromVersion, toVersion, modifierID)
+	if errInDuplicate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_STATE, "duplicate kv state failed: "+errInDuplicate.Error())
+		return errInDuplicate
+	}
+	return nil
+}
+
+func DuplicateKVStateByVersionWithStorage(s *storage.Storage, fromTeamID int, toTeamID int, fromAppID int, toAppID int, fromVersion int, toVersion int, modifierID int) error {
 	// get target version K-V state from database
-	kvStates, errInRetrieveKVStates := controller.Storage.KVStateStorage.RetrieveKVStatesByTeamIDAppIDAndVersion(fromTeamID, fromAppID, fromVersion)
+	kvStates, errInRetrieveKVStates := s.KVStateStorage.RetrieveKVStatesByTeamIDAppIDAndVersion(fromTeamID, fromAppID, fromVersion)
 	if errInRetrieveKVStates != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_STATE, "get kv state failed: "+errInRetrieveKVStates.Error())
-		return errInRetrieveKVStates
+		return errors.New("get kv state failed: " + errInRetrieveKVStates.Error())
 	}
 
 	// set fork info
@@ -84,21 +99,28 @@ func (controller *Controller) DuplicateKVStateByVersion(c *gin.Context, fromTeam
 
 	// and put them to the database as duplicate
 	for _, kvState := range kvStates {
-		errInCreateKVState := controller.Storage.KVStateStorage.Create(kvState)
+		errInCreateKVState := s.KVStateStorage.Create(kvState)
 		if errInCreateKVState != nil {
-			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_STATE, "create kv state failed: "+errInCreateKVState.Error())
-			return errInCreateKVState
+			return errors.New("create kv state failed: " + errInCreateKVState.Error())
 		}
 	}
 	return nil
 }
 
 func (controller *Controller) DuplicateSetStateByVersion(c *gin.Context, fromTeamID int, toTeamID int, fromAppID int, toAppID int, fromVersion int, toVersion int, modifierID int) error {
+	errInDuplicate := DuplicateSetStateByVersionWithStorage(controller.Storage, fromTeamID, toTeamID, fromAppID, toAppID, fromVersion, toVersion, modifierID)
+	if errInDuplicate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_STATE, "duplicate set state failed: "+errInDuplicate.Error())
+		return errInDuplicate
+	}
+	return nil
+}
+
+func DuplicateSetStateByVersionWithStorage(s *storage.Storage, fromTeamID int, toTeamID int, fromAppID int, toAppID int, fromVersion int, toVersion int, modifierID int) error {
 	// get target version set state from database
-	setStates, errInRetrieveSetStates := controller.Storage.SetStateStorage.RetrieveSetStatesByTeamIDAppIDAndVersion(fromTeamID, fromAppID, model.SET_STATE_TYPE_DISPLAY_NAME, fromVersion)
+	setStates, errInRetrieveSetStates := s.SetStateStorage.RetrieveSetStatesByTeamIDAppIDAndVersion(fromTeamID, fromAppID, model.SET_STATE_TYPE_DISPLAY_NAME, fromVersion)
 	if errInRetrieveSetStates != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_STATE, "get set state failed: "+errInRetrieveSetStates.Error())
-		return errInRetrieveSetStates
+		return errors.New("get set state failed: " + errInRetrieveSetStates.Error())
 	}
 
 	// set fork info
@@ -108,10 +130,9 @@ func (controller *Controller) DuplicateSetStateByVersion(c *gin.Context, fromTea
 
 	// and put them to the database as duplicate
 	for _, setState := range setStates {
-		errInCreateSetState := controller.Storage.SetStateStorage.Create(setState)
+		errInCreateSetState := s.SetStateStorage.Create(setState)
 		if errInCreateSetState != nil {
-			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_STATE, "create set state failed: "+errInCreateSetState.Error())
-			return errInCreateSetState
+			return errors.New("create set state failed: " + errInCreateSetState.Error())
 		}
 	}
 
@@ -119,11 +140,19 @@ func (controller *Controller) DuplicateSetStateByVersion(c *gin.Context, fromTea
 }
 
 func (controller *Controller) DuplicateActionByVersion(c *gin.Context, fromTeamID int, toTeamID int, fromAppID int, toAppID int, fromVersion int, toVersion int, makeItPublic bool, modifierID int, isForkApp bool) error {
+	errInDuplicate := DuplicateActionByVersionWithStorage(controller.Storage, fromTeamID, toTeamID, fromAppID, toAppID, fromVersion, toVersion, makeItPublic, modifierID, isForkApp)
+	if errInDuplicate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_ACTION, "duplicate action failed: "+errInDuplicate.Error())
+		return errInDuplicate
+	}
+	return nil
+}
+
+func DuplicateActionByVersionWithStorage(s *storage.Storage, fromTeamID int, toTeamID int, fromAppID int, toAppID int, fromVersion int, toVersion int, makeItPublic bool, modifierID int, isForkApp bool) error {
 	// get target version action from database
-	actions, errinRetrieveAction := controller.Storage.ActionStorage.RetrieveActionsByTeamIDAppIDAndVersion(fromTeamID, fromAppID, fromVersion)
+	actions, errinRetrieveAction := s.ActionStorage.RetrieveActionsByTeamIDAppIDAndVersion(fromTeamID, fromAppID, fromVersion)
 	if errinRetrieveAction != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ACTION, "get action failed: "+errinRetrieveAction.Error())
-		return errinRetrieveAction
+		return errors.New("get action failed: " + errinRetrieveAction.Error())
 	}
 
 	// set fork info
@@ -156,10 +185,130 @@ func (controller *Controller) DuplicateActionByVersion(c *gin.Context, fromTeamI
 		fmt.Printf("[DUMP] DuplicateActionByVersion() action: %+v\n", action)
 
 		// create action
-		_, errInCreateAction := controller.Storage.ActionStorage.Create(action)
+		_, errInCreateAction := s.ActionStorage.Create(action)
 		if errInCreateAction != nil {
-			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_ACTION, "create action failed: "+errInCreateAction.Error())
-			return errInCreateAction
+			return errors.New("create action failed: " + errInCreateAction.Error())
+		}
+	}
+	return nil
+}
+
+// DuplicateAppUnitsByVersionWithStorage copy all app following components & actions (tree state, k-v state, set state and actions) from version to version.
+// pass the transaction storage to make it atomic.
+func DuplicateAppUnitsByVersionWithStorage(s *storage.Storage, fromTeamID int, toTeamID int, fromAppID int, toAppID int, fromVersion int, toVersion int, makeItPublic bool, modifierID int, isForkApp bool) error {
+	if errInDuplicate := DuplicateTreeStateByVersionWithStorage(s, fromTeamID, toTeamID, fromAppID, toAppID, fromVersion, toVersion, modifierID); errInDuplicate != nil {
+		return errInDuplicate
+	}
+	if errInDuplicate := DuplicateKVStateByVersionWithStorage(s, fromTeamID, toTeamID, fromAppID, toAppID, fromVersion, toVersion, modifierID); errInDuplicate != nil {
+		return errInDuplicate
+	}
+	if errInDuplicate := DuplicateSetStateByVersionWithStorage(s, fromTeamID, toTeamID, fromAppID, toAppID, fromVersion, toVersion, modifierID); errInDuplicate != nil {
+		return errInDuplicate
+	}
+	return DuplicateActionByVersionWithStorage(s, fromTeamID, toTeamID, fromAppID, toAppID, fromVersion, toVersion, makeItPublic, modifierID, isForkApp)
+}
+
+// CleanAppUnitsByVersionWithStorage delete all app following components & actions of target version.
+func CleanAppUnitsByVersionWithStorage(s *storage.Storage, teamID int, appID int, version int) error {
+	if errInDelete := s.TreeStateStorage.DeleteAllTypeTreeStatesByTeamIDAppIDAndVersion(teamID, appID, version); errInDelete != nil {
+		return errors.New("delete tree state failed: " + errInDelete.Error())
+	}
+	if errInDelete := s.KVStateStorage.DeleteAllTypeKVStatesByTeamIDAppIDAndVersion(teamID, appID, version); errInDelete != nil {
+		return errors.New("delete kv state failed: " + errInDelete.Error())
+	}
+	if errInDelete := s.SetStateStorage.DeleteAllTypeSetStatesByTeamIDAppIDAndVersion(teamID, appID, version); errInDelete != nil {
+		return errors.New("delete set state failed: " + errInDelete.Error())
+	}
+	if errInDelete := s.ActionStorage.DeleteAllActionsByTeamIDAppIDAndVersion(teamID, appID, version); errInDelete != nil {
+		return errors.New("delete action failed: " + errInDelete.Error())
+	}
+	return nil
+}
+
+// BuildAppBundleWithStorage collect target version app following components & actions into portable bundle.
+func BuildAppBundleWithStorage(s *storage.Storage, app *model.App, version int) (*model.AppBundle, error) {
+	teamID := app.ExportTeamID()
+	appID := app.ExportID()
+	treeStates, errInRetrieveTreeStates := s.TreeStateStorage.RetrieveTreeStatesByTeamIDAppIDAndVersion(teamID, appID, version)
+	if errInRetrieveTreeStates != nil {
+		return nil, errors.New("get tree state failed: " + errInRetrieveTreeStates.Error())
+	}
+	kvStates, errInRetrieveKVStates := s.KVStateStorage.RetrieveKVStatesByTeamIDAppIDAndVersion(teamID, appID, version)
+	if errInRetrieveKVStates != nil {
+		return nil, errors.New("get kv state failed: " + errInRetrieveKVStates.Error())
+	}
+	setStates, errInRetrieveSetStates := s.SetStateStorage.RetrieveSetStatesByTeamIDAppIDAndVersion(teamID, appID, model.SET_STATE_TYPE_DISPLAY_NAME, version)
+	if errInRetrieveSetStates != nil {
+		return nil, errors.New("get set state failed: " + errInRetrieveSetStates.Error())
+	}
+	actions, errInRetrieveActions := s.ActionStorage.RetrieveActionsByTeamIDAppIDAndVersion(teamID, appID, version)
+	if errInRetrieveActions != nil {
+		return nil, errors.New("get action failed: " + errInRetrieveActions.Error())
+	}
+
+	// build resource lookup table for resource placeholder description
+	resourcesLT := make(map[int]*model.Resource)
+	for _, action := range actions {
+		if action.IsVirtualAction() || action.ExportResourceID() == 0 {
+			continue
+		}
+		if _, hit := resourcesLT[action.ExportResourceID()]; hit {
+			continue
+		}
+		resource, errInRetrieveResource := s.ResourceStorage.RetrieveByTeamIDAndResourceID(teamID, action.ExportResourceID())
+		if errInRetrieveResource != nil {
+			continue
+		}
+		resourcesLT[action.ExportResourceID()] = resource
+	}
+
+	return model.NewAppBundle(app, treeStates, kvStates, setStates, actions, resourcesLT), nil
+}
+
+// ImportAppBundleWithStorage create app edit version following components & actions by bundle.
+// the resourceMapping is map[placeholder]resourceID, all resource placeholders used by actions must be mapped.
+func ImportAppBundleWithStorage(s *storage.Storage, app *model.App, bundle *model.AppBundle, modifierID int, resourceMapping map[string]int) error {
+	// put tree states to the database, and record the bundle-new id map
+	idConvertMap := map[int]int{}
+	treeStates := make([]*model.TreeState, 0, len(bundle.TreeStates))
+	for _, bundleTreeState := range bundle.TreeStates {
+		treeState := bundleTreeState.ExportTreeState(app, modifierID)
+		treeStateID, errInCreateTreeState := s.TreeStateStorage.Create(treeState)
+		if errInCreateTreeState != nil {
+			return errors.New("create tree state failed: " + errInCreateTreeState.Error())
+		}
+		idConvertMap[bundleTreeState.ID] = treeStateID
+		treeStates = append(treeStates, treeState)
+	}
+
+	// update tree states parent & children relation
+	for _, treeState := range treeStates {
+		treeState.ResetChildrenNodeRefIDsByMap(idConvertMap)
+		treeState.ResetParentNodeRefIDByMap(idConvertMap)
+		if errInUpdateTreeState := s.TreeStateStorage.Update(treeState); errInUpdateTreeState != nil {
+			return errors.New("update tree state failed: " + errInUpdateTreeState.Error())
+		}
+	}
+
+	for _, bundleKVState := range bundle.KVStates {
+		if errInCreateKVState := s.KVStateStorage.Create(bundleKVState.ExportKVState(app, modifierID)); errInCreateKVState != nil {
+			return errors.New("create kv state failed: " + errInCreateKVState.Error())
+		}
+	}
+
+	for _, bundleSetState := range bundle.SetStates {
+		if errInCreateSetState := s.SetStateStorage.Create(bundleSetState.ExportSetState(app, modifierID)); errInCreateSetState != nil {
+			return errors.New("create set state failed: " + errInCreateSetState.Error())
+		}
+	}
+
+	for _, bundleAction := range bundle.Actions {
+		action, errInExportAction := bundleAction.ExportAction(app, modifierID, resourceMapping)
+		if errInExportAction != nil {
+			return errInExportAction
+		}
+		if _, errInCreateAction := s.ActionStorage.Create(action); errInCreateAction != nil {
+			return errors.New("create action failed: " + errInCreateAction.Error())
 		}
 	}
 	return nil
@@ -182,17 +331,25 @@ func (controller *Controller) InitAppSnapshot(c *gin.Context, teamID int, appID
 	return newAppSnapShot, nil
 }
 
-// SaveAppSnapshotByVersion() method do following process:
+func (controller *Controller) SaveAppSnapshotByVersion(c *gin.Context, teamID int, appID int, userID int, fromVersion int, toVersion int, snapshotTriggerMode int) (*model.AppSnapshot, error) {
+	newAppSnapShot, errInSaveSnapshot := SaveAppSnapshotByVersionWithStorage(controller.Storage, teamID, appID, fromVersion, toVersion, snapshotTriggerMode)
+	if errInSaveSnapshot != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_SNAPSHOT, "save snapshot failed: "+errInSaveSnapshot.Error())
+		return nil, errInSaveSnapshot
+	}
+	return newAppSnapShot, nil
+}
+
+// SaveAppSnapshotByVersionWithStorage() method do following process:
 // - get current version snapshot
 // - set it to target version
 // - save it
 // - create new empty snapshot for current version
-func (controller *Controller) SaveAppSnapshotByVersion(c *gin.Context, teamID int, appID int, userID int, fromVersion int, toVersion int, snapshotTriggerMode int) (*model.AppSnapshot, error) {
+func SaveAppSnapshotByVersionWithStorage(s *storage.Storage, teamID int, appID int, fromVersion int, toVersion int, snapshotTriggerMode int) (*model.AppSnapshot, error) {
 	// retrieve app mainline version snapshot
-	editVersionAppSnapshot, errInRetrieveSnapshot := controller.Storage.AppSnapshotStorage.RetrieveByTeamIDAppIDAndTargetVersion(teamID, appID, fromVersion)
+	editVersionAppSnapshot, errInRetrieveSnapshot := s.AppSnapshotStorage.RetrieveByTeamIDAppIDAndTargetVersion(teamID, appID, fromVersion)
 	if errInRetrieveSnapshot != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_SNAPSHOT, "get snapshot failed: "+errInRetrieveSnapshot.Error())
-		return nil, errInRetrieveSnapshot
+		return nil, errors.New("get snapshot failed: " + errInRetrieveSnapshot.Error())
 	}
 
 	// set mainline version
@@ -200,10 +357,9 @@ func (controller *Controller) SaveAppSnapshotByVersion(c *gin.Context, teamID in
 	editVersionAppSnapshot.SetTriggerMode(snapshotTriggerMode)
 
 	// update old edit version snapshot
-	errInUpdateSnapshot := controller.Storage.AppSnapshotStorage.UpdateWholeSnapshot(editVersionAppSnapshot)
+	errInUpdateSnapshot := s.AppSnapshotStorage.UpdateWholeSnapshot(editVersionAppSnapshot)
 	if errInUpdateSnapshot != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_SNAPSHOT, "update snapshot failed: "+errInUpdateSnapshot.Error())
-		return nil, errInUpdateSnapshot
+		return nil, errors.New("update snapshot failed: " + errInUpdateSnapshot.Error())
 	}
 
 	// create new edit version snapshot
@@ -211,10 +367,9 @@ func (controller *Controller) SaveAppSnapshotByVersion(c *gin.Context, teamID in
 	newAppSnapShot.SetTriggerModeAuto()
 
 	// storage new edit version snapshot
-	_, errInCreateSnapshot := controller.Storage.AppSnapshotStorage.Create(newAppSnapShot)
+	_, errInCreateSnapshot := s.AppSnapshotStorage.Create(newAppSnapShot)
 	if errInCreateSnapshot != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_SNAPSHOT, "create snapshot failed: "+errInCreateSnapshot.Error())
-		return nil, errInCreateSnapshot
+		return nil, errors.New("create snapshot failed: " + errInCreateSnapshot.Error())
 	}
 
 	return newAppSnapShot, nil
@@ -0,0 +1,12 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+	"github.com/illacloud/builder-backend/src/response"
+)
+
+// GetConnectorList feedback the catalogue of registered connectors with their capabilities.
+func (controller *Controller) GetConnectorList(c *gin.Context) {
+	controller.FeedbackOK(c, response.NewGetConnectorListResponse(common.ExportConnectorRegistrations()))
+}
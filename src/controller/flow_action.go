@@ -2,18 +2,14 @@ package controller
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
-	"log"
 	"net/http"
-	"regexp"
-	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/illacloud/builder-backend/src/model"
 	"github.com/illacloud/builder-backend/src/request"
 	"github.com/illacloud/builder-backend/src/response"
+	"github.com/illacloud/builder-backend/src/storage"
 	"github.com/illacloud/builder-backend/src/utils/accesscontrol"
 	"github.com/illacloud/builder-backend/src/utils/illaresourcemanagersdk"
 )
@@ -83,8 +79,14 @@ func (controller *Controller) CreateFlowAction(c *gin.Context) {
 		return
 	}
 
-	// create flowAction
-	_, errInCreateAction := controller.Storage.FlowActionStorage.Create(flowAction)
+	// create flowAction, the resource is locked so it will not be deleted meanwhile
+	errInCreateAction := controller.Storage.Transaction(func(txStorage *storage.Storage) error {
+		if errInLock := lockActionResource(txStorage, teamID, flowAction.ExportType(), flowAction.ExportResourceID()); errInLock != nil {
+			return errInLock
+		}
+		_, errInCreate := txStorage.FlowActionStorage.Create(flowAction)
+		return errInCreate
+	})
 	if errInCreateAction != nil {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_FLOW_ACTION, "create flowAction error: "+errInCreateAction.Error())
 		return
@@ -163,8 +165,13 @@ func (controller *Controller) UpdateFlowAction(c *gin.Context) {
 		return
 	}
 
-	// update flowAction
-	errInUpdateAction := controller.Storage.FlowActionStorage.UpdateWholeFlowAction(inDatabaseFlowAction)
+	// update flowAction, the resource is locked so it will not be deleted meanwhile
+	errInUpdateAction := controller.Storage.Transaction(func(txStorage *storage.Storage) error {
+		if errInLock := lockActionResource(txStorage, teamID, inDatabaseFlowAction.ExportType(), inDatabaseFlowAction.ExportResourceID()); errInLock != nil {
+			return errInLock
+		}
+		return txStorage.FlowActionStorage.UpdateWholeFlowAction(inDatabaseFlowAction)
+	})
 	if errInUpdateAction != nil {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_FLOW_ACTION, "update flowAction error: "+errInUpdateAction.Error())
 		return
@@ -327,9 +334,25 @@ func (controller *Controller) RunFlowAction(c *gin.Context) {
 		}
 	}
 
+	// the template saved by editor authorizes the team variable placeholders, the onboarding flowAction has no saved template
+	persistedTemplate := ""
+	if model.DoesActionHasBeenCreated(flowActionID) {
+		persistedTemplate = flowAction.Template
+	}
+
 	// update flowAction data with run flowAction reqeust
 	flowAction.UpdateWithRunFlowActionRequest(runFlowActionRequest, userID)
-	fmt.Printf("[DUMP] flowAction: %+v\n", flowAction)
+
+	// return mock data instead of calling the real connector when mock enabled
+	if flowAction.IsMockEnabled() {
+		mockResult, errInExportMockResult := flowAction.ExportMockResult()
+		if errInExportMockResult != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_EXECUTE_FLOW_ACTION_FAILED, "run flowAction error: "+errInExportMockResult.Error())
+			return
+		}
+		c.JSON(http.StatusOK, mockResult)
+		return
+	}
 
 	// assembly flowAction
 	flowActionFactory := model.NewFlowActionFactoryByFlowAction(flowAction)
@@ -341,6 +364,7 @@ func (controller *Controller) RunFlowAction(c *gin.Context) {
 
 	// get resource
 	resource := model.NewResource()
+	var resourceOptions map[string]interface{}
 	if !flowAction.IsVirtualFlowAction() {
 		// process normal resource flowAction
 		var errInRetrieveResource error
@@ -349,9 +373,22 @@ func (controller *Controller) RunFlowAction(c *gin.Context) {
 			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_RESOURCE, "get resource failed: "+errInRetrieveResource.Error())
 			return
 		}
+		// resolve the team variables referenced by resource options and flowAction template
+		var errInResolveTeamVariables error
+		resource, errInResolveTeamVariables = controller.resolveTeamVariables(teamID, resource, false, model.NewTeamVariableRunTemplate(&flowAction.Template, persistedTemplate, runFlowActionRequest.ExportContext()))
+		if errInResolveTeamVariables != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_RESOURCE_FAILED, "resolve team variables failed: "+errInResolveTeamVariables.Error())
+			return
+		}
+		var errInExportResourceOptions error
+		resourceOptions, errInExportResourceOptions = resource.ExportOptionsInMap()
+		if errInExportResourceOptions != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_RESOURCE_FAILED, "get resource options failed: "+errInExportResourceOptions.Error())
+			return
+		}
 		// resource option validate only happend in create or update phrase
 		// note that validate will set resprce options to flowActionAssemblyLine
-		_, errInValidateResourceOptions := flowActionAssemblyLine.ValidateResourceOptions(resource.ExportOptionsInMap())
+		_, errInValidateResourceOptions := flowActionAssemblyLine.ValidateResourceOptions(resourceOptions)
 		if errInValidateResourceOptions != nil {
 			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_RESOURCE_FAILED, "validate resource failed: "+errInValidateResourceOptions.Error())
 			return
@@ -362,8 +399,6 @@ func (controller *Controller) RunFlowAction(c *gin.Context) {
 	}
 
 	// check flowAction template
-	fmt.Printf("[DUMP] flowAction.ExportTemplateInMap(): %+v\n", flowAction.ExportTemplateInMap())
-	fmt.Printf("[DUMP] flowAction.ExportRawTemplateInMap(): %+v\n", flowAction.ExportRawTemplateInMap())
 	_, errInValidate := flowActionAssemblyLine.ValidateActionTemplate(flowAction.ExportTemplateInMap())
 	if errInValidate != nil {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate flowAction template error: "+errInValidate.Error())
@@ -371,30 +406,17 @@ func (controller *Controller) RunFlowAction(c *gin.Context) {
 	}
 
 	// run
-	log.Printf("[DUMP]flowAction: %+v\n", flowAction)
-	log.Printf("[DUMP] resource.ExportOptionsInMap(): %+v, flowAction.ExportTemplateInMap(): %+v\n", resource.ExportOptionsInMap(), flowAction.ExportTemplateInMap())
-	flowActionRunResult, errInRunAction := flowActionAssemblyLine.Run(resource.ExportOptionsInMap(), flowAction.ExportTemplateInMap(), flowAction.ExportRawTemplateInMap())
+	actionRunContext, cancelActionRun := NewActionRunContext(c, resource, flowAction.ExportRunTimeout())
+	defer cancelActionRun()
+	flowActionRunLog := model.NewActionRunLogByFlowAction(flowAction, model.ACTION_RUN_LOG_SOURCE_EDITOR, userID)
+	flowActionRunResult, errInRunAction := flowActionAssemblyLine.Run(actionRunContext, resourceOptions, flowAction.ExportTemplateInMap(), flowAction.ExportRawTemplateInMap())
+	flowActionRunLog.Finish(flowActionRunResult, errInRunAction)
+	controller.recordActionRun(flowActionRunLog)
+	if errInRunAction == nil && flowAction.IsInvalidatingResourceCache() {
+		controller.invalidateResourceCache(teamID, flowAction.ExportResourceID())
+	}
 	if errInRunAction != nil {
-		if strings.HasPrefix(errInRunAction.Error(), "Error 1064:") {
-			lineNumber, _ := strconv.Atoi(errInRunAction.Error()[len(errInRunAction.Error())-1:])
-			message := ""
-			regexp, _ := regexp.Compile(`to use`)
-			match := regexp.FindStringIndex(errInRunAction.Error())
-			if len(match) == 2 {
-				message = errInRunAction.Error()[match[1]:]
-			}
-			c.JSON(http.StatusBadRequest, gin.H{
-				"errorCode":    400,
-				"errorFlag":    ERROR_FLAG_EXECUTE_FLOW_ACTION_FAILED,
-				"errorMessage": errors.New("SQL syntax error").Error(),
-				"errorData": map[string]interface{}{
-					"lineNumber": lineNumber,
-					"message":    "SQL syntax error" + message,
-				},
-			})
-			return
-		}
-		controller.FeedbackBadRequest(c, ERROR_FLAG_EXECUTE_FLOW_ACTION_FAILED, "run flowAction error: "+errInRunAction.Error())
+		controller.feedbackRunActionError(c, ERROR_FLAG_EXECUTE_FLOW_ACTION_FAILED, "run flowAction error: ", errInRunAction)
 		return
 	}
 
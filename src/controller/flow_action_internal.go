@@ -4,11 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
-	"regexp"
-	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/illacloud/builder-backend/src/model"
@@ -223,9 +219,22 @@ func (controller *Controller) RunFlowActionInternal(c *gin.Context) {
 		return
 	}
 
+	// the template saved by editor authorizes the team variable placeholders
+	persistedTemplate := flowAction.Template
+
 	// update flowAction data with run flowAction reqeust
 	flowAction.UpdateWithRunFlowActionRequest(runFlowActionRequest, model.ANONYMOUS_USER_ID)
-	fmt.Printf("[DUMP] flowAction: %+v\n", flowAction)
+
+	// return mock data instead of calling the real connector when mock enabled
+	if flowAction.IsMockEnabled() {
+		mockResult, errInExportMockResult := flowAction.ExportMockResult()
+		if errInExportMockResult != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_EXECUTE_FLOW_ACTION_FAILED, "run flowAction error: "+errInExportMockResult.Error())
+			return
+		}
+		c.JSON(http.StatusOK, mockResult)
+		return
+	}
 
 	// assembly flowAction
 	flowActionFactory := model.NewFlowActionFactoryByFlowAction(flowAction)
@@ -237,6 +246,7 @@ func (controller *Controller) RunFlowActionInternal(c *gin.Context) {
 
 	// get resource
 	resource := model.NewResource()
+	var resourceOptions map[string]interface{}
 	if !flowAction.IsVirtualFlowAction() {
 		// process normal resource flowAction
 		var errInRetrieveResource error
@@ -245,9 +255,22 @@ func (controller *Controller) RunFlowActionInternal(c *gin.Context) {
 			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_RESOURCE, "get resource failed: "+errInRetrieveResource.Error())
 			return
 		}
+		// resolve the team variables referenced by resource options and flowAction template
+		var errInResolveTeamVariables error
+		resource, errInResolveTeamVariables = controller.resolveTeamVariables(teamID, resource, false, model.NewTeamVariableRunTemplate(&flowAction.Template, persistedTemplate, runFlowActionRequest.ExportContext()))
+		if errInResolveTeamVariables != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_RESOURCE_FAILED, "resolve team variables failed: "+errInResolveTeamVariables.Error())
+			return
+		}
+		var errInExportResourceOptions error
+		resourceOptions, errInExportResourceOptions = resource.ExportOptionsInMap()
+		if errInExportResourceOptions != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_RESOURCE_FAILED, "get resource options failed: "+errInExportResourceOptions.Error())
+			return
+		}
 		// resource option validate only happend in create or update phrase
 		// note that validate will set resprce options to flowActionAssemblyLine
-		_, errInValidateResourceOptions := flowActionAssemblyLine.ValidateResourceOptions(resource.ExportOptionsInMap())
+		_, errInValidateResourceOptions := flowActionAssemblyLine.ValidateResourceOptions(resourceOptions)
 		if errInValidateResourceOptions != nil {
 			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_RESOURCE_FAILED, "validate resource failed: "+errInValidateResourceOptions.Error())
 			return
@@ -259,8 +282,6 @@ func (controller *Controller) RunFlowActionInternal(c *gin.Context) {
 	}
 
 	// check flowAction template
-	fmt.Printf("[DUMP] flowAction.ExportTemplateInMap(): %+v\n", flowAction.ExportTemplateInMap())
-	fmt.Printf("[DUMP] flowAction.ExportRawTemplateInMap(): %+v\n", flowAction.ExportRawTemplateInMap())
 	_, errInValidateActionTemplate := flowActionAssemblyLine.ValidateActionTemplate(flowAction.ExportTemplateInMap())
 	if errInValidateActionTemplate != nil {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate flowAction template error: "+errInValidate.Error())
@@ -268,30 +289,17 @@ func (controller *Controller) RunFlowActionInternal(c *gin.Context) {
 	}
 
 	// run
-	log.Printf("[DUMP]flowAction: %+v\n", flowAction)
-	log.Printf("[DUMP] resource.ExportOptionsInMap(): %+v, flowAction.ExportTemplateInMap(): %+v\n", resource.ExportOptionsInMap(), flowAction.ExportTemplateInMap())
-	flowActionRunResult, errInRunAction := flowActionAssemblyLine.Run(resource.ExportOptionsInMap(), flowAction.ExportTemplateInMap(), flowAction.ExportRawTemplateInMap())
+	actionRunContext, cancelActionRun := NewActionRunContext(c, resource, flowAction.ExportRunTimeout())
+	defer cancelActionRun()
+	flowActionRunLog := model.NewActionRunLogByFlowAction(flowAction, model.ACTION_RUN_LOG_SOURCE_INTERNAL, model.ANONYMOUS_USER_ID)
+	flowActionRunResult, errInRunAction := flowActionAssemblyLine.Run(actionRunContext, resourceOptions, flowAction.ExportTemplateInMap(), flowAction.ExportRawTemplateInMap())
+	flowActionRunLog.Finish(flowActionRunResult, errInRunAction)
+	controller.recordActionRun(flowActionRunLog)
+	if errInRunAction == nil && flowAction.IsInvalidatingResourceCache() {
+		controller.invalidateResourceCache(teamID, flowAction.ExportResourceID())
+	}
 	if errInRunAction != nil {
-		if strings.HasPrefix(errInRunAction.Error(), "Error 1064:") {
-			lineNumber, _ := strconv.Atoi(errInRunAction.Error()[len(errInRunAction.Error())-1:])
-			message := ""
-			regexp, _ := regexp.Compile(`to use`)
-			match := regexp.FindStringIndex(errInRunAction.Error())
-			if len(match) == 2 {
-				message = errInRunAction.Error()[match[1]:]
-			}
-			c.JSON(http.StatusBadRequest, gin.H{
-				"errorCode":    400,
-				"errorFlag":    ERROR_FLAG_EXECUTE_FLOW_ACTION_FAILED,
-				"errorMessage": errors.New("SQL syntax error").Error(),
-				"errorData": map[string]interface{}{
-					"lineNumber": lineNumber,
-					"message":    "SQL syntax error" + message,
-				},
-			})
-			return
-		}
-		controller.FeedbackBadRequest(c, ERROR_FLAG_EXECUTE_FLOW_ACTION_FAILED, "run flowAction error: "+errInRunAction.Error())
+		controller.feedbackRunActionError(c, ERROR_FLAG_EXECUTE_FLOW_ACTION_FAILED, "run flowAction error: ", errInRunAction)
 		return
 	}
 
@@ -91,6 +91,11 @@ func (controller *Controller) GenerateSQL(c *gin.Context) {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_RESOURCE, "error in fetch resource: "+errInGetResource.Error())
 		return
 	}
+	resource, errInResolveTeamVariables := controller.resolveTeamVariables(teamID, resource, false)
+	if errInResolveTeamVariables != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_RESOURCE_FAILED, "resolve team variables failed: "+errInResolveTeamVariables.Error())
+		return
+	}
 
 	// fetch resource meta info
 	actionFactory := model.NewActionFactoryByResource(resource)
@@ -99,7 +104,7 @@ func (controller *Controller) GenerateSQL(c *gin.Context) {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate action type error: "+errInBuild.Error())
 		return
 	}
-	resourceMetaInfo, errInGetMetaInfo := actionAssemblyLine.GetMetaInfo(resource.ExportOptionsInMap())
+	resourceMetaInfo, _, errInGetMetaInfo := controller.fetchResourceMetaInfo(c.Request.Context(), resource, actionAssemblyLine, false)
 	if errInGetMetaInfo != nil {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_RESOURCE_META_INFO, "error in fetch resource meta info: "+errInGetMetaInfo.Error())
 		return
@@ -16,7 +16,6 @@ func (controller *Controller) GoogleOAuth2Exchange(c *gin.Context) {
 
 	fmt.Printf("[DUMP] GoogleOAuth2Exchange().state: %+v\n", state)
 	fmt.Printf("[DUMP] GoogleOAuth2Exchange().errInGetState: %+v\n", errInGetState)
-	fmt.Printf("[DUMP] GoogleOAuth2Exchange().code: %+v\n", code)
 	fmt.Printf("[DUMP] GoogleOAuth2Exchange().errInGetCode: %+v\n", errInGetCode)
 	fmt.Printf("[DUMP] GoogleOAuth2Exchange().errorOAuth2Callback: %+v\n", errorOAuth2Callback)
 
@@ -72,7 +71,6 @@ func (controller *Controller) GoogleOAuth2Exchange(c *gin.Context) {
 
 	// exchange access token
 	exchangeTokenResponse, errInExchangeOAuthToken := oauthgoogle.ExchangeOAuthToken(code)
-	fmt.Printf("[DUMP] GoogleOAuth2Exchange.exchangeTokenResponse: %+v\n", exchangeTokenResponse)
 	if errInExchangeOAuthToken != nil {
 		fmt.Printf("[FAILED] 6\n")
 
@@ -80,7 +78,11 @@ func (controller *Controller) GoogleOAuth2Exchange(c *gin.Context) {
 		return
 	}
 	resourceOptionGoogleSheets.UpdateByExchangeTokenResponse(exchangeTokenResponse)
-	resource.UpdateGoogleSheetOAuth2Options(userID, resourceOptionGoogleSheets)
+	errInUpdateOptions := resource.UpdateGoogleSheetOAuth2Options(userID, resourceOptionGoogleSheets)
+	if errInUpdateOptions != nil {
+		controller.FeedbackRedirect(c, redirectURIForFailed)
+		return
+	}
 
 	// update resource
 	errInUpdateResource := controller.Storage.ResourceStorage.UpdateWholeResource(resource)
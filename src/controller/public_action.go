@@ -2,11 +2,7 @@ package controller
 
 import (
 	"encoding/json"
-	"errors"
 	"net/http"
-	"regexp"
-	"strconv"
-	"strings"
 
 	"github.com/illacloud/builder-backend/src/model"
 	"github.com/illacloud/builder-backend/src/request"
@@ -60,9 +56,31 @@ func (controller *Controller) RunPublicAction(c *gin.Context) {
 		return
 	}
 
+	// only the template of released app authorizes the team variable placeholders for anonymous run
+	app, errInRetrieveApp := controller.Storage.AppStorage.RetrieveAppByTeamIDAndAppID(teamID, action.AppRefID)
+	if errInRetrieveApp != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_APP, "get app failed: "+errInRetrieveApp.Error())
+		return
+	}
+	persistedTemplate := ""
+	if action.Version == app.ExportReleaseVersion() {
+		persistedTemplate = action.Template
+	}
+
 	// update action data with run action reqeust
 	action.UpdateWithRunActionRequest(runActionRequest, userID)
 
+	// return mock data instead of calling the real connector when mock enabled
+	if action.IsMockEnabled() {
+		mockResult, errInExportMockResult := action.ExportMockResult()
+		if errInExportMockResult != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_EXECUTE_ACTION_FAILED, "run action error: "+errInExportMockResult.Error())
+			return
+		}
+		c.JSON(http.StatusOK, mockResult)
+		return
+	}
+
 	// assembly action
 	actionFactory := model.NewActionFactoryByAction(action)
 	actionAssemblyLine, errInBuild := actionFactory.Build()
@@ -73,6 +91,7 @@ func (controller *Controller) RunPublicAction(c *gin.Context) {
 
 	// get resource
 	resource := model.NewResource()
+	var resourceOptions map[string]interface{}
 	if !action.IsVirtualAction() {
 		// process normal resource action
 		var errInRetrieveResource error
@@ -81,9 +100,29 @@ func (controller *Controller) RunPublicAction(c *gin.Context) {
 			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_RESOURCE, "get resource failed: "+errInRetrieveResource.Error())
 			return
 		}
+		// run with the resource environment which the app binds to the action version
+		var errInSwitchResourceEnvironment error
+		resource, errInSwitchResourceEnvironment = controller.switchResourceEnvironment(action, resource)
+		if errInSwitchResourceEnvironment != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_RESOURCE, "get resource environment failed: "+errInSwitchResourceEnvironment.Error())
+			return
+		}
+		// resolve the team variables referenced by resource options and action template
+		var errInResolveTeamVariables error
+		resource, errInResolveTeamVariables = controller.resolveTeamVariables(teamID, resource, true, model.NewTeamVariableRunTemplate(&action.Template, persistedTemplate, runActionRequest.ExportContext()))
+		if errInResolveTeamVariables != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_RESOURCE_FAILED, "resolve team variables failed: "+errInResolveTeamVariables.Error())
+			return
+		}
+		var errInExportResourceOptions error
+		resourceOptions, errInExportResourceOptions = resource.ExportOptionsInMap()
+		if errInExportResourceOptions != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_RESOURCE_FAILED, "get resource options failed: "+errInExportResourceOptions.Error())
+			return
+		}
 		// resource option validate only happend in create or update phrase
 		// note that validate will set resprce options to actionAssemblyLine
-		_, errInValidateResourceOptions := actionAssemblyLine.ValidateResourceOptions(resource.ExportOptionsInMap())
+		_, errInValidateResourceOptions := actionAssemblyLine.ValidateResourceOptions(resourceOptions)
 		if errInValidateResourceOptions != nil {
 			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_RESOURCE_FAILED, "validate resource failed: "+errInValidateResourceOptions.Error())
 			return
@@ -101,28 +140,24 @@ func (controller *Controller) RunPublicAction(c *gin.Context) {
 	}
 
 	// run
-	actionRunResult, errInRunAction := actionAssemblyLine.Run(resource.ExportOptionsInMap(), action.ExportTemplateInMap(), action.ExportRawTemplateInMap())
+	// serve from result cache when the action opts in
+	actionCacheKey := controller.newActionCacheKey(action)
+	if cachedResult, hit := controller.getCachedActionResult(c, actionCacheKey); hit {
+		c.JSON(http.StatusOK, cachedResult)
+		return
+	}
+
+	actionRunContext, cancelActionRun := NewActionRunContext(c, resource, action.ExportRunTimeout())
+	defer cancelActionRun()
+	actionRunLog := model.NewActionRunLogByAction(action, model.ACTION_RUN_LOG_SOURCE_PUBLIC, userID)
+	actionRunResult, errInRunAction := actionAssemblyLine.Run(actionRunContext, resourceOptions, action.ExportTemplateInMap(), action.ExportRawTemplateInMap())
+	actionRunLog.Finish(actionRunResult, errInRunAction)
+	controller.recordActionRun(actionRunLog)
+	if errInRunAction == nil {
+		controller.cacheActionResult(action, actionCacheKey, actionRunResult)
+	}
 	if errInRunAction != nil {
-		if strings.HasPrefix(errInRunAction.Error(), "Error 1064:") {
-			lineNumber, _ := strconv.Atoi(errInRunAction.Error()[len(errInRunAction.Error())-1:])
-			message := ""
-			regexp, _ := regexp.Compile(`to use`)
-			match := regexp.FindStringIndex(errInRunAction.Error())
-			if len(match) == 2 {
-				message = errInRunAction.Error()[match[1]:]
-			}
-			c.JSON(http.StatusBadRequest, gin.H{
-				"errorCode":    400,
-				"errorFlag":    ERROR_FLAG_EXECUTE_ACTION_FAILED,
-				"errorMessage": errors.New("SQL syntax error").Error(),
-				"errorData": map[string]interface{}{
-					"lineNumber": lineNumber,
-					"message":    "SQL syntax error" + message,
-				},
-			})
-			return
-		}
-		controller.FeedbackBadRequest(c, ERROR_FLAG_EXECUTE_ACTION_FAILED, "run action error: "+errInRunAction.Error())
+		controller.feedbackRunActionError(c, ERROR_FLAG_EXECUTE_ACTION_FAILED, "run action error: ", errInRunAction)
 		return
 	}
 
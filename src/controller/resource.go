@@ -2,13 +2,17 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
 	"github.com/illacloud/builder-backend/src/model"
 	"github.com/illacloud/builder-backend/src/request"
 	"github.com/illacloud/builder-backend/src/response"
+	"github.com/illacloud/builder-backend/src/storage"
 	"github.com/illacloud/builder-backend/src/utils/accesscontrol"
 	"github.com/illacloud/builder-backend/src/utils/auditlogger"
 )
@@ -90,7 +94,11 @@ func (controller *Controller) CreateResource(c *gin.Context) {
 	}
 
 	// new resource
-	resource := model.NewResourceByCreateResourceRequest(teamID, userID, createResourceRequest)
+	resource, errInNewResource := model.NewResourceByCreateResourceRequest(teamID, userID, createResourceRequest)
+	if errInNewResource != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_RESOURCE, "create resources error: "+errInNewResource.Error())
+		return
+	}
 
 	// validate options
 	errInValidateResourceContent := controller.ValidateResourceConternt(c, resource)
@@ -209,7 +217,11 @@ func (controller *Controller) UpdateResource(c *gin.Context) {
 	}
 
 	// update field
-	resource.UpdateByUpdateResourceRequest(userID, updateResourceRequest)
+	errInUpdateField := resource.UpdateByUpdateResourceRequest(userID, updateResourceRequest)
+	if errInUpdateField != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_RESOURCE, "update resources error: "+errInUpdateField.Error())
+		return
+	}
 
 	// validate options
 	errInValidateResourceContent := controller.ValidateResourceConternt(c, resource)
@@ -224,6 +236,9 @@ func (controller *Controller) UpdateResource(c *gin.Context) {
 		return
 	}
 
+	// drop the pooled connections dialed with the old options
+	common.GetConnectionPoolManager().InvalidateResource(resource.ExportID())
+
 	// audit log
 	auditLogger := auditlogger.GetInstance()
 	auditLogger.Log(&auditlogger.LogInfo{
@@ -275,6 +290,62 @@ func (controller *Controller) DeleteResource(c *gin.Context) {
 		return
 	}
 
+	// check the re-point target, the actions still used the resource can be re-pointed to another resource of the same type
+	force, _ := strconv.ParseBool(c.Query(PARAM_FORCE))
+	repointToResourceID := convertOptionalIDQuery(c, PARAM_REPOINT_TO)
+	if c.Query(PARAM_REPOINT_TO) != "" && repointToResourceID == 0 {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_PARAM_FAILED, "invalid re-point target resource id.")
+		return
+	}
+	if repointToResourceID != 0 {
+		repointToResource, errInRetrieveRepointToResource := controller.Storage.ResourceStorage.RetrieveByTeamIDAndResourceID(teamID, repointToResourceID)
+		if errInRetrieveRepointToResource != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_RESOURCE, "get re-point target resource error: "+errInRetrieveRepointToResource.Error())
+			return
+		}
+		if repointToResource.ExportID() == resourceID || repointToResource.ExportType() != resource.ExportType() {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_PARAM_FAILED, "the actions can only be re-pointed to another resource of the same type.")
+			return
+		}
+	}
+
+	// delete, the usage is collected after locking the resource row, which the action create and update apis lock in share mode,
+	// so the actions saved meanwhile are re-pointed too. the resource still used by actions is deleted only by force.
+	var resourceUsage *model.ResourceUsage
+	errInDeleteResource := controller.Storage.Transaction(func(txStorage *storage.Storage) error {
+		if errInLock := txStorage.ResourceStorage.LockByTeamIDAndResourceID(teamID, resourceID, true); errInLock != nil {
+			return errInLock
+		}
+		var errInRetrieveResourceUsage error
+		resourceUsage, errInRetrieveResourceUsage = retrieveResourceUsage(txStorage, teamID, resourceID)
+		if errInRetrieveResourceUsage != nil {
+			return errInRetrieveResourceUsage
+		}
+		if resourceUsage.IsInUse() && !force {
+			return errResourceInUse
+		}
+		if resourceUsage.IsInUse() && repointToResourceID != 0 {
+			resourceUsage.RepointResource(repointToResourceID, userID)
+			if errInRepoint := saveRepointedResourceUsage(txStorage, resourceUsage); errInRepoint != nil {
+				return errInRepoint
+			}
+		}
+		return txStorage.ResourceStorage.Delete(teamID, resourceID)
+	})
+	if errors.Is(errInDeleteResource, errResourceInUse) {
+		apps, errInRetrieveApps := controller.Storage.AppStorage.RetrieveByIDs(resourceUsage.ExportAppIDs())
+		if errInRetrieveApps != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_APP, "get apps error: "+errInRetrieveApps.Error())
+			return
+		}
+		controller.FeedbackBadRequestWithData(c, ERROR_FLAG_CAN_NOT_DELETE_RESOURCE_IN_USE, "resource is still used by actions, delete it with force or re-point the actions to another resource.", model.NewResourceUsageForExport(resourceUsage, apps))
+		return
+	}
+	if errInDeleteResource != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_RESOURCE, "delete resources error: "+errInDeleteResource.Error())
+		return
+	}
+
 	// audit log
 	auditLogger := auditlogger.GetInstance()
 	auditLogger.Log(&auditlogger.LogInfo{
@@ -287,12 +358,8 @@ func (controller *Controller) DeleteResource(c *gin.Context) {
 		ResourceType: resource.ExportTypeInString(),
 	})
 
-	// delete
-	errInDeleteResource := controller.Storage.ResourceStorage.Delete(teamID, resourceID)
-	if errInDeleteResource != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_RESOURCE, "delete resources error: "+errInDeleteResource.Error())
-		return
-	}
+	// close the pooled connections of deleted resource
+	common.GetConnectionPoolManager().InvalidateResource(resourceID)
 
 	// feedback
 	controller.FeedbackOK(c, response.NewDeleteResourceResponse(resourceID))
@@ -340,11 +407,34 @@ func (controller *Controller) TestConnection(c *gin.Context) {
 		return
 	}
 
+	// fetch exists resource options for the masked secret fields
+	existsOptions := map[string]interface{}{}
+	if testResourceConnectionRequest.HasResourceID() {
+		existsResource, errInRetrieveResource := controller.Storage.ResourceStorage.RetrieveByTeamIDAndResourceID(teamID, testResourceConnectionRequest.ExportResourceIDInInt())
+		if errInRetrieveResource != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_RESOURCE, "get resources error: "+errInRetrieveResource.Error())
+			return
+		}
+		// the environment not saved yet has no exists options, its masked secret fields never take the options of other environment
+		if existsVariant, errInSwitchEnvironment := existsResource.ExportEnvironmentVariant(testResourceConnectionRequest.ExportEnvironment()); errInSwitchEnvironment == nil {
+			var errInExportOptions error
+			existsOptions, errInExportOptions = existsVariant.ExportOptionsInMap()
+			if errInExportOptions != nil {
+				controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_RESOURCE, "get resource options error: "+errInExportOptions.Error())
+				return
+			}
+		}
+	}
+
 	// new temp resource
-	resource := model.NewResourceByTestResourceConnectionRequest(teamID, userID, testResourceConnectionRequest)
+	resource, errInNewResource := model.NewResourceByTestResourceConnectionRequest(teamID, userID, testResourceConnectionRequest, existsOptions)
+	if errInNewResource != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_TEST_RESOURCE_CONNECTION, "test resource connection error: "+errInNewResource.Error())
+		return
+	}
 
 	// test connection
-	errInTestConnection := controller.TestResourceConnection(c, resource)
+	errInTestConnection := controller.TestResourceConnection(c, resource, existsOptions)
 	if errInTestConnection != nil {
 		return
 	}
@@ -388,7 +478,52 @@ func (controller *Controller) GetMetaInfo(c *gin.Context) {
 	}
 
 	// fetch meta info
-	resourceMetaInfo, errInGetMetaInfo := controller.GetResourceMetaInfo(c, resource)
+	resourceMetaInfo, errInGetMetaInfo := controller.GetResourceMetaInfo(c, resource, false)
+	if errInGetMetaInfo != nil {
+		return
+	}
+
+	// feedback
+	c.JSON(http.StatusOK, resourceMetaInfo)
+	return
+}
+
+// RefreshMetaInfo fetch the meta info from data source and overwrite the cached one, for the schema changed in data source.
+func (controller *Controller) RefreshMetaInfo(c *gin.Context) {
+	// fetch needed param
+	teamID, errInGetTeamID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_ID)
+	resourceID, errInGetResourceID := controller.GetMagicIntParamFromRequest(c, PARAM_RESOURCE_ID)
+	userAuthToken, errInGetAuthToken := controller.GetUserAuthTokenFromHeader(c)
+	if errInGetTeamID != nil || errInGetResourceID != nil || errInGetAuthToken != nil {
+		return
+	}
+
+	// validate
+	canAccess, errInCheckAttr := controller.AttributeGroup.CanAccess(
+		teamID,
+		userAuthToken,
+		accesscontrol.UNIT_TYPE_RESOURCE,
+		accesscontrol.DEFAULT_UNIT_ID,
+		accesscontrol.ACTION_ACCESS_VIEW,
+	)
+	if errInCheckAttr != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "error in check attribute: "+errInCheckAttr.Error())
+		return
+	}
+	if !canAccess {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// get resource
+	resource, errInRetrieveResource := controller.Storage.ResourceStorage.RetrieveByTeamIDAndResourceID(teamID, resourceID)
+	if errInRetrieveResource != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_RESOURCE, "get resources error: "+errInRetrieveResource.Error())
+		return
+	}
+
+	// fetch meta info
+	resourceMetaInfo, errInGetMetaInfo := controller.GetResourceMetaInfo(c, resource, true)
 	if errInGetMetaInfo != nil {
 		return
 	}
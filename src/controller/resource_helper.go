@@ -1,11 +1,14 @@
 package controller
 
 import (
+	"context"
 	"errors"
+	"log"
 
 	"github.com/gin-gonic/gin"
 	"github.com/illacloud/builder-backend/src/actionruntime/common"
 	"github.com/illacloud/builder-backend/src/model"
+	"github.com/illacloud/builder-backend/src/utils/config"
 	"github.com/illacloud/builder-backend/src/utils/resourcelist"
 )
 
@@ -23,15 +26,35 @@ func (controller *Controller) ValidateResourceConternt(c *gin.Context, resource
 	}
 
 	// check template
-	_, errInValidate := resourceAssemblyLine.ValidateResourceOptions(resource.ExportOptionsInMap())
+	resourceOptions, errInExportOptions := resource.ExportOptionsInMap()
+	if errInExportOptions != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "get resource options error: "+errInExportOptions.Error())
+		return errInExportOptions
+	}
+	_, errInValidate := resourceAssemblyLine.ValidateResourceOptions(resourceOptions)
 	if errInValidate != nil {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate resource option error: "+errInValidate.Error())
 		return errInValidate
 	}
+
+	// check environment variants
+	environments, errInExportEnvironments := resource.ExportEnvironmentsInMap()
+	if errInExportEnvironments != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "get resource environments error: "+errInExportEnvironments.Error())
+		return errInExportEnvironments
+	}
+	for environment, options := range environments {
+		_, errInValidate := resourceAssemblyLine.ValidateResourceOptions(options)
+		if errInValidate != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate resource option of environment "+environment+" error: "+errInValidate.Error())
+			return errInValidate
+		}
+	}
 	return nil
 }
 
-func (controller *Controller) TestResourceConnection(c *gin.Context, resource *model.Resource) error {
+// TestResourceConnection test the resource built from request, the persisted options of the resource under editing authorize the secret placeholders.
+func (controller *Controller) TestResourceConnection(c *gin.Context, resource *model.Resource, persistedOptions map[string]interface{}) error {
 	if resourcelist.IsVirtualResourceHaveNoOption(resource.ExportType()) {
 		return nil
 	}
@@ -44,15 +67,27 @@ func (controller *Controller) TestResourceConnection(c *gin.Context, resource *m
 		return errInBuild
 	}
 
+	// resolve the team variables referenced by resource options
+	resource, errInResolveTeamVariables := model.ResolveTeamVariablesForTestConnection(resource, persistedOptions, controller.newTeamVariablesRetriever(resource.TeamID))
+	if errInResolveTeamVariables != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "resolve team variables error: "+errInResolveTeamVariables.Error())
+		return errInResolveTeamVariables
+	}
+
 	// check template
-	_, errInValidate := resourceAssemblyLine.ValidateResourceOptions(resource.ExportOptionsInMap())
+	resourceOptions, errInExportOptions := resource.ExportOptionsInMap()
+	if errInExportOptions != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "get resource options error: "+errInExportOptions.Error())
+		return errInExportOptions
+	}
+	_, errInValidate := resourceAssemblyLine.ValidateResourceOptions(resourceOptions)
 	if errInValidate != nil {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate resource option error: "+errInValidate.Error())
 		return errInValidate
 	}
 
 	// test connection
-	resourceConnection, errInTestConnection := resourceAssemblyLine.TestConnection(resource.ExportOptionsInMap())
+	resourceConnection, errInTestConnection := resourceAssemblyLine.TestConnection(c.Request.Context(), resourceOptions)
 	if errInTestConnection != nil {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_TEST_RESOURCE_CONNECTION, "test resource connection error: "+errInTestConnection.Error())
 		return errInTestConnection
@@ -65,7 +100,16 @@ func (controller *Controller) TestResourceConnection(c *gin.Context, resource *m
 	return nil
 }
 
-func (controller *Controller) GetResourceMetaInfo(c *gin.Context, resource *model.Resource) (*common.MetaInfoResult, error) {
+const (
+	RESOURCE_META_CACHE_HEADER         = "Illa-Meta-Cache"
+	RESOURCE_META_CACHE_STATUS_HIT     = "HIT"
+	RESOURCE_META_CACHE_STATUS_MISS    = "MISS"
+	RESOURCE_META_CACHE_STATUS_REFRESH = "REFRESH"
+	RESOURCE_META_CACHE_STATUS_BYPASS  = "BYPASS"
+)
+
+// GetResourceMetaInfo feedback the error and return it when failed, the cached meta info is skipped and overwritten when refresh.
+func (controller *Controller) GetResourceMetaInfo(c *gin.Context, resource *model.Resource, refresh bool) (*common.MetaInfoResult, error) {
 	if resourcelist.IsVirtualResourceHaveNoOption(resource.ExportType()) {
 		return nil, nil
 	}
@@ -78,12 +122,58 @@ func (controller *Controller) GetResourceMetaInfo(c *gin.Context, resource *mode
 		return nil, errInBuild
 	}
 
+	// resolve the team variables referenced by resource options
+	resource, errInResolveTeamVariables := controller.resolveTeamVariables(resource.TeamID, resource, false)
+	if errInResolveTeamVariables != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "resolve team variables error: "+errInResolveTeamVariables.Error())
+		return nil, errInResolveTeamVariables
+	}
+
 	// check template
-	resourceMetaInfo, errInGetMetaInfo := resourceAssemblyLine.GetMetaInfo(resource.ExportOptionsInMap())
+	resourceMetaInfo, cacheStatus, errInGetMetaInfo := controller.fetchResourceMetaInfo(c.Request.Context(), resource, resourceAssemblyLine, refresh)
+	c.Header(RESOURCE_META_CACHE_HEADER, cacheStatus)
 	if errInGetMetaInfo != nil {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "get resource meta info error: "+errInGetMetaInfo.Error())
 		return nil, errInGetMetaInfo
 	}
 
-	return &resourceMetaInfo, nil
+	return resourceMetaInfo, nil
+}
+
+// fetchResourceMetaInfo return the meta info of the resolved resource and the cache status,
+// the meta info is served from cache unless refresh, and the fetched meta info is cached.
+func (controller *Controller) fetchResourceMetaInfo(ctx context.Context, resource *model.Resource, connector common.DataConnector, refresh bool) (*common.MetaInfoResult, string, error) {
+	resourceOptions, errInExportOptions := resource.ExportOptionsInMap()
+	if errInExportOptions != nil {
+		return nil, RESOURCE_META_CACHE_STATUS_BYPASS, errInExportOptions
+	}
+	ttl := config.GetInstance().GetResourceMetaCacheTTL()
+	cacheStatus := RESOURCE_META_CACHE_STATUS_BYPASS
+	cacheKey := ""
+	if controller.Cache != nil && ttl > 0 {
+		cacheKey = model.NewResourceMetaCacheKey(resource.TeamID, resource.ExportID(), resource.ExportType(), resourceOptions)
+		cacheStatus = RESOURCE_META_CACHE_STATUS_REFRESH
+	}
+	if cacheKey != "" && !refresh {
+		cachedMetaInfo, hit, errInGetMetaInfo := controller.Cache.ResourceMetaCache.GetMetaInfo(cacheKey)
+		if errInGetMetaInfo != nil {
+			log.Printf("[ERROR] get resource meta cache failed: %s\n", errInGetMetaInfo.Error())
+		}
+		if hit {
+			return cachedMetaInfo, RESOURCE_META_CACHE_STATUS_HIT, nil
+		}
+		cacheStatus = RESOURCE_META_CACHE_STATUS_MISS
+	}
+
+	// fetch from data source
+	resourceMetaInfo, errInGetMetaInfo := connector.GetMetaInfo(common.ContextWithResourceID(ctx, resource.ExportID()), resourceOptions)
+	if errInGetMetaInfo != nil {
+		return nil, cacheStatus, errInGetMetaInfo
+	}
+	if cacheKey != "" && resourceMetaInfo.Success {
+		if errInSetMetaInfo := controller.Cache.ResourceMetaCache.SetMetaInfo(cacheKey, &resourceMetaInfo, ttl); errInSetMetaInfo != nil {
+			log.Printf("[ERROR] set resource meta cache failed: %s\n", errInSetMetaInfo.Error())
+		}
+	}
+	return &resourceMetaInfo, cacheStatus, nil
 }
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/illacloud/builder-backend/src/model"
+	"github.com/illacloud/builder-backend/src/response"
+	"github.com/illacloud/builder-backend/src/storage"
+	"github.com/illacloud/builder-backend/src/utils/accesscontrol"
+	"github.com/illacloud/builder-backend/src/utils/resourcelist"
+)
+
+func (controller *Controller) GetResourceUsages(c *gin.Context) {
+	// fetch needed param
+	teamID, errInGetTeamID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_ID)
+	resourceID, errInGetResourceID := controller.GetMagicIntParamFromRequest(c, PARAM_RESOURCE_ID)
+	userAuthToken, errInGetAuthToken := controller.GetUserAuthTokenFromHeader(c)
+	if errInGetTeamID != nil || errInGetResourceID != nil || errInGetAuthToken != nil {
+		return
+	}
+
+	// validate
+	canAccess, errInCheckAttr := controller.AttributeGroup.CanAccess(
+		teamID,
+		userAuthToken,
+		accesscontrol.UNIT_TYPE_RESOURCE,
+		resourceID,
+		accesscontrol.ACTION_ACCESS_VIEW,
+	)
+	if errInCheckAttr != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "error in check attribute: "+errInCheckAttr.Error())
+		return
+	}
+	if !canAccess {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// get resource
+	_, errInRetrieveResource := controller.Storage.ResourceStorage.RetrieveByTeamIDAndResourceID(teamID, resourceID)
+	if errInRetrieveResource != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_RESOURCE, "get resources error: "+errInRetrieveResource.Error())
+		return
+	}
+
+	// collect usage
+	resourceUsage, errInRetrieveResourceUsage := retrieveResourceUsage(controller.Storage, teamID, resourceID)
+	if errInRetrieveResourceUsage != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_RESOURCE, "get resource usages error: "+errInRetrieveResourceUsage.Error())
+		return
+	}
+	apps, errInRetrieveApps := controller.Storage.AppStorage.RetrieveByIDs(resourceUsage.ExportAppIDs())
+	if errInRetrieveApps != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_APP, "get apps error: "+errInRetrieveApps.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, response.NewGetResourceUsagesResponse(resourceUsage, apps))
+	return
+}
+
+var errResourceInUse = errors.New("resource is still used by actions")
+
+// retrieveResourceUsage collect the actions of all app versions and the flow actions of all workflow versions referencing the resource.
+// pass the transaction storage to collect the usage in transaction.
+func retrieveResourceUsage(fromStorage *storage.Storage, teamID int, resourceID int) (*model.ResourceUsage, error) {
+	actions, errInRetrieveActions := fromStorage.ActionStorage.RetrieveActionsByTeamIDAndResourceID(teamID, resourceID)
+	if errInRetrieveActions != nil {
+		return nil, errInRetrieveActions
+	}
+	flowActions, errInRetrieveFlowActions := fromStorage.FlowActionStorage.RetrieveFlowActionsByTeamIDAndResourceID(teamID, resourceID)
+	if errInRetrieveFlowActions != nil {
+		return nil, errInRetrieveFlowActions
+	}
+	return model.NewResourceUsage(resourceID, actions, flowActions), nil
+}
+
+// lockActionResource take the shared lock of the resource used by action in transaction, see ResourceStorage.LockByTeamIDAndResourceID.
+// the virtual resources have no resource row to lock.
+func lockActionResource(txStorage *storage.Storage, teamID int, actionType int, resourceID int) error {
+	if resourceID == 0 || resourcelist.IsVirtualResourceByIntType(actionType) {
+		return nil
+	}
+	if errInLock := txStorage.ResourceStorage.LockByTeamIDAndResourceID(teamID, resourceID, false); errInLock != nil {
+		return errors.New("get action resource error: " + errInLock.Error())
+	}
+	return nil
+}
+
+// saveRepointedResourceUsage save the actions and flow actions re-pointed to another resource.
+func saveRepointedResourceUsage(txStorage *storage.Storage, resourceUsage *model.ResourceUsage) error {
+	for _, action := range resourceUsage.Actions {
+		if errInUpdateAction := txStorage.ActionStorage.UpdateWholeAction(action); errInUpdateAction != nil {
+			return errInUpdateAction
+		}
+	}
+	for _, flowAction := range resourceUsage.FlowActions {
+		if errInUpdateFlowAction := txStorage.FlowActionStorage.UpdateWholeFlowAction(flowAction); errInUpdateFlowAction != nil {
+			return errInUpdateFlowAction
+		}
+	}
+	return nil
+}
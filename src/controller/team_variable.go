@@ -0,0 +1,249 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/illacloud/builder-backend/src/model"
+	"github.com/illacloud/builder-backend/src/request"
+	"github.com/illacloud/builder-backend/src/response"
+	"github.com/illacloud/builder-backend/src/utils/accesscontrol"
+)
+
+func (controller *Controller) GetAllTeamVariables(c *gin.Context) {
+	// fetch needed param
+	teamID, errInGetTeamID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_ID)
+	userAuthToken, errInGetAuthToken := controller.GetUserAuthTokenFromHeader(c)
+	if errInGetTeamID != nil || errInGetAuthToken != nil {
+		return
+	}
+
+	// validate
+	canAccess, errInCheckAttr := controller.AttributeGroup.CanAccess(
+		teamID,
+		userAuthToken,
+		accesscontrol.UNIT_TYPE_RESOURCE,
+		accesscontrol.DEFAULT_UNIT_ID,
+		accesscontrol.ACTION_ACCESS_VIEW,
+	)
+	if errInCheckAttr != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "error in check attribute: "+errInCheckAttr.Error())
+		return
+	}
+	if !canAccess {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// retrieve
+	teamVariables, errInRetrieveTeamVariables := controller.Storage.TeamVariableStorage.RetrieveByTeamID(teamID)
+	if errInRetrieveTeamVariables != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_VARIABLE, "get team variables error: "+errInRetrieveTeamVariables.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, response.NewGetTeamVariableListResponse(teamVariables))
+	return
+}
+
+func (controller *Controller) CreateTeamVariable(c *gin.Context) {
+	// fetch needed param
+	teamID, errInGetTeamID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_ID)
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	userAuthToken, errInGetAuthToken := controller.GetUserAuthTokenFromHeader(c)
+	if errInGetTeamID != nil || errInGetUserID != nil || errInGetAuthToken != nil {
+		return
+	}
+
+	// validate
+	canManage, errInCheckAttr := controller.AttributeGroup.CanManage(
+		teamID,
+		userAuthToken,
+		accesscontrol.UNIT_TYPE_RESOURCE,
+		accesscontrol.DEFAULT_UNIT_ID,
+		accesscontrol.ACTION_MANAGE_CREATE_RESOURCE,
+	)
+	if errInCheckAttr != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "error in check attribute: "+errInCheckAttr.Error())
+		return
+	}
+	if !canManage {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// parse request body
+	req := request.NewCreateTeamVariableRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate request body
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// create
+	teamVariable, errInNewTeamVariable := model.NewTeamVariableByCreateTeamVariableRequest(teamID, userID, req)
+	if errInNewTeamVariable != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate team variable error: "+errInNewTeamVariable.Error())
+		return
+	}
+	if errInValidateName := controller.validateTeamVariableNameUnique(teamVariable); errInValidateName != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate team variable error: "+errInValidateName.Error())
+		return
+	}
+	_, errInCreateTeamVariable := controller.Storage.TeamVariableStorage.Create(teamVariable)
+	if errInCreateTeamVariable != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_TEAM_VARIABLE, "create team variable error: "+errInCreateTeamVariable.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewTeamVariableForExport(teamVariable))
+	return
+}
+
+func (controller *Controller) UpdateTeamVariable(c *gin.Context) {
+	// fetch needed param
+	teamID, errInGetTeamID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_ID)
+	teamVariableID, errInGetTeamVariableID := controller.GetMagicIntParamFromRequest(c, PARAM_VARIABLE_ID)
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	userAuthToken, errInGetAuthToken := controller.GetUserAuthTokenFromHeader(c)
+	if errInGetTeamID != nil || errInGetTeamVariableID != nil || errInGetUserID != nil || errInGetAuthToken != nil {
+		return
+	}
+
+	// validate
+	canManage, errInCheckAttr := controller.AttributeGroup.CanManage(
+		teamID,
+		userAuthToken,
+		accesscontrol.UNIT_TYPE_RESOURCE,
+		accesscontrol.DEFAULT_UNIT_ID,
+		accesscontrol.ACTION_MANAGE_EDIT_RESOURCE,
+	)
+	if errInCheckAttr != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "error in check attribute: "+errInCheckAttr.Error())
+		return
+	}
+	if !canManage {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// parse request body
+	req := request.NewUpdateTeamVariableRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate request body
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// fetch team variable
+	teamVariable, errInRetrieveTeamVariable := controller.Storage.TeamVariableStorage.RetrieveByTeamIDAndID(teamID, teamVariableID)
+	if errInRetrieveTeamVariable != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_VARIABLE, "get team variable error: "+errInRetrieveTeamVariable.Error())
+		return
+	}
+
+	// update
+	if errInUpdateByRequest := teamVariable.UpdateByUpdateTeamVariableRequest(userID, req); errInUpdateByRequest != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate team variable error: "+errInUpdateByRequest.Error())
+		return
+	}
+	if errInValidateName := controller.validateTeamVariableNameUnique(teamVariable); errInValidateName != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate team variable error: "+errInValidateName.Error())
+		return
+	}
+	errInUpdateTeamVariable := controller.Storage.TeamVariableStorage.UpdateWholeTeamVariable(teamVariable)
+	if errInUpdateTeamVariable != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM_VARIABLE, "update team variable error: "+errInUpdateTeamVariable.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewTeamVariableForExport(teamVariable))
+	return
+}
+
+func (controller *Controller) DeleteTeamVariable(c *gin.Context) {
+	// fetch needed param
+	teamID, errInGetTeamID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_ID)
+	teamVariableID, errInGetTeamVariableID := controller.GetMagicIntParamFromRequest(c, PARAM_VARIABLE_ID)
+	userAuthToken, errInGetAuthToken := controller.GetUserAuthTokenFromHeader(c)
+	if errInGetTeamID != nil || errInGetTeamVariableID != nil || errInGetAuthToken != nil {
+		return
+	}
+
+	// validate
+	canManage, errInCheckAttr := controller.AttributeGroup.CanManage(
+		teamID,
+		userAuthToken,
+		accesscontrol.UNIT_TYPE_RESOURCE,
+		accesscontrol.DEFAULT_UNIT_ID,
+		accesscontrol.ACTION_MANAGE_EDIT_RESOURCE,
+	)
+	if errInCheckAttr != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "error in check attribute: "+errInCheckAttr.Error())
+		return
+	}
+	if !canManage {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// fetch team variable
+	_, errInRetrieveTeamVariable := controller.Storage.TeamVariableStorage.RetrieveByTeamIDAndID(teamID, teamVariableID)
+	if errInRetrieveTeamVariable != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_VARIABLE, "get team variable error: "+errInRetrieveTeamVariable.Error())
+		return
+	}
+
+	// delete
+	errInDelete := controller.Storage.TeamVariableStorage.DeleteByTeamIDAndID(teamID, teamVariableID)
+	if errInDelete != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_TEAM_VARIABLE, "delete team variable error: "+errInDelete.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, response.NewDeleteTeamVariableResponse(teamVariableID))
+	return
+}
+
+// validateTeamVariableNameUnique check no other variable of team has the same placeholder.
+func (controller *Controller) validateTeamVariableNameUnique(teamVariable *model.TeamVariable) error {
+	count, errInCount := controller.Storage.TeamVariableStorage.CountByTeamIDNameAndKind(teamVariable.TeamID, teamVariable.Name, teamVariable.IsSecret, teamVariable.ID)
+	if errInCount != nil {
+		return errInCount
+	}
+	if count > 0 {
+		return errors.New(teamVariable.ExportPlaceholder() + " already exists")
+	}
+	return nil
+}
+
+// resolveTeamVariables resolve the team variable placeholders in resource options and run templates right before running.
+// the placeholders in run templates supplied by client are resolved only when the persisted templates reference them,
+// and with onlyPersisted (like anonymous run) all placeholders including the variables need the reference.
+func (controller *Controller) resolveTeamVariables(teamID int, resource *model.Resource, onlyPersisted bool, runTemplates ...*model.TeamVariableRunTemplate) (*model.Resource, error) {
+	return model.ResolveTeamVariablesForRun(resource, controller.newTeamVariablesRetriever(teamID), onlyPersisted, runTemplates...)
+}
+
+func (controller *Controller) newTeamVariablesRetriever(teamID int) func() ([]*model.TeamVariable, error) {
+	return func() ([]*model.TeamVariable, error) {
+		return controller.Storage.TeamVariableStorage.RetrieveByTeamID(teamID)
+	}
+}
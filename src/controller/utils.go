@@ -32,6 +32,8 @@ const (
 	PARAM_PAGE_LIMIT       = "pageLimit"
 	PARAM_PAGE             = "page"
 	PARAM_SNAPSHOT_ID      = "snapshotID"
+	PARAM_SCHEDULE_ID      = "scheduleID"
+	PARAM_VARIABLE_ID      = "variableID"
 	PARAM_STATE            = "state"
 	PARAM_CODE             = "code"
 	PARAM_ERROR            = "error"
@@ -51,6 +53,16 @@ const (
 	PARAM_FROM_VERSION     = "fromVersion"
 	PARAM_TO_VERSION       = "toVersion"
 	PARAM_IS_FORK_WORKFLOW = "isForkWorkflow"
+	PARAM_WEBHOOK_ID       = "webhookID"
+	PARAM_STATUS           = "status"
+	PARAM_SOURCE           = "source"
+	PARAM_KIND             = "kind"
+	PARAM_FROM             = "from"
+	PARAM_TO               = "to"
+	PARAM_STREAM           = "stream"
+	PARAM_PREVIEW          = "preview"
+	PARAM_FORCE            = "force"
+	PARAM_REPOINT_TO       = "repointTo"
 )
 
 const (
@@ -83,6 +95,8 @@ const (
 	ERROR_FLAG_CAN_NOT_CREATE_APP             = "ERROR_FLAG_CAN_NOT_CREATE_APP"
 	ERROR_FLAG_CAN_NOT_CREATE_STATE           = "ERROR_FLAG_CAN_NOT_CREATE_STATE"
 	ERROR_FLAG_CAN_NOT_CREATE_SNAPSHOT        = "ERROR_FLAG_CAN_NOT_CREATE_SNAPSHOT"
+	ERROR_FLAG_CAN_NOT_CREATE_ACTION_SCHEDULE = "ERROR_FLAG_CAN_NOT_CREATE_ACTION_SCHEDULE"
+	ERROR_FLAG_CAN_NOT_CREATE_TEAM_VARIABLE   = "ERROR_FLAG_CAN_NOT_CREATE_TEAM_VARIABLE"
 	ERROR_FLAG_CAN_NOT_CREATE_COMPONENT_TREE  = "ERROR_FLAG_CAN_NOT_CREATE_COMPONENT_TREE"
 
 	// can not get resource
@@ -99,6 +113,9 @@ const (
 	ERROR_FLAG_CAN_NOT_GET_BUILDER_DESCRIPTION = "ERROR_FLAG_CAN_NOT_GET_BUILDER_DESCRIPTION"
 	ERROR_FLAG_CAN_NOT_GET_STATE               = "ERROR_FLAG_CAN_NOT_GET_STATE"
 	ERROR_FLAG_CAN_NOT_GET_SNAPSHOT            = "ERROR_FLAG_CAN_NOT_GET_SNAPSHOT"
+	ERROR_FLAG_CAN_NOT_GET_ACTION_SCHEDULE     = "ERROR_FLAG_CAN_NOT_GET_ACTION_SCHEDULE"
+	ERROR_FLAG_CAN_NOT_GET_ACTION_RUN_LOG      = "ERROR_FLAG_CAN_NOT_GET_ACTION_RUN_LOG"
+	ERROR_FLAG_CAN_NOT_GET_TEAM_VARIABLE       = "ERROR_FLAG_CAN_NOT_GET_TEAM_VARIABLE"
 
 	// can not update resource
 	ERROR_FLAG_CAN_NOT_UPDATE_USER            = "ERROR_FLAG_CAN_NOT_UPDATE_USER"
@@ -112,6 +129,8 @@ const (
 	ERROR_FLAG_CAN_NOT_UPDATE_APP             = "ERROR_FLAG_CAN_NOT_UPDATE_APP"
 	ERROR_FLAG_CAN_NOT_UPDATE_TREE_STATE      = "ERROR_FLAG_CAN_NOT_UPDATE_TREE_STATE"
 	ERROR_FLAG_CAN_NOT_UPDATE_SNAPSHOT        = "ERROR_FLAG_CAN_NOT_UPDATE_SNAPSHOT"
+	ERROR_FLAG_CAN_NOT_UPDATE_ACTION_SCHEDULE = "ERROR_FLAG_CAN_NOT_UPDATE_ACTION_SCHEDULE"
+	ERROR_FLAG_CAN_NOT_UPDATE_TEAM_VARIABLE   = "ERROR_FLAG_CAN_NOT_UPDATE_TEAM_VARIABLE"
 
 	// can not delete
 	ERROR_FLAG_CAN_NOT_DELETE_USER            = "ERROR_FLAG_CAN_NOT_DELETE_USER"
@@ -122,12 +141,17 @@ const (
 	ERROR_FLAG_CAN_NOT_DELETE_DOMAIN          = "ERROR_FLAG_CAN_NOT_DELETE_DOMAIN"
 	ERROR_FLAG_CAN_NOT_DELETE_ACTION          = "ERROR_FLAG_CAN_NOT_DELETE_ACTION"
 	ERROR_FLAG_CAN_NOT_DELETE_RESOURCE        = "ERROR_FLAG_CAN_NOT_DELETE_RESOURCE"
+	ERROR_FLAG_CAN_NOT_DELETE_RESOURCE_IN_USE = "ERROR_FLAG_CAN_NOT_DELETE_RESOURCE_IN_USE"
 	ERROR_FLAG_CAN_NOT_DELETE_APP             = "ERROR_FLAG_CAN_NOT_DELETE_APP"
+	ERROR_FLAG_CAN_NOT_DELETE_ACTION_SCHEDULE = "ERROR_FLAG_CAN_NOT_DELETE_ACTION_SCHEDULE"
+	ERROR_FLAG_CAN_NOT_DELETE_TEAM_VARIABLE   = "ERROR_FLAG_CAN_NOT_DELETE_TEAM_VARIABLE"
 
 	// can not other operation
 	ERROR_FLAG_CAN_NOT_CHECK_TEAM_MEMBER        = "ERROR_FLAG_CAN_NOT_CHECK_TEAM_MEMBER"
 	ERROR_FLAG_CAN_NOT_DUPLICATE_APP            = "ERROR_FLAG_CAN_NOT_DUPLICATE_APP"
 	ERROR_FLAG_CAN_NOT_RELEASE_APP              = "ERROR_FLAG_CAN_NOT_RELEASE_APP"
+	ERROR_FLAG_CAN_NOT_EXPORT_APP               = "ERROR_FLAG_CAN_NOT_EXPORT_APP"
+	ERROR_FLAG_CAN_NOT_IMPORT_APP               = "ERROR_FLAG_CAN_NOT_IMPORT_APP"
 	ERROR_FLAG_CAN_NOT_TEST_RESOURCE_CONNECTION = "ERROR_FLAG_CAN_NOT_TEST_RESOURCE_CONNECTION"
 
 	// permission failed
@@ -404,6 +428,17 @@ func (controller *Controller) FeedbackBadRequest(c *gin.Context, errorFlag strin
 	return
 }
 
+// FeedbackBadRequestWithData feedback bad request with the error detail in errorData.
+func (controller *Controller) FeedbackBadRequestWithData(c *gin.Context, errorFlag string, errorMessage string, errorData interface{}) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"errorCode":    400,
+		"errorFlag":    errorFlag,
+		"errorMessage": errorMessage,
+		"errorData":    errorData,
+	})
+	return
+}
+
 func (controller *Controller) FeedbackRedirect(c *gin.Context, uri string) {
 	c.Redirect(302, uri)
 	return
@@ -0,0 +1,325 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+	"github.com/illacloud/builder-backend/src/actionruntime/serversidetransformer"
+	"github.com/illacloud/builder-backend/src/actionruntime/trigger"
+	"github.com/illacloud/builder-backend/src/model"
+	"github.com/illacloud/builder-backend/src/response"
+	"github.com/illacloud/builder-backend/src/utils/accesscontrol"
+	"github.com/illacloud/builder-backend/src/utils/config"
+	"github.com/illacloud/builder-backend/src/utils/idconvertor"
+)
+
+const WEBHOOK_ROUTE_PREFIX = "/api/v1/webhooks/"
+
+// GetFlowActionWebhook export the inbound webhook URL and signing secret of trigger flow action,
+// the webhook and its random secret are created at the first time.
+func (controller *Controller) GetFlowActionWebhook(c *gin.Context) {
+	controller.feedbackFlowActionWebhook(c, false)
+}
+
+// RotateFlowActionWebhookSecret replace the signing secret of trigger flow action webhook, the old secret stops working at once.
+func (controller *Controller) RotateFlowActionWebhookSecret(c *gin.Context) {
+	controller.feedbackFlowActionWebhook(c, true)
+}
+
+func (controller *Controller) feedbackFlowActionWebhook(c *gin.Context, rotateSecret bool) {
+	// fetch needed param
+	teamID, errInGetTeamID := controller.GetMagicIntParamFromRequest(c, PARAM_TEAM_ID)
+	flowActionID, errInGetActionID := controller.GetMagicIntParamFromRequest(c, PARAM_FLOW_ACTION_ID)
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	userAuthToken, errInGetAuthToken := controller.GetUserAuthTokenFromHeader(c)
+	if errInGetTeamID != nil || errInGetActionID != nil || errInGetUserID != nil || errInGetAuthToken != nil {
+		return
+	}
+
+	// validate, the secret can only be viewed by the users who can edit it
+	canManage, errInCheckAttr := controller.AttributeGroup.CanManage(
+		teamID,
+		userAuthToken,
+		accesscontrol.UNIT_TYPE_FLOW_ACTION,
+		flowActionID,
+		accesscontrol.ACTION_MANAGE_EDIT_FLOW_ACTION,
+	)
+	if errInCheckAttr != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "error in check attribute: "+errInCheckAttr.Error())
+		return
+	}
+	if !canManage {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// fetch data
+	flowAction, errInGetAction := controller.Storage.FlowActionStorage.RetrieveFlowActionByTeamIDAndID(teamID, flowActionID)
+	if errInGetAction != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_FLOW_ACTION, "get flowAction error: "+errInGetAction.Error())
+		return
+	}
+	if !flowAction.IsTriggerFlowAction() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_FLOW_ACTION, "flowAction is not a trigger")
+		return
+	}
+	triggerTemplate, errInExportTemplate := trigger.NewTriggerTemplateByActionOptions(flowAction.ExportTemplateInMap())
+	if errInExportTemplate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_FLOW_ACTION, "get trigger template error: "+errInExportTemplate.Error())
+		return
+	}
+
+	// get or create webhook
+	newWebhook, errInNewWebhook := model.NewFlowActionWebhookByTriggerFlowAction(flowAction, userID)
+	if errInNewWebhook != nil {
+		controller.FeedbackInternalServerError(c, ERROR_FLAG_CAN_NOT_GET_FLOW_ACTION, "generate webhook secret error: "+errInNewWebhook.Error())
+		return
+	}
+	webhook, errInCreateWebhook := controller.Storage.FlowActionWebhookStorage.CreateOrRetrieve(newWebhook)
+	if errInCreateWebhook != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_FLOW_ACTION, "get webhook error: "+errInCreateWebhook.Error())
+		return
+	}
+	if rotateSecret {
+		if errInRotate := webhook.RotateSecret(userID); errInRotate != nil {
+			controller.FeedbackInternalServerError(c, ERROR_FLAG_CAN_NOT_UPDATE_FLOW_ACTION, "generate webhook secret error: "+errInRotate.Error())
+			return
+		}
+		if errInUpdateWebhook := controller.Storage.FlowActionWebhookStorage.UpdateWholeFlowActionWebhook(webhook); errInUpdateWebhook != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_FLOW_ACTION, "update webhook error: "+errInUpdateWebhook.Error())
+			return
+		}
+	}
+	secret, errInExportSecret := webhook.ExportSecret()
+	if errInExportSecret != nil {
+		controller.FeedbackInternalServerError(c, ERROR_FLAG_CAN_NOT_GET_FLOW_ACTION, "decrypt webhook secret error: "+errInExportSecret.Error())
+		return
+	}
+
+	// feedback
+	webhookURL := exportRequestBaseURL(c) + WEBHOOK_ROUTE_PREFIX + webhook.UID.String()
+	controller.FeedbackOK(c, response.NewGetFlowActionWebhookResponse(flowActionID, webhookURL, secret, triggerTemplate))
+	return
+}
+
+// TriggerWebhook receive the inbound webhook request, validate the signature,
+// and run the workflow flow actions in background with the request payload as context.
+// the webhook URL is stable across the workflow versions, the released version runs when the workflow has been released.
+func (controller *Controller) TriggerWebhook(c *gin.Context) {
+	// fetch needed param
+	webhookID, errInGetWebhookID := controller.GetStringParamFromRequest(c, PARAM_WEBHOOK_ID)
+	if errInGetWebhookID != nil {
+		return
+	}
+
+	// fetch webhook, and the flow actions of the version to run, it is the released version if any
+	webhook, errInRetrieveWebhook := controller.Storage.FlowActionWebhookStorage.RetrieveByUID(webhookID)
+	if errInRetrieveWebhook != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_FLOW_ACTION, "webhook not found")
+		return
+	}
+	version, errInRetrieveVersion := controller.Storage.FlowActionStorage.RetrieveLatestVersionByTeamIDAndWorkflowID(webhook.TeamID, webhook.WorkflowID)
+	if errInRetrieveVersion != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_FLOW_ACTION, "get workflow version error: "+errInRetrieveVersion.Error())
+		return
+	}
+	flowActions, errInRetrieveFlowActions := controller.Storage.FlowActionStorage.RetrieveFlowActionsByTeamIDWorkflowIDAndVersion(webhook.TeamID, webhook.WorkflowID, version)
+	if errInRetrieveFlowActions != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_FLOW_ACTION, "get flowActions error: "+errInRetrieveFlowActions.Error())
+		return
+	}
+	var triggerFlowAction *model.FlowAction
+	flowActionsInWorkflow := make([]*model.FlowAction, 0, len(flowActions))
+	for _, flowAction := range flowActions {
+		if !flowAction.IsTriggerFlowAction() {
+			flowActionsInWorkflow = append(flowActionsInWorkflow, flowAction)
+			continue
+		}
+		if flowAction.ExportDisplayName() == webhook.TriggerName {
+			triggerFlowAction = flowAction
+		}
+	}
+	if triggerFlowAction == nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_FLOW_ACTION, "webhook trigger not found in workflow version "+strconv.Itoa(version))
+		return
+	}
+	triggerTemplate, errInExportTemplate := trigger.NewTriggerTemplateByActionOptions(triggerFlowAction.ExportTemplateInMap())
+	if errInExportTemplate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_FLOW_ACTION, "get trigger template error: "+errInExportTemplate.Error())
+		return
+	}
+
+	// read body and validate signature
+	body, errInReadBody := io.ReadAll(io.LimitReader(c.Request.Body, trigger.WEBHOOK_MAX_BODY_SIZE+1))
+	if errInReadBody != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "read request body error: "+errInReadBody.Error())
+		return
+	}
+	if len(body) > trigger.WEBHOOK_MAX_BODY_SIZE {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "request body too large")
+		return
+	}
+	secret, errInExportSecret := webhook.ExportSecret()
+	if errInExportSecret != nil {
+		controller.FeedbackInternalServerError(c, ERROR_FLAG_CAN_NOT_GET_FLOW_ACTION, "decrypt webhook secret error: "+errInExportSecret.Error())
+		return
+	}
+	errInVerify := trigger.VerifyWebhookSignature(secret, c.GetHeader(trigger.WEBHOOK_TIMESTAMP_HEADER), c.GetHeader(trigger.WEBHOOK_SIGNATURE_HEADER), body, time.Now())
+	if errInVerify != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "validate webhook signature error: "+errInVerify.Error())
+		return
+	}
+
+	// build payload
+	payload, errInNewPayload := trigger.NewWebhookPayload(c.Request, body, triggerTemplate)
+	if errInNewPayload != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate webhook request error: "+errInNewPayload.Error())
+		return
+	}
+
+	// resolve the flow actions following the trigger
+	flowActionsToRun, errInSortFlowActions := model.SortFlowActionsByGraph(triggerFlowAction, flowActionsInWorkflow)
+	if errInSortFlowActions != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_FLOW_ACTION, "resolve workflow graph error: "+errInSortFlowActions.Error())
+		return
+	}
+
+	// reject the replayed delivery, the signature covers the timestamp and body, so it is the nonce of delivery.
+	// it is checked after all validations, so the rejected request can be retried with the same signature
+	if controller.Cache == nil {
+		controller.FeedbackInternalServerError(c, ERROR_FLAG_ACCESS_DENIED, "webhook delivery check is unavailable")
+		return
+	}
+	firstDelivery, errInMarkDelivered := controller.Cache.WebhookDeliveryCache.MarkDelivered(webhookID, c.GetHeader(trigger.WEBHOOK_SIGNATURE_HEADER), trigger.WEBHOOK_DELIVERY_TTL)
+	if errInMarkDelivered != nil {
+		controller.FeedbackInternalServerError(c, ERROR_FLAG_ACCESS_DENIED, "check webhook delivery error: "+errInMarkDelivered.Error())
+		return
+	}
+	if !firstDelivery {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "webhook request already delivered")
+		return
+	}
+
+	// run in background, the webhook caller should not wait for the whole workflow
+	go controller.runWorkflowByWebhook(webhookID, triggerFlowAction, flowActionsToRun, payload)
+
+	// feedback
+	c.JSON(http.StatusAccepted, gin.H{
+		"workflowID":      idconvertor.ConvertIntToString(triggerFlowAction.WorkflowID),
+		"version":         version,
+		"flowActionCount": len(flowActionsToRun),
+	})
+}
+
+// runWorkflowByWebhook run the flow actions in graph order, every flow action result is added to the context of the following ones.
+func (controller *Controller) runWorkflowByWebhook(webhookID string, triggerFlowAction *model.FlowAction, flowActions []*model.FlowAction, payload map[string]interface{}) {
+	// the run is in background, the panic of any flow action must not bring down the server
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("[ERROR] run workflow by webhook %s panic: %v\n%s\n", webhookID, recovered, debug.Stack())
+		}
+	}()
+	runContext := trigger.FlattenWebhookContext(triggerFlowAction.ExportDisplayName(), payload)
+	runContext[trigger.FIELD_WEBHOOK_PAYLOAD] = payload
+	for _, flowAction := range flowActions {
+		result, errInRun := controller.runFlowActionWithContext(flowAction, runContext)
+		if errInRun != nil {
+			log.Printf("[ERROR] run flowAction %d by webhook %s failed: %s\n", flowAction.ExportID(), webhookID, errInRun.Error())
+			return
+		}
+		resultInJSON, _ := json.Marshal(map[string]interface{}{"data": result.Rows, "extra": result.Extra})
+		var resultForContext interface{}
+		json.Unmarshal(resultInJSON, &resultForContext)
+		for key, value := range trigger.FlattenWebhookContext(flowAction.ExportDisplayName(), resultForContext) {
+			runContext[key] = value
+		}
+		runContext[serversidetransformer.CONTEXT_FIELD_PREVIOUS_ACTION_DATA] = resultForContext.(map[string]interface{})["data"]
+	}
+}
+
+func (controller *Controller) runFlowActionWithContext(flowAction *model.FlowAction, runContext map[string]interface{}) (common.RuntimeResult, error) {
+	flowAction.MergeRunFlowActionContextToRawTemplate(runContext)
+
+	// return mock data instead of calling the real connector when mock enabled
+	if flowAction.IsMockEnabled() {
+		return flowAction.ExportMockResult()
+	}
+
+	// assembly flowAction
+	flowActionFactory := model.NewFlowActionFactoryByFlowAction(flowAction)
+	flowActionAssemblyLine, errInBuild := flowActionFactory.Build()
+	if errInBuild != nil {
+		return common.RuntimeResult{}, errors.New("validate flowAction type error: " + errInBuild.Error())
+	}
+
+	// get resource
+	resource := model.NewResource()
+	var resourceOptions map[string]interface{}
+	if !flowAction.IsVirtualFlowAction() {
+		var errInRetrieveResource error
+		resource, errInRetrieveResource = controller.Storage.ResourceStorage.RetrieveByTeamIDAndResourceID(flowAction.TeamID, flowAction.ExportResourceID())
+		if errInRetrieveResource != nil {
+			return common.RuntimeResult{}, errors.New("get resource failed: " + errInRetrieveResource.Error())
+		}
+		var errInResolveTeamVariables error
+		resource, errInResolveTeamVariables = controller.resolveTeamVariables(flowAction.TeamID, resource, true, model.NewPersistedTeamVariableRunTemplate(&flowAction.Template, runContext))
+		if errInResolveTeamVariables != nil {
+			return common.RuntimeResult{}, errors.New("resolve team variables failed: " + errInResolveTeamVariables.Error())
+		}
+		var errInExportResourceOptions error
+		resourceOptions, errInExportResourceOptions = resource.ExportOptionsInMap()
+		if errInExportResourceOptions != nil {
+			return common.RuntimeResult{}, errors.New("get resource options failed: " + errInExportResourceOptions.Error())
+		}
+		if _, errInValidateResourceOptions := flowActionAssemblyLine.ValidateResourceOptions(resourceOptions); errInValidateResourceOptions != nil {
+			return common.RuntimeResult{}, errors.New("validate resource failed: " + errInValidateResourceOptions.Error())
+		}
+	}
+
+	// check flowAction template
+	if _, errInValidate := flowActionAssemblyLine.ValidateActionTemplate(flowAction.ExportTemplateInMap()); errInValidate != nil {
+		return common.RuntimeResult{}, errors.New("validate flowAction template error: " + errInValidate.Error())
+	}
+
+	// run
+	actionTimeout := flowAction.ExportRunTimeout()
+	if actionTimeout <= 0 {
+		actionTimeout = config.GetInstance().GetActionRunTimeout()
+	}
+	ctx := common.ContextWithResultLimits(common.ContextWithResourceID(context.Background(), resource.ExportID()), resource.ExportResultLimits())
+	ctx, cancel := context.WithTimeout(ctx, actionTimeout)
+	defer cancel()
+	flowActionRunLog := model.NewActionRunLogByFlowAction(flowAction, model.ACTION_RUN_LOG_SOURCE_WEBHOOK, model.ANONYMOUS_USER_ID)
+	flowActionRunResult, errInRunAction := flowActionAssemblyLine.Run(ctx, resourceOptions, flowAction.ExportTemplateInMap(), flowAction.ExportRawTemplateInMap())
+	flowActionRunLog.Finish(flowActionRunResult, errInRunAction)
+	controller.recordActionRun(flowActionRunLog)
+	if errInRunAction == nil && flowAction.IsInvalidatingResourceCache() {
+		controller.invalidateResourceCache(flowAction.TeamID, flowAction.ExportResourceID())
+	}
+	return flowActionRunResult, errInRunAction
+}
+
+// exportRequestBaseURL build the public base URL of this server by request, the proxy forwarded headers are respected.
+func exportRequestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if forwardedProto := c.GetHeader("X-Forwarded-Proto"); forwardedProto != "" {
+		scheme = forwardedProto
+	}
+	host := c.Request.Host
+	if forwardedHost := c.GetHeader("X-Forwarded-Host"); forwardedHost != "" {
+		host = forwardedHost
+	}
+	return scheme + "://" + host
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
 	"github.com/illacloud/builder-backend/src/request"
 	"github.com/illacloud/builder-backend/src/utils/idconvertor"
 	"github.com/illacloud/builder-backend/src/utils/illaresourcemanagersdk"
@@ -272,6 +273,25 @@ func (action *Action) UpdateAcitonByUpdateActionRequest(app *App, userID int, re
 	action.InitUpdatedAt()
 }
 
+func (action *Action) IsEditVersion() bool {
+	return action.Version == APP_EDIT_VERSION
+}
+
+func (action *Action) IsMockEnabled() bool {
+	ac := action.ExportConfig()
+	return ac.MockConfig.IsEnabled(!action.IsEditVersion())
+}
+
+func (action *Action) ExportMockResult() (common.RuntimeResult, error) {
+	ac := action.ExportConfig()
+	return ac.MockConfig.ExportMockDataAsRuntimeResult()
+}
+
+func (action *Action) ExportRunTimeout() time.Duration {
+	ac := action.ExportConfig()
+	return ac.AdvancedConfig.ExportTimeout()
+}
+
 func (action *Action) IsVirtualAction() bool {
 	return resourcelist.IsVirtualResourceByIntType(action.Type)
 }
@@ -328,6 +348,13 @@ func (action *Action) MergeRunActionContextToRawTemplate(context map[string]inte
 	action.RawTemplate = string(templateJsonByte)
 }
 
+// RepointResource point the action to another resource of the same type, for deleting the referenced resource.
+func (action *Action) RepointResource(resourceID int, userID int) {
+	action.ResourceRefID = resourceID
+	action.UpdatedBy = userID
+	action.InitUpdatedAt()
+}
+
 func (action *Action) SetResourceIDByAiAgent(aiAgent *illaresourcemanagersdk.AIAgentForExport) {
 	action.ResourceRefID = aiAgent.ExportIDInInt()
 }
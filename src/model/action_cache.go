@@ -0,0 +1,146 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	parser_sql "github.com/illacloud/builder-backend/src/utils/parser/sql"
+)
+
+const (
+	ACTION_CACHE_MAX_TTL = 24 * time.Hour
+)
+
+const (
+	ACTION_KIND_UNKNOWN = iota
+	ACTION_KIND_READ
+	ACTION_KIND_WRITE
+)
+
+const (
+	ACTION_TEMPLATE_FIELD_MODE   = "mode"
+	ACTION_TEMPLATE_FIELD_QUERY  = "query"
+	ACTION_TEMPLATE_FIELD_METHOD = "method"
+)
+
+// CacheConfig is the opt-in server side result cache of action, the TTL is in seconds.
+type CacheConfig struct {
+	Enabled bool `json:"enabled"`
+	TTL     int  `json:"ttl"`
+}
+
+func (cc *CacheConfig) IsEnabled() bool {
+	return cc != nil && cc.Enabled && cc.TTL > 0
+}
+
+func (cc *CacheConfig) ExportTTL() time.Duration {
+	if cc == nil || cc.TTL <= 0 {
+		return 0
+	}
+	ttl := time.Duration(cc.TTL) * time.Second
+	if ttl > ACTION_CACHE_MAX_TTL {
+		return ACTION_CACHE_MAX_TTL
+	}
+	return ttl
+}
+
+// the connector methods which only read data
+var readActionMethods = map[string]bool{
+	"GET":            true,
+	"HEAD":           true,
+	"OPTIONS":        true,
+	"list":           true,
+	"get":            true,
+	"read":           true,
+	"query":          true,
+	"scan":           true,
+	"getItem":        true,
+	"listRecords":    true,
+	"retrieveRecord": true,
+	"find":           true,
+	"getView":        true,
+}
+
+// ClassifyActionTemplate tell if the action template reads or writes data, by the sql statements in the dialect of action type or the connector method.
+func ClassifyActionTemplate(actionType int, template map[string]interface{}) int {
+	if mode, _ := template[ACTION_TEMPLATE_FIELD_MODE].(string); mode == "sql" || mode == "sql-safe" {
+		query, _ := template[ACTION_TEMPLATE_FIELD_QUERY].(string)
+		return classifySQL(actionType, query)
+	}
+	if method, hit := template[ACTION_TEMPLATE_FIELD_METHOD].(string); hit {
+		if readActionMethods[method] {
+			return ACTION_KIND_READ
+		}
+		return ACTION_KIND_WRITE
+	}
+	return ACTION_KIND_UNKNOWN
+}
+
+// classifySQL treat the script as read only when every statement in it is read,
+// the script can not be classified is treated as write.
+func classifySQL(actionType int, query string) int {
+	statements, errInClassify := parser_sql.NewSQLClassifier(actionType).Classify(query)
+	if errInClassify != nil {
+		return ACTION_KIND_WRITE
+	}
+	if len(statements) == 0 {
+		return ACTION_KIND_UNKNOWN
+	}
+	for _, statement := range statements {
+		if !statement.IsRead() {
+			return ACTION_KIND_WRITE
+		}
+	}
+	return ACTION_KIND_READ
+}
+
+// NewActionCacheKey build the cache key by team, action, version and the hash of the resolved template and run context.
+// the resource generation is included, so all cached results of a resource are invalidated by increasing it.
+func NewActionCacheKey(teamID int, resourceID int, resourceGeneration int64, actionID int, version int, template map[string]interface{}, runContext interface{}) string {
+	payload, _ := json.Marshal([]interface{}{template, runContext})
+	digest := sha256.Sum256(payload)
+	return strings.Join([]string{
+		strconv.Itoa(teamID),
+		strconv.Itoa(resourceID),
+		strconv.FormatInt(resourceGeneration, 10),
+		strconv.Itoa(actionID),
+		strconv.Itoa(version),
+		hex.EncodeToString(digest[:]),
+	}, ":")
+}
+
+func (action *Action) ExportCacheConfig() *CacheConfig {
+	return action.ExportConfig().CacheConfig
+}
+
+// IsCacheable check if the action result can be served from cache, the write action is never cached.
+func (action *Action) IsCacheable() bool {
+	return action.ExportCacheConfig().IsEnabled() && ClassifyActionTemplate(action.ExportType(), action.ExportTemplateInMap()) != ACTION_KIND_WRITE
+}
+
+// IsInvalidatingResourceCache check if the succeeded run should invalidate the cached results of the resource.
+// the unknown kind action is treated as write unless it opts in the cache.
+func (action *Action) IsInvalidatingResourceCache() bool {
+	if action.IsVirtualAction() {
+		return false
+	}
+	switch ClassifyActionTemplate(action.ExportType(), action.ExportTemplateInMap()) {
+	case ACTION_KIND_READ:
+		return false
+	case ACTION_KIND_WRITE:
+		return true
+	default:
+		return !action.ExportCacheConfig().IsEnabled()
+	}
+}
+
+func (action *FlowAction) IsInvalidatingResourceCache() bool {
+	if action.IsVirtualFlowAction() {
+		return false
+	}
+	return ClassifyActionTemplate(action.ExportType(), action.ExportTemplateInMap()) != ACTION_KIND_READ
+}
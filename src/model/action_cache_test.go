@@ -0,0 +1,38 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/illacloud/builder-backend/src/utils/resourcelist"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyActionTemplate(t *testing.T) {
+	cases := []struct {
+		template map[string]interface{}
+		kind     int
+	}{
+		{map[string]interface{}{"mode": "sql", "query": "select * from users"}, ACTION_KIND_READ},
+		{map[string]interface{}{"mode": "sql-safe", "query": "-- list\n  SELECT 1;"}, ACTION_KIND_READ},
+		{map[string]interface{}{"mode": "sql", "query": "with t as (select 1) select * from t"}, ACTION_KIND_READ},
+		{map[string]interface{}{"mode": "sql", "query": "with t as (delete from users returning *) select * from t"}, ACTION_KIND_WRITE},
+		{map[string]interface{}{"mode": "sql", "query": "select 1; drop table users"}, ACTION_KIND_WRITE},
+		{map[string]interface{}{"mode": "sql", "query": "update users set name = 'a'"}, ACTION_KIND_WRITE},
+		{map[string]interface{}{"mode": "sql", "query": "select * into backup from users"}, ACTION_KIND_WRITE},
+		{map[string]interface{}{"mode": "sql", "query": "select 'a;b'; show tables"}, ACTION_KIND_READ},
+		{map[string]interface{}{"method": "GET"}, ACTION_KIND_READ},
+		{map[string]interface{}{"method": "POST"}, ACTION_KIND_WRITE},
+		{map[string]interface{}{"mode": "gui"}, ACTION_KIND_UNKNOWN},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.kind, ClassifyActionTemplate(resourcelist.TYPE_POSTGRESQL_ID, c.template), c.template)
+	}
+}
+
+func TestNewActionCacheKey(t *testing.T) {
+	template := map[string]interface{}{"mode": "sql", "query": "select * from users where id = {{input1.value}}"}
+	key := NewActionCacheKey(1, 2, 0, 3, 0, template, map[string]interface{}{"input1.value": 1})
+	assert.Equal(t, key, NewActionCacheKey(1, 2, 0, 3, 0, template, map[string]interface{}{"input1.value": 1}))
+	assert.NotEqual(t, key, NewActionCacheKey(1, 2, 0, 3, 0, template, map[string]interface{}{"input1.value": 2}))
+	assert.NotEqual(t, key, NewActionCacheKey(1, 2, 1, 3, 0, template, map[string]interface{}{"input1.value": 1}), "the resource generation invalidates the key")
+}
@@ -17,6 +17,7 @@ package model
 import (
 	"encoding/json"
 	"errors"
+	"time"
 )
 
 const (
@@ -29,6 +30,7 @@ type ActionConfig struct {
 	IsVirtualResource bool            `json:"isVirtualResource"`
 	AdvancedConfig    *AdvancedConfig `json:"advancedConfig"` // 2023_4_20: add advanced config for action
 	MockConfig        *MockConfig     `json:"mockConfig"`
+	CacheConfig       *CacheConfig    `json:"cacheConfig"`
 }
 
 type AdvancedConfig struct {
@@ -39,6 +41,7 @@ type AdvancedConfig struct {
 	IsPeriodically     bool     `json:"isPeriodically"`
 	PeriodInterval     string   `json:"periodInterval"`
 	Mock               string   `json:"mock"`
+	Timeout            int      `json:"timeout"` // action run timeout in milliseconds, 0 means use the server default
 }
 
 func NewActionConfig() *ActionConfig {
@@ -57,7 +60,19 @@ func NewActionConfig() *ActionConfig {
 			MockData:             "",
 			EnableForReleasedApp: false,
 		},
+		CacheConfig: &CacheConfig{
+			Enabled: false,
+			TTL:     0,
+		},
+	}
+}
+
+// ExportTimeout return the action run timeout, 0 when not set.
+func (ac *AdvancedConfig) ExportTimeout() time.Duration {
+	if ac == nil || ac.Timeout <= 0 {
+		return 0
 	}
+	return time.Duration(ac.Timeout) * time.Millisecond
 }
 
 func (ac *ActionConfig) ExportToJSONString() string {
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+	"github.com/illacloud/builder-backend/src/utils/resourcelist"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActionFactoryBuild(t *testing.T) {
+	for _, actionType := range []int{resourcelist.TYPE_MYSQL_ID, resourcelist.TYPE_NEON_ID, resourcelist.TYPE_UPSTASH_ID, resourcelist.TYPE_SERVER_SIDE_TRANSFORMER_ID} {
+		connector, err := (&ActionFactory{Type: actionType}).Build()
+		assert.Nil(t, err, resourcelist.GetResourceIDMappedType(actionType))
+		assert.NotNil(t, connector)
+	}
+	_, err := (&ActionFactory{Type: resourcelist.TYPE_TRANSFORMER_ID}).Build()
+	assert.NotNil(t, err, "the frontend transformer has no connector")
+}
+
+func TestConnectorRegistrations(t *testing.T) {
+	registrations := common.ExportConnectorRegistrations()
+	assert.Equal(t, 32, len(registrations))
+	for _, registration := range registrations {
+		assert.Equal(t, registration.ID, resourcelist.GetResourceNameMappedID(registration.Type))
+		if registration.Type == resourcelist.TYPE_POSTGRESQL {
+			assert.True(t, registration.Capabilities.Streaming)
+			assert.True(t, registration.Capabilities.Preview)
+			assert.True(t, registration.Capabilities.Transaction)
+		}
+	}
+}
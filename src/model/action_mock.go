@@ -1,7 +1,71 @@
 package model
 
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+)
+
+const (
+	MOCK_RESULT_EXTRA_FIELD_IS_MOCK = "isMock"
+)
+
 type MockConfig struct {
 	Enabled              bool   `json:"enabled"`
 	MockData             string `json:"mockData"`
 	EnableForReleasedApp bool   `json:"enableForReleasedApp"`
 }
+
+// IsEnabled check if the mock data should be used instead of running the action.
+// the released app only use mock data when EnableForReleasedApp is set.
+func (mc *MockConfig) IsEnabled(isReleaseVersion bool) bool {
+	if mc == nil || !mc.Enabled {
+		return false
+	}
+	if isReleaseVersion {
+		return mc.EnableForReleasedApp
+	}
+	return true
+}
+
+func (mc *MockConfig) ExportMockDataAsRuntimeResult() (common.RuntimeResult, error) {
+	return NewRuntimeResultByMockData(mc.MockData)
+}
+
+// NewRuntimeResultByMockData convert mock data json string to action run result.
+// the mock data can be a json object (as single row) or a json array of objects (as rows).
+func NewRuntimeResultByMockData(mockData string) (common.RuntimeResult, error) {
+	runtimeResult := common.RuntimeResult{
+		Success: false,
+		Rows:    []map[string]interface{}{},
+		Extra: map[string]interface{}{
+			MOCK_RESULT_EXTRA_FIELD_IS_MOCK: true,
+		},
+	}
+	if len(mockData) == 0 {
+		runtimeResult.SetSuccess()
+		return runtimeResult, nil
+	}
+
+	var payload interface{}
+	if errInUnmarshal := json.Unmarshal([]byte(mockData), &payload); errInUnmarshal != nil {
+		return runtimeResult, errors.New("invalid mock data: " + errInUnmarshal.Error())
+	}
+	switch payloadAsserted := payload.(type) {
+	case map[string]interface{}:
+		runtimeResult.Rows = append(runtimeResult.Rows, payloadAsserted)
+	case []interface{}:
+		for _, row := range payloadAsserted {
+			rowAsserted, rowAssertPass := row.(map[string]interface{})
+			if !rowAssertPass {
+				return runtimeResult, errors.New("invalid mock data: array element must be json object")
+			}
+			runtimeResult.Rows = append(runtimeResult.Rows, rowAsserted)
+		}
+	default:
+		return runtimeResult, errors.New("invalid mock data: must be json object or array of json objects")
+	}
+	runtimeResult.SetSuccess()
+	return runtimeResult, nil
+}
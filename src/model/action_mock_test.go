@@ -0,0 +1,40 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMockConfigIsEnabled(t *testing.T) {
+	var nilMockConfig *MockConfig
+	assert.False(t, nilMockConfig.IsEnabled(false), "nil mock config should be disabled")
+
+	mockConfig := &MockConfig{Enabled: true, EnableForReleasedApp: false}
+	assert.True(t, mockConfig.IsEnabled(false), "mock should be enabled for edit version")
+	assert.False(t, mockConfig.IsEnabled(true), "mock should be disabled for release version")
+
+	mockConfig.EnableForReleasedApp = true
+	assert.True(t, mockConfig.IsEnabled(true), "mock should be enabled for release version")
+}
+
+func TestNewRuntimeResultByMockData(t *testing.T) {
+	runtimeResult, err := NewRuntimeResultByMockData(`[{"id": 1}, {"id": 2}]`)
+	assert.Nil(t, err)
+	assert.True(t, runtimeResult.Success)
+	assert.Equal(t, 2, len(runtimeResult.Rows), "the rows length should be equal")
+
+	runtimeResult, err = NewRuntimeResultByMockData(`{"id": 1}`)
+	assert.Nil(t, err)
+	assert.Equal(t, []map[string]interface{}{{"id": float64(1)}}, runtimeResult.Rows, "the rows should be equal")
+
+	runtimeResult, err = NewRuntimeResultByMockData(``)
+	assert.Nil(t, err)
+	assert.True(t, runtimeResult.Success)
+
+	_, err = NewRuntimeResultByMockData(`[1, 2]`)
+	assert.NotNil(t, err)
+
+	_, err = NewRuntimeResultByMockData(`not json`)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,167 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+)
+
+const (
+	ACTION_RUN_LOG_KIND_ACTION      = "action"
+	ACTION_RUN_LOG_KIND_FLOW_ACTION = "flowAction"
+)
+
+const (
+	ACTION_RUN_LOG_SOURCE_EDITOR   = "editor"
+	ACTION_RUN_LOG_SOURCE_PUBLIC   = "public"
+	ACTION_RUN_LOG_SOURCE_INTERNAL = "internal"
+	ACTION_RUN_LOG_SOURCE_SCHEDULE = "schedule"
+	ACTION_RUN_LOG_SOURCE_WEBHOOK  = "webhook"
+)
+
+const (
+	ACTION_RUN_LOG_STATUS_SUCCESS = "success"
+	ACTION_RUN_LOG_STATUS_FAILED  = "failed"
+)
+
+const (
+	// the run parameters over this size will be truncated, only a preview is kept.
+	ACTION_RUN_LOG_PARAMETERS_MAX_SIZE = 4 * 1024
+	ACTION_RUN_LOG_ERROR_MAX_SIZE      = 4 * 1024
+)
+
+const (
+	ACTION_RUN_LOG_PARAMETERS_FIELD_TRUNCATED = "truncated"
+	ACTION_RUN_LOG_PARAMETERS_FIELD_PREVIEW   = "preview"
+)
+
+// ActionRunLog record every run of action and flow action, for debugging the failed runs afterwards.
+// the app and workflow fields are exclusive by the kind.
+type ActionRunLog struct {
+	ID            int       `gorm:"column:id;type:bigserial;primary_key"`
+	TeamID        int       `gorm:"column:team_id;type:bigserial"`
+	Kind          string    `gorm:"column:kind;type:varchar;size:16;not null"`
+	Source        string    `gorm:"column:source;type:varchar;size:16;not null"`
+	AppRefID      int       `gorm:"column:app_ref_id;type:bigint;not null"`
+	WorkflowRefID int       `gorm:"column:workflow_ref_id;type:bigint;not null"`
+	ActionRefID   int       `gorm:"column:action_ref_id;type:bigint;not null"`
+	ActionName    string    `gorm:"column:action_name;type:varchar;size:255;not null"`
+	ActionType    int       `gorm:"column:action_type;type:smallint;not null"`
+	Version       int       `gorm:"column:version;type:bigint;not null"`
+	ResourceRefID int       `gorm:"column:resource_ref_id;type:bigint;not null"`
+	Status        string    `gorm:"column:status;type:varchar;size:16;not null"`
+	RowCount      int       `gorm:"column:row_count;type:bigint;not null"`
+	Parameters    string    `gorm:"column:parameters;type:jsonb"`
+	Error         string    `gorm:"column:error;type:text"`
+	ExecutedBy    int       `gorm:"column:executed_by;type:bigint;not null"`
+	StartedAt     time.Time `gorm:"column:started_at;type:timestamp;not null"`
+	Duration      int64     `gorm:"column:duration;type:bigint;not null"` // in milliseconds
+}
+
+func NewActionRunLogByAction(action *Action, source string, userID int) *ActionRunLog {
+	runLog := &ActionRunLog{
+		TeamID:        action.TeamID,
+		Kind:          ACTION_RUN_LOG_KIND_ACTION,
+		Source:        source,
+		AppRefID:      action.AppRefID,
+		ActionRefID:   action.ID,
+		ActionName:    action.Name,
+		ActionType:    action.Type,
+		Version:       action.Version,
+		ResourceRefID: action.ResourceRefID,
+		ExecutedBy:    userID,
+		StartedAt:     time.Now().UTC(),
+	}
+	runLog.SetParameters(action.ExportRawTemplateInMap()[ACTION_RUNTIME_INFO_FIELD_CONTEXT])
+	return runLog
+}
+
+func NewActionRunLogByFlowAction(flowAction *FlowAction, source string, userID int) *ActionRunLog {
+	runLog := &ActionRunLog{
+		TeamID:        flowAction.TeamID,
+		Kind:          ACTION_RUN_LOG_KIND_FLOW_ACTION,
+		Source:        source,
+		WorkflowRefID: flowAction.WorkflowID,
+		ActionRefID:   flowAction.ID,
+		ActionName:    flowAction.Name,
+		ActionType:    flowAction.Type,
+		Version:       flowAction.Version,
+		ResourceRefID: flowAction.ResourceID,
+		ExecutedBy:    userID,
+		StartedAt:     time.Now().UTC(),
+	}
+	runLog.SetParameters(flowAction.ExportRawTemplateInMap()[ACTION_RUNTIME_INFO_FIELD_CONTEXT])
+	return runLog
+}
+
+// SetParameters store the run context (the resolved variables) as parameters,
+// the parameters over ACTION_RUN_LOG_PARAMETERS_MAX_SIZE are replaced by a truncated preview.
+func (runLog *ActionRunLog) SetParameters(parameters interface{}) {
+	parametersInJSON, errInMarshal := json.Marshal(parameters)
+	if errInMarshal != nil {
+		runLog.Parameters = "null"
+		return
+	}
+	if len(parametersInJSON) <= ACTION_RUN_LOG_PARAMETERS_MAX_SIZE {
+		runLog.Parameters = string(parametersInJSON)
+		return
+	}
+	truncated, _ := json.Marshal(map[string]interface{}{
+		ACTION_RUN_LOG_PARAMETERS_FIELD_TRUNCATED: true,
+		ACTION_RUN_LOG_PARAMETERS_FIELD_PREVIEW:   truncateString(string(parametersInJSON), ACTION_RUN_LOG_PARAMETERS_MAX_SIZE),
+	})
+	runLog.Parameters = string(truncated)
+}
+
+func (runLog *ActionRunLog) SetResourceID(resourceID int) {
+	runLog.ResourceRefID = resourceID
+}
+
+// SetRowCount set the row count of the stream mode run, which result carries no rows.
+func (runLog *ActionRunLog) SetRowCount(rowCount int) {
+	runLog.RowCount = rowCount
+}
+
+func (runLog *ActionRunLog) Succeed(result common.RuntimeResult) {
+	runLog.Status = ACTION_RUN_LOG_STATUS_SUCCESS
+	runLog.Duration = time.Since(runLog.StartedAt).Milliseconds()
+	runLog.RowCount = len(result.Rows)
+}
+
+func (runLog *ActionRunLog) Fail(err error) {
+	runLog.Status = ACTION_RUN_LOG_STATUS_FAILED
+	runLog.Duration = time.Since(runLog.StartedAt).Milliseconds()
+	runLog.Error = truncateString(err.Error(), ACTION_RUN_LOG_ERROR_MAX_SIZE)
+}
+
+// Finish record the run result by the connector returned values.
+func (runLog *ActionRunLog) Finish(result common.RuntimeResult, err error) {
+	if err != nil {
+		runLog.Fail(err)
+		return
+	}
+	runLog.Succeed(result)
+}
+
+func (runLog *ActionRunLog) ExportParametersInInterface() interface{} {
+	var parameters interface{}
+	json.Unmarshal([]byte(runLog.Parameters), &parameters)
+	return parameters
+}
+
+// truncateString cut the string to max bytes without breaking the utf-8 characters.
+func truncateString(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	cut := max
+	for cut > 0 && !isRuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
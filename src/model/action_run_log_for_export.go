@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	"github.com/illacloud/builder-backend/src/utils/idconvertor"
+	"github.com/illacloud/builder-backend/src/utils/resourcelist"
+)
+
+type ActionRunLogForExport struct {
+	ID         string      `json:"runID"`
+	TeamID     string      `json:"teamID"`
+	Kind       string      `json:"kind"`
+	Source     string      `json:"source"`
+	AppID      string      `json:"appID,omitempty"`
+	WorkflowID string      `json:"workflowID,omitempty"`
+	ActionID   string      `json:"actionID"`
+	ActionName string      `json:"actionName"`
+	ActionType string      `json:"actionType"`
+	Version    int         `json:"version"`
+	ResourceID string      `json:"resourceID"`
+	Status     string      `json:"status"`
+	RowCount   int         `json:"rowCount"`
+	Parameters interface{} `json:"parameters"`
+	Error      string      `json:"error"`
+	ExecutedBy string      `json:"executedBy"`
+	StartedAt  time.Time   `json:"startedAt"`
+	Duration   int64       `json:"duration"`
+}
+
+func NewActionRunLogForExport(runLog *ActionRunLog) *ActionRunLogForExport {
+	runLogForExport := &ActionRunLogForExport{
+		ID:         idconvertor.ConvertIntToString(runLog.ID),
+		TeamID:     idconvertor.ConvertIntToString(runLog.TeamID),
+		Kind:       runLog.Kind,
+		Source:     runLog.Source,
+		ActionID:   idconvertor.ConvertIntToString(runLog.ActionRefID),
+		ActionName: runLog.ActionName,
+		ActionType: resourcelist.GetResourceIDMappedType(runLog.ActionType),
+		Version:    runLog.Version,
+		ResourceID: idconvertor.ConvertIntToString(runLog.ResourceRefID),
+		Status:     runLog.Status,
+		RowCount:   runLog.RowCount,
+		Parameters: runLog.ExportParametersInInterface(),
+		Error:      runLog.Error,
+		ExecutedBy: idconvertor.ConvertIntToString(runLog.ExecutedBy),
+		StartedAt:  runLog.StartedAt,
+		Duration:   runLog.Duration,
+	}
+	if runLog.AppRefID != 0 {
+		runLogForExport.AppID = idconvertor.ConvertIntToString(runLog.AppRefID)
+	}
+	if runLog.WorkflowRefID != 0 {
+		runLogForExport.WorkflowID = idconvertor.ConvertIntToString(runLog.WorkflowRefID)
+	}
+	return runLogForExport
+}
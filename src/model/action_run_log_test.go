@@ -0,0 +1,25 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActionRunLogParameters(t *testing.T) {
+	runLog := &ActionRunLog{}
+	runLog.SetParameters(map[string]interface{}{"input1.value": "a"})
+	assert.Equal(t, map[string]interface{}{"input1.value": "a"}, runLog.ExportParametersInInterface())
+
+	// the large parameters are truncated but still valid json
+	runLog.SetParameters(map[string]interface{}{"input1.value": strings.Repeat("中", ACTION_RUN_LOG_PARAMETERS_MAX_SIZE)})
+	parameters := runLog.ExportParametersInInterface().(map[string]interface{})
+	assert.Equal(t, true, parameters[ACTION_RUN_LOG_PARAMETERS_FIELD_TRUNCATED])
+	assert.LessOrEqual(t, len(parameters[ACTION_RUN_LOG_PARAMETERS_FIELD_PREVIEW].(string)), ACTION_RUN_LOG_PARAMETERS_MAX_SIZE)
+
+	runLog.Fail(errors.New("connection refused"))
+	assert.Equal(t, ACTION_RUN_LOG_STATUS_FAILED, runLog.Status)
+	assert.Equal(t, "connection refused", runLog.Error)
+}
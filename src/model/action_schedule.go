@@ -0,0 +1,225 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/illacloud/builder-backend/src/request"
+	"github.com/illacloud/builder-backend/src/utils/cronexpr"
+)
+
+const (
+	ACTION_SCHEDULE_RUN_STATUS_SUCCESS = "success"
+	ACTION_SCHEDULE_RUN_STATUS_FAILED  = "failed"
+)
+
+// the result over this size will not be stored, avoid the big query result blow up the table.
+const ACTION_SCHEDULE_RESULT_MAX_SIZE = 64 * 1024
+
+// ACTION_SCHEDULE_RUN_HISTORY_MAX_LEN is the run history count kept for each schedule.
+const ACTION_SCHEDULE_RUN_HISTORY_MAX_LEN = 100
+
+// ActionSchedule run the action of the released app version periodically on server side.
+// the action is referenced by display name, since the released action will be recreated in every release.
+type ActionSchedule struct {
+	ID         int       `gorm:"column:id;type:bigserial;primary_key"`
+	UID        uuid.UUID `gorm:"column:uid;type:uuid;not null"`
+	TeamID     int       `gorm:"column:team_id;type:bigserial"`
+	AppRefID   int       `gorm:"column:app_ref_id;type:bigint;not null"`
+	ActionName string    `gorm:"column:action_name;type:varchar;size:255;not null"`
+	Schedule   string    `gorm:"column:schedule;type:varchar;size:255;not null"`
+	Enabled    bool      `gorm:"column:enabled;type:boolean;not null"`
+	PushToRoom bool      `gorm:"column:push_to_room;type:boolean;not null"`
+	NextRunAt  time.Time `gorm:"column:next_run_at;type:timestamp;not null"`
+	LastRunAt  time.Time `gorm:"column:last_run_at;type:timestamp"`
+	LastStatus string    `gorm:"column:last_status;type:varchar;size:16"`
+	LastResult string    `gorm:"column:last_result;type:jsonb"`
+	LastError  string    `gorm:"column:last_error;type:text"`
+	CreatedAt  time.Time `gorm:"column:created_at;type:timestamp;not null"`
+	CreatedBy  int       `gorm:"column:created_by;type:bigint;not null"`
+	UpdatedAt  time.Time `gorm:"column:updated_at;type:timestamp;not null"`
+	UpdatedBy  int       `gorm:"column:updated_by;type:bigint;not null"`
+}
+
+// ActionScheduleRun is the run history of action schedule.
+type ActionScheduleRun struct {
+	ID            int       `gorm:"column:id;type:bigserial;primary_key"`
+	TeamID        int       `gorm:"column:team_id;type:bigserial"`
+	AppRefID      int       `gorm:"column:app_ref_id;type:bigint;not null"`
+	ScheduleRefID int       `gorm:"column:schedule_ref_id;type:bigint;not null"`
+	ActionRefID   int       `gorm:"column:action_ref_id;type:bigint;not null"`
+	Version       int       `gorm:"column:version;type:bigint;not null"`
+	Status        string    `gorm:"column:status;type:varchar;size:16;not null"`
+	Result        string    `gorm:"column:result;type:jsonb"`
+	Error         string    `gorm:"column:error;type:text"`
+	StartedAt     time.Time `gorm:"column:started_at;type:timestamp;not null"`
+	Duration      int64     `gorm:"column:duration;type:bigint;not null"` // in milliseconds
+}
+
+func NewActionScheduleByRequest(teamID int, appID int, userID int, req *request.CreateActionScheduleRequest) (*ActionSchedule, error) {
+	actionSchedule := &ActionSchedule{
+		TeamID:     teamID,
+		AppRefID:   appID,
+		ActionName: req.ActionName,
+		Schedule:   req.Schedule,
+		Enabled:    req.Enabled,
+		PushToRoom: req.PushToRoom,
+		LastResult: "null",
+		CreatedBy:  userID,
+		UpdatedBy:  userID,
+	}
+	if errInSchedule := actionSchedule.ScheduleNextRun(time.Now().UTC()); errInSchedule != nil {
+		return nil, errInSchedule
+	}
+	actionSchedule.InitUID()
+	actionSchedule.InitCreatedAt()
+	actionSchedule.InitUpdatedAt()
+	return actionSchedule, nil
+}
+
+func (actionSchedule *ActionSchedule) InitUID() {
+	actionSchedule.UID = uuid.New()
+}
+
+func (actionSchedule *ActionSchedule) InitCreatedAt() {
+	actionSchedule.CreatedAt = time.Now().UTC()
+}
+
+func (actionSchedule *ActionSchedule) InitUpdatedAt() {
+	actionSchedule.UpdatedAt = time.Now().UTC()
+}
+
+func (actionSchedule *ActionSchedule) UpdateByRequest(userID int, req *request.UpdateActionScheduleRequest) error {
+	scheduleChanged := req.Schedule != actionSchedule.Schedule
+	actionSchedule.ActionName = req.ActionName
+	actionSchedule.Schedule = req.Schedule
+	actionSchedule.PushToRoom = req.PushToRoom
+	// re-calculate next run time when schedule changed or re-enabled
+	if scheduleChanged || (req.Enabled && !actionSchedule.Enabled) {
+		if errInSchedule := actionSchedule.ScheduleNextRun(time.Now().UTC()); errInSchedule != nil {
+			return errInSchedule
+		}
+	}
+	actionSchedule.Enabled = req.Enabled
+	actionSchedule.UpdatedBy = userID
+	actionSchedule.InitUpdatedAt()
+	return nil
+}
+
+// ScheduleNextRun set the next run time after the given time by the schedule expression.
+func (actionSchedule *ActionSchedule) ScheduleNextRun(after time.Time) error {
+	nextRunAt, errInCalculate := actionSchedule.ExportNextRunAfter(after)
+	if errInCalculate != nil {
+		return errInCalculate
+	}
+	actionSchedule.NextRunAt = nextRunAt
+	return nil
+}
+
+// ExportNextRunAfter calculate the next run time after the given time, without modifying the schedule.
+func (actionSchedule *ActionSchedule) ExportNextRunAfter(after time.Time) (time.Time, error) {
+	schedule, errInParse := cronexpr.Parse(actionSchedule.Schedule)
+	if errInParse != nil {
+		return time.Time{}, errInParse
+	}
+	nextRunAt := schedule.Next(after)
+	if nextRunAt.IsZero() {
+		return time.Time{}, errors.New("schedule " + actionSchedule.Schedule + " will never run")
+	}
+	return nextRunAt, nil
+}
+
+// Disable stop the schedule which can not run anymore, like the expression is invalid.
+func (actionSchedule *ActionSchedule) Disable(reason error) {
+	actionSchedule.Enabled = false
+	actionSchedule.LastStatus = ACTION_SCHEDULE_RUN_STATUS_FAILED
+	actionSchedule.LastError = reason.Error()
+	actionSchedule.InitUpdatedAt()
+}
+
+func (actionSchedule *ActionSchedule) ExportID() int {
+	return actionSchedule.ID
+}
+
+func (actionSchedule *ActionSchedule) ExportTeamID() int {
+	return actionSchedule.TeamID
+}
+
+func (actionSchedule *ActionSchedule) ExportAppID() int {
+	return actionSchedule.AppRefID
+}
+
+func (actionSchedule *ActionSchedule) ExportActionName() string {
+	return actionSchedule.ActionName
+}
+
+func (actionSchedule *ActionSchedule) ExportNextRunAt() time.Time {
+	return actionSchedule.NextRunAt
+}
+
+func (actionSchedule *ActionSchedule) IsPushToRoom() bool {
+	return actionSchedule.PushToRoom
+}
+
+func (actionSchedule *ActionSchedule) ExportLastResultInInterface() interface{} {
+	var result interface{}
+	json.Unmarshal([]byte(actionSchedule.LastResult), &result)
+	return result
+}
+
+// RecordRun update the last run info by the run history.
+func (actionSchedule *ActionSchedule) RecordRun(run *ActionScheduleRun) {
+	actionSchedule.LastRunAt = run.StartedAt
+	actionSchedule.LastStatus = run.Status
+	actionSchedule.LastResult = run.Result
+	actionSchedule.LastError = run.Error
+}
+
+func NewActionScheduleRun(actionSchedule *ActionSchedule, startedAt time.Time) *ActionScheduleRun {
+	return &ActionScheduleRun{
+		TeamID:        actionSchedule.TeamID,
+		AppRefID:      actionSchedule.AppRefID,
+		ScheduleRefID: actionSchedule.ID,
+		Result:        "null",
+		StartedAt:     startedAt,
+	}
+}
+
+func (run *ActionScheduleRun) SetAction(action *Action) {
+	run.ActionRefID = action.ID
+	run.Version = action.Version
+}
+
+// Succeed record the run result, the result larger than ACTION_SCHEDULE_RESULT_MAX_SIZE will not be stored.
+func (run *ActionScheduleRun) Succeed(result interface{}) {
+	run.Status = ACTION_SCHEDULE_RUN_STATUS_SUCCESS
+	run.Duration = time.Since(run.StartedAt).Milliseconds()
+	resultInJSON, errInMarshal := json.Marshal(result)
+	if errInMarshal != nil {
+		run.Error = "marshal result failed: " + errInMarshal.Error()
+		return
+	}
+	if len(resultInJSON) > ACTION_SCHEDULE_RESULT_MAX_SIZE {
+		run.Error = "result is too large to store, dropped"
+		return
+	}
+	run.Result = string(resultInJSON)
+}
+
+func (run *ActionScheduleRun) Fail(err error) {
+	run.Status = ACTION_SCHEDULE_RUN_STATUS_FAILED
+	run.Duration = time.Since(run.StartedAt).Milliseconds()
+	run.Error = err.Error()
+}
+
+func (run *ActionScheduleRun) IsSucceed() bool {
+	return run.Status == ACTION_SCHEDULE_RUN_STATUS_SUCCESS
+}
+
+func (run *ActionScheduleRun) ExportResultInInterface() interface{} {
+	var result interface{}
+	json.Unmarshal([]byte(run.Result), &result)
+	return result
+}
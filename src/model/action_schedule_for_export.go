@@ -0,0 +1,79 @@
+package model
+
+import (
+	"time"
+
+	"github.com/illacloud/builder-backend/src/utils/idconvertor"
+)
+
+type ActionScheduleForExport struct {
+	ID         string      `json:"scheduleID"`
+	UID        string      `json:"uid"`
+	TeamID     string      `json:"teamID"`
+	AppID      string      `json:"appID"`
+	ActionName string      `json:"actionName"`
+	Schedule   string      `json:"schedule"`
+	Enabled    bool        `json:"enabled"`
+	PushToRoom bool        `json:"pushToRoom"`
+	NextRunAt  time.Time   `json:"nextRunAt"`
+	LastRunAt  time.Time   `json:"lastRunAt"`
+	LastStatus string      `json:"lastStatus"`
+	LastResult interface{} `json:"lastResult"`
+	LastError  string      `json:"lastError"`
+	CreatedAt  time.Time   `json:"createdAt"`
+	CreatedBy  string      `json:"createdBy"`
+	UpdatedAt  time.Time   `json:"updatedAt"`
+	UpdatedBy  string      `json:"updatedBy"`
+}
+
+func NewActionScheduleForExport(actionSchedule *ActionSchedule) *ActionScheduleForExport {
+	return &ActionScheduleForExport{
+		ID:         idconvertor.ConvertIntToString(actionSchedule.ID),
+		UID:        actionSchedule.UID.String(),
+		TeamID:     idconvertor.ConvertIntToString(actionSchedule.TeamID),
+		AppID:      idconvertor.ConvertIntToString(actionSchedule.AppRefID),
+		ActionName: actionSchedule.ActionName,
+		Schedule:   actionSchedule.Schedule,
+		Enabled:    actionSchedule.Enabled,
+		PushToRoom: actionSchedule.PushToRoom,
+		NextRunAt:  actionSchedule.NextRunAt,
+		LastRunAt:  actionSchedule.LastRunAt,
+		LastStatus: actionSchedule.LastStatus,
+		LastResult: actionSchedule.ExportLastResultInInterface(),
+		LastError:  actionSchedule.LastError,
+		CreatedAt:  actionSchedule.CreatedAt,
+		CreatedBy:  idconvertor.ConvertIntToString(actionSchedule.CreatedBy),
+		UpdatedAt:  actionSchedule.UpdatedAt,
+		UpdatedBy:  idconvertor.ConvertIntToString(actionSchedule.UpdatedBy),
+	}
+}
+
+func (resp *ActionScheduleForExport) ExportForFeedback() interface{} {
+	return resp
+}
+
+type ActionScheduleRunForExport struct {
+	ID         string      `json:"runID"`
+	ScheduleID string      `json:"scheduleID"`
+	ActionID   string      `json:"actionID"`
+	Version    int         `json:"version"`
+	Status     string      `json:"status"`
+	Result     interface{} `json:"result"`
+	Error      string      `json:"error"`
+	StartedAt  time.Time   `json:"startedAt"`
+	Duration   int64       `json:"duration"`
+}
+
+func NewActionScheduleRunForExport(run *ActionScheduleRun) *ActionScheduleRunForExport {
+	return &ActionScheduleRunForExport{
+		ID:         idconvertor.ConvertIntToString(run.ID),
+		ScheduleID: idconvertor.ConvertIntToString(run.ScheduleRefID),
+		ActionID:   idconvertor.ConvertIntToString(run.ActionRefID),
+		Version:    run.Version,
+		Status:     run.Status,
+		Result:     run.ExportResultInInterface(),
+		Error:      run.Error,
+		StartedAt:  run.StartedAt,
+		Duration:   run.Duration,
+	}
+}
@@ -200,6 +200,20 @@ func (app *App) SetPublishedToMarketplace(publishedToMarketpalce bool, modifyUse
 	app.InitUpdatedAt()
 }
 
+// SetReleaseEnvironment bind the released versions to the resource environment.
+func (app *App) SetReleaseEnvironment(environment string, userID int) {
+	appConfig := app.ExportConfig()
+	appConfig.SetReleaseEnvironment(environment)
+	app.Config = appConfig.ExportToJSONString()
+	app.UpdatedBy = userID
+	app.InitUpdatedAt()
+}
+
+// ExportEnvironmentByAction return the resource environment the action runs with, by the app version of action.
+func (app *App) ExportEnvironmentByAction(action *Action) string {
+	return app.ExportConfig().ExportEnvironmentByVersion(action.Version)
+}
+
 func (app *App) SetID(appID int) {
 	app.ID = appID
 }
@@ -0,0 +1,252 @@
+package model
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/illacloud/builder-backend/src/utils/resourcelist"
+)
+
+// APP_BUNDLE_FORMAT_VERSION is the version of bundle layout, bump it when the layout changed in an incompatible way.
+const APP_BUNDLE_FORMAT_VERSION = 1
+
+const APP_BUNDLE_RESOURCE_PLACEHOLDER_PREFIX = "resource_"
+
+// AppBundle is the portable form of an app, it can be imported into another team or self-hosted instance.
+// the resources used by actions are replaced by placeholders, the importer maps them to the existing resources.
+type AppBundle struct {
+	FormatVersion int                      `json:"formatVersion"`
+	ExportedAt    time.Time                `json:"exportedAt"`
+	App           *AppBundleApp            `json:"app"`
+	TreeStates    []*AppBundleTreeState    `json:"treeStates"`
+	KVStates      []*AppBundleKVState      `json:"kvStates"`
+	SetStates     []*AppBundleSetState     `json:"setStates"`
+	Actions       []*AppBundleAction       `json:"actions"`
+	Resources     []*AppBundleResourceSlot `json:"resources"`
+}
+
+type AppBundleApp struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	WaterMark   bool   `json:"waterMark"`
+	Cover       string `json:"cover"`
+}
+
+// AppBundleTreeState keep the original tree state id as bundle local id, the parent & children refs point to it.
+type AppBundleTreeState struct {
+	ID                 int    `json:"id"`
+	StateType          int    `json:"stateType"`
+	ParentNodeRefID    int    `json:"parentNodeRefID"`
+	ChildrenNodeRefIDs string `json:"childrenNodeRefIDs"`
+	Name               string `json:"name"`
+	Content            string `json:"content"`
+}
+
+type AppBundleKVState struct {
+	StateType int    `json:"stateType"`
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+}
+
+type AppBundleSetState struct {
+	StateType int    `json:"stateType"`
+	Value     string `json:"value"`
+}
+
+type AppBundleAction struct {
+	Name                string `json:"displayName"`
+	Type                string `json:"actionType"`
+	ResourcePlaceholder string `json:"resourcePlaceholder,omitempty"`
+	TriggerMode         string `json:"triggerMode"`
+	Transformer         string `json:"transformer"`
+	Template            string `json:"template"`
+	Config              string `json:"config"`
+}
+
+// AppBundleResourceSlot describe which resource the placeholder stands for, no options (and secrets) exported.
+type AppBundleResourceSlot struct {
+	Placeholder  string `json:"placeholder"`
+	ResourceName string `json:"resourceName"`
+	ResourceType string `json:"resourceType"`
+}
+
+func (bundle *AppBundle) ExportForFeedback() interface{} {
+	return bundle
+}
+
+// NewAppBundle build bundle from target version app data, the resources lookup table is map[resourceID]*Resource.
+func NewAppBundle(app *App, treeStates []*TreeState, kvStates []*KVState, setStates []*SetState, actions []*Action, resourcesLT map[int]*Resource) *AppBundle {
+	appConfig := app.ExportConfig()
+	bundle := &AppBundle{
+		FormatVersion: APP_BUNDLE_FORMAT_VERSION,
+		ExportedAt:    time.Now().UTC(),
+		App: &AppBundleApp{
+			Name:        app.ExportAppName(),
+			Description: appConfig.Description,
+			WaterMark:   appConfig.WaterMark,
+			Cover:       appConfig.Cover,
+		},
+		TreeStates: make([]*AppBundleTreeState, 0, len(treeStates)),
+		KVStates:   make([]*AppBundleKVState, 0, len(kvStates)),
+		SetStates:  make([]*AppBundleSetState, 0, len(setStates)),
+		Actions:    make([]*AppBundleAction, 0, len(actions)),
+		Resources:  make([]*AppBundleResourceSlot, 0),
+	}
+	for _, treeState := range treeStates {
+		bundle.TreeStates = append(bundle.TreeStates, &AppBundleTreeState{
+			ID:                 treeState.ID,
+			StateType:          treeState.StateType,
+			ParentNodeRefID:    treeState.ParentNodeRefID,
+			ChildrenNodeRefIDs: treeState.ChildrenNodeRefIDs,
+			Name:               treeState.Name,
+			Content:            treeState.Content,
+		})
+	}
+	for _, kvState := range kvStates {
+		bundle.KVStates = append(bundle.KVStates, &AppBundleKVState{
+			StateType: kvState.StateType,
+			Key:       kvState.Key,
+			Value:     kvState.Value,
+		})
+	}
+	for _, setState := range setStates {
+		bundle.SetStates = append(bundle.SetStates, &AppBundleSetState{
+			StateType: setState.StateType,
+			Value:     setState.Value,
+		})
+	}
+
+	// replace resource references by placeholders
+	placeholders := make(map[int]string)
+	for _, action := range actions {
+		bundleAction := &AppBundleAction{
+			Name:        action.Name,
+			Type:        action.ExportTypeInString(),
+			TriggerMode: action.TriggerMode,
+			Transformer: action.Transformer,
+			Template:    action.Template,
+			Config:      action.Config,
+		}
+		if !action.IsVirtualAction() && action.ExportResourceID() != 0 {
+			placeholder, hit := placeholders[action.ExportResourceID()]
+			if !hit {
+				placeholder = APP_BUNDLE_RESOURCE_PLACEHOLDER_PREFIX + strconv.Itoa(len(placeholders)+1)
+				placeholders[action.ExportResourceID()] = placeholder
+				slot := &AppBundleResourceSlot{
+					Placeholder:  placeholder,
+					ResourceType: action.ExportTypeInString(),
+				}
+				if resource, hitResource := resourcesLT[action.ExportResourceID()]; hitResource {
+					slot.ResourceName = resource.Name
+				}
+				bundle.Resources = append(bundle.Resources, slot)
+			}
+			bundleAction.ResourcePlaceholder = placeholder
+		}
+		bundle.Actions = append(bundle.Actions, bundleAction)
+	}
+	return bundle
+}
+
+func (bundle *AppBundle) Validate() error {
+	if bundle.FormatVersion == 0 || bundle.FormatVersion > APP_BUNDLE_FORMAT_VERSION {
+		return errors.New("unsupported app bundle format version: " + strconv.Itoa(bundle.FormatVersion))
+	}
+	if bundle.App == nil {
+		return errors.New("app bundle missing app info")
+	}
+	// the action type must be the type of resource it refers to
+	for _, bundleAction := range bundle.Actions {
+		if bundleAction.ResourcePlaceholder == "" {
+			continue
+		}
+		slot, hit := bundle.LookupResourceSlot(bundleAction.ResourcePlaceholder)
+		if !hit {
+			return errors.New("resource placeholder " + bundleAction.ResourcePlaceholder + " of action " + bundleAction.Name + " is not defined")
+		}
+		if slot.ResourceType != bundleAction.Type {
+			return errors.New("action " + bundleAction.Name + " type " + bundleAction.Type + " does not match resource placeholder " + slot.Placeholder + " type " + slot.ResourceType)
+		}
+	}
+	return nil
+}
+
+func (bundle *AppBundle) LookupResourceSlot(placeholder string) (*AppBundleResourceSlot, bool) {
+	for _, slot := range bundle.Resources {
+		if slot.Placeholder == placeholder {
+			return slot, true
+		}
+	}
+	return nil, false
+}
+
+// NewAppByAppBundle create a private edit version app by bundle info.
+func NewAppByAppBundle(bundle *AppBundle, appName string, teamID int, modifyUserID int) *App {
+	if appName == "" {
+		appName = bundle.App.Name
+	}
+	app := NewAppByCreateAppRequest(appName, teamID, modifyUserID)
+	appConfig := NewAppConfig()
+	appConfig.Description = bundle.App.Description
+	appConfig.WaterMark = bundle.App.WaterMark
+	appConfig.Cover = bundle.App.Cover
+	app.Config = appConfig.ExportToJSONString()
+	return app
+}
+
+func (bundleTreeState *AppBundleTreeState) ExportTreeState(app *App, modifyUserID int) *TreeState {
+	treeState := &TreeState{
+		StateType:          bundleTreeState.StateType,
+		ParentNodeRefID:    bundleTreeState.ParentNodeRefID,
+		ChildrenNodeRefIDs: bundleTreeState.ChildrenNodeRefIDs,
+		Name:               bundleTreeState.Name,
+		Content:            bundleTreeState.Content,
+	}
+	if treeState.ChildrenNodeRefIDs == "" {
+		treeState.ChildrenNodeRefIDs = "[]"
+	}
+	treeState.InitForFork(app.ExportTeamID(), app.ExportID(), APP_EDIT_VERSION, modifyUserID)
+	return treeState
+}
+
+func (bundleKVState *AppBundleKVState) ExportKVState(app *App, modifyUserID int) *KVState {
+	kvState := &KVState{
+		StateType: bundleKVState.StateType,
+		Key:       bundleKVState.Key,
+		Value:     bundleKVState.Value,
+	}
+	kvState.InitForFork(app.ExportTeamID(), app.ExportID(), APP_EDIT_VERSION, modifyUserID)
+	return kvState
+}
+
+func (bundleSetState *AppBundleSetState) ExportSetState(app *App, modifyUserID int) *SetState {
+	setState := &SetState{
+		StateType: bundleSetState.StateType,
+		Value:     bundleSetState.Value,
+	}
+	setState.InitForFork(app.ExportTeamID(), app.ExportID(), APP_EDIT_VERSION, modifyUserID)
+	return setState
+}
+
+// ExportAction build action for the imported app, the resource placeholder is resolved by resourceMapping (map[placeholder]resourceID).
+func (bundleAction *AppBundleAction) ExportAction(app *App, modifyUserID int, resourceMapping map[string]int) (*Action, error) {
+	action := &Action{
+		Name:        bundleAction.Name,
+		Type:        resourcelist.GetResourceNameMappedID(bundleAction.Type),
+		TriggerMode: bundleAction.TriggerMode,
+		Transformer: bundleAction.Transformer,
+		Template:    bundleAction.Template,
+		Config:      bundleAction.Config,
+	}
+	if bundleAction.ResourcePlaceholder != "" {
+		resourceID, hit := resourceMapping[bundleAction.ResourcePlaceholder]
+		if !hit {
+			return nil, errors.New("resource placeholder " + bundleAction.ResourcePlaceholder + " of action " + bundleAction.Name + " is not mapped")
+		}
+		action.ResourceRefID = resourceID
+	}
+	action.InitForFork(app.ExportTeamID(), app.ExportID(), APP_EDIT_VERSION, modifyUserID)
+	action.SetPrivate(modifyUserID)
+	return action, nil
+}
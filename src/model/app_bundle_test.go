@@ -0,0 +1,41 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/illacloud/builder-backend/src/utils/resourcelist"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppBundleResourcePlaceholder(t *testing.T) {
+	app := NewAppByCreateAppRequest("app", 1, 1)
+	actions := []*Action{
+		{Name: "query1", Type: resourcelist.TYPE_POSTGRESQL_ID, ResourceRefID: 10, Config: "{}"},
+		{Name: "query2", Type: resourcelist.TYPE_POSTGRESQL_ID, ResourceRefID: 10, Config: "{}"},
+		{Name: "transformer1", Type: resourcelist.TYPE_TRANSFORMER_ID, Config: "{}"},
+	}
+	resourcesLT := map[int]*Resource{10: {ID: 10, Name: "pg"}}
+	bundle := NewAppBundle(app, nil, nil, nil, actions, resourcesLT)
+	assert.Nil(t, bundle.Validate())
+	assert.Equal(t, 1, len(bundle.Resources))
+	assert.Equal(t, "pg", bundle.Resources[0].ResourceName)
+	assert.Equal(t, bundle.Actions[0].ResourcePlaceholder, bundle.Actions[1].ResourcePlaceholder)
+	assert.Equal(t, "", bundle.Actions[2].ResourcePlaceholder)
+
+	// import into another team
+	targetApp := NewAppByAppBundle(bundle, "", 2, 3)
+	assert.Equal(t, "app", targetApp.ExportAppName())
+	_, errInExportAction := bundle.Actions[0].ExportAction(targetApp, 3, map[string]int{})
+	assert.NotNil(t, errInExportAction, "unmapped placeholder should be rejected")
+	action, errInExportAction := bundle.Actions[0].ExportAction(targetApp, 3, map[string]int{bundle.Resources[0].Placeholder: 20})
+	assert.Nil(t, errInExportAction)
+	assert.Equal(t, 20, action.ExportResourceID())
+	assert.Equal(t, 2, action.TeamID)
+
+	// the action type must match the resource placeholder type
+	bundle.Actions[1].Type = resourcelist.GetResourceIDMappedType(resourcelist.TYPE_MYSQL_ID)
+	assert.NotNil(t, bundle.Validate())
+	slot, hit := bundle.LookupResourceSlot(bundle.Actions[0].ResourcePlaceholder)
+	assert.True(t, hit)
+	assert.Equal(t, resourcelist.GetResourceIDMappedType(resourcelist.TYPE_POSTGRESQL_ID), slot.ResourceType)
+}
@@ -24,6 +24,8 @@ const APP_CONFIG_FIELD_WATER_MARK = "waterMark"
 const APP_CONFIG_FIELD_DESCRIPTION = "description"
 const APP_CONFIG_FIELD_PUBLISHED_TO_MARKETPLACE = "publishedToMarketplace"
 const APP_CONFIG_FIELD_PUBLISH_WITH_AI_AGENT = "publishWithAIAgent"
+const APP_CONFIG_FIELD_EDIT_ENVIRONMENT = "editEnvironment"
+const APP_CONFIG_FIELD_RELEASE_ENVIRONMENT = "releaseEnvironment"
 
 type AppConfig struct {
 	Public                 bool   `json:"public"` // switch for public app (which can view by anonymous user)
@@ -32,6 +34,8 @@ type AppConfig struct {
 	PublishedToMarketplace bool   `json:"publishedToMarketplace"`
 	PublishWithAIAgent     bool   `json:"publishWithAIAgent"`
 	Cover                  string `json:"cover"`
+	EditEnvironment        string `json:"editEnvironment"`    // the resource environment of edit version, empty for the base options
+	ReleaseEnvironment     string `json:"releaseEnvironment"` // the resource environment of released versions, empty for the base options
 }
 
 func NewAppConfig() *AppConfig {
@@ -79,6 +83,19 @@ func (appConfig *AppConfig) SetCover(cover string) {
 	appConfig.Cover = cover
 }
 
+func (appConfig *AppConfig) SetReleaseEnvironment(environment string) {
+	appConfig.ReleaseEnvironment = environment
+}
+
+// ExportEnvironmentByVersion return the resource environment the app version runs with,
+// the edit version runs with the edit environment and the released versions run with the release environment.
+func (appConfig *AppConfig) ExportEnvironmentByVersion(version int) string {
+	if version == APP_EDIT_VERSION {
+		return appConfig.EditEnvironment
+	}
+	return appConfig.ReleaseEnvironment
+}
+
 func (appConfig *AppConfig) UpdateAppConfigByConfigAppRawRequest(rawReq map[string]interface{}) error {
 	assertPass := true
 	for key, value := range rawReq {
@@ -108,6 +125,22 @@ func (appConfig *AppConfig) UpdateAppConfigByConfigAppRawRequest(rawReq map[stri
 			if !assertPass {
 				return errors.New("update app config failed due to assert failed")
 			}
+		case APP_CONFIG_FIELD_EDIT_ENVIRONMENT:
+			appConfig.EditEnvironment, assertPass = value.(string)
+			if !assertPass {
+				return errors.New("update app config failed due to assert failed")
+			}
+			if appConfig.EditEnvironment != RESOURCE_ENVIRONMENT_DEFAULT && !IsValidResourceEnvironmentName(appConfig.EditEnvironment) {
+				return errors.New("update app config failed due to invalid environment name")
+			}
+		case APP_CONFIG_FIELD_RELEASE_ENVIRONMENT:
+			appConfig.ReleaseEnvironment, assertPass = value.(string)
+			if !assertPass {
+				return errors.New("update app config failed due to assert failed")
+			}
+			if appConfig.ReleaseEnvironment != RESOURCE_ENVIRONMENT_DEFAULT && !IsValidResourceEnvironmentName(appConfig.ReleaseEnvironment) {
+				return errors.New("update app config failed due to invalid environment name")
+			}
 		default:
 		}
 	}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
 	"github.com/illacloud/builder-backend/src/request"
 	"github.com/illacloud/builder-backend/src/utils/idconvertor"
 	"github.com/illacloud/builder-backend/src/utils/illaresourcemanagersdk"
@@ -128,6 +129,13 @@ func (action *FlowAction) InitForFork(teamID int, workflowID int, version int, u
 	action.InitUpdatedAt()
 }
 
+// RepointResource point the flow action to another resource of the same type, for deleting the referenced resource.
+func (action *FlowAction) RepointResource(resourceID int, userID int) {
+	action.ResourceID = resourceID
+	action.UpdatedBy = userID
+	action.InitUpdatedAt()
+}
+
 func (action *FlowAction) AppendNewVersion(newVersion int) {
 	action.CleanID()
 	action.InitUID()
@@ -214,6 +222,21 @@ func (action *FlowAction) UpdateFlowAcitonByUpdateFlowActionRequest(teamID int,
 	action.InitUpdatedAt()
 }
 
+func (action *FlowAction) IsMockEnabled() bool {
+	ac := action.ExportConfig()
+	return ac.FlowMockConfig.IsEnabled()
+}
+
+func (action *FlowAction) ExportMockResult() (common.RuntimeResult, error) {
+	ac := action.ExportConfig()
+	return ac.FlowMockConfig.ExportMockDataAsRuntimeResult()
+}
+
+func (action *FlowAction) ExportRunTimeout() time.Duration {
+	ac := action.ExportConfig()
+	return ac.FlowAdvancedConfig.ExportTimeout()
+}
+
 func (action *FlowAction) IsVirtualFlowAction() bool {
 	return resourcelist.IsVirtualResourceByIntType(action.Type)
 }
@@ -274,6 +297,10 @@ func (action *FlowAction) SetResourceIDByAiAgent(aiAgent *illaresourcemanagersdk
 	action.ResourceID = aiAgent.ExportIDInInt()
 }
 
+func (action *FlowAction) IsTriggerFlowAction() bool {
+	return action.Type == resourcelist.TYPE_TRIGGER_ID
+}
+
 func DoesFlowActionHasBeenCreated(actionID int) bool {
 	return actionID > INVALIED_ACTION_ID
 }
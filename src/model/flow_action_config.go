@@ -16,12 +16,14 @@ package model
 
 import (
 	"encoding/json"
+	"time"
 )
 
 type FlowActionConfig struct {
 	IsVirtualResource  bool                `json:"isVirtualResource"`
 	FlowAdvancedConfig *FlowAdvancedConfig `json:"advancedConfig"` // 2023_4_20: add advanced config for action
 	FlowMockConfig     *FlowMockConfig     `json:"mockConfig"`
+	NextFlowActions    []string            `json:"nextFlowActions"` // the display names of flow actions run after this one, they are the edges of workflow graph
 }
 
 type FlowAdvancedConfig struct {
@@ -32,6 +34,7 @@ type FlowAdvancedConfig struct {
 	IsPeriodically     bool     `json:"isPeriodically"`
 	PeriodInterval     string   `json:"periodInterval"`
 	Mock               string   `json:"mock"`
+	Timeout            int      `json:"timeout"` // action run timeout in milliseconds, 0 means use the server default
 }
 
 func NewFlowActionConfig() *FlowActionConfig {
@@ -51,6 +54,14 @@ func NewFlowActionConfig() *FlowActionConfig {
 	}
 }
 
+// ExportTimeout return the action run timeout, 0 when not set.
+func (ac *FlowAdvancedConfig) ExportTimeout() time.Duration {
+	if ac == nil || ac.Timeout <= 0 {
+		return 0
+	}
+	return time.Duration(ac.Timeout) * time.Millisecond
+}
+
 func (ac *FlowActionConfig) ExportToJSONString() string {
 	r, _ := json.Marshal(ac)
 	return string(r)
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"errors"
+)
+
+// SortFlowActionsByGraph return the flow actions reachable from the trigger along the workflow graph edges,
+// every flow action is placed after all of its reachable predecessors. the unreachable flow actions are not returned.
+func SortFlowActionsByGraph(triggerFlowAction *FlowAction, flowActions []*FlowAction) ([]*FlowAction, error) {
+	flowActionsByName := make(map[string]*FlowAction, len(flowActions))
+	for _, flowAction := range flowActions {
+		flowActionsByName[flowAction.ExportDisplayName()] = flowAction
+	}
+	exportNext := func(flowAction *FlowAction) ([]*FlowAction, error) {
+		nextFlowActions := make([]*FlowAction, 0)
+		for _, name := range flowAction.ExportConfig().NextFlowActions {
+			nextFlowAction, hit := flowActionsByName[name]
+			if !hit {
+				return nil, errors.New("flowAction " + flowAction.ExportDisplayName() + " links to unknown flowAction: " + name)
+			}
+			nextFlowActions = append(nextFlowActions, nextFlowAction)
+		}
+		return nextFlowActions, nil
+	}
+
+	// collect the reachable flow actions and count their predecessors
+	edges := make(map[string][]*FlowAction)
+	predecessorCounts := make(map[string]int)
+	reachable := []*FlowAction{triggerFlowAction}
+	visited := map[string]bool{triggerFlowAction.ExportDisplayName(): true}
+	for i := 0; i < len(reachable); i++ {
+		nextFlowActions, errInExportNext := exportNext(reachable[i])
+		if errInExportNext != nil {
+			return nil, errInExportNext
+		}
+		edges[reachable[i].ExportDisplayName()] = nextFlowActions
+		for _, nextFlowAction := range nextFlowActions {
+			predecessorCounts[nextFlowAction.ExportDisplayName()]++
+			if !visited[nextFlowAction.ExportDisplayName()] {
+				visited[nextFlowAction.ExportDisplayName()] = true
+				reachable = append(reachable, nextFlowAction)
+			}
+		}
+	}
+
+	// run the flow action when all of its predecessors ran
+	if predecessorCounts[triggerFlowAction.ExportDisplayName()] > 0 {
+		return nil, errors.New("workflow graph has a cycle")
+	}
+	sorted := make([]*FlowAction, 0, len(reachable)-1)
+	ready := []*FlowAction{triggerFlowAction}
+	for len(ready) > 0 {
+		flowAction := ready[0]
+		ready = ready[1:]
+		if flowAction != triggerFlowAction {
+			sorted = append(sorted, flowAction)
+		}
+		for _, nextFlowAction := range edges[flowAction.ExportDisplayName()] {
+			predecessorCounts[nextFlowAction.ExportDisplayName()]--
+			if predecessorCounts[nextFlowAction.ExportDisplayName()] == 0 {
+				ready = append(ready, nextFlowAction)
+			}
+		}
+	}
+	if len(sorted) != len(reachable)-1 {
+		return nil, errors.New("workflow graph has a cycle")
+	}
+	return sorted, nil
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newFlowActionInGraph(name string, nextFlowActions ...string) *FlowAction {
+	config := NewFlowActionConfig()
+	config.NextFlowActions = nextFlowActions
+	configInJSON, _ := json.Marshal(config)
+	return &FlowAction{Name: name, Config: string(configInJSON)}
+}
+
+func exportFlowActionNames(flowActions []*FlowAction) []string {
+	names := make([]string, 0, len(flowActions))
+	for _, flowAction := range flowActions {
+		names = append(names, flowAction.ExportDisplayName())
+	}
+	return names
+}
+
+func TestSortFlowActionsByGraph(t *testing.T) {
+	// trigger -> fetch -> (transform, notify), transform -> save, notify -> save, orphan is unreachable
+	trigger := newFlowActionInGraph("trigger1", "fetch")
+	flowActions := []*FlowAction{
+		newFlowActionInGraph("save"),
+		newFlowActionInGraph("orphan", "save"),
+		newFlowActionInGraph("notify", "save"),
+		newFlowActionInGraph("transform", "save"),
+		newFlowActionInGraph("fetch", "transform", "notify"),
+	}
+	sorted, errInSort := SortFlowActionsByGraph(trigger, flowActions)
+	assert.Nil(t, errInSort)
+	assert.Equal(t, []string{"fetch", "transform", "notify", "save"}, exportFlowActionNames(sorted))
+
+	// cycle
+	_, errInSort = SortFlowActionsByGraph(trigger, []*FlowAction{newFlowActionInGraph("fetch", "transform"), newFlowActionInGraph("transform", "fetch")})
+	assert.NotNil(t, errInSort)
+
+	// unknown edge
+	_, errInSort = SortFlowActionsByGraph(trigger, []*FlowAction{newFlowActionInGraph("fetch", "missing")})
+	assert.NotNil(t, errInSort)
+}
@@ -1,6 +1,18 @@
 package model
 
+import (
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+)
+
 type FlowMockConfig struct {
 	Enabled  bool   `json:"enabled"`
 	MockData string `json:"mockData"`
 }
+
+func (mc *FlowMockConfig) IsEnabled() bool {
+	return mc != nil && mc.Enabled
+}
+
+func (mc *FlowMockConfig) ExportMockDataAsRuntimeResult() (common.RuntimeResult, error) {
+	return NewRuntimeResultByMockData(mc.MockData)
+}
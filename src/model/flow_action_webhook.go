@@ -0,0 +1,85 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/illacloud/builder-backend/src/utils/secretcrypto"
+)
+
+const FLOW_ACTION_WEBHOOK_SECRET_LENGTH = 32
+
+// FlowActionWebhook is the inbound webhook of workflow trigger, it is keyed by the workflow and the trigger display name,
+// so all versions of the trigger share one webhook. the signing secret is random and encrypted at rest.
+type FlowActionWebhook struct {
+	ID          int       `gorm:"column:id;type:bigserial;primary_key"`
+	UID         uuid.UUID `gorm:"column:uid;type:uuid;not null"`
+	TeamID      int       `gorm:"column:team_id;type:bigserial"`
+	WorkflowID  int       `gorm:"column:workflow_id;type:bigint;not null"`
+	TriggerName string    `gorm:"column:trigger_name;type:varchar;size:255;not null"`
+	Secret      string    `gorm:"column:secret;type:text;not null"`
+	CreatedAt   time.Time `gorm:"column:created_at;type:timestamp;not null"`
+	CreatedBy   int       `gorm:"column:created_by;type:bigint;not null"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;type:timestamp;not null"`
+	UpdatedBy   int       `gorm:"column:updated_by;type:bigint;not null"`
+}
+
+func NewFlowActionWebhookByTriggerFlowAction(triggerFlowAction *FlowAction, userID int) (*FlowActionWebhook, error) {
+	webhook := &FlowActionWebhook{
+		TeamID:      triggerFlowAction.TeamID,
+		WorkflowID:  triggerFlowAction.WorkflowID,
+		TriggerName: triggerFlowAction.ExportDisplayName(),
+		CreatedBy:   userID,
+		UpdatedBy:   userID,
+	}
+	if errInRotate := webhook.RotateSecret(userID); errInRotate != nil {
+		return nil, errInRotate
+	}
+	webhook.InitUID()
+	webhook.InitCreatedAt()
+	return webhook, nil
+}
+
+func (webhook *FlowActionWebhook) InitUID() {
+	webhook.UID = uuid.New()
+}
+
+func (webhook *FlowActionWebhook) InitCreatedAt() {
+	webhook.CreatedAt = time.Now().UTC()
+}
+
+func (webhook *FlowActionWebhook) InitUpdatedAt() {
+	webhook.UpdatedAt = time.Now().UTC()
+}
+
+// RotateSecret replace the signing secret with a new random one, the deliveries signed by the old secret are rejected after it.
+func (webhook *FlowActionWebhook) RotateSecret(userID int) error {
+	secret := make([]byte, FLOW_ACTION_WEBHOOK_SECRET_LENGTH)
+	if _, errInRead := rand.Read(secret); errInRead != nil {
+		return errInRead
+	}
+	encrypted, errInEncrypt := secretcrypto.EncryptValue(hex.EncodeToString(secret))
+	if errInEncrypt != nil {
+		return errInEncrypt
+	}
+	webhook.Secret = encrypted
+	webhook.UpdatedBy = userID
+	webhook.InitUpdatedAt()
+	return nil
+}
+
+// ExportSecret export the decrypted signing secret.
+func (webhook *FlowActionWebhook) ExportSecret() (string, error) {
+	decrypted, errInDecrypt := secretcrypto.DecryptValue(webhook.Secret)
+	if errInDecrypt != nil {
+		return "", errInDecrypt
+	}
+	secret, assertPass := decrypted.(string)
+	if !assertPass || secret == "" {
+		return "", errors.New("invalid webhook secret")
+	}
+	return secret, nil
+}
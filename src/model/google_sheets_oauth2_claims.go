@@ -58,13 +58,10 @@ func GenerateGoogleSheetsOAuth2Token(teamID int, userID int, resourceID int, cre
 			},
 		},
 	}
-	fmt.Printf("[DUMP] createOAuthTokenRequest.claims(): %+v\n", claims)
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	conf := config.GetInstance()
 	accessToken, err := token.SignedString([]byte(conf.GetSecretKey()))
-	fmt.Printf("[DUMP] createOAuthTokenRequest.token(): %+v\n", token)
-	fmt.Printf("[DUMP] createOAuthTokenRequest.accessToken(): %+v\n", accessToken)
 
 	if err != nil {
 		return "", err
@@ -5,69 +5,104 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
 	"github.com/illacloud/builder-backend/src/request"
+	"github.com/illacloud/builder-backend/src/utils/config"
 	"github.com/illacloud/builder-backend/src/utils/resourcelist"
+	"github.com/mitchellh/mapstructure"
 )
 
+const RESOURCE_OPTION_FIELD_RESULT_LIMITS = "resultLimits"
+
 type Resource struct {
-	ID        int       `gorm:"column:id;type:bigserial;primary_key"`
-	UID       uuid.UUID `gorm:"column:uid;type:uuid;not null"`
-	TeamID    int       `gorm:"column:team_id;type:bigserial"`
-	Name      string    `gorm:"column:name;type:varchar;size:200;not null"`
-	Type      int       `gorm:"column:type;type:smallint;not null"`
-	Options   string    `gorm:"column:options;type:jsonb"`
-	CreatedAt time.Time `gorm:"column:created_at;type:timestamp;not null"`
-	CreatedBy int       `gorm:"column:created_by;type:bigint;not null"`
-	UpdatedAt time.Time `gorm:"column:updated_at;type:timestamp;not null"`
-	UpdatedBy int       `gorm:"column:updated_by;type:bigint;not null"`
+	ID           int       `gorm:"column:id;type:bigserial;primary_key"`
+	UID          uuid.UUID `gorm:"column:uid;type:uuid;not null"`
+	TeamID       int       `gorm:"column:team_id;type:bigserial"`
+	Name         string    `gorm:"column:name;type:varchar;size:200;not null"`
+	Type         int       `gorm:"column:type;type:smallint;not null"`
+	Options      string    `gorm:"column:options;type:jsonb"`
+	Environments string    `gorm:"column:environments;type:jsonb"`
+	CreatedAt    time.Time `gorm:"column:created_at;type:timestamp;not null"`
+	CreatedBy    int       `gorm:"column:created_by;type:bigint;not null"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;type:timestamp;not null"`
+	UpdatedBy    int       `gorm:"column:updated_by;type:bigint;not null"`
 }
 
 func NewResource() *Resource {
 	return &Resource{}
 }
 
-func NewResourceByCreateResourceRequest(teamID int, userID int, req *request.CreateResourceRequest) *Resource {
+func NewResourceByCreateResourceRequest(teamID int, userID int, req *request.CreateResourceRequest) (*Resource, error) {
 	resource := &Resource{
 		TeamID:    teamID,
 		Name:      req.ResourceName,
 		Type:      resourcelist.GetResourceNameMappedID(req.ResourceType),
-		Options:   req.ExportOptionsInString(),
 		CreatedBy: userID,
 		UpdatedBy: userID,
 	}
+	if errInSetOptions := resource.SetOptions(req.Content); errInSetOptions != nil {
+		return nil, errInSetOptions
+	}
+	if errInSetEnvironments := resource.SetEnvironments(req.ExportEnvironments()); errInSetEnvironments != nil {
+		return nil, errInSetEnvironments
+	}
 	resource.InitUID()
 	resource.InitCreatedAt()
 	resource.InitUpdatedAt()
-	return resource
+	return resource, nil
 }
 
-func NewResourceByTestResourceConnectionRequest(teamID int, userID int, req *request.TestResourceConnectionRequest) *Resource {
+// NewResourceByTestResourceConnectionRequest build a temp resource for test connection,
+// the masked secret fields will be filled by the exists resource options (if exists).
+func NewResourceByTestResourceConnectionRequest(teamID int, userID int, req *request.TestResourceConnectionRequest, existsOptions map[string]interface{}) (*Resource, error) {
 	resource := &Resource{
 		TeamID:    teamID,
 		Name:      req.ResourceName,
 		Type:      resourcelist.GetResourceNameMappedID(req.ResourceType),
-		Options:   req.ExportOptionsInString(),
 		CreatedBy: userID,
 		UpdatedBy: userID,
 	}
+	if errInSetOptions := resource.SetOptionsAndKeepMaskedSecret(req.Content, existsOptions); errInSetOptions != nil {
+		return nil, errInSetOptions
+	}
 	resource.InitUID()
 	resource.InitCreatedAt()
 	resource.InitUpdatedAt()
-	return resource
+	return resource, nil
 }
 
-func (resource *Resource) UpdateByUpdateResourceRequest(userID int, req *request.UpdateResourceRequest) {
+// UpdateByUpdateResourceRequest update resource, the masked secret fields in request will keep the existing value.
+func (resource *Resource) UpdateByUpdateResourceRequest(userID int, req *request.UpdateResourceRequest) error {
+	existsOptions, errInExportOptions := resource.ExportOptionsInMap()
+	if errInExportOptions != nil {
+		return errInExportOptions
+	}
+	existsEnvironments, errInExportEnvironments := resource.ExportEnvironmentsInMap()
+	if errInExportEnvironments != nil {
+		return errInExportEnvironments
+	}
 	resource.Name = req.ResourceName
 	resource.Type = resourcelist.GetResourceNameMappedID(req.ResourceType)
-	resource.Options = req.ExportOptionsInString()
+	if errInSetOptions := resource.SetOptionsAndKeepMaskedSecret(req.Content, existsOptions); errInSetOptions != nil {
+		return errInSetOptions
+	}
+	if req.IsEnvironmentsSet() {
+		if errInSetEnvironments := resource.SetEnvironmentsAndKeepMaskedSecret(req.ExportEnvironments(), existsEnvironments); errInSetEnvironments != nil {
+			return errInSetEnvironments
+		}
+	}
 	resource.UpdatedBy = userID
 	resource.InitUpdatedAt()
+	return nil
 }
 
-func (resource *Resource) UpdateGoogleSheetOAuth2Options(userID int, options *ResourceOptionGoogleSheets) {
-	resource.Options = options.ExportInString()
+func (resource *Resource) UpdateGoogleSheetOAuth2Options(userID int, options *ResourceOptionGoogleSheets) error {
+	if errInSetOptions := resource.SetOptions(options.ExportInMap()); errInSetOptions != nil {
+		return errInSetOptions
+	}
 	resource.UpdatedBy = userID
 	resource.InitUpdatedAt()
+	return nil
 }
 
 func (resource *Resource) CleanID() {
@@ -90,6 +125,10 @@ func (resource *Resource) ExportUpdatedAt() time.Time {
 	return resource.UpdatedAt
 }
 
+func (resource *Resource) ExportID() int {
+	return resource.ID
+}
+
 func (resource *Resource) ExportType() int {
 	return resource.Type
 }
@@ -98,12 +137,20 @@ func (resource *Resource) ExportTypeInString() string {
 	return resourcelist.GetResourceIDMappedType(resource.Type)
 }
 
-func (resource *Resource) ExportOptionsInMap() map[string]interface{} {
-	var options map[string]interface{}
-	json.Unmarshal([]byte(resource.Options), &options)
-	return options
-}
-
 func (resource *Resource) CanCreateOAuthToken() bool {
 	return resourcelist.CanCreateOAuthToken(resource.Type)
 }
+
+// ExportResultLimits export the result limits of resource options ("resultLimits": {"maxRows", "maxBytes"}),
+// the limits are capped by the server limits, and the server limits are used when not set.
+func (resource *Resource) ExportResultLimits() *common.ResultLimits {
+	serverLimits := common.NewResultLimits(config.GetInstance().GetActionResultMaxRows(), config.GetInstance().GetActionResultMaxBytes())
+	limits := common.NewResultLimits(0, 0)
+	// the result limits are not secret, so read them without decrypting the options
+	var options map[string]interface{}
+	json.Unmarshal([]byte(resource.Options), &options)
+	if rawLimits, hit := options[RESOURCE_OPTION_FIELD_RESULT_LIMITS]; hit {
+		mapstructure.WeakDecode(rawLimits, limits)
+	}
+	return limits.Cap(serverLimits)
+}
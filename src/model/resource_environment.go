@@ -0,0 +1,128 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"sort"
+)
+
+// the environment variants of resource options are keyed by environment name, like "staging" and "production".
+// every variant is a whole options of the resource type, the empty environment name runs with the base options.
+const RESOURCE_ENVIRONMENT_DEFAULT = ""
+const RESOURCE_ENVIRONMENTS_MAX_COUNT = 16
+
+var resourceEnvironmentNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+func IsValidResourceEnvironmentName(environment string) bool {
+	return resourceEnvironmentNameRegexp.MatchString(environment)
+}
+
+func validateResourceEnvironmentNames(environments map[string]map[string]interface{}) error {
+	if len(environments) > RESOURCE_ENVIRONMENTS_MAX_COUNT {
+		return errors.New("too many resource environments")
+	}
+	for environment := range environments {
+		if !IsValidResourceEnvironmentName(environment) {
+			return errors.New("invalid resource environment name: " + environment)
+		}
+	}
+	return nil
+}
+
+// SetEnvironments store the environment variants of options with secret fields encrypted.
+func (resource *Resource) SetEnvironments(environments map[string]map[string]interface{}) error {
+	if errInValidate := validateResourceEnvironmentNames(environments); errInValidate != nil {
+		return errInValidate
+	}
+	// always store an object, so the cleared environments are written by the whole resource update
+	environmentsEncrypted := make(map[string]map[string]interface{}, len(environments))
+	for environment, options := range environments {
+		optionsCopied := copyOptions(options)
+		if errInEncrypt := encryptSecretOptions(resource.Type, optionsCopied); errInEncrypt != nil {
+			return errInEncrypt
+		}
+		environmentsEncrypted[environment] = optionsCopied
+	}
+	environmentsInJSON, errInMarshal := json.Marshal(environmentsEncrypted)
+	if errInMarshal != nil {
+		return errInMarshal
+	}
+	resource.Environments = string(environmentsInJSON)
+	return nil
+}
+
+// SetEnvironmentsAndKeepMaskedSecret store the environment variants, the masked secret fields will keep the existing value of the same environment.
+func (resource *Resource) SetEnvironmentsAndKeepMaskedSecret(environments map[string]map[string]interface{}, existsEnvironments map[string]map[string]interface{}) error {
+	environmentsCopied := make(map[string]map[string]interface{}, len(environments))
+	for environment, options := range environments {
+		optionsCopied := copyOptions(options)
+		keepMaskedSecretOptions(resource.Type, optionsCopied, existsEnvironments[environment])
+		environmentsCopied[environment] = optionsCopied
+	}
+	return resource.SetEnvironments(environmentsCopied)
+}
+
+func (resource *Resource) exportEnvironmentsInRaw() map[string]map[string]interface{} {
+	environments := make(map[string]map[string]interface{})
+	if resource.Environments != "" {
+		json.Unmarshal([]byte(resource.Environments), &environments)
+	}
+	return environments
+}
+
+// ExportEnvironmentsInMap export the environment variants with secret fields decrypted.
+func (resource *Resource) ExportEnvironmentsInMap() (map[string]map[string]interface{}, error) {
+	environments := resource.exportEnvironmentsInRaw()
+	for environment, options := range environments {
+		if errInDecrypt := decryptSecretOptions(resource.Type, options); errInDecrypt != nil {
+			return nil, errors.New("decrypt resource options of environment " + environment + " failed: " + errInDecrypt.Error())
+		}
+	}
+	return environments, nil
+}
+
+// ExportEnvironmentsInMapWithSecretMasked export the environment variants for api response, the secret fields are masked.
+func (resource *Resource) ExportEnvironmentsInMapWithSecretMasked() map[string]map[string]interface{} {
+	environments := resource.exportEnvironmentsInRaw()
+	for _, options := range environments {
+		maskSecretOptions(resource.Type, options)
+	}
+	return environments
+}
+
+func (resource *Resource) ExportEnvironmentNames() []string {
+	names := make([]string, 0)
+	for environment := range resource.exportEnvironmentsInRaw() {
+		names = append(names, environment)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (resource *Resource) HasEnvironments() bool {
+	return len(resource.exportEnvironmentsInRaw()) > 0
+}
+
+// ExportEnvironmentVariant return a copy of resource running with the options of environment,
+// the copy keeps the base options when the resource has no environment variants at all.
+// it fails when the resource has variants but not the one of environment, the other environment must not run with the base options.
+// the copy is for running only, do not save it.
+func (resource *Resource) ExportEnvironmentVariant(environment string) (*Resource, error) {
+	variant := *resource
+	if environment == RESOURCE_ENVIRONMENT_DEFAULT {
+		return &variant, nil
+	}
+	environments := resource.exportEnvironmentsInRaw()
+	if len(environments) == 0 {
+		return &variant, nil
+	}
+	options, hit := environments[environment]
+	if !hit {
+		return nil, errors.New("resource " + resource.Name + " has no options of environment: " + environment)
+	}
+	// the variant options are still encrypted, they are decrypted by ExportOptionsInMap like the base options
+	optionsInJSON, _ := json.Marshal(options)
+	variant.Options = string(optionsInJSON)
+	return &variant, nil
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/illacloud/builder-backend/src/utils/resourcelist"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceEnvironmentVariant(t *testing.T) {
+	resource := &Resource{Type: resourcelist.TYPE_POSTGRESQL_ID}
+	assert.Nil(t, resource.SetOptions(map[string]interface{}{"host": "staging.local", "databasePassword": "staging-password"}))
+	errInSetEnvironments := resource.SetEnvironments(map[string]map[string]interface{}{
+		"production": {"host": "production.local", "databasePassword": "production-password"},
+	})
+	assert.Nil(t, errInSetEnvironments)
+	assert.False(t, strings.Contains(resource.Environments, "production-password"), "the secret of variant should be encrypted at rest")
+	assert.Equal(t, []string{"production"}, resource.ExportEnvironmentNames())
+	assert.Equal(t, RESOURCE_SECRET_MASK, resource.ExportEnvironmentsInMapWithSecretMasked()["production"]["databasePassword"])
+
+	// switch variant
+	productionResource, errInSwitch := resource.ExportEnvironmentVariant("production")
+	assert.Nil(t, errInSwitch)
+	production := mustExportOptionsInMap(t, productionResource)
+	assert.Equal(t, "production.local", production["host"])
+	assert.Equal(t, "production-password", production["databasePassword"])
+	defaultResource, errInSwitch := resource.ExportEnvironmentVariant(RESOURCE_ENVIRONMENT_DEFAULT)
+	assert.Nil(t, errInSwitch)
+	assert.Equal(t, "staging.local", mustExportOptionsInMap(t, defaultResource)["host"])
+	_, errInSwitch = resource.ExportEnvironmentVariant("missing")
+	assert.NotNil(t, errInSwitch, "missing variant should not fall back to base options")
+	withoutEnvironments := &Resource{Type: resourcelist.TYPE_POSTGRESQL_ID, Options: resource.Options}
+	baseResource, errInSwitch := withoutEnvironments.ExportEnvironmentVariant("production")
+	assert.Nil(t, errInSwitch, "the resource without variants should run with base options")
+	assert.Equal(t, "staging.local", mustExportOptionsInMap(t, baseResource)["host"])
+	assert.Equal(t, "staging.local", mustExportOptionsInMap(t, resource)["host"], "the resource should not be modified")
+
+	// keep masked secret of variant
+	masked := resource.ExportEnvironmentsInMapWithSecretMasked()
+	masked["production"]["host"] = "production2.local"
+	existsEnvironments, errInExportEnvironments := resource.ExportEnvironmentsInMap()
+	assert.Nil(t, errInExportEnvironments)
+	assert.Nil(t, resource.SetEnvironmentsAndKeepMaskedSecret(masked, existsEnvironments))
+	updatedEnvironments, errInExportEnvironments := resource.ExportEnvironmentsInMap()
+	assert.Nil(t, errInExportEnvironments)
+	assert.Equal(t, "production-password", updatedEnvironments["production"]["databasePassword"])
+
+	// invalid name
+	assert.NotNil(t, resource.SetEnvironments(map[string]map[string]interface{}{"prod env": {}}))
+}
+
+func TestAppConfigEnvironmentByVersion(t *testing.T) {
+	appConfig := &AppConfig{EditEnvironment: "staging"}
+	appConfig.SetReleaseEnvironment("production")
+	assert.Equal(t, "staging", appConfig.ExportEnvironmentByVersion(APP_EDIT_VERSION))
+	assert.Equal(t, "production", appConfig.ExportEnvironmentByVersion(3))
+}
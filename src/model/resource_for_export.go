@@ -11,30 +11,32 @@ import (
 )
 
 type ResourceForExport struct {
-	ID        string                 `json:"resourceID"`
-	UID       uuid.UUID              `json:"uid"`
-	TeamID    string                 `json:"teamID"`
-	Name      string                 `json:"resourceName" validate:"required"`
-	Type      string                 `json:"resourceType" validate:"required"`
-	Options   map[string]interface{} `json:"content" validate:"required"`
-	CreatedAt time.Time              `json:"createdAt,omitempty"`
-	CreatedBy string                 `json:"createdBy,omitempty"`
-	UpdatedAt time.Time              `json:"updatedAt,omitempty"`
-	UpdatedBy string                 `json:"updatedBy,omitempty"`
+	ID           string                            `json:"resourceID"`
+	UID          uuid.UUID                         `json:"uid"`
+	TeamID       string                            `json:"teamID"`
+	Name         string                            `json:"resourceName" validate:"required"`
+	Type         string                            `json:"resourceType" validate:"required"`
+	Options      map[string]interface{}            `json:"content" validate:"required"`
+	Environments map[string]map[string]interface{} `json:"environments"`
+	CreatedAt    time.Time                         `json:"createdAt,omitempty"`
+	CreatedBy    string                            `json:"createdBy,omitempty"`
+	UpdatedAt    time.Time                         `json:"updatedAt,omitempty"`
+	UpdatedBy    string                            `json:"updatedBy,omitempty"`
 }
 
 func NewResourceForExport(r *Resource) *ResourceForExport {
 	return &ResourceForExport{
-		ID:        idconvertor.ConvertIntToString(r.ID),
-		UID:       r.UID,
-		TeamID:    idconvertor.ConvertIntToString(r.TeamID),
-		Name:      r.Name,
-		Type:      resourcelist.GetResourceIDMappedType(r.Type),
-		Options:   r.ExportOptionsInMap(),
-		CreatedAt: r.CreatedAt,
-		CreatedBy: idconvertor.ConvertIntToString(r.CreatedBy),
-		UpdatedAt: r.UpdatedAt,
-		UpdatedBy: idconvertor.ConvertIntToString(r.UpdatedBy),
+		ID:           idconvertor.ConvertIntToString(r.ID),
+		UID:          r.UID,
+		TeamID:       idconvertor.ConvertIntToString(r.TeamID),
+		Name:         r.Name,
+		Type:         resourcelist.GetResourceIDMappedType(r.Type),
+		Options:      r.ExportOptionsInMapWithSecretMasked(),
+		Environments: r.ExportEnvironmentsInMapWithSecretMasked(),
+		CreatedAt:    r.CreatedAt,
+		CreatedBy:    idconvertor.ConvertIntToString(r.CreatedBy),
+		UpdatedAt:    r.UpdatedAt,
+		UpdatedBy:    idconvertor.ConvertIntToString(r.UpdatedBy),
 	}
 }
 
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// NewResourceMetaCacheKey build the meta info cache key by team, resource and the hash of the resolved options,
+// so the cached meta info is missed once the options, the environment or the referenced team variables changed.
+func NewResourceMetaCacheKey(teamID int, resourceID int, resourceType int, options map[string]interface{}) string {
+	payload, _ := json.Marshal(options)
+	digest := sha256.Sum256(payload)
+	return strings.Join([]string{
+		strconv.Itoa(teamID),
+		strconv.Itoa(resourceID),
+		strconv.Itoa(resourceType),
+		hex.EncodeToString(digest[:]),
+	}, ":")
+}
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/illacloud/builder-backend/src/utils/resourcelist"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewResourceMetaCacheKey(t *testing.T) {
+	options := map[string]interface{}{"host": "db.example.com", "port": "5432", "databaseName": "app"}
+	key := NewResourceMetaCacheKey(1, 2, resourcelist.TYPE_POSTGRESQL_ID, options)
+	assert.Equal(t, key, NewResourceMetaCacheKey(1, 2, resourcelist.TYPE_POSTGRESQL_ID, map[string]interface{}{"databaseName": "app", "port": "5432", "host": "db.example.com"}))
+	assert.NotEqual(t, key, NewResourceMetaCacheKey(1, 2, resourcelist.TYPE_POSTGRESQL_ID, map[string]interface{}{"host": "db.example.com", "port": "5432", "databaseName": "staging"}), "the changed options invalidate the key")
+	assert.NotEqual(t, key, NewResourceMetaCacheKey(1, 3, resourcelist.TYPE_POSTGRESQL_ID, options))
+}
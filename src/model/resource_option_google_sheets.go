@@ -2,7 +2,6 @@ package model
 
 import (
 	"encoding/json"
-	"fmt"
 
 	"github.com/illacloud/builder-backend/src/utils/oauthgoogle"
 	"github.com/mitchellh/mapstructure"
@@ -28,9 +27,11 @@ type ResourceOptionGoogleSheets struct {
 }
 
 func NewResourceOptionGoogleSheetsByResource(resource *Resource) (*ResourceOptionGoogleSheets, error) {
-	fmt.Printf("[DUMP] NewResourceOptionGoogleSheetsByResource().resource.Options: %+v\n", resource.Options)
 	resourceOptionGoogleSheets := &ResourceOptionGoogleSheets{}
-	resourceOptions := resource.ExportOptionsInMap()
+	resourceOptions, errInExportOptions := resource.ExportOptionsInMap()
+	if errInExportOptions != nil {
+		return nil, errInExportOptions
+	}
 	errInDecode := mapstructure.Decode(resourceOptions, &resourceOptionGoogleSheets)
 	if errInDecode != nil {
 		return nil, errInDecode
@@ -41,8 +42,6 @@ func NewResourceOptionGoogleSheetsByResource(resource *Resource) (*ResourceOptio
 		return nil, errInDecodeSub
 	}
 	resourceOptionGoogleSheets.Options = opts
-	jstr, _ := json.Marshal(resourceOptionGoogleSheets)
-	fmt.Printf("[DUMP] NewResourceOptionGoogleSheetsByResource().resourceOptionGoogleSheets: %+v\n", string(jstr))
 	return resourceOptionGoogleSheets, nil
 }
 
@@ -70,3 +69,10 @@ func (i *ResourceOptionGoogleSheets) ExportInString() string {
 	byteData, _ := json.Marshal(i)
 	return string(byteData)
 }
+
+func (i *ResourceOptionGoogleSheets) ExportInMap() map[string]interface{} {
+	var options map[string]interface{}
+	byteData, _ := json.Marshal(i)
+	json.Unmarshal(byteData, &options)
+	return options
+}
@@ -0,0 +1,201 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/illacloud/builder-backend/src/utils/resourcelist"
+	"github.com/illacloud/builder-backend/src/utils/secretcrypto"
+)
+
+// RESOURCE_SECRET_MASK replace the secret fields in api response,
+// the client send it back in update request means keep the existing secret.
+const RESOURCE_SECRET_MASK = "********"
+
+// lookupOptionField return the parent map and the key of the field path, the key is matched case insensitively.
+func lookupOptionField(options map[string]interface{}, fieldPath string) (map[string]interface{}, string, bool) {
+	keys := strings.Split(fieldPath, ".")
+	current := options
+	for i, key := range keys {
+		matchedKey, hit := matchOptionKey(current, key)
+		if !hit {
+			return nil, "", false
+		}
+		if i == len(keys)-1 {
+			return current, matchedKey, true
+		}
+		next, assertPass := current[matchedKey].(map[string]interface{})
+		if !assertPass {
+			return nil, "", false
+		}
+		current = next
+	}
+	return nil, "", false
+}
+
+func matchOptionKey(options map[string]interface{}, key string) (string, bool) {
+	if _, hit := options[key]; hit {
+		return key, true
+	}
+	for optionKey := range options {
+		if strings.EqualFold(optionKey, key) {
+			return optionKey, true
+		}
+	}
+	return "", false
+}
+
+func isEmptyOptionValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	valueAsserted, assertPass := value.(string)
+	return assertPass && valueAsserted == ""
+}
+
+func encryptSecretOptions(resourceType int, options map[string]interface{}) error {
+	for _, fieldPath := range resourcelist.GetSecretFieldsByIntType(resourceType) {
+		parent, key, hit := lookupOptionField(options, fieldPath)
+		if !hit || isEmptyOptionValue(parent[key]) || secretcrypto.IsEncryptedValue(parent[key]) {
+			continue
+		}
+		encrypted, errInEncrypt := secretcrypto.EncryptValue(parent[key])
+		if errInEncrypt != nil {
+			return errInEncrypt
+		}
+		parent[key] = encrypted
+	}
+	return nil
+}
+
+func decryptSecretOptions(resourceType int, options map[string]interface{}) error {
+	for _, fieldPath := range resourcelist.GetSecretFieldsByIntType(resourceType) {
+		parent, key, hit := lookupOptionField(options, fieldPath)
+		if !hit {
+			continue
+		}
+		decrypted, errInDecrypt := secretcrypto.DecryptValue(parent[key])
+		if errInDecrypt != nil {
+			return errInDecrypt
+		}
+		parent[key] = decrypted
+	}
+	return nil
+}
+
+func maskSecretOptions(resourceType int, options map[string]interface{}) {
+	for _, fieldPath := range resourcelist.GetSecretFieldsByIntType(resourceType) {
+		parent, key, hit := lookupOptionField(options, fieldPath)
+		if !hit || isEmptyOptionValue(parent[key]) {
+			continue
+		}
+		parent[key] = RESOURCE_SECRET_MASK
+	}
+}
+
+// keepMaskedSecretOptions fill the masked secret fields in new options with the existing ones.
+func keepMaskedSecretOptions(resourceType int, newOptions map[string]interface{}, existsOptions map[string]interface{}) {
+	for _, fieldPath := range resourcelist.GetSecretFieldsByIntType(resourceType) {
+		parent, key, hit := lookupOptionField(newOptions, fieldPath)
+		if !hit || parent[key] != RESOURCE_SECRET_MASK {
+			continue
+		}
+		existsParent, existsKey, existsHit := lookupOptionField(existsOptions, fieldPath)
+		if !existsHit {
+			parent[key] = ""
+			continue
+		}
+		parent[key] = existsParent[existsKey]
+	}
+}
+
+// copyOptions deep copy the options by json round trip, so the request content will not be modified.
+func copyOptions(options map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{})
+	optionsInJSON, _ := json.Marshal(options)
+	json.Unmarshal(optionsInJSON, &copied)
+	return copied
+}
+
+// SetOptions store the options with secret fields encrypted.
+func (resource *Resource) SetOptions(options map[string]interface{}) error {
+	optionsCopied := copyOptions(options)
+	if errInEncrypt := encryptSecretOptions(resource.Type, optionsCopied); errInEncrypt != nil {
+		return errInEncrypt
+	}
+	optionsInJSON, errInMarshal := json.Marshal(optionsCopied)
+	if errInMarshal != nil {
+		return errInMarshal
+	}
+	resource.Options = string(optionsInJSON)
+	return nil
+}
+
+// SetOptionsAndKeepMaskedSecret store the options, the masked secret fields will keep the existing value.
+func (resource *Resource) SetOptionsAndKeepMaskedSecret(options map[string]interface{}, existsOptions map[string]interface{}) error {
+	optionsCopied := copyOptions(options)
+	keepMaskedSecretOptions(resource.Type, optionsCopied, existsOptions)
+	return resource.SetOptions(optionsCopied)
+}
+
+// ExportOptionsInMap export the options with secret fields decrypted, for running the connector.
+// it fails when any secret field can not be decrypted, the connector must not run with the encrypted value.
+func (resource *Resource) ExportOptionsInMap() (map[string]interface{}, error) {
+	var options map[string]interface{}
+	json.Unmarshal([]byte(resource.Options), &options)
+	if options == nil {
+		return options, nil
+	}
+	if errInDecrypt := decryptSecretOptions(resource.Type, options); errInDecrypt != nil {
+		return nil, errors.New("decrypt resource options failed: " + errInDecrypt.Error())
+	}
+	return options, nil
+}
+
+// ExportOptionsInMapWithSecretMasked export the options for api response, the secret fields are masked.
+func (resource *Resource) ExportOptionsInMapWithSecretMasked() map[string]interface{} {
+	var options map[string]interface{}
+	json.Unmarshal([]byte(resource.Options), &options)
+	if options == nil {
+		return options
+	}
+	maskSecretOptions(resource.Type, options)
+	return options
+}
+
+func hasPlaintextSecretOptions(resourceType int, options map[string]interface{}) bool {
+	for _, fieldPath := range resourcelist.GetSecretFieldsByIntType(resourceType) {
+		parent, key, hit := lookupOptionField(options, fieldPath)
+		if hit && !isEmptyOptionValue(parent[key]) && !secretcrypto.IsEncryptedValue(parent[key]) {
+			return true
+		}
+	}
+	return false
+}
+
+// EncryptPlaintextSecrets encrypt the plaintext secret fields of options and environment variants, which were saved before the encryption.
+// it returns false when all secret fields are encrypted already, then the resource need not to be saved.
+func (resource *Resource) EncryptPlaintextSecrets() (bool, error) {
+	encrypted := false
+	var options map[string]interface{}
+	json.Unmarshal([]byte(resource.Options), &options)
+	if options != nil && hasPlaintextSecretOptions(resource.Type, options) {
+		if errInSetOptions := resource.SetOptions(options); errInSetOptions != nil {
+			return false, errInSetOptions
+		}
+		encrypted = true
+	}
+	environments := resource.exportEnvironmentsInRaw()
+	for _, environmentOptions := range environments {
+		if !hasPlaintextSecretOptions(resource.Type, environmentOptions) {
+			continue
+		}
+		if errInSetEnvironments := resource.SetEnvironments(environments); errInSetEnvironments != nil {
+			return false, errInSetEnvironments
+		}
+		encrypted = true
+		break
+	}
+	return encrypted, nil
+}
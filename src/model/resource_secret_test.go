@@ -0,0 +1,114 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/illacloud/builder-backend/src/utils/resourcelist"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustExportOptionsInMap(t *testing.T, resource *Resource) map[string]interface{} {
+	options, errInExportOptions := resource.ExportOptionsInMap()
+	assert.Nil(t, errInExportOptions)
+	return options
+}
+
+func TestResourceSecretOptions(t *testing.T) {
+	resource := &Resource{Type: resourcelist.TYPE_POSTGRESQL_ID}
+	options := map[string]interface{}{
+		"host":             "127.0.0.1",
+		"databasePassword": "password",
+		"ssl": map[string]interface{}{
+			"ssl":       true,
+			"clientKey": "key",
+		},
+	}
+	errInSetOptions := resource.SetOptions(options)
+	assert.Nil(t, errInSetOptions)
+	assert.False(t, strings.Contains(resource.Options, "password"), "the secret should be encrypted at rest")
+	assert.Equal(t, "password", options["databasePassword"], "the input options should not be modified")
+
+	// decrypt
+	exported := mustExportOptionsInMap(t, resource)
+	assert.Equal(t, "password", exported["databasePassword"])
+	assert.Equal(t, "key", exported["ssl"].(map[string]interface{})["clientKey"])
+
+	// mask
+	masked := resource.ExportOptionsInMapWithSecretMasked()
+	assert.Equal(t, RESOURCE_SECRET_MASK, masked["databasePassword"])
+	assert.Equal(t, "127.0.0.1", masked["host"])
+
+	// keep existing secret when masked value sent back
+	masked["host"] = "127.0.0.2"
+	errInUpdate := resource.SetOptionsAndKeepMaskedSecret(masked, mustExportOptionsInMap(t, resource))
+	assert.Nil(t, errInUpdate)
+	updated := mustExportOptionsInMap(t, resource)
+	assert.Equal(t, "127.0.0.2", updated["host"])
+	assert.Equal(t, "password", updated["databasePassword"])
+	assert.Equal(t, "key", updated["ssl"].(map[string]interface{})["clientKey"])
+}
+
+func TestResourceLegacyPlaintextOptions(t *testing.T) {
+	resource := &Resource{
+		Type:    resourcelist.TYPE_MYSQL_ID,
+		Options: `{"host": "127.0.0.1", "databasePassword": "password"}`,
+	}
+	assert.Equal(t, "password", mustExportOptionsInMap(t, resource)["databasePassword"], "legacy plaintext secret should be readable")
+}
+
+func TestResourceSSHTunnelSecretOptions(t *testing.T) {
+	resource := &Resource{Type: resourcelist.TYPE_REDIS_ID}
+	options := map[string]interface{}{
+		"host":             "10.0.0.8",
+		"databasePassword": "password",
+		"sshTunnel": map[string]interface{}{
+			"enabled":    true,
+			"authMethod": "privateKey",
+			"privateKey": "private-key",
+			"passphrase": "secret-phrase",
+		},
+	}
+	errInSetOptions := resource.SetOptions(options)
+	assert.Nil(t, errInSetOptions)
+	assert.False(t, strings.Contains(resource.Options, "private-key"), "the ssh tunnel key should be encrypted at rest")
+	assert.False(t, strings.Contains(resource.Options, "secret-phrase"), "the ssh tunnel passphrase should be encrypted at rest")
+
+	masked := resource.ExportOptionsInMapWithSecretMasked()
+	assert.Equal(t, RESOURCE_SECRET_MASK, masked["sshTunnel"].(map[string]interface{})["privateKey"])
+	assert.Equal(t, "private-key", mustExportOptionsInMap(t, resource)["sshTunnel"].(map[string]interface{})["privateKey"])
+}
+
+func TestResourceUndecryptableOptions(t *testing.T) {
+	resource := &Resource{
+		Type:    resourcelist.TYPE_MYSQL_ID,
+		Options: `{"host": "127.0.0.1", "databasePassword": "illa-enc:v1:broken"}`,
+	}
+	_, errInExportOptions := resource.ExportOptionsInMap()
+	assert.NotNil(t, errInExportOptions, "the connector should not run with the encrypted secret")
+}
+
+func TestResourceEncryptPlaintextSecrets(t *testing.T) {
+	resource := &Resource{
+		Type:         resourcelist.TYPE_MYSQL_ID,
+		Options:      `{"host": "127.0.0.1", "databasePassword": "password"}`,
+		Environments: `{"staging": {"host": "10.0.0.8", "databasePassword": "staging-password"}}`,
+	}
+	encrypted, errInEncrypt := resource.EncryptPlaintextSecrets()
+	assert.Nil(t, errInEncrypt)
+	assert.True(t, encrypted)
+	assert.False(t, strings.Contains(resource.Options, "password"), "the legacy secret should be encrypted at rest")
+	assert.False(t, strings.Contains(resource.Environments, "staging-password"), "the legacy secret of environment should be encrypted at rest")
+	assert.Equal(t, "password", mustExportOptionsInMap(t, resource)["databasePassword"])
+	environments, errInExportEnvironments := resource.ExportEnvironmentsInMap()
+	assert.Nil(t, errInExportEnvironments)
+	assert.Equal(t, "staging-password", environments["staging"]["databasePassword"])
+
+	// the encrypted secrets are kept as is
+	options, environmentsInJSON := resource.Options, resource.Environments
+	encrypted, errInEncrypt = resource.EncryptPlaintextSecrets()
+	assert.Nil(t, errInEncrypt)
+	assert.False(t, encrypted)
+	assert.Equal(t, options, resource.Options)
+	assert.Equal(t, environmentsInJSON, resource.Environments)
+}
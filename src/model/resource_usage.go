@@ -0,0 +1,59 @@
+package model
+
+import (
+	"github.com/illacloud/builder-backend/src/utils/resourcelist"
+)
+
+// ResourceUsage is the actions and flow actions of all versions referencing the resource.
+type ResourceUsage struct {
+	ResourceID  int
+	Actions     []*Action
+	FlowActions []*FlowAction
+}
+
+// NewResourceUsage collect the usage of resource, the virtual resource actions are skipped,
+// their reference id points to other units like ai agent and only collides with the resource id.
+func NewResourceUsage(resourceID int, actions []*Action, flowActions []*FlowAction) *ResourceUsage {
+	resourceUsage := &ResourceUsage{
+		ResourceID:  resourceID,
+		Actions:     make([]*Action, 0),
+		FlowActions: make([]*FlowAction, 0),
+	}
+	for _, action := range actions {
+		if action.ExportResourceID() == resourceID && !resourcelist.IsVirtualResourceByIntType(action.ExportType()) {
+			resourceUsage.Actions = append(resourceUsage.Actions, action)
+		}
+	}
+	for _, flowAction := range flowActions {
+		if flowAction.ExportResourceID() == resourceID && !resourcelist.IsVirtualResourceByIntType(flowAction.ExportType()) {
+			resourceUsage.FlowActions = append(resourceUsage.FlowActions, flowAction)
+		}
+	}
+	return resourceUsage
+}
+
+func (resourceUsage *ResourceUsage) IsInUse() bool {
+	return len(resourceUsage.Actions) > 0 || len(resourceUsage.FlowActions) > 0
+}
+
+func (resourceUsage *ResourceUsage) ExportAppIDs() []int {
+	appIDs := make([]int, 0)
+	appIDsHit := make(map[int]bool)
+	for _, action := range resourceUsage.Actions {
+		if !appIDsHit[action.AppRefID] {
+			appIDsHit[action.AppRefID] = true
+			appIDs = append(appIDs, action.AppRefID)
+		}
+	}
+	return appIDs
+}
+
+// RepointResource point all actions and flow actions to the given resource, the caller should save them.
+func (resourceUsage *ResourceUsage) RepointResource(resourceID int, userID int) {
+	for _, action := range resourceUsage.Actions {
+		action.RepointResource(resourceID, userID)
+	}
+	for _, flowAction := range resourceUsage.FlowActions {
+		flowAction.RepointResource(resourceID, userID)
+	}
+}
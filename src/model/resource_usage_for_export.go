@@ -0,0 +1,116 @@
+package model
+
+import (
+	"sort"
+
+	"github.com/illacloud/builder-backend/src/utils/idconvertor"
+)
+
+// ResourceUsageForExport is the usage of resource grouped by app or workflow and version.
+type ResourceUsageForExport struct {
+	ResourceID      string                            `json:"resourceID"`
+	ActionCount     int                               `json:"actionCount"`
+	FlowActionCount int                               `json:"flowActionCount"`
+	Apps            []*ResourceUsageAppForExport      `json:"apps"`
+	Workflows       []*ResourceUsageWorkflowForExport `json:"workflows"`
+}
+
+type ResourceUsageAppForExport struct {
+	AppID    string                           `json:"appID"`
+	AppName  string                           `json:"appName"`
+	Versions []*ResourceUsageVersionForExport `json:"versions"`
+}
+
+type ResourceUsageWorkflowForExport struct {
+	WorkflowID string                           `json:"workflowID"`
+	Versions   []*ResourceUsageVersionForExport `json:"versions"`
+}
+
+type ResourceUsageVersionForExport struct {
+	Version int                             `json:"version"`
+	Actions []*ResourceUsageActionForExport `json:"actions"`
+}
+
+// ResourceUsageActionForExport is the action of app or the flow action of workflow.
+type ResourceUsageActionForExport struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// NewResourceUsageForExport export the usage, the apps are given for the app names.
+func NewResourceUsageForExport(resourceUsage *ResourceUsage, apps []*App) *ResourceUsageForExport {
+	appNames := make(map[int]string, len(apps))
+	for _, app := range apps {
+		appNames[app.ExportID()] = app.ExportAppName()
+	}
+
+	// group actions by app and version
+	appActions := make(map[int]map[int][]*ResourceUsageActionForExport)
+	for _, action := range resourceUsage.Actions {
+		if appActions[action.AppRefID] == nil {
+			appActions[action.AppRefID] = make(map[int][]*ResourceUsageActionForExport)
+		}
+		appActions[action.AppRefID][action.Version] = append(appActions[action.AppRefID][action.Version], &ResourceUsageActionForExport{
+			ID:   idconvertor.ConvertIntToString(action.ExportID()),
+			Name: action.Name,
+			Type: action.ExportTypeInString(),
+		})
+	}
+	appsForExport := make([]*ResourceUsageAppForExport, 0, len(appActions))
+	for _, appID := range sortedResourceUsageKeys(appActions) {
+		appsForExport = append(appsForExport, &ResourceUsageAppForExport{
+			AppID:    idconvertor.ConvertIntToString(appID),
+			AppName:  appNames[appID],
+			Versions: newResourceUsageVersionsForExport(appActions[appID]),
+		})
+	}
+
+	// group flow actions by workflow and version
+	workflowActions := make(map[int]map[int][]*ResourceUsageActionForExport)
+	for _, flowAction := range resourceUsage.FlowActions {
+		if workflowActions[flowAction.WorkflowID] == nil {
+			workflowActions[flowAction.WorkflowID] = make(map[int][]*ResourceUsageActionForExport)
+		}
+		workflowActions[flowAction.WorkflowID][flowAction.Version] = append(workflowActions[flowAction.WorkflowID][flowAction.Version], &ResourceUsageActionForExport{
+			ID:   idconvertor.ConvertIntToString(flowAction.ExportID()),
+			Name: flowAction.Name,
+			Type: flowAction.ExportTypeInString(),
+		})
+	}
+	workflowsForExport := make([]*ResourceUsageWorkflowForExport, 0, len(workflowActions))
+	for _, workflowID := range sortedResourceUsageKeys(workflowActions) {
+		workflowsForExport = append(workflowsForExport, &ResourceUsageWorkflowForExport{
+			WorkflowID: idconvertor.ConvertIntToString(workflowID),
+			Versions:   newResourceUsageVersionsForExport(workflowActions[workflowID]),
+		})
+	}
+
+	return &ResourceUsageForExport{
+		ResourceID:      idconvertor.ConvertIntToString(resourceUsage.ResourceID),
+		ActionCount:     len(resourceUsage.Actions),
+		FlowActionCount: len(resourceUsage.FlowActions),
+		Apps:            appsForExport,
+		Workflows:       workflowsForExport,
+	}
+}
+
+func newResourceUsageVersionsForExport(versionActions map[int][]*ResourceUsageActionForExport) []*ResourceUsageVersionForExport {
+	versions := make([]*ResourceUsageVersionForExport, 0, len(versionActions))
+	for _, version := range sortedResourceUsageKeys(versionActions) {
+		versions = append(versions, &ResourceUsageVersionForExport{
+			Version: version,
+			Actions: versionActions[version],
+		})
+	}
+	return versions
+}
+
+func sortedResourceUsageKeys[T any](m map[int]T) []int {
+	keys := make([]int, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	return keys
+}
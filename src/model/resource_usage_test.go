@@ -0,0 +1,34 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/illacloud/builder-backend/src/utils/resourcelist"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceUsage(t *testing.T) {
+	actions := []*Action{
+		{ID: 1, AppRefID: 10, Version: 0, ResourceRefID: 5, Name: "query1", Type: resourcelist.TYPE_POSTGRESQL_ID},
+		{ID: 2, AppRefID: 10, Version: 2, ResourceRefID: 5, Name: "query1", Type: resourcelist.TYPE_POSTGRESQL_ID},
+		{ID: 3, AppRefID: 11, Version: 0, ResourceRefID: 5, Name: "agent1", Type: resourcelist.TYPE_AI_AGENT_ID},
+	}
+	flowActions := []*FlowAction{
+		{ID: 4, WorkflowID: 20, Version: 0, ResourceID: 5, Name: "query2", Type: resourcelist.TYPE_POSTGRESQL_ID},
+	}
+	resourceUsage := NewResourceUsage(5, actions, flowActions)
+	assert.True(t, resourceUsage.IsInUse())
+	assert.Equal(t, 2, len(resourceUsage.Actions), "the ai agent action references the agent rather than the resource")
+	assert.Equal(t, []int{10}, resourceUsage.ExportAppIDs())
+
+	resourceUsageForExport := NewResourceUsageForExport(resourceUsage, []*App{{ID: 10, Name: "orders"}})
+	assert.Equal(t, 1, len(resourceUsageForExport.Apps))
+	assert.Equal(t, "orders", resourceUsageForExport.Apps[0].AppName)
+	assert.Equal(t, 2, len(resourceUsageForExport.Apps[0].Versions))
+	assert.Equal(t, 1, len(resourceUsageForExport.Workflows))
+
+	resourceUsage.RepointResource(6, 1)
+	assert.Equal(t, 6, actions[0].ExportResourceID())
+	assert.Equal(t, 6, flowActions[0].ExportResourceID())
+	assert.False(t, NewResourceUsage(5, nil, nil).IsInUse())
+}
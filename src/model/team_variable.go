@@ -0,0 +1,469 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/illacloud/builder-backend/src/request"
+	"github.com/illacloud/builder-backend/src/utils/secretcrypto"
+)
+
+// the team variables are referenced from resource options and action templates by placeholder like "%{vars.API_HOST}",
+// and the secrets are referenced like "%{secrets.API_KEY}". the placeholders are resolved on server side right before running,
+// so the secret value never leaves the server.
+const (
+	TEAM_VARIABLE_NAMESPACE_VARS    = "vars"
+	TEAM_VARIABLE_NAMESPACE_SECRETS = "secrets"
+)
+
+const TEAM_VARIABLE_VALUE_MAX_SIZE = 64 * 1024
+
+var teamVariableNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,127}$`)
+
+var teamVariablePlaceholderRegexp = regexp.MustCompile(`%\{\s*(vars|secrets)\.([A-Za-z_][A-Za-z0-9_]*)\s*\}`)
+
+// TeamVariable is the team level configuration variable or secret, the value of secret is encrypted at rest and write-only over the api.
+type TeamVariable struct {
+	ID          int       `gorm:"column:id;type:bigserial;primary_key"`
+	UID         uuid.UUID `gorm:"column:uid;type:uuid;not null"`
+	TeamID      int       `gorm:"column:team_id;type:bigserial"`
+	Name        string    `gorm:"column:name;type:varchar;size:128;not null"`
+	Value       string    `gorm:"column:value;type:text;not null"`
+	IsSecret    bool      `gorm:"column:is_secret;type:boolean;not null"`
+	Description string    `gorm:"column:description;type:varchar;size:255;not null"`
+	CreatedAt   time.Time `gorm:"column:created_at;type:timestamp;not null"`
+	CreatedBy   int       `gorm:"column:created_by;type:bigint;not null"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;type:timestamp;not null"`
+	UpdatedBy   int       `gorm:"column:updated_by;type:bigint;not null"`
+}
+
+func NewTeamVariableByCreateTeamVariableRequest(teamID int, userID int, req *request.CreateTeamVariableRequest) (*TeamVariable, error) {
+	if !IsValidTeamVariableName(req.Name) {
+		return nil, errors.New("invalid team variable name: " + req.Name)
+	}
+	teamVariable := &TeamVariable{
+		TeamID:      teamID,
+		Name:        req.Name,
+		IsSecret:    req.IsSecret,
+		Description: req.Description,
+		CreatedBy:   userID,
+		UpdatedBy:   userID,
+	}
+	if errInSetValue := teamVariable.SetValue(req.Value); errInSetValue != nil {
+		return nil, errInSetValue
+	}
+	teamVariable.InitUID()
+	teamVariable.InitCreatedAt()
+	teamVariable.InitUpdatedAt()
+	return teamVariable, nil
+}
+
+// UpdateByUpdateTeamVariableRequest update the variable, the masked value of secret means keep the existing value.
+func (teamVariable *TeamVariable) UpdateByUpdateTeamVariableRequest(userID int, req *request.UpdateTeamVariableRequest) error {
+	if !IsValidTeamVariableName(req.Name) {
+		return errors.New("invalid team variable name: " + req.Name)
+	}
+	teamVariable.Name = req.Name
+	teamVariable.Description = req.Description
+	if !(teamVariable.IsSecret && req.Value == RESOURCE_SECRET_MASK) {
+		if errInSetValue := teamVariable.SetValue(req.Value); errInSetValue != nil {
+			return errInSetValue
+		}
+	}
+	teamVariable.UpdatedBy = userID
+	teamVariable.InitUpdatedAt()
+	return nil
+}
+
+func (teamVariable *TeamVariable) InitUID() {
+	teamVariable.UID = uuid.New()
+}
+
+func (teamVariable *TeamVariable) InitCreatedAt() {
+	teamVariable.CreatedAt = time.Now().UTC()
+}
+
+func (teamVariable *TeamVariable) InitUpdatedAt() {
+	teamVariable.UpdatedAt = time.Now().UTC()
+}
+
+func (teamVariable *TeamVariable) ExportID() int {
+	return teamVariable.ID
+}
+
+func (teamVariable *TeamVariable) ExportNamespace() string {
+	if teamVariable.IsSecret {
+		return TEAM_VARIABLE_NAMESPACE_SECRETS
+	}
+	return TEAM_VARIABLE_NAMESPACE_VARS
+}
+
+// ExportPlaceholder export the placeholder referencing the variable, like "%{secrets.API_KEY}".
+func (teamVariable *TeamVariable) ExportPlaceholder() string {
+	return "%{" + teamVariable.ExportNamespace() + "." + teamVariable.Name + "}"
+}
+
+// SetValue store the value, the value of secret is encrypted.
+func (teamVariable *TeamVariable) SetValue(value string) error {
+	if len(value) > TEAM_VARIABLE_VALUE_MAX_SIZE {
+		return errors.New("team variable value is too large")
+	}
+	if !teamVariable.IsSecret || value == "" {
+		teamVariable.Value = value
+		return nil
+	}
+	encrypted, errInEncrypt := secretcrypto.EncryptValue(value)
+	if errInEncrypt != nil {
+		return errInEncrypt
+	}
+	teamVariable.Value = encrypted
+	return nil
+}
+
+// ExportValue export the value with secret decrypted, it is for resolving placeholders only.
+func (teamVariable *TeamVariable) ExportValue() (string, error) {
+	if !teamVariable.IsSecret {
+		return teamVariable.Value, nil
+	}
+	decrypted, errInDecrypt := secretcrypto.DecryptValue(teamVariable.Value)
+	if errInDecrypt != nil {
+		return "", errInDecrypt
+	}
+	value, _ := decrypted.(string)
+	return value, nil
+}
+
+// ExportValueForFeedback export the value for api response, the secret is always masked.
+func (teamVariable *TeamVariable) ExportValueForFeedback() string {
+	if teamVariable.IsSecret {
+		return RESOURCE_SECRET_MASK
+	}
+	return teamVariable.Value
+}
+
+func IsValidTeamVariableName(name string) bool {
+	return teamVariableNameRegexp.MatchString(name)
+}
+
+// TeamVariableResolver replace the team variable placeholders with the variable values.
+type TeamVariableResolver struct {
+	variables map[string]*TeamVariable
+}
+
+func NewTeamVariableResolver(teamVariables []*TeamVariable) *TeamVariableResolver {
+	resolver := &TeamVariableResolver{
+		variables: make(map[string]*TeamVariable, len(teamVariables)),
+	}
+	for _, teamVariable := range teamVariables {
+		resolver.variables[teamVariable.ExportNamespace()+"."+teamVariable.Name] = teamVariable
+	}
+	return resolver
+}
+
+// ResolveString replace the placeholders in s, the unknown placeholder is an error rather than sending it to the data source.
+func (resolver *TeamVariableResolver) ResolveString(s string) (string, error) {
+	var errInResolve error
+	resolved := teamVariablePlaceholderRegexp.ReplaceAllStringFunc(s, func(placeholder string) string {
+		if errInResolve != nil {
+			return placeholder
+		}
+		matches := teamVariablePlaceholderRegexp.FindStringSubmatch(placeholder)
+		teamVariable, hit := resolver.variables[matches[1]+"."+matches[2]]
+		if !hit {
+			errInResolve = errors.New("team variable not found: " + matches[1] + "." + matches[2])
+			return placeholder
+		}
+		value, errInExportValue := teamVariable.ExportValue()
+		if errInExportValue != nil {
+			errInResolve = errors.New("team variable " + matches[1] + "." + matches[2] + " can not be read: " + errInExportValue.Error())
+			return placeholder
+		}
+		return value
+	})
+	if errInResolve != nil {
+		return "", errInResolve
+	}
+	return resolved, nil
+}
+
+// ResolveValue resolve the placeholders in the strings of value recursively, the value is not modified.
+func (resolver *TeamVariableResolver) ResolveValue(value interface{}) (interface{}, error) {
+	switch valueAsserted := value.(type) {
+	case string:
+		return resolver.ResolveString(valueAsserted)
+	case map[string]interface{}:
+		resolved := make(map[string]interface{}, len(valueAsserted))
+		for key, subValue := range valueAsserted {
+			resolvedSubValue, errInResolve := resolver.ResolveValue(subValue)
+			if errInResolve != nil {
+				return nil, errInResolve
+			}
+			resolved[key] = resolvedSubValue
+		}
+		return resolved, nil
+	case []interface{}:
+		resolved := make([]interface{}, len(valueAsserted))
+		for i, subValue := range valueAsserted {
+			resolvedSubValue, errInResolve := resolver.ResolveValue(subValue)
+			if errInResolve != nil {
+				return nil, errInResolve
+			}
+			resolved[i] = resolvedSubValue
+		}
+		return resolved, nil
+	default:
+		return value, nil
+	}
+}
+
+// ResolveResource return a copy of resource with the placeholders in options resolved, the copy is for running only, do not save it.
+func (resolver *TeamVariableResolver) ResolveResource(resource *Resource) (*Resource, error) {
+	options, errInExportOptions := resource.ExportOptionsInMap()
+	if errInExportOptions != nil {
+		return nil, errInExportOptions
+	}
+	if !HasTeamVariablePlaceholders(options) {
+		return resource, nil
+	}
+	resolvedOptions, errInResolve := resolver.ResolveValue(options)
+	if errInResolve != nil {
+		return nil, errInResolve
+	}
+	resolved := *resource
+	if errInSetOptions := resolved.SetOptions(resolvedOptions.(map[string]interface{})); errInSetOptions != nil {
+		return nil, errInSetOptions
+	}
+	return &resolved, nil
+}
+
+// ResolveRunValue resolve the placeholders in the value supplied by client for running, the value is not modified.
+// the secret placeholders (and all placeholders when onlyPersisted) are resolved only where the persisted value references them,
+// see authorizeRunString.
+func (resolver *TeamVariableResolver) ResolveRunValue(runValue interface{}, persistedValue interface{}, onlyPersisted bool) (interface{}, error) {
+	switch runValueAsserted := runValue.(type) {
+	case string:
+		if errInAuthorize := authorizeRunString(runValueAsserted, persistedValue, onlyPersisted); errInAuthorize != nil {
+			return nil, errInAuthorize
+		}
+		return resolver.ResolveString(runValueAsserted)
+	case map[string]interface{}:
+		persistedMap, _ := persistedValue.(map[string]interface{})
+		resolved := make(map[string]interface{}, len(runValueAsserted))
+		for key, subValue := range runValueAsserted {
+			resolvedSubValue, errInResolve := resolver.ResolveRunValue(subValue, persistedMap[key], onlyPersisted)
+			if errInResolve != nil {
+				return nil, errInResolve
+			}
+			resolved[key] = resolvedSubValue
+		}
+		return resolved, nil
+	case []interface{}:
+		persistedSlice, _ := persistedValue.([]interface{})
+		resolved := make([]interface{}, len(runValueAsserted))
+		for i, subValue := range runValueAsserted {
+			var persistedSubValue interface{}
+			if i < len(persistedSlice) {
+				persistedSubValue = persistedSlice[i]
+			}
+			resolvedSubValue, errInResolve := resolver.ResolveRunValue(subValue, persistedSubValue, onlyPersisted)
+			if errInResolve != nil {
+				return nil, errInResolve
+			}
+			resolved[i] = resolvedSubValue
+		}
+		return resolved, nil
+	default:
+		return runValue, nil
+	}
+}
+
+// ResolveRunTemplate resolve the placeholders in the json template of action or flow action supplied by client for running,
+// the persisted template is the one saved by editor, the run context in raw template is left as is.
+func (resolver *TeamVariableResolver) ResolveRunTemplate(runTemplate string, persistedTemplate string, onlyPersisted bool) (string, error) {
+	var runTemplateInMap map[string]interface{}
+	if errInUnmarshal := json.Unmarshal([]byte(runTemplate), &runTemplateInMap); errInUnmarshal != nil || !HasTeamVariablePlaceholders(runTemplateInMap) {
+		return runTemplate, nil
+	}
+	var persistedTemplateInMap map[string]interface{}
+	json.Unmarshal([]byte(persistedTemplate), &persistedTemplateInMap)
+	resolvedTemplate, errInResolve := resolver.ResolveRunValue(runTemplateInMap, persistedTemplateInMap, onlyPersisted)
+	if errInResolve != nil {
+		return "", errInResolve
+	}
+	templateInByte, _ := json.Marshal(resolvedTemplate)
+	return string(templateInByte), nil
+}
+
+// the "{{ }}" expressions in persisted template are evaluated by client before running
+var runTemplateExpressionRegexp = regexp.MustCompile(`(?s)\{\{.*?\}\}`)
+
+// authorizeRunString check the placeholders in the string supplied by client are referenced by the persisted string at the same position.
+// the secret placeholders always need the authorization, and the variable placeholders need it when onlyPersisted (like anonymous run).
+// the client string must match the persisted string with the "{{ }}" expressions as wildcards,
+// and the placeholder in the wildcards comes from the run context, which is refused.
+func authorizeRunString(runString string, persistedValue interface{}, onlyPersisted bool) error {
+	var expressionIndexes []int
+	for _, placeholderIndex := range teamVariablePlaceholderRegexp.FindAllStringSubmatchIndex(runString, -1) {
+		namespace := runString[placeholderIndex[2]:placeholderIndex[3]]
+		reference := namespace + "." + runString[placeholderIndex[4]:placeholderIndex[5]]
+		if namespace != TEAM_VARIABLE_NAMESPACE_SECRETS && !onlyPersisted {
+			continue
+		}
+		if expressionIndexes == nil {
+			persistedString, ok := persistedValue.(string)
+			if !ok {
+				return errors.New("team variable " + reference + " can only be referenced in the saved action or resource")
+			}
+			expressionIndexes = newPersistedStringPattern(persistedString).FindStringSubmatchIndex(runString)
+			if expressionIndexes == nil {
+				return errors.New("team variable " + reference + " can only be referenced in the saved action or resource")
+			}
+		}
+		for i := 2; i+1 < len(expressionIndexes); i += 2 {
+			if expressionIndexes[i] >= 0 && placeholderIndex[0] < expressionIndexes[i+1] && expressionIndexes[i] < placeholderIndex[1] {
+				return errors.New("team variable " + reference + " can not be referenced in run context")
+			}
+		}
+	}
+	return nil
+}
+
+func newPersistedStringPattern(persistedString string) *regexp.Regexp {
+	var pattern strings.Builder
+	pattern.WriteString(`(?s)^`)
+	last := 0
+	for _, expressionIndex := range runTemplateExpressionRegexp.FindAllStringIndex(persistedString, -1) {
+		pattern.WriteString(regexp.QuoteMeta(persistedString[last:expressionIndex[0]]))
+		pattern.WriteString(`(.*?)`)
+		last = expressionIndex[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(persistedString[last:]))
+	pattern.WriteString(`$`)
+	return regexp.MustCompile(pattern.String())
+}
+
+// HasTeamVariablePlaceholders check if the strings of value reference any team variable.
+func HasTeamVariablePlaceholders(value interface{}) bool {
+	return hasTeamVariablePlaceholders(value, false)
+}
+
+// HasTeamSecretPlaceholders check if the strings of value reference any team secret.
+func HasTeamSecretPlaceholders(value interface{}) bool {
+	return hasTeamVariablePlaceholders(value, true)
+}
+
+func hasTeamVariablePlaceholders(value interface{}, secretsOnly bool) bool {
+	switch valueAsserted := value.(type) {
+	case string:
+		if !strings.Contains(valueAsserted, "%{") {
+			return false
+		}
+		for _, matches := range teamVariablePlaceholderRegexp.FindAllStringSubmatch(valueAsserted, -1) {
+			if !secretsOnly || matches[1] == TEAM_VARIABLE_NAMESPACE_SECRETS {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		for _, subValue := range valueAsserted {
+			if hasTeamVariablePlaceholders(subValue, secretsOnly) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, subValue := range valueAsserted {
+			if hasTeamVariablePlaceholders(subValue, secretsOnly) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TeamVariableRunTemplate is the template of action or flow action to resolve for running.
+// the Template is supplied by client and resolved in place, the Persisted is the template saved by editor which authorizes the placeholders,
+// and the RunContext is supplied by client, it must not reference any secret.
+type TeamVariableRunTemplate struct {
+	Template   *string
+	Persisted  string
+	RunContext interface{}
+}
+
+func NewTeamVariableRunTemplate(template *string, persisted string, runContext interface{}) *TeamVariableRunTemplate {
+	return &TeamVariableRunTemplate{
+		Template:   template,
+		Persisted:  persisted,
+		RunContext: runContext,
+	}
+}
+
+// NewPersistedTeamVariableRunTemplate build the run template for the template which is not supplied by client, like the scheduled run.
+func NewPersistedTeamVariableRunTemplate(template *string, runContext interface{}) *TeamVariableRunTemplate {
+	return NewTeamVariableRunTemplate(template, *template, runContext)
+}
+
+// ResolveTeamVariablesForRun resolve the placeholders in persisted resource options and run templates right before running,
+// the returned resource is a copy for running, and the run templates are resolved in place.
+// the placeholders in run templates are authorized by the persisted templates, see TeamVariableResolver.ResolveRunValue.
+// the team variables are retrieved only when placeholders are referenced.
+func ResolveTeamVariablesForRun(resource *Resource, retrieveTeamVariables func() ([]*TeamVariable, error), onlyPersisted bool, runTemplates ...*TeamVariableRunTemplate) (*Resource, error) {
+	options, errInExportOptions := resource.ExportOptionsInMap()
+	if errInExportOptions != nil {
+		return nil, errInExportOptions
+	}
+	referenced := HasTeamVariablePlaceholders(options)
+	for _, runTemplate := range runTemplates {
+		if hasTeamVariablePlaceholders(runTemplate.RunContext, !onlyPersisted) {
+			return nil, errors.New("team variable can not be referenced in run context")
+		}
+		referenced = referenced || strings.Contains(*runTemplate.Template, "%{")
+	}
+	if !referenced {
+		return resource, nil
+	}
+	teamVariables, errInRetrieveTeamVariables := retrieveTeamVariables()
+	if errInRetrieveTeamVariables != nil {
+		return nil, errInRetrieveTeamVariables
+	}
+	resolver := NewTeamVariableResolver(teamVariables)
+	resolvedResource, errInResolveResource := resolver.ResolveResource(resource)
+	if errInResolveResource != nil {
+		return nil, errInResolveResource
+	}
+	for _, runTemplate := range runTemplates {
+		resolvedTemplate, errInResolveTemplate := resolver.ResolveRunTemplate(*runTemplate.Template, runTemplate.Persisted, onlyPersisted)
+		if errInResolveTemplate != nil {
+			return nil, errInResolveTemplate
+		}
+		*runTemplate.Template = resolvedTemplate
+	}
+	return resolvedResource, nil
+}
+
+// ResolveTeamVariablesForTestConnection resolve the placeholders in the options of resource built from the test connection request,
+// the secret placeholders are resolved only where the persisted options (of the resource under editing) reference them.
+func ResolveTeamVariablesForTestConnection(resource *Resource, persistedOptions map[string]interface{}, retrieveTeamVariables func() ([]*TeamVariable, error)) (*Resource, error) {
+	options, errInExportOptions := resource.ExportOptionsInMap()
+	if errInExportOptions != nil {
+		return nil, errInExportOptions
+	}
+	if !HasTeamVariablePlaceholders(options) {
+		return resource, nil
+	}
+	teamVariables, errInRetrieveTeamVariables := retrieveTeamVariables()
+	if errInRetrieveTeamVariables != nil {
+		return nil, errInRetrieveTeamVariables
+	}
+	resolvedOptions, errInResolve := NewTeamVariableResolver(teamVariables).ResolveRunValue(options, persistedOptions, false)
+	if errInResolve != nil {
+		return nil, errInResolve
+	}
+	resolved := *resource
+	if errInSetOptions := resolved.SetOptions(resolvedOptions.(map[string]interface{})); errInSetOptions != nil {
+		return nil, errInSetOptions
+	}
+	return &resolved, nil
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"github.com/illacloud/builder-backend/src/utils/idconvertor"
+)
+
+type TeamVariableForExport struct {
+	ID          string    `json:"variableID"`
+	UID         string    `json:"uid"`
+	TeamID      string    `json:"teamID"`
+	Name        string    `json:"name"`
+	Value       string    `json:"value"`
+	IsSecret    bool      `json:"isSecret"`
+	Placeholder string    `json:"placeholder"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"createdAt"`
+	CreatedBy   string    `json:"createdBy"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	UpdatedBy   string    `json:"updatedBy"`
+}
+
+func NewTeamVariableForExport(teamVariable *TeamVariable) *TeamVariableForExport {
+	return &TeamVariableForExport{
+		ID:          idconvertor.ConvertIntToString(teamVariable.ID),
+		UID:         teamVariable.UID.String(),
+		TeamID:      idconvertor.ConvertIntToString(teamVariable.TeamID),
+		Name:        teamVariable.Name,
+		Value:       teamVariable.ExportValueForFeedback(),
+		IsSecret:    teamVariable.IsSecret,
+		Placeholder: teamVariable.ExportPlaceholder(),
+		Description: teamVariable.Description,
+		CreatedAt:   teamVariable.CreatedAt,
+		CreatedBy:   idconvertor.ConvertIntToString(teamVariable.CreatedBy),
+		UpdatedAt:   teamVariable.UpdatedAt,
+		UpdatedBy:   idconvertor.ConvertIntToString(teamVariable.UpdatedBy),
+	}
+}
+
+func (resp *TeamVariableForExport) ExportForFeedback() interface{} {
+	return resp
+}
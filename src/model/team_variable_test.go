@@ -0,0 +1,102 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/illacloud/builder-backend/src/request"
+	"github.com/illacloud/builder-backend/src/utils/resourcelist"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeamVariableSecretValue(t *testing.T) {
+	secret, errInNew := NewTeamVariableByCreateTeamVariableRequest(1, 1, &request.CreateTeamVariableRequest{Name: "API_KEY", Value: "key-1", IsSecret: true})
+	assert.Nil(t, errInNew)
+	assert.False(t, strings.Contains(secret.Value, "key-1"), "the secret should be encrypted at rest")
+	assert.Equal(t, RESOURCE_SECRET_MASK, NewTeamVariableForExport(secret).Value)
+	assert.Equal(t, "%{secrets.API_KEY}", secret.ExportPlaceholder())
+
+	// keep the secret when the masked value sent back
+	assert.Nil(t, secret.UpdateByUpdateTeamVariableRequest(1, &request.UpdateTeamVariableRequest{Name: "API_KEY", Value: RESOURCE_SECRET_MASK}))
+	value, _ := secret.ExportValue()
+	assert.Equal(t, "key-1", value)
+
+	_, errInNew = NewTeamVariableByCreateTeamVariableRequest(1, 1, &request.CreateTeamVariableRequest{Name: "API-KEY"})
+	assert.NotNil(t, errInNew)
+}
+
+func TestResolveTeamVariablesForRun(t *testing.T) {
+	host, _ := NewTeamVariableByCreateTeamVariableRequest(1, 1, &request.CreateTeamVariableRequest{Name: "API_HOST", Value: "api.example.com"})
+	key, _ := NewTeamVariableByCreateTeamVariableRequest(1, 1, &request.CreateTeamVariableRequest{Name: "API_KEY", Value: "key-1", IsSecret: true})
+	retrieved := 0
+	retrieveTeamVariables := func() ([]*TeamVariable, error) {
+		retrieved++
+		return []*TeamVariable{host, key}, nil
+	}
+
+	resource := &Resource{Type: resourcelist.TYPE_RESTAPI_ID}
+	assert.Nil(t, resource.SetOptions(map[string]interface{}{
+		"baseUrl": "https://%{vars.API_HOST}/v1",
+		"headers": []interface{}{map[string]interface{}{"key": "Authorization", "value": "Bearer %{ secrets.API_KEY }"}},
+	}))
+	template := `{"url": "/users", "body": "%{secrets.API_KEY}"}`
+	resolved, errInResolve := ResolveTeamVariablesForRun(resource, retrieveTeamVariables, false, NewPersistedTeamVariableRunTemplate(&template, nil))
+	assert.Nil(t, errInResolve)
+	options := mustExportOptionsInMap(t, resolved)
+	assert.Equal(t, "https://api.example.com/v1", options["baseUrl"])
+	assert.Equal(t, "Bearer key-1", options["headers"].([]interface{})[0].(map[string]interface{})["value"])
+	assert.Equal(t, `{"body":"key-1","url":"/users"}`, template)
+	assert.Equal(t, "https://%{vars.API_HOST}/v1", mustExportOptionsInMap(t, resource)["baseUrl"], "the resource should not be modified")
+
+	// the secret is not readable by the vars namespace
+	template = `{"body": "%{vars.API_KEY}"}`
+	_, errInResolve = ResolveTeamVariablesForRun(resource, retrieveTeamVariables, false, NewPersistedTeamVariableRunTemplate(&template, nil))
+	assert.NotNil(t, errInResolve)
+
+	// no placeholder, no retrieving
+	retrieved = 0
+	plain := &Resource{Type: resourcelist.TYPE_RESTAPI_ID, Options: `{"baseUrl": "https://example.com"}`}
+	_, errInResolve = ResolveTeamVariablesForRun(plain, func() ([]*TeamVariable, error) { return nil, errors.New("unexpected") }, false)
+	assert.Nil(t, errInResolve)
+	assert.Equal(t, 0, retrieved)
+}
+
+func TestResolveTeamVariablesForRunWithClientTemplate(t *testing.T) {
+	host, _ := NewTeamVariableByCreateTeamVariableRequest(1, 1, &request.CreateTeamVariableRequest{Name: "API_HOST", Value: "api.example.com"})
+	key, _ := NewTeamVariableByCreateTeamVariableRequest(1, 1, &request.CreateTeamVariableRequest{Name: "API_KEY", Value: "key-1", IsSecret: true})
+	retrieveTeamVariables := func() ([]*TeamVariable, error) { return []*TeamVariable{host, key}, nil }
+	resource := &Resource{Type: resourcelist.TYPE_RESTAPI_ID, Options: `{"baseUrl": "https://example.com"}`}
+	persisted := `{"url": "/users/{{ input.value }}", "body": "%{secrets.API_KEY}"}`
+
+	// the secret referenced by the saved template is resolved
+	template := `{"url": "/users/1", "body": "%{secrets.API_KEY}"}`
+	_, errInResolve := ResolveTeamVariablesForRun(resource, retrieveTeamVariables, false, NewTeamVariableRunTemplate(&template, persisted, nil))
+	assert.Nil(t, errInResolve)
+	assert.Equal(t, `{"body":"key-1","url":"/users/1"}`, template)
+
+	// the secret injected by client, into the template or into the evaluated expression, is refused
+	for _, template := range []string{
+		`{"url": "/users/1", "body": "%{secrets.API_KEY}", "query": "%{secrets.API_KEY}"}`,
+		`{"url": "/users/%{secrets.API_KEY}", "body": "%{secrets.API_KEY}"}`,
+	} {
+		_, errInResolve = ResolveTeamVariablesForRun(resource, retrieveTeamVariables, false, NewTeamVariableRunTemplate(&template, persisted, nil))
+		assert.NotNil(t, errInResolve, template)
+	}
+	template = `{"url": "/users/1", "body": "%{secrets.API_KEY}"}`
+	_, errInResolve = ResolveTeamVariablesForRun(resource, retrieveTeamVariables, false, NewTeamVariableRunTemplate(&template, persisted, map[string]interface{}{"input": "%{secrets.API_KEY}"}))
+	assert.NotNil(t, errInResolve)
+
+	// the variables can be injected by editor, but not by anonymous client
+	template = `{"url": "/users/%{vars.API_HOST}", "body": "%{secrets.API_KEY}"}`
+	_, errInResolve = ResolveTeamVariablesForRun(resource, retrieveTeamVariables, false, NewTeamVariableRunTemplate(&template, persisted, nil))
+	assert.Nil(t, errInResolve)
+	template = `{"url": "/users/%{vars.API_HOST}", "body": "%{secrets.API_KEY}"}`
+	_, errInResolve = ResolveTeamVariablesForRun(resource, retrieveTeamVariables, true, NewTeamVariableRunTemplate(&template, persisted, nil))
+	assert.NotNil(t, errInResolve)
+
+	// the unsaved template can not reference any secret
+	template = `{"body": "%{secrets.API_KEY}"}`
+	_, errInResolve = ResolveTeamVariablesForRun(resource, retrieveTeamVariables, false, NewTeamVariableRunTemplate(&template, "", nil))
+	assert.NotNil(t, errInResolve)
+}
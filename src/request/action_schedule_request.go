@@ -0,0 +1,23 @@
+package request
+
+type CreateActionScheduleRequest struct {
+	ActionName string `json:"actionName" validate:"required"`
+	Schedule   string `json:"schedule" validate:"required"` // cron expression like "*/5 * * * *", or "@every 10m"
+	Enabled    bool   `json:"enabled"`
+	PushToRoom bool   `json:"pushToRoom"`
+}
+
+func NewCreateActionScheduleRequest() *CreateActionScheduleRequest {
+	return &CreateActionScheduleRequest{}
+}
+
+type UpdateActionScheduleRequest struct {
+	ActionName string `json:"actionName" validate:"required"`
+	Schedule   string `json:"schedule" validate:"required"`
+	Enabled    bool   `json:"enabled"`
+	PushToRoom bool   `json:"pushToRoom"`
+}
+
+func NewUpdateActionScheduleRequest() *UpdateActionScheduleRequest {
+	return &UpdateActionScheduleRequest{}
+}
@@ -0,0 +1,117 @@
+package request
+
+import (
+	"fmt"
+
+	"github.com/illacloud/builder-backend/src/utils/idconvertor"
+)
+
+// The batch run actions HTTP request body like:
+// ```json
+//
+//	{
+//	    "runs": [
+//	        {
+//	            "actionID": "ILAfx4p1C7dA",
+//	            "context": {"input1.value": "jame"}
+//	        },
+//	        {
+//	            "actionID": "ILAfx4p1C7dB",
+//	            "content": {"mode": "sql", "query": "select * from orders where user_id = {{query1.data[0].id}}"},
+//	            "context": {"query1.data[0].id": 1},
+//	            "runAfter": ["ILAfx4p1C7dA"]
+//	        }
+//	    ]
+//	}
+//
+// ```
+// the content is optional, the saved action template is used when it is absent.
+// the run starts after all runs in its runAfter succeeded, and it is skipped when any of them failed.
+
+type BatchRunActionsRequest struct {
+	Runs []*BatchRunActionItem `json:"runs" validate:"required,min=1,max=100,dive,required"`
+}
+
+type BatchRunActionItem struct {
+	ActionID string                 `json:"actionID" validate:"required"`
+	Content  map[string]interface{} `json:"content"`
+	Context  map[string]interface{} `json:"context"`
+	RunAfter []string               `json:"runAfter"`
+}
+
+func NewBatchRunActionsRequest() *BatchRunActionsRequest {
+	return &BatchRunActionsRequest{}
+}
+
+func (item *BatchRunActionItem) ExportActionIDInInt() int {
+	return idconvertor.ConvertStringToInt(item.ActionID)
+}
+
+// ExportRunActionRequest build the run action request of item, the given template is used when the item has no content.
+func (item *BatchRunActionItem) ExportRunActionRequest(template map[string]interface{}) *RunActionRequest {
+	content := item.Content
+	if content == nil {
+		content = template
+	}
+	context := item.Context
+	if context == nil {
+		context = map[string]interface{}{}
+	}
+	return &RunActionRequest{
+		Content: content,
+		Context: context,
+	}
+}
+
+// ExportRunAfterIndexes resolve the runAfter of every run to the positions in request,
+// the duplicate actions, unknown dependencies and dependency cycles are rejected.
+func (req *BatchRunActionsRequest) ExportRunAfterIndexes() ([][]int, error) {
+	indexes := make(map[string]int, len(req.Runs))
+	for i, run := range req.Runs {
+		if _, hit := indexes[run.ActionID]; hit {
+			return nil, fmt.Errorf("action %s is duplicated in batch", run.ActionID)
+		}
+		indexes[run.ActionID] = i
+	}
+	runAfterIndexes := make([][]int, len(req.Runs))
+	for i, run := range req.Runs {
+		for _, dependency := range run.RunAfter {
+			dependencyIndex, hit := indexes[dependency]
+			if !hit {
+				return nil, fmt.Errorf("action %s runs after action %s which is not in batch", run.ActionID, dependency)
+			}
+			runAfterIndexes[i] = append(runAfterIndexes[i], dependencyIndex)
+		}
+	}
+
+	// detect cycles by depth first search
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make([]int, len(req.Runs))
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch states[i] {
+		case visiting:
+			return fmt.Errorf("action %s is in a runAfter cycle", req.Runs[i].ActionID)
+		case visited:
+			return nil
+		}
+		states[i] = visiting
+		for _, dependencyIndex := range runAfterIndexes[i] {
+			if err := visit(dependencyIndex); err != nil {
+				return err
+			}
+		}
+		states[i] = visited
+		return nil
+	}
+	for i := range req.Runs {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return runAfterIndexes, nil
+}
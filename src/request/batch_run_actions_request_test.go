@@ -0,0 +1,46 @@
+package request
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBatchRunActionsRequest(runAfters map[string][]string, actionIDs ...string) *BatchRunActionsRequest {
+	req := NewBatchRunActionsRequest()
+	for _, actionID := range actionIDs {
+		req.Runs = append(req.Runs, &BatchRunActionItem{ActionID: actionID, RunAfter: runAfters[actionID]})
+	}
+	return req
+}
+
+func TestExportRunAfterIndexes(t *testing.T) {
+	testCases := []struct {
+		name      string
+		actionIDs []string
+		runAfters map[string][]string
+		expected  [][]int
+		failed    bool
+	}{
+		{name: "no dependency", actionIDs: []string{"a", "b"}, expected: [][]int{nil, nil}},
+		{name: "chain", actionIDs: []string{"c", "b", "a"}, runAfters: map[string][]string{"c": {"b"}, "b": {"a"}}, expected: [][]int{{1}, {2}, nil}},
+		{name: "diamond", actionIDs: []string{"a", "b", "c", "d"}, runAfters: map[string][]string{"b": {"a"}, "c": {"a"}, "d": {"b", "c"}}, expected: [][]int{nil, {0}, {0}, {1, 2}}},
+		{name: "self cycle", actionIDs: []string{"a", "b"}, runAfters: map[string][]string{"b": {"b"}}, failed: true},
+		{name: "3-node cycle", actionIDs: []string{"a", "b", "c"}, runAfters: map[string][]string{"a": {"c"}, "b": {"a"}, "c": {"b"}}, failed: true},
+		{name: "cycle behind acyclic run", actionIDs: []string{"a", "b", "c"}, runAfters: map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"b"}}, failed: true},
+		{name: "unknown runAfter", actionIDs: []string{"a", "b"}, runAfters: map[string][]string{"b": {"x"}}, failed: true},
+		{name: "duplicate action ID", actionIDs: []string{"a", "b", "a"}, failed: true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			indexes, err := newTestBatchRunActionsRequest(testCase.runAfters, testCase.actionIDs...).ExportRunAfterIndexes()
+			if testCase.failed {
+				assert.NotNil(t, err)
+				assert.Nil(t, indexes)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.expected, indexes)
+		})
+	}
+}
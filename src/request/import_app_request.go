@@ -0,0 +1,33 @@
+package request
+
+import (
+	"encoding/json"
+
+	"github.com/illacloud/builder-backend/src/utils/idconvertor"
+)
+
+type ImportAppRequest struct {
+	Name            string            `json:"appName"`
+	Bundle          json.RawMessage   `json:"bundle" validate:"required"`
+	ResourceMapping map[string]string `json:"resourceMapping"` // map[placeholder]resourceID
+}
+
+func NewImportAppRequest() *ImportAppRequest {
+	return &ImportAppRequest{}
+}
+
+func (req *ImportAppRequest) ExportAppName() string {
+	return req.Name
+}
+
+func (req *ImportAppRequest) ExportBundleInByte() []byte {
+	return req.Bundle
+}
+
+func (req *ImportAppRequest) ExportResourceMappingInInt() map[string]int {
+	resourceMapping := make(map[string]int, len(req.ResourceMapping))
+	for placeholder, resourceID := range req.ResourceMapping {
+		resourceMapping[placeholder] = idconvertor.ConvertStringToInt(resourceID)
+	}
+	return resourceMapping
+}
@@ -1,7 +1,9 @@
 package request
 
+// the optional environment bind the release to the resource environment, the current binding is kept when omitted.
 type ReleaseAppRequest struct {
-	Public bool `json:"public" validate:"required"`
+	Public      bool    `json:"public" validate:"required"`
+	Environment *string `json:"environment"`
 }
 
 func NewReleaseAppRequest() *ReleaseAppRequest {
@@ -11,3 +13,14 @@ func NewReleaseAppRequest() *ReleaseAppRequest {
 func (req *ReleaseAppRequest) ExportPublic() bool {
 	return req.Public
 }
+
+func (req *ReleaseAppRequest) IsEnvironmentSet() bool {
+	return req.Environment != nil
+}
+
+func (req *ReleaseAppRequest) ExportEnvironment() string {
+	if req.Environment == nil {
+		return ""
+	}
+	return *req.Environment
+}
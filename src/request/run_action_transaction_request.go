@@ -0,0 +1,65 @@
+package request
+
+import (
+	"github.com/illacloud/builder-backend/src/utils/idconvertor"
+)
+
+// The run actions in transaction HTTP request body like:
+// ```json
+//
+//	{
+//	    "resourceID": "ILAfx4p1C7dD",
+//	    "actions": [
+//	        {
+//	            "actionID": "ILAfx4p1C7dA",
+//	            "context": {"input1.value": "jame"}
+//	        },
+//	        {
+//	            "actionID": "ILAfx4p1C7dB",
+//	            "content": {"mode": "sql-safe", "query": "insert into items (order_id) values ({{input2.value}})"},
+//	            "context": {"input2.value": 1}
+//	        }
+//	    ]
+//	}
+//
+// ```
+// the content is optional, the saved action template is used when it is absent.
+
+type RunActionTransactionRequest struct {
+	ResourceID string                      `json:"resourceID" validate:"required"`
+	Actions    []*RunActionTransactionItem `json:"actions"    validate:"required,min=1,max=50,dive,required"`
+}
+
+type RunActionTransactionItem struct {
+	ActionID string                 `json:"actionID" validate:"required"`
+	Content  map[string]interface{} `json:"content"`
+	Context  map[string]interface{} `json:"context"`
+}
+
+func NewRunActionTransactionRequest() *RunActionTransactionRequest {
+	return &RunActionTransactionRequest{}
+}
+
+func (req *RunActionTransactionRequest) ExportResourceIDInInt() int {
+	return idconvertor.ConvertStringToInt(req.ResourceID)
+}
+
+func (item *RunActionTransactionItem) ExportActionIDInInt() int {
+	return idconvertor.ConvertStringToInt(item.ActionID)
+}
+
+// ExportRunActionRequest build the run action request of item, the given template is used when the item has no content.
+func (item *RunActionTransactionItem) ExportRunActionRequest(template map[string]interface{}) *RunActionRequest {
+	content := item.Content
+	if content == nil {
+		content = template
+	}
+	context := item.Context
+	if context == nil {
+		context = map[string]interface{}{}
+	}
+	return &RunActionRequest{
+		Content: content,
+		Context: context,
+	}
+}
@@ -0,0 +1,23 @@
+package request
+
+// the value of secret is write-only, send back the masked value "********" in update request to keep the existing secret.
+type CreateTeamVariableRequest struct {
+	Name        string `json:"name" validate:"required,max=128"`
+	Value       string `json:"value"`
+	IsSecret    bool   `json:"isSecret"`
+	Description string `json:"description" validate:"max=255"`
+}
+
+func NewCreateTeamVariableRequest() *CreateTeamVariableRequest {
+	return &CreateTeamVariableRequest{}
+}
+
+type UpdateTeamVariableRequest struct {
+	Name        string `json:"name" validate:"required,max=128"`
+	Value       string `json:"value"`
+	Description string `json:"description" validate:"max=255"`
+}
+
+func NewUpdateTeamVariableRequest() *UpdateTeamVariableRequest {
+	return &UpdateTeamVariableRequest{}
+}
@@ -1,10 +1,15 @@
 package request
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/illacloud/builder-backend/src/utils/idconvertor"
+)
 
 // the test resource connection request like:
 //
 //	{
+//	    "resourceID": "ILAfx4p1C7dX",
 //	    "resourceName": "sample",
 //	    "resourceType": "postgresql",
 //	    "content": {
@@ -21,16 +26,29 @@ import "encoding/json"
 //	        }
 //	    }
 //	}
+//
+// the resourceID is optional, it is required when test an exists resource with masked secret fields.
+// the masked secret fields in content are filled by the exists resource options of the environment (the base options when empty).
 type TestResourceConnectionRequest struct {
+	ResourceID   string                 `json:"resourceID"`
 	ResourceName string                 `json:"resourceName" validate:"required,min=1,max=128"`
 	ResourceType string                 `json:"resourceType" validate:"required"`
 	Content      map[string]interface{} `json:"content" 	    validate:"required"`
+	Environment  string                 `json:"environment"`
 }
 
 func NewTestResourceConnectionRequest() *TestResourceConnectionRequest {
 	return &TestResourceConnectionRequest{}
 }
 
+func (req *TestResourceConnectionRequest) ExportResourceIDInInt() int {
+	return idconvertor.ConvertStringToInt(req.ResourceID)
+}
+
+func (req *TestResourceConnectionRequest) HasResourceID() bool {
+	return req.ResourceID != ""
+}
+
 func (req *TestResourceConnectionRequest) ExportType() string {
 	return req.ResourceType
 }
@@ -39,3 +57,7 @@ func (req *TestResourceConnectionRequest) ExportOptionsInString() string {
 	content, _ := json.Marshal(req.Content)
 	return string(content)
 }
+
+func (req *TestResourceConnectionRequest) ExportEnvironment() string {
+	return req.Environment
+}
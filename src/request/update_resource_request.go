@@ -21,10 +21,13 @@ import "encoding/json"
 //	        }
 //	    }
 //	}
+//
+// the optional "environments" holds the environment variants of content keyed by environment name, like {"production": {...}}.
 type UpdateResourceRequest struct {
-	ResourceName string                 `json:"resourceName" validate:"required,min=1,max=128"`
-	ResourceType string                 `json:"resourceType" validate:"required"`
-	Content      map[string]interface{} `json:"content" 	    validate:"required"`
+	ResourceName string                            `json:"resourceName" validate:"required,min=1,max=128"`
+	ResourceType string                            `json:"resourceType" validate:"required"`
+	Content      map[string]interface{}            `json:"content" 	    validate:"required"`
+	Environments map[string]map[string]interface{} `json:"environments"`
 }
 
 func NewUpdateResourceRequest() *UpdateResourceRequest {
@@ -39,3 +42,12 @@ func (req *UpdateResourceRequest) ExportOptionsInString() string {
 	content, _ := json.Marshal(req.Content)
 	return string(content)
 }
+
+func (req *UpdateResourceRequest) ExportEnvironments() map[string]map[string]interface{} {
+	return req.Environments
+}
+
+// IsEnvironmentsSet return false when the request omit environments, the existing environments are kept in this case.
+func (req *UpdateResourceRequest) IsEnvironmentsSet() bool {
+	return req.Environments != nil
+}
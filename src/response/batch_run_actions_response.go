@@ -0,0 +1,71 @@
+package response
+
+import (
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+)
+
+const (
+	BATCH_RUN_ACTION_STATUS_SUCCEEDED = "succeeded"
+	BATCH_RUN_ACTION_STATUS_FAILED    = "failed"
+	BATCH_RUN_ACTION_STATUS_SKIPPED   = "skipped"
+)
+
+// BatchRunActionResult is the result of a run in batch, the CacheStatus is the same as the run action cache header.
+type BatchRunActionResult struct {
+	ActionID     string                `json:"actionID"`
+	Status       string                `json:"status"`
+	CacheStatus  string                `json:"cacheStatus,omitempty"`
+	Result       *common.RuntimeResult `json:"result,omitempty"`
+	ErrorFlag    string                `json:"errorFlag,omitempty"`
+	ErrorMessage string                `json:"errorMessage,omitempty"`
+	ErrorData    *common.QueryError    `json:"errorData,omitempty"`
+}
+
+func NewBatchRunActionSucceededResult(actionID string, cacheStatus string, result *common.RuntimeResult) *BatchRunActionResult {
+	return &BatchRunActionResult{
+		ActionID:    actionID,
+		Status:      BATCH_RUN_ACTION_STATUS_SUCCEEDED,
+		CacheStatus: cacheStatus,
+		Result:      result,
+	}
+}
+
+func NewBatchRunActionFailedResult(actionID string, errorFlag string, errorMessage string, err error) *BatchRunActionResult {
+	result := &BatchRunActionResult{
+		ActionID:     actionID,
+		Status:       BATCH_RUN_ACTION_STATUS_FAILED,
+		ErrorFlag:    errorFlag,
+		ErrorMessage: errorMessage,
+	}
+	if queryError, ok := common.AsQueryError(err); ok {
+		result.ErrorData = queryError
+	}
+	return result
+}
+
+func NewBatchRunActionSkippedResult(actionID string, errorMessage string) *BatchRunActionResult {
+	return &BatchRunActionResult{
+		ActionID:     actionID,
+		Status:       BATCH_RUN_ACTION_STATUS_SKIPPED,
+		ErrorMessage: errorMessage,
+	}
+}
+
+func (result *BatchRunActionResult) IsSucceeded() bool {
+	return result.Status == BATCH_RUN_ACTION_STATUS_SUCCEEDED
+}
+
+// BatchRunActionsResponse is the results of batch run, in the order of request runs.
+type BatchRunActionsResponse struct {
+	Results []*BatchRunActionResult `json:"results"`
+}
+
+func NewBatchRunActionsResponse(results []*BatchRunActionResult) *BatchRunActionsResponse {
+	return &BatchRunActionsResponse{
+		Results: results,
+	}
+}
+
+func (resp *BatchRunActionsResponse) ExportForFeedback() interface{} {
+	return resp
+}
@@ -29,7 +29,7 @@ func NewCreateResourceResponse(resource *model.Resource) *CreateResourceResponse
 		TeamID:    idconvertor.ConvertIntToString(resource.TeamID),
 		Name:      resource.Name,
 		Type:      resourcelist.GetResourceIDMappedType(resource.Type),
-		Options:   resource.ExportOptionsInMap(),
+		Options:   resource.ExportOptionsInMapWithSecretMasked(),
 		CreatedAt: resource.CreatedAt,
 		CreatedBy: idconvertor.ConvertIntToString(resource.CreatedBy),
 		UpdatedAt: resource.UpdatedAt,
@@ -0,0 +1,20 @@
+package response
+
+import (
+	"github.com/illacloud/builder-backend/src/utils/idconvertor"
+)
+
+type DeleteActionScheduleResponse struct {
+	ID string `json:"scheduleID"`
+}
+
+func NewDeleteActionScheduleResponse(id int) *DeleteActionScheduleResponse {
+	resp := &DeleteActionScheduleResponse{
+		ID: idconvertor.ConvertIntToString(id),
+	}
+	return resp
+}
+
+func (resp *DeleteActionScheduleResponse) ExportForFeedback() interface{} {
+	return resp
+}
@@ -0,0 +1,20 @@
+package response
+
+import (
+	"github.com/illacloud/builder-backend/src/utils/idconvertor"
+)
+
+type DeleteTeamVariableResponse struct {
+	ID string `json:"variableID"`
+}
+
+func NewDeleteTeamVariableResponse(id int) *DeleteTeamVariableResponse {
+	resp := &DeleteTeamVariableResponse{
+		ID: idconvertor.ConvertIntToString(id),
+	}
+	return resp
+}
+
+func (resp *DeleteTeamVariableResponse) ExportForFeedback() interface{} {
+	return resp
+}
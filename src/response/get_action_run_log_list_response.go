@@ -0,0 +1,27 @@
+package response
+
+import (
+	"github.com/illacloud/builder-backend/src/model"
+)
+
+type GetActionRunLogListResponse struct {
+	RunList    []*model.ActionRunLogForExport `json:"runList"`
+	TotalPages int                            `json:"totalPages"`
+	TotalRows  int64                          `json:"totalRows"`
+}
+
+func NewGetActionRunLogListResponse(runLogs []*model.ActionRunLog, totalPages int, totalRows int64) *GetActionRunLogListResponse {
+	resp := &GetActionRunLogListResponse{
+		RunList:    make([]*model.ActionRunLogForExport, 0),
+		TotalPages: totalPages,
+		TotalRows:  totalRows,
+	}
+	for _, runLog := range runLogs {
+		resp.RunList = append(resp.RunList, model.NewActionRunLogForExport(runLog))
+	}
+	return resp
+}
+
+func (resp *GetActionRunLogListResponse) ExportForFeedback() interface{} {
+	return resp
+}
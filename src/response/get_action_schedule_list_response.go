@@ -0,0 +1,23 @@
+package response
+
+import (
+	"github.com/illacloud/builder-backend/src/model"
+)
+
+type GetActionScheduleListResponse struct {
+	ActionScheduleList []*model.ActionScheduleForExport `json:"actionScheduleList"`
+}
+
+func NewGetActionScheduleListResponse(actionSchedules []*model.ActionSchedule) *GetActionScheduleListResponse {
+	resp := &GetActionScheduleListResponse{
+		ActionScheduleList: make([]*model.ActionScheduleForExport, 0),
+	}
+	for _, actionSchedule := range actionSchedules {
+		resp.ActionScheduleList = append(resp.ActionScheduleList, model.NewActionScheduleForExport(actionSchedule))
+	}
+	return resp
+}
+
+func (resp *GetActionScheduleListResponse) ExportForFeedback() interface{} {
+	return resp
+}
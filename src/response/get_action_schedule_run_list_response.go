@@ -0,0 +1,25 @@
+package response
+
+import (
+	"github.com/illacloud/builder-backend/src/model"
+)
+
+type GetActionScheduleRunListResponse struct {
+	RunList    []*model.ActionScheduleRunForExport `json:"runList"`
+	TotalPages int                                 `json:"totalPages"`
+}
+
+func NewGetActionScheduleRunListResponse(runs []*model.ActionScheduleRun, totalPages int) *GetActionScheduleRunListResponse {
+	resp := &GetActionScheduleRunListResponse{
+		RunList:    make([]*model.ActionScheduleRunForExport, 0),
+		TotalPages: totalPages,
+	}
+	for _, run := range runs {
+		resp.RunList = append(resp.RunList, model.NewActionScheduleRunForExport(run))
+	}
+	return resp
+}
+
+func (resp *GetActionScheduleRunListResponse) ExportForFeedback() interface{} {
+	return resp
+}
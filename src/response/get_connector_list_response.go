@@ -0,0 +1,36 @@
+package response
+
+import (
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+	"github.com/illacloud/builder-backend/src/utils/resourcelist"
+)
+
+type ConnectorForExport struct {
+	Type         string                       `json:"type"`
+	ID           int                          `json:"id"`
+	Virtual      bool                         `json:"virtual"`
+	Capabilities common.ConnectorCapabilities `json:"capabilities"`
+}
+
+type GetConnectorListResponse struct {
+	Connectors []*ConnectorForExport `json:"connectors"`
+}
+
+func NewGetConnectorListResponse(registrations []*common.ConnectorRegistration) *GetConnectorListResponse {
+	resp := &GetConnectorListResponse{
+		Connectors: make([]*ConnectorForExport, 0, len(registrations)),
+	}
+	for _, registration := range registrations {
+		resp.Connectors = append(resp.Connectors, &ConnectorForExport{
+			Type:         registration.Type,
+			ID:           registration.ID,
+			Virtual:      resourcelist.IsVirtualResourceByIntType(registration.ID),
+			Capabilities: registration.Capabilities,
+		})
+	}
+	return resp
+}
+
+func (resp *GetConnectorListResponse) ExportForFeedback() interface{} {
+	return resp
+}
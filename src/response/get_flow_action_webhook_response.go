@@ -0,0 +1,32 @@
+package response
+
+import (
+	"github.com/illacloud/builder-backend/src/actionruntime/trigger"
+	"github.com/illacloud/builder-backend/src/utils/idconvertor"
+)
+
+type GetFlowActionWebhookResponse struct {
+	FlowActionID    string `json:"flowActionID"`
+	WebhookURL      string `json:"webhookURL"`
+	Secret          string `json:"secret"`
+	Method          string `json:"method"`
+	BodyType        string `json:"bodyType"`
+	SignatureHeader string `json:"signatureHeader"`
+	TimestampHeader string `json:"timestampHeader"`
+}
+
+func NewGetFlowActionWebhookResponse(flowActionID int, webhookURL string, secret string, template *trigger.TriggerTemplate) *GetFlowActionWebhookResponse {
+	return &GetFlowActionWebhookResponse{
+		FlowActionID:    idconvertor.ConvertIntToString(flowActionID),
+		WebhookURL:      webhookURL,
+		Secret:          secret,
+		Method:          template.Method,
+		BodyType:        template.BodyType,
+		SignatureHeader: trigger.WEBHOOK_SIGNATURE_HEADER,
+		TimestampHeader: trigger.WEBHOOK_TIMESTAMP_HEADER,
+	}
+}
+
+func (resp *GetFlowActionWebhookResponse) ExportForFeedback() interface{} {
+	return resp
+}
@@ -29,7 +29,7 @@ func NewGetResourceResponse(resource *model.Resource) *GetResourceResponse {
 		TeamID:    idconvertor.ConvertIntToString(resource.TeamID),
 		Name:      resource.Name,
 		Type:      resourcelist.GetResourceIDMappedType(resource.Type),
-		Options:   resource.ExportOptionsInMap(),
+		Options:   resource.ExportOptionsInMapWithSecretMasked(),
 		CreatedAt: resource.CreatedAt,
 		CreatedBy: idconvertor.ConvertIntToString(resource.CreatedBy),
 		UpdatedAt: resource.UpdatedAt,
@@ -0,0 +1,19 @@
+package response
+
+import (
+	"github.com/illacloud/builder-backend/src/model"
+)
+
+type GetResourceUsagesResponse struct {
+	*model.ResourceUsageForExport
+}
+
+func NewGetResourceUsagesResponse(resourceUsage *model.ResourceUsage, apps []*model.App) *GetResourceUsagesResponse {
+	return &GetResourceUsagesResponse{
+		ResourceUsageForExport: model.NewResourceUsageForExport(resourceUsage, apps),
+	}
+}
+
+func (resp *GetResourceUsagesResponse) ExportForFeedback() interface{} {
+	return resp
+}
@@ -0,0 +1,23 @@
+package response
+
+import (
+	"github.com/illacloud/builder-backend/src/model"
+)
+
+type GetTeamVariableListResponse struct {
+	TeamVariableList []*model.TeamVariableForExport `json:"teamVariableList"`
+}
+
+func NewGetTeamVariableListResponse(teamVariables []*model.TeamVariable) *GetTeamVariableListResponse {
+	resp := &GetTeamVariableListResponse{
+		TeamVariableList: make([]*model.TeamVariableForExport, 0),
+	}
+	for _, teamVariable := range teamVariables {
+		resp.TeamVariableList = append(resp.TeamVariableList, model.NewTeamVariableForExport(teamVariable))
+	}
+	return resp
+}
+
+func (resp *GetTeamVariableListResponse) ExportForFeedback() interface{} {
+	return resp
+}
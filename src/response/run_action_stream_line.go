@@ -0,0 +1,59 @@
+package response
+
+import (
+	"encoding/json"
+
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+)
+
+const (
+	RUN_ACTION_STREAM_LINE_TYPE_ROW   = "row"
+	RUN_ACTION_STREAM_LINE_TYPE_END   = "end"
+	RUN_ACTION_STREAM_LINE_TYPE_ERROR = "error"
+)
+
+// RunActionStreamLine is a line of the NDJSON run action stream, the stream is rows lines end with an end or error line.
+type RunActionStreamLine struct {
+	Type         string                 `json:"type"`
+	Data         json.RawMessage        `json:"data,omitempty"`
+	Success      bool                   `json:"success,omitempty"`
+	RowCount     int                    `json:"rowCount,omitempty"`
+	Columns      []*common.ColumnSchema `json:"columns,omitempty"`
+	Extra        map[string]interface{} `json:"extra,omitempty"`
+	ErrorMessage string                 `json:"errorMessage,omitempty"`
+	ErrorData    *common.QueryError     `json:"errorData,omitempty"`
+}
+
+func NewRunActionStreamRowLine(rowInJSON []byte) *RunActionStreamLine {
+	return &RunActionStreamLine{
+		Type: RUN_ACTION_STREAM_LINE_TYPE_ROW,
+		Data: rowInJSON,
+	}
+}
+
+func NewRunActionStreamEndLine(result common.RuntimeResult, rowCount int) *RunActionStreamLine {
+	return &RunActionStreamLine{
+		Type:     RUN_ACTION_STREAM_LINE_TYPE_END,
+		Success:  result.Success,
+		RowCount: rowCount,
+		Columns:  result.Columns,
+		Extra:    result.Extra,
+	}
+}
+
+func NewRunActionStreamErrorLine(err error) *RunActionStreamLine {
+	line := &RunActionStreamLine{
+		Type:         RUN_ACTION_STREAM_LINE_TYPE_ERROR,
+		ErrorMessage: err.Error(),
+	}
+	if queryError, ok := common.AsQueryError(err); ok {
+		line.ErrorData = queryError
+	}
+	return line
+}
+
+// ExportInLine export the line in JSON with the line break.
+func (line *RunActionStreamLine) ExportInLine() []byte {
+	lineInJSON, _ := json.Marshal(line)
+	return append(lineInJSON, '\n')
+}
@@ -0,0 +1,41 @@
+package response
+
+import (
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+)
+
+// RunActionTransactionResponse is the results of the committed transaction, in the order of request actions.
+type RunActionTransactionResponse struct {
+	Committed bool                   `json:"committed"`
+	Results   []common.RuntimeResult `json:"results"`
+}
+
+func NewRunActionTransactionResponse(results []common.RuntimeResult) *RunActionTransactionResponse {
+	return &RunActionTransactionResponse{
+		Committed: true,
+		Results:   results,
+	}
+}
+
+func (resp *RunActionTransactionResponse) ExportForFeedback() interface{} {
+	return resp
+}
+
+// RunActionTransactionErrorData is the errorData of the rolled back transaction,
+// the FailedIndex is the position of the failed action in request, it is -1 when the commit failed.
+type RunActionTransactionErrorData struct {
+	FailedIndex    int                `json:"failedIndex"`
+	FailedActionID string             `json:"failedActionID,omitempty"`
+	QueryError     *common.QueryError `json:"queryError,omitempty"`
+}
+
+func NewRunActionTransactionErrorData(failedIndex int, failedActionID string, err error) *RunActionTransactionErrorData {
+	errorData := &RunActionTransactionErrorData{
+		FailedIndex:    failedIndex,
+		FailedActionID: failedActionID,
+	}
+	if queryError, ok := common.AsQueryError(err); ok {
+		errorData.QueryError = queryError
+	}
+	return errorData
+}
@@ -28,13 +28,18 @@ func (r *Router) RegisterRouters(engine *gin.Engine) {
 	appsRouter := routerGroup.Group("/apps")
 	publicAppRouter := routerGroup.Group("/teams/byIdentifier/:teamIdentifier/publicApps")
 	resourceRouter := routerGroup.Group("/teams/:teamID/resources")
+	teamVariableRouter := routerGroup.Group("/teams/:teamID/variables")
 	actionRouter := routerGroup.Group("/teams/:teamID/apps/:appID/actions")
+	actionScheduleRouter := routerGroup.Group("/teams/:teamID/apps/:appID/actionSchedules")
+	actionRunRouter := routerGroup.Group("/teams/:teamID/actionRuns")
 	publicActionRouter := routerGroup.Group("/teams/byIdentifier/:teamIdentifier/apps/:appID/publicActions")
 	internalActionRouter := routerGroup.Group("/teams/:teamID/apps/:appID/internalActions")
 	roomRouter := routerGroup.Group("/teams/:teamID/room")
 	statusRouter := routerGroup.Group("/status")
 	oauth2Router := routerGroup.Group("/oauth2")
 	flowActionRouter := routerGroup.Group("/teams/:teamID/workflow/:workflowID/flowActions")
+	webhookRouter := routerGroup.Group("/webhooks")
+	connectorRouter := routerGroup.Group("/connectors")
 
 	// register auth
 	builderRouter.Use(remotejwtauth.RemoteJWTAuth())
@@ -42,9 +47,13 @@ func (r *Router) RegisterRouters(engine *gin.Engine) {
 	appsRouter.Use(remotejwtauth.RemoteJWTAuth())
 	roomRouter.Use(remotejwtauth.RemoteJWTAuth())
 	actionRouter.Use(remotejwtauth.RemoteJWTAuth())
+	actionScheduleRouter.Use(remotejwtauth.RemoteJWTAuth())
+	actionRunRouter.Use(remotejwtauth.RemoteJWTAuth())
 	internalActionRouter.Use(remotejwtauth.RemoteJWTAuth())
 	resourceRouter.Use(remotejwtauth.RemoteJWTAuth())
+	teamVariableRouter.Use(remotejwtauth.RemoteJWTAuth())
 	flowActionRouter.Use(remotejwtauth.RemoteJWTAuth())
+	connectorRouter.Use(remotejwtauth.RemoteJWTAuth())
 
 	// builder routers
 	builderRouter.GET("/desc", r.Controller.GetTeamBuilderDesc)
@@ -61,6 +70,8 @@ func (r *Router) RegisterRouters(engine *gin.Engine) {
 	appRouter.GET(":appID/snapshotList/limit/:pageLimit/page/:page", r.Controller.GetSnapshotList)
 	appRouter.GET(":appID/snapshot/:snapshotID", r.Controller.GetSnapshot)
 	appRouter.POST(":appID/recoverSnapshot/:snapshotID", r.Controller.RecoverSnapshot)
+	appRouter.GET(":appID/export", r.Controller.ExportApp)
+	appRouter.POST("/import", r.Controller.ImportApp)
 	appRouter.GET("/list", r.Controller.GetAllAppByPage)
 	appRouter.GET("/list/like", r.Controller.SearchAppByKeywordsByPageUsingURIParam)
 	appRouter.GET("/list/limit/:limit/page/:page/sortBy/:sortBy/like/keywords/:keywords", r.Controller.SearchAppByKeywordsByPage)
@@ -79,6 +90,19 @@ func (r *Router) RegisterRouters(engine *gin.Engine) {
 	actionRouter.PUT("/:actionID", r.Controller.UpdateAction)
 	actionRouter.DELETE("/:actionID", r.Controller.DeleteAction)
 	actionRouter.POST("/:actionID/run", r.Controller.RunAction)
+	actionRouter.POST("/transaction/run", r.Controller.RunActionsInTransaction)
+	actionRouter.POST("/batchRun", r.Controller.BatchRunActions)
+	actionRouter.GET("/:actionID/runs", r.Controller.GetActionRunLogList)
+
+	// action run routers
+	actionRunRouter.GET("", r.Controller.GetTeamActionRunLogList)
+
+	// action schedule routers
+	actionScheduleRouter.POST("", r.Controller.CreateActionSchedule)
+	actionScheduleRouter.GET("", r.Controller.GetAllActionSchedules)
+	actionScheduleRouter.PUT("/:scheduleID", r.Controller.UpdateActionSchedule)
+	actionScheduleRouter.DELETE("/:scheduleID", r.Controller.DeleteActionSchedule)
+	actionScheduleRouter.GET("/:scheduleID/runs/limit/:pageLimit/page/:page", r.Controller.GetActionScheduleRunList)
 
 	// internal action routers
 	internalActionRouter.POST("/generateSQL", r.Controller.GenerateSQL)
@@ -89,12 +113,20 @@ func (r *Router) RegisterRouters(engine *gin.Engine) {
 	resourceRouter.GET("/:resourceID", r.Controller.GetResource)
 	resourceRouter.PUT("/:resourceID", r.Controller.UpdateResource)
 	resourceRouter.DELETE("/:resourceID", r.Controller.DeleteResource)
+	resourceRouter.GET("/:resourceID/usages", r.Controller.GetResourceUsages)
 	resourceRouter.POST("/testConnection", r.Controller.TestConnection)
 	resourceRouter.GET("/:resourceID/meta", r.Controller.GetMetaInfo)
+	resourceRouter.POST("/:resourceID/meta/refresh", r.Controller.RefreshMetaInfo)
 	resourceRouter.POST("/:resourceID/token", r.Controller.CreateGoogleOAuthToken)
 	resourceRouter.GET("/:resourceID/oauth2", r.Controller.GetGoogleSheetsOAuth2Token)
 	resourceRouter.POST("/:resourceID/refresh", r.Controller.RefreshGoogleSheetsOAuth)
 
+	// team variable routers
+	teamVariableRouter.GET("", r.Controller.GetAllTeamVariables)
+	teamVariableRouter.POST("", r.Controller.CreateTeamVariable)
+	teamVariableRouter.PUT("/:variableID", r.Controller.UpdateTeamVariable)
+	teamVariableRouter.DELETE("/:variableID", r.Controller.DeleteTeamVariable)
+
 	// public app routers
 	publicAppRouter.GET(":appID/versions/:version", r.Controller.GetFullPublicApp)
 	publicAppRouter.GET(":appID/isPublic", r.Controller.IsPublicApp)
@@ -111,6 +143,14 @@ func (r *Router) RegisterRouters(engine *gin.Engine) {
 	flowActionRouter.PUT("/:flowActionID", r.Controller.UpdateFlowAction)
 	flowActionRouter.DELETE("/:flowActionID", r.Controller.DeleteFlowAction)
 	flowActionRouter.POST("/:flowActionID/run", r.Controller.RunFlowAction)
+	flowActionRouter.GET("/:flowActionID/webhook", r.Controller.GetFlowActionWebhook)
+	flowActionRouter.POST("/:flowActionID/webhook/rotateSecret", r.Controller.RotateFlowActionWebhookSecret)
+
+	// webhook routers, authorized by signature instead of jwt
+	webhookRouter.Any("/:webhookID", r.Controller.TriggerWebhook)
+
+	// connector routers
+	connectorRouter.GET("", r.Controller.GetConnectorList)
 
 	// status router
 	statusRouter.GET("", r.Controller.GetStatus)
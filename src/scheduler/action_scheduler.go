@@ -0,0 +1,245 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/illacloud/builder-backend/src/actionruntime/common"
+	"github.com/illacloud/builder-backend/src/cache"
+	"github.com/illacloud/builder-backend/src/model"
+	"github.com/illacloud/builder-backend/src/storage"
+	"github.com/illacloud/builder-backend/src/utils/config"
+	"github.com/illacloud/builder-backend/src/utils/idconvertor"
+	"github.com/illacloud/builder-backend/src/utils/illawebsocketsdk"
+	"go.uber.org/zap"
+)
+
+const (
+	// the max due schedules fetched in one tick
+	ACTION_SCHEDULER_BATCH_SIZE = 100
+	// the max actions running at the same time in this instance
+	ACTION_SCHEDULER_MAX_CONCURRENCY = 8
+)
+
+// ActionScheduler poll the due action schedules and run the actions of the released app version.
+// multiple backend replicas can run the scheduler, every schedule is claimed by only one replica in each round.
+type ActionScheduler struct {
+	Storage      *storage.Storage
+	Cache        *cache.Cache
+	Config       *config.Config
+	WebsocketAPI *illawebsocketsdk.IllaWebsocketRestAPI
+	logger       *zap.SugaredLogger
+	slots        chan struct{}
+}
+
+func NewActionScheduler(s *storage.Storage, c *cache.Cache, logger *zap.SugaredLogger) *ActionScheduler {
+	return &ActionScheduler{
+		Storage:      s,
+		Cache:        c,
+		Config:       config.GetInstance(),
+		WebsocketAPI: illawebsocketsdk.NewIllaWebsocketRestAPI(),
+		logger:       logger,
+		slots:        make(chan struct{}, ACTION_SCHEDULER_MAX_CONCURRENCY),
+	}
+}
+
+// Start run the scheduler loop in background.
+func (scheduler *ActionScheduler) Start() {
+	go func() {
+		ticker := time.NewTicker(scheduler.Config.GetActionSchedulerInterval())
+		defer ticker.Stop()
+		for range ticker.C {
+			scheduler.Tick(time.Now().UTC())
+		}
+	}()
+}
+
+// Tick claim and run all due schedules.
+func (scheduler *ActionScheduler) Tick(now time.Time) {
+	actionSchedules, errInRetrieve := scheduler.Storage.ActionScheduleStorage.RetrieveDue(now, ACTION_SCHEDULER_BATCH_SIZE)
+	if errInRetrieve != nil {
+		scheduler.logger.Errorw("retrieve due action schedules failed", "err", errInRetrieve)
+		return
+	}
+	for _, actionSchedule := range actionSchedules {
+		nextRunAt, errInCalculate := actionSchedule.ExportNextRunAfter(now)
+		if errInCalculate != nil {
+			actionSchedule.Disable(errInCalculate)
+			scheduler.Storage.ActionScheduleStorage.UpdateWholeActionSchedule(actionSchedule)
+			continue
+		}
+		claimed, errInClaim := scheduler.Storage.ActionScheduleStorage.Claim(actionSchedule, nextRunAt)
+		if errInClaim != nil {
+			scheduler.logger.Errorw("claim action schedule failed", "scheduleID", actionSchedule.ExportID(), "err", errInClaim)
+			continue
+		}
+		// claimed by other replica
+		if !claimed {
+			continue
+		}
+		scheduler.slots <- struct{}{}
+		go func(actionSchedule *model.ActionSchedule) {
+			defer func() { <-scheduler.slots }()
+			scheduler.Run(actionSchedule)
+		}(actionSchedule)
+	}
+}
+
+// Run execute the scheduled action, store the result & run history, and push the result to app room.
+func (scheduler *ActionScheduler) Run(actionSchedule *model.ActionSchedule) {
+	run := model.NewActionScheduleRun(actionSchedule, time.Now().UTC())
+	action, result, errInRun := scheduler.runAction(actionSchedule)
+	if action != nil {
+		run.SetAction(action)
+	}
+	if errInRun != nil {
+		run.Fail(errInRun)
+	} else {
+		run.Succeed(result)
+	}
+
+	// store
+	actionSchedule.RecordRun(run)
+	if errInUpdate := scheduler.Storage.ActionScheduleStorage.UpdateLastRun(actionSchedule); errInUpdate != nil {
+		scheduler.logger.Errorw("update action schedule last run failed", "scheduleID", actionSchedule.ExportID(), "err", errInUpdate)
+	}
+	if _, errInCreate := scheduler.Storage.ActionScheduleRunStorage.Create(run); errInCreate != nil {
+		scheduler.logger.Errorw("create action schedule run failed", "scheduleID", actionSchedule.ExportID(), "err", errInCreate)
+	}
+	if errInPrune := scheduler.Storage.ActionScheduleRunStorage.PruneByScheduleID(actionSchedule.ExportID(), model.ACTION_SCHEDULE_RUN_HISTORY_MAX_LEN); errInPrune != nil {
+		scheduler.logger.Errorw("prune action schedule runs failed", "scheduleID", actionSchedule.ExportID(), "err", errInPrune)
+	}
+
+	// push to app room
+	if !actionSchedule.IsPushToRoom() {
+		return
+	}
+	errInPush := scheduler.WebsocketAPI.PushScheduledActionResult(actionSchedule.ExportTeamID(), actionSchedule.ExportAppID(), &illawebsocketsdk.ScheduledActionResult{
+		ScheduleID: idconvertor.ConvertIntToString(actionSchedule.ExportID()),
+		ActionID:   idconvertor.ConvertIntToString(run.ActionRefID),
+		ActionName: actionSchedule.ExportActionName(),
+		Status:     run.Status,
+		Result:     run.ExportResultInInterface(),
+		Error:      run.Error,
+		StartedAt:  run.StartedAt,
+		Duration:   run.Duration,
+	})
+	if errInPush != nil {
+		scheduler.logger.Errorw("push scheduled action result to room failed", "scheduleID", actionSchedule.ExportID(), "err", errInPush)
+	}
+}
+
+func (scheduler *ActionScheduler) runAction(actionSchedule *model.ActionSchedule) (*model.Action, interface{}, error) {
+	teamID := actionSchedule.ExportTeamID()
+
+	// the scheduled action always run with the released app version
+	app, errInRetrieveApp := scheduler.Storage.AppStorage.RetrieveAppByTeamIDAndAppID(teamID, actionSchedule.ExportAppID())
+	if errInRetrieveApp != nil {
+		return nil, nil, errors.New("get app failed: " + errInRetrieveApp.Error())
+	}
+	if app.ExportReleaseVersion() == model.APP_EDIT_VERSION {
+		return nil, nil, errors.New("app has not been released")
+	}
+	actions, errInRetrieveActions := scheduler.Storage.ActionStorage.RetrieveActionsByTeamIDAppIDAndVersion(teamID, app.ExportID(), app.ExportReleaseVersion())
+	if errInRetrieveActions != nil {
+		return nil, nil, errors.New("get actions failed: " + errInRetrieveActions.Error())
+	}
+	var action *model.Action
+	for _, releasedAction := range actions {
+		if releasedAction.ExportDisplayName() == actionSchedule.ExportActionName() {
+			action = releasedAction
+			break
+		}
+	}
+	if action == nil {
+		return nil, nil, errors.New("action " + actionSchedule.ExportActionName() + " not found in released app")
+	}
+
+	// return mock data instead of calling the real connector when mock enabled
+	if action.IsMockEnabled() {
+		mockResult, errInExportMockResult := action.ExportMockResult()
+		return action, mockResult, errInExportMockResult
+	}
+
+	// assembly action
+	actionFactory := model.NewActionFactoryByAction(action)
+	actionAssemblyLine, errInBuild := actionFactory.Build()
+	if errInBuild != nil {
+		return action, nil, errors.New("validate action type error: " + errInBuild.Error())
+	}
+
+	// get resource
+	resource := model.NewResource()
+	var resourceOptions map[string]interface{}
+	if !action.IsVirtualAction() {
+		var errInRetrieveResource error
+		resource, errInRetrieveResource = scheduler.Storage.ResourceStorage.RetrieveByTeamIDAndResourceID(teamID, action.ExportResourceID())
+		if errInRetrieveResource != nil {
+			return action, nil, errors.New("get resource failed: " + errInRetrieveResource.Error())
+		}
+		// the scheduled run always runs the released action, so run with the release environment of app
+		var errInSwitchEnvironment error
+		resource, errInSwitchEnvironment = resource.ExportEnvironmentVariant(app.ExportEnvironmentByAction(action))
+		if errInSwitchEnvironment != nil {
+			return action, nil, errors.New("switch resource environment failed: " + errInSwitchEnvironment.Error())
+		}
+		// resolve the team variables referenced by resource options and action template
+		var errInResolveTeamVariables error
+		resource, errInResolveTeamVariables = model.ResolveTeamVariablesForRun(resource, func() ([]*model.TeamVariable, error) {
+			return scheduler.Storage.TeamVariableStorage.RetrieveByTeamID(teamID)
+		}, false, model.NewPersistedTeamVariableRunTemplate(&action.Template, nil))
+		if errInResolveTeamVariables != nil {
+			return action, nil, errors.New("resolve team variables failed: " + errInResolveTeamVariables.Error())
+		}
+		var errInExportResourceOptions error
+		resourceOptions, errInExportResourceOptions = resource.ExportOptionsInMap()
+		if errInExportResourceOptions != nil {
+			return action, nil, errors.New("get resource options failed: " + errInExportResourceOptions.Error())
+		}
+		if _, errInValidateResourceOptions := actionAssemblyLine.ValidateResourceOptions(resourceOptions); errInValidateResourceOptions != nil {
+			return action, nil, errors.New("validate resource failed: " + errInValidateResourceOptions.Error())
+		}
+	} else {
+		// there is no user in scheduled run, run as anonymous
+		action.AppendRuntimeInfoForVirtualResource("", teamID)
+	}
+
+	// there is no run context from client in scheduled run
+	action.MergeRunActionContextToRawTemplate(map[string]interface{}{})
+
+	// check action template
+	if _, errInValidate := actionAssemblyLine.ValidateActionTemplate(action.ExportTemplateInMap()); errInValidate != nil {
+		return action, nil, errors.New("validate action template error: " + errInValidate.Error())
+	}
+
+	// run
+	actionTimeout := action.ExportRunTimeout()
+	if actionTimeout <= 0 {
+		actionTimeout = scheduler.Config.GetActionRunTimeout()
+	}
+	ctx := common.ContextWithResourceID(context.Background(), resource.ExportID())
+	ctx = common.ContextWithResultLimits(ctx, resource.ExportResultLimits())
+	cancel := context.CancelFunc(func() {})
+	if actionTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, actionTimeout)
+	}
+	defer cancel()
+	actionRunLog := model.NewActionRunLogByAction(action, model.ACTION_RUN_LOG_SOURCE_SCHEDULE, model.ANONYMOUS_USER_ID)
+	actionRunResult, errInRunAction := actionAssemblyLine.Run(ctx, resourceOptions, action.ExportTemplateInMap(), action.ExportRawTemplateInMap())
+	actionRunLog.Finish(actionRunResult, errInRunAction)
+	if _, errInCreateRunLog := scheduler.Storage.ActionRunLogStorage.Create(actionRunLog); errInCreateRunLog != nil {
+		scheduler.logger.Errorw("record action run log failed", "actionID", action.ExportID(), "err", errInCreateRunLog)
+	}
+	if errInRunAction != nil {
+		return action, nil, errors.New("run action error: " + errInRunAction.Error())
+	}
+
+	// the scheduled write action invalidates the cached results of the resource as well
+	if action.IsInvalidatingResourceCache() {
+		if errInInvalidate := scheduler.Cache.ActionResultCache.InvalidateResource(teamID, action.ExportResourceID()); errInInvalidate != nil {
+			scheduler.logger.Errorw("invalidate action result cache failed", "actionID", action.ExportID(), "err", errInInvalidate)
+		}
+	}
+	return action, actionRunResult, nil
+}
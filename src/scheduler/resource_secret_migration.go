@@ -0,0 +1,87 @@
+package scheduler
+
+import (
+	"github.com/illacloud/builder-backend/src/storage"
+	"go.uber.org/zap"
+)
+
+// the resources fetched in one batch while migrating
+const RESOURCE_SECRET_MIGRATION_BATCH_SIZE = 100
+
+// ResourceSecretMigration encrypt the plaintext secret fields of the resources saved before the secret encryption, include the environment variants.
+// it is a one-off job run at startup, the encrypted fields are skipped, so running it again or by multiple replicas is harmless.
+type ResourceSecretMigration struct {
+	Storage *storage.Storage
+	logger  *zap.SugaredLogger
+}
+
+func NewResourceSecretMigration(s *storage.Storage, logger *zap.SugaredLogger) *ResourceSecretMigration {
+	return &ResourceSecretMigration{
+		Storage: s,
+		logger:  logger,
+	}
+}
+
+// Start run the migration in background, the plaintext secrets are still readable while migrating.
+func (migration *ResourceSecretMigration) Start() {
+	go func() {
+		migratedCount, errInRun := migration.Run()
+		if errInRun != nil {
+			migration.logger.Errorw("resource secret migration failed", "migrated", migratedCount, "err", errInRun)
+			return
+		}
+		migration.logger.Infow("resource secret migration finished", "migrated", migratedCount)
+	}()
+}
+
+// Run walk through all resources and encrypt their plaintext secrets, return the count of the resources saved.
+// the failed resource is logged and skipped, so one broken resource does not block the others.
+func (migration *ResourceSecretMigration) Run() (int, error) {
+	migratedCount := 0
+	afterID := 0
+	for {
+		resources, errInRetrieve := migration.Storage.ResourceStorage.RetrieveBatchAfterID(afterID, RESOURCE_SECRET_MIGRATION_BATCH_SIZE)
+		if errInRetrieve != nil {
+			return migratedCount, errInRetrieve
+		}
+		for _, resource := range resources {
+			afterID = resource.ID
+			migrated, errInMigrate := migration.migrateResource(resource.TeamID, resource.ID)
+			if errInMigrate != nil {
+				migration.logger.Errorw("encrypt resource secrets failed", "resourceID", resource.ID, "err", errInMigrate)
+				continue
+			}
+			if migrated {
+				migratedCount++
+			}
+		}
+		if len(resources) < RESOURCE_SECRET_MIGRATION_BATCH_SIZE {
+			return migratedCount, nil
+		}
+	}
+}
+
+// migrateResource encrypt the resource with the row locked, so the concurrent update of resource is not overwritten by the stale options.
+func (migration *ResourceSecretMigration) migrateResource(teamID int, resourceID int) (bool, error) {
+	migrated := false
+	errInTransaction := migration.Storage.Transaction(func(txStorage *storage.Storage) error {
+		if errInLock := txStorage.ResourceStorage.LockByTeamIDAndResourceID(teamID, resourceID, true); errInLock != nil {
+			return errInLock
+		}
+		resource, errInRetrieve := txStorage.ResourceStorage.RetrieveByTeamIDAndResourceID(teamID, resourceID)
+		if errInRetrieve != nil {
+			return errInRetrieve
+		}
+		encrypted, errInEncrypt := resource.EncryptPlaintextSecrets()
+		if errInEncrypt != nil || !encrypted {
+			return errInEncrypt
+		}
+		// the updated time is kept, the options are not changed by user
+		if errInUpdate := txStorage.ResourceStorage.UpdateWholeResource(resource); errInUpdate != nil {
+			return errInUpdate
+		}
+		migrated = true
+		return nil
+	})
+	return migrated, errInTransaction
+}
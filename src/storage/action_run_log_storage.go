@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/illacloud/builder-backend/src/model"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ActionRunLogFilter, the zero value fields are not filtered.
+type ActionRunLogFilter struct {
+	TeamID     int
+	Kind       string
+	Source     string
+	AppID      int
+	WorkflowID int
+	ActionID   int
+	ActionName string // match the action by ID or by name, since the released action has different ID
+	ExecutedBy int
+	Status     string
+	From       time.Time
+	To         time.Time
+}
+
+func NewActionRunLogFilter(teamID int) *ActionRunLogFilter {
+	return &ActionRunLogFilter{
+		TeamID: teamID,
+	}
+}
+
+func (filter *ActionRunLogFilter) scope(db *gorm.DB) *gorm.DB {
+	db = db.Where("team_id = ?", filter.TeamID)
+	if filter.Kind != "" {
+		db = db.Where("kind = ?", filter.Kind)
+	}
+	if filter.Source != "" {
+		db = db.Where("source = ?", filter.Source)
+	}
+	if filter.AppID != 0 {
+		db = db.Where("app_ref_id = ?", filter.AppID)
+	}
+	if filter.WorkflowID != 0 {
+		db = db.Where("workflow_ref_id = ?", filter.WorkflowID)
+	}
+	if filter.ActionID != 0 && filter.ActionName != "" {
+		db = db.Where("(action_ref_id = ? OR action_name = ?)", filter.ActionID, filter.ActionName)
+	} else if filter.ActionID != 0 {
+		db = db.Where("action_ref_id = ?", filter.ActionID)
+	}
+	if filter.ExecutedBy != 0 {
+		db = db.Where("executed_by = ?", filter.ExecutedBy)
+	}
+	if filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+	if !filter.From.IsZero() {
+		db = db.Where("started_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		db = db.Where("started_at < ?", filter.To)
+	}
+	return db
+}
+
+type ActionRunLogStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewActionRunLogStorage(logger *zap.SugaredLogger, db *gorm.DB) *ActionRunLogStorage {
+	return &ActionRunLogStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (impl *ActionRunLogStorage) Create(runLog *model.ActionRunLog) (int, error) {
+	if err := impl.db.Create(runLog).Error; err != nil {
+		return 0, err
+	}
+	return runLog.ID, nil
+}
+
+func (impl *ActionRunLogStorage) RetrieveByFilterAndPage(filter *ActionRunLogFilter, pagination *Pagination) ([]*model.ActionRunLog, error) {
+	var runLogs []*model.ActionRunLog
+	if err := impl.db.Scopes(filter.scope, paginate(impl.db, pagination)).Find(&runLogs).Error; err != nil {
+		return nil, err
+	}
+	return runLogs, nil
+}
+
+func (impl *ActionRunLogStorage) RetrieveCountByFilter(filter *ActionRunLogFilter) (int64, error) {
+	var count int64
+	if err := impl.db.Model(&model.ActionRunLog{}).Scopes(filter.scope).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (impl *ActionRunLogStorage) DeleteByTeamIDAndAppID(teamID int, appID int) error {
+	if err := impl.db.Where("team_id = ? AND app_ref_id = ?", teamID, appID).Delete(&model.ActionRunLog{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
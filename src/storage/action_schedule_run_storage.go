@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"github.com/illacloud/builder-backend/src/model"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type ActionScheduleRunStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewActionScheduleRunStorage(logger *zap.SugaredLogger, db *gorm.DB) *ActionScheduleRunStorage {
+	return &ActionScheduleRunStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (impl *ActionScheduleRunStorage) Create(run *model.ActionScheduleRun) (int, error) {
+	if err := impl.db.Create(run).Error; err != nil {
+		return 0, err
+	}
+	return run.ID, nil
+}
+
+func (impl *ActionScheduleRunStorage) RetrieveByTeamIDScheduleIDAndPage(teamID int, actionScheduleID int, pagination *Pagination) ([]*model.ActionScheduleRun, error) {
+	var runs []*model.ActionScheduleRun
+	if err := impl.db.Scopes(paginate(impl.db, pagination)).Where("team_id = ? AND schedule_ref_id = ?", teamID, actionScheduleID).Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+func (impl *ActionScheduleRunStorage) RetrieveCountByTeamIDAndScheduleID(teamID int, actionScheduleID int) (int64, error) {
+	var count int64
+	if err := impl.db.Model(&model.ActionScheduleRun{}).Where("team_id = ? AND schedule_ref_id = ?", teamID, actionScheduleID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// PruneByScheduleID keep the latest maxLen run history of the schedule.
+func (impl *ActionScheduleRunStorage) PruneByScheduleID(actionScheduleID int, maxLen int) error {
+	keep := impl.db.Model(&model.ActionScheduleRun{}).Select("id").Where("schedule_ref_id = ?", actionScheduleID).Order("id desc").Limit(maxLen)
+	if err := impl.db.Where("schedule_ref_id = ? AND id NOT IN (?)", actionScheduleID, keep).Delete(&model.ActionScheduleRun{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (impl *ActionScheduleRunStorage) DeleteByTeamIDAndScheduleID(teamID int, actionScheduleID int) error {
+	if err := impl.db.Where("team_id = ? AND schedule_ref_id = ?", teamID, actionScheduleID).Delete(&model.ActionScheduleRun{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (impl *ActionScheduleRunStorage) DeleteByTeamIDAndAppID(teamID int, appID int) error {
+	if err := impl.db.Where("team_id = ? AND app_ref_id = ?", teamID, appID).Delete(&model.ActionScheduleRun{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
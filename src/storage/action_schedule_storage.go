@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/illacloud/builder-backend/src/model"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type ActionScheduleStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewActionScheduleStorage(logger *zap.SugaredLogger, db *gorm.DB) *ActionScheduleStorage {
+	return &ActionScheduleStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (impl *ActionScheduleStorage) Create(actionSchedule *model.ActionSchedule) (int, error) {
+	if err := impl.db.Create(actionSchedule).Error; err != nil {
+		return 0, err
+	}
+	return actionSchedule.ID, nil
+}
+
+func (impl *ActionScheduleStorage) UpdateWholeActionSchedule(actionSchedule *model.ActionSchedule) error {
+	// use Select("*") for update the zero value fields like "enabled = false"
+	if err := impl.db.Model(actionSchedule).Select("*").Where("id = ?", actionSchedule.ID).Updates(actionSchedule).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (impl *ActionScheduleStorage) RetrieveByTeamIDAndID(teamID int, actionScheduleID int) (*model.ActionSchedule, error) {
+	var actionSchedule *model.ActionSchedule
+	if err := impl.db.Where("team_id = ? AND id = ?", teamID, actionScheduleID).First(&actionSchedule).Error; err != nil {
+		return nil, err
+	}
+	return actionSchedule, nil
+}
+
+func (impl *ActionScheduleStorage) RetrieveByTeamIDAndAppID(teamID int, appID int) ([]*model.ActionSchedule, error) {
+	var actionSchedules []*model.ActionSchedule
+	if err := impl.db.Where("team_id = ? AND app_ref_id = ?", teamID, appID).Order("id asc").Find(&actionSchedules).Error; err != nil {
+		return nil, err
+	}
+	return actionSchedules, nil
+}
+
+// RetrieveDue retrieve the enabled schedules which should run before now.
+func (impl *ActionScheduleStorage) RetrieveDue(now time.Time, limit int) ([]*model.ActionSchedule, error) {
+	var actionSchedules []*model.ActionSchedule
+	if err := impl.db.Where("enabled = ? AND next_run_at <= ?", true, now).Order("next_run_at asc").Limit(limit).Find(&actionSchedules).Error; err != nil {
+		return nil, err
+	}
+	return actionSchedules, nil
+}
+
+// Claim move the next run time forward only if it is not changed by others,
+// so the schedule runs exactly once when multiple backend replicas are polling.
+func (impl *ActionScheduleStorage) Claim(actionSchedule *model.ActionSchedule, nextRunAt time.Time) (bool, error) {
+	result := impl.db.Model(&model.ActionSchedule{}).
+		Where("id = ? AND next_run_at = ?", actionSchedule.ID, actionSchedule.NextRunAt).
+		UpdateColumn("next_run_at", nextRunAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected != 1 {
+		return false, nil
+	}
+	actionSchedule.NextRunAt = nextRunAt
+	return true, nil
+}
+
+// UpdateLastRun only update the last run fields, the schedule may be modified by user while running.
+func (impl *ActionScheduleStorage) UpdateLastRun(actionSchedule *model.ActionSchedule) error {
+	if err := impl.db.Model(&model.ActionSchedule{}).Where("id = ?", actionSchedule.ID).UpdateColumns(map[string]interface{}{
+		"last_run_at": actionSchedule.LastRunAt,
+		"last_status": actionSchedule.LastStatus,
+		"last_result": actionSchedule.LastResult,
+		"last_error":  actionSchedule.LastError,
+	}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (impl *ActionScheduleStorage) DeleteByTeamIDAndID(teamID int, actionScheduleID int) error {
+	if err := impl.db.Where("team_id = ? AND id = ?", teamID, actionScheduleID).Delete(&model.ActionSchedule{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (impl *ActionScheduleStorage) DeleteByTeamIDAndAppID(teamID int, appID int) error {
+	if err := impl.db.Where("team_id = ? AND app_ref_id = ?", teamID, appID).Delete(&model.ActionSchedule{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -148,6 +148,14 @@ func (impl *ActionStorage) RetrieveActionsByTeamIDAppIDVersionAndType(teamID int
 	return actions, nil
 }
 
+func (impl *ActionStorage) RetrieveActionsByTeamIDAndResourceID(teamID int, resourceID int) ([]*model.Action, error) {
+	var actions []*model.Action
+	if err := impl.db.Where("team_id = ? AND resource_ref_id = ?", teamID, resourceID).Order("app_ref_id, version, name").Find(&actions).Error; err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
+
 func (impl *ActionStorage) RetrieveActionByTeamIDActionID(teamID int, actionID int) (*model.Action, error) {
 	var action *model.Action
 	if err := impl.db.Where("team_id = ? AND id = ?", teamID, actionID).First(&action).Error; err != nil {
@@ -156,6 +164,14 @@ func (impl *ActionStorage) RetrieveActionByTeamIDActionID(teamID int, actionID i
 	return action, nil
 }
 
+func (impl *ActionStorage) RetrieveActionsByTeamIDAppIDAndIDs(teamID int, appID int, actionIDs []int) ([]*model.Action, error) {
+	var actions []*model.Action
+	if err := impl.db.Where("team_id = ? AND app_ref_id = ? AND id IN ?", teamID, appID, actionIDs).Find(&actions).Error; err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
+
 func (impl *ActionStorage) DeleteActionsByApp(teamID int, appID int) error {
 	if err := impl.db.Where("team_id = ? AND app_ref_id = ?", teamID, appID).Delete(&model.Action{}).Error; err != nil {
 		return err
@@ -93,6 +93,16 @@ func (impl *FlowActionStorage) RetrieveFlowActionsByTeamIDWorkflowIDAndVersion(t
 	return actions, nil
 }
 
+// RetrieveLatestVersionByTeamIDAndWorkflowID retrieve the latest version of workflow flow actions,
+// the released versions are duplicated with increasing version number, and it is APP_EDIT_VERSION when the workflow never released.
+func (impl *FlowActionStorage) RetrieveLatestVersionByTeamIDAndWorkflowID(teamID int, workflowID int) (int, error) {
+	var version int
+	if err := impl.db.Model(&model.FlowAction{}).Select("COALESCE(MAX(version), 0)").Where("team_id = ? AND workflow_id = ?", teamID, workflowID).Scan(&version).Error; err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
 func (impl *FlowActionStorage) RetrieveFlowActionsByTeamIDWorkflowIDVersionAndType(teamID int, workflowID int, version int, actionType int) ([]*model.FlowAction, error) {
 	var actions []*model.FlowAction
 	if err := impl.db.Where("team_id = ? AND workflow_id = ? AND version = ? AND type = ?", teamID, workflowID, version, actionType).Find(&actions).Error; err != nil {
@@ -101,6 +111,14 @@ func (impl *FlowActionStorage) RetrieveFlowActionsByTeamIDWorkflowIDVersionAndTy
 	return actions, nil
 }
 
+func (impl *FlowActionStorage) RetrieveFlowActionsByTeamIDAndResourceID(teamID int, resourceID int) ([]*model.FlowAction, error) {
+	var actions []*model.FlowAction
+	if err := impl.db.Where("team_id = ? AND resource_id = ?", teamID, resourceID).Order("workflow_id, version, name").Find(&actions).Error; err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
+
 func (impl *FlowActionStorage) RetrieveFlowActionByTeamIDFlowActionID(teamID int, flowActionID int) (*model.FlowAction, error) {
 	var action *model.FlowAction
 	if err := impl.db.Where("team_id = ? AND id = ?", teamID, flowActionID).First(&action).Error; err != nil {
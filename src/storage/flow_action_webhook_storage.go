@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"github.com/illacloud/builder-backend/src/model"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FlowActionWebhookStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewFlowActionWebhookStorage(logger *zap.SugaredLogger, db *gorm.DB) *FlowActionWebhookStorage {
+	return &FlowActionWebhookStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// CreateOrRetrieve create the webhook of trigger, the existing one is returned when the trigger already has a webhook.
+func (impl *FlowActionWebhookStorage) CreateOrRetrieve(webhook *model.FlowActionWebhook) (*model.FlowActionWebhook, error) {
+	if err := impl.db.Clauses(clause.OnConflict{DoNothing: true}).Create(webhook).Error; err != nil {
+		return nil, err
+	}
+	return impl.RetrieveByTeamIDWorkflowIDAndTriggerName(webhook.TeamID, webhook.WorkflowID, webhook.TriggerName)
+}
+
+func (impl *FlowActionWebhookStorage) UpdateWholeFlowActionWebhook(webhook *model.FlowActionWebhook) error {
+	if err := impl.db.Model(webhook).Select("*").Where("id = ?", webhook.ID).Updates(webhook).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (impl *FlowActionWebhookStorage) RetrieveByTeamIDWorkflowIDAndTriggerName(teamID int, workflowID int, triggerName string) (*model.FlowActionWebhook, error) {
+	var webhook *model.FlowActionWebhook
+	if err := impl.db.Where("team_id = ? AND workflow_id = ? AND trigger_name = ?", teamID, workflowID, triggerName).First(&webhook).Error; err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (impl *FlowActionWebhookStorage) RetrieveByUID(uid string) (*model.FlowActionWebhook, error) {
+	var webhook *model.FlowActionWebhook
+	if err := impl.db.Where("uid = ?", uid).First(&webhook).Error; err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/illacloud/builder-backend/src/model"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ResourceStorage struct {
@@ -42,6 +43,21 @@ func (impl *ResourceStorage) RetrieveByTeamIDAndResourceID(teamID int, resourceI
 	return resource, nil
 }
 
+// LockByTeamIDAndResourceID lock the resource row until the transaction ends, call it with the transaction storage.
+// the resource deletion takes the exclusive lock and saving the actions using the resource takes the shared lock,
+// so the action saved while deleting is either in the usage collected by deletion, or refused since the resource is gone.
+func (impl *ResourceStorage) LockByTeamIDAndResourceID(teamID int, resourceID int, exclusive bool) error {
+	strength := "SHARE"
+	if exclusive {
+		strength = "UPDATE"
+	}
+	var resource *model.Resource
+	if err := impl.db.Clauses(clause.Locking{Strength: strength}).Where("id = ? AND team_id = ?", resourceID, teamID).First(&resource).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
 func (impl *ResourceStorage) RetrieveByTeamID(teamID int) ([]*model.Resource, error) {
 	var resources []*model.Resource
 	if err := impl.db.Where("team_id = ?", teamID).Find(&resources).Error; err != nil {
@@ -58,6 +74,15 @@ func (impl *ResourceStorage) RetrieveAllByUpdatedTime(teamID int) ([]*model.Reso
 	return resources, nil
 }
 
+// RetrieveBatchAfterID retrieve the resources of all teams in id order, for walking through all resources batch by batch.
+func (impl *ResourceStorage) RetrieveBatchAfterID(afterID int, limit int) ([]*model.Resource, error) {
+	var resources []*model.Resource
+	if err := impl.db.Where("id > ?", afterID).Order("id").Limit(limit).Find(&resources).Error; err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
 func (impl *ResourceStorage) CountResourceByTeamID(teamID int) (int, error) {
 	var count int64
 	if err := impl.db.Model(&model.Resource{}).Where("team_id = ?", teamID).Count(&count).Error; err != nil {
@@ -6,25 +6,52 @@ import (
 )
 
 type Storage struct {
-	AppStorage         *AppStorage
-	ActionStorage      *ActionStorage
-	FlowActionStorage  *FlowActionStorage
-	AppSnapshotStorage *AppSnapshotStorage
-	KVStateStorage     *KVStateStorage
-	ResourceStorage    *ResourceStorage
-	SetStateStorage    *SetStateStorage
-	TreeStateStorage   *TreeStateStorage
+	AppStorage               *AppStorage
+	ActionStorage            *ActionStorage
+	ActionScheduleStorage    *ActionScheduleStorage
+	ActionScheduleRunStorage *ActionScheduleRunStorage
+	ActionRunLogStorage      *ActionRunLogStorage
+	FlowActionStorage        *FlowActionStorage
+	FlowActionWebhookStorage *FlowActionWebhookStorage
+	AppSnapshotStorage       *AppSnapshotStorage
+	KVStateStorage           *KVStateStorage
+	ResourceStorage          *ResourceStorage
+	SetStateStorage          *SetStateStorage
+	TeamVariableStorage      *TeamVariableStorage
+	TreeStateStorage         *TreeStateStorage
+	logger                   *zap.SugaredLogger
+	db                       *gorm.DB
 }
 
 func NewStorage(postgresDriver *gorm.DB, logger *zap.SugaredLogger) *Storage {
 	return &Storage{
-		AppStorage:         NewAppStorage(logger, postgresDriver),
-		ActionStorage:      NewActionStorage(logger, postgresDriver),
-		FlowActionStorage:  NewFlowActionStorage(logger, postgresDriver),
-		AppSnapshotStorage: NewAppSnapshotStorage(logger, postgresDriver),
-		KVStateStorage:     NewKVStateStorage(logger, postgresDriver),
-		ResourceStorage:    NewResourceStorage(logger, postgresDriver),
-		SetStateStorage:    NewSetStateStorage(logger, postgresDriver),
-		TreeStateStorage:   NewTreeStateStorage(logger, postgresDriver),
+		AppStorage:               NewAppStorage(logger, postgresDriver),
+		ActionStorage:            NewActionStorage(logger, postgresDriver),
+		ActionScheduleStorage:    NewActionScheduleStorage(logger, postgresDriver),
+		ActionScheduleRunStorage: NewActionScheduleRunStorage(logger, postgresDriver),
+		ActionRunLogStorage:      NewActionRunLogStorage(logger, postgresDriver),
+		FlowActionStorage:        NewFlowActionStorage(logger, postgresDriver),
+		FlowActionWebhookStorage: NewFlowActionWebhookStorage(logger, postgresDriver),
+		AppSnapshotStorage:       NewAppSnapshotStorage(logger, postgresDriver),
+		KVStateStorage:           NewKVStateStorage(logger, postgresDriver),
+		ResourceStorage:          NewResourceStorage(logger, postgresDriver),
+		SetStateStorage:          NewSetStateStorage(logger, postgresDriver),
+		TeamVariableStorage:      NewTeamVariableStorage(logger, postgresDriver),
+		TreeStateStorage:         NewTreeStateStorage(logger, postgresDriver),
+		logger:                   logger,
+		db:                       postgresDriver,
 	}
 }
+
+// WithTx return a storage which all methods run with the given transaction handle.
+func (s *Storage) WithTx(tx *gorm.DB) *Storage {
+	return NewStorage(tx, s.logger)
+}
+
+// Transaction run process in a database transaction, the process should use txStorage for all database operations.
+// the transaction will be committed when process return nil, otherwise rollback.
+func (s *Storage) Transaction(process func(txStorage *Storage) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return process(s.WithTx(tx))
+	})
+}
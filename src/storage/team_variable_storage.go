@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"github.com/illacloud/builder-backend/src/model"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type TeamVariableStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewTeamVariableStorage(logger *zap.SugaredLogger, db *gorm.DB) *TeamVariableStorage {
+	return &TeamVariableStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (impl *TeamVariableStorage) Create(teamVariable *model.TeamVariable) (int, error) {
+	if err := impl.db.Create(teamVariable).Error; err != nil {
+		return 0, err
+	}
+	return teamVariable.ID, nil
+}
+
+func (impl *TeamVariableStorage) UpdateWholeTeamVariable(teamVariable *model.TeamVariable) error {
+	// use Select("*") for update the zero value fields like the cleared value
+	if err := impl.db.Model(teamVariable).Select("*").Where("id = ?", teamVariable.ID).Updates(teamVariable).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (impl *TeamVariableStorage) RetrieveByTeamIDAndID(teamID int, teamVariableID int) (*model.TeamVariable, error) {
+	var teamVariable *model.TeamVariable
+	if err := impl.db.Where("team_id = ? AND id = ?", teamID, teamVariableID).First(&teamVariable).Error; err != nil {
+		return nil, err
+	}
+	return teamVariable, nil
+}
+
+func (impl *TeamVariableStorage) RetrieveByTeamID(teamID int) ([]*model.TeamVariable, error) {
+	var teamVariables []*model.TeamVariable
+	if err := impl.db.Where("team_id = ?", teamID).Order("name asc").Find(&teamVariables).Error; err != nil {
+		return nil, err
+	}
+	return teamVariables, nil
+}
+
+// CountByTeamIDNameAndKind count the variables with the same placeholder, the excludeID is skipped for the updating variable.
+func (impl *TeamVariableStorage) CountByTeamIDNameAndKind(teamID int, name string, isSecret bool, excludeID int) (int64, error) {
+	var count int64
+	if err := impl.db.Model(&model.TeamVariable{}).Where("team_id = ? AND name = ? AND is_secret = ? AND id <> ?", teamID, name, isSecret, excludeID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (impl *TeamVariableStorage) DeleteByTeamIDAndID(teamID int, teamVariableID int) error {
+	if err := impl.db.Where("team_id = ? AND id = ?", teamID, teamVariableID).Delete(&model.TeamVariable{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -36,6 +36,8 @@ const (
 	AUDIT_LOG_RUN_ACTION = 9
 
 	AUDIT_LOG_TRIGGER_TASK = 10
+
+	AUDIT_LOG_EXPORT_APP = 11
 )
 
 const (
@@ -192,7 +194,7 @@ func (a *AuditLogger) Log(logInfo *LogInfo) {
 	// Context data
 	contextData := make(map[string]interface{})
 	switch logInfo.EventType {
-	case AUDIT_LOG_CREATE_APP, AUDIT_LOG_EDIT_APP, AUDIT_LOG_DELETE_APP, AUDIT_LOG_VIEW_APP, AUDIT_LOG_DEPLOY_APP:
+	case AUDIT_LOG_CREATE_APP, AUDIT_LOG_EDIT_APP, AUDIT_LOG_DELETE_APP, AUDIT_LOG_VIEW_APP, AUDIT_LOG_DEPLOY_APP, AUDIT_LOG_EXPORT_APP:
 		if logInfo.AppName == "" {
 			logInfo.AppID = -1
 			logInfo.AppName = "Tutorial App"
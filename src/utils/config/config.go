@@ -56,6 +56,7 @@ type Config struct {
 	WebsocketServerConnectionHostCenterEurope string `env:"ILLA_WEBSOCKET_CONNECTION_HOST_CENTER_EUROPE" envDefault:"0.0.0.0"`
 	WebsocketServerConnectionPortCenterEurope string `env:"ILLA_WEBSOCKET_CONNECTION_PORT_CENTER_EUROPE" envDefault:"80"`
 	WSSEnabled                                string `env:"ILLA_WSS_ENABLED" envDefault:"false"`
+	WebsocketClusterEnabled                   string `env:"ILLA_WEBSOCKET_CLUSTER_ENABLED" envDefault:"false"`
 
 	// key for idconvertor
 	RandomKey string `env:"ILLA_RANDOM_KEY"  envDefault:"abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"`
@@ -80,6 +81,23 @@ type Config struct {
 	DriveTeamBucketName   string `env:"ILLA_DRIVE_TEAM_BUCKET_NAME" envDefault:"illa-cloud-team"`
 	DriveUploadTimeoutRaw string `env:"ILLA_DRIVE_UPLOAD_TIMEOUT" envDefault:"30s"`
 	DriveUploadTimeout    time.Duration
+	// action run config
+	ActionRunTimeoutRaw string `env:"ILLA_ACTION_RUN_TIMEOUT" envDefault:"120s"`
+	ActionRunTimeout    time.Duration
+	// action result limits, the result limits of resource can not exceed them
+	ActionResultMaxRows  int   `env:"ILLA_ACTION_RESULT_MAX_ROWS" envDefault:"100000"`
+	ActionResultMaxBytes int64 `env:"ILLA_ACTION_RESULT_MAX_BYTES" envDefault:"67108864"`
+	// the max concurrent runs of a batch run request
+	ActionBatchRunConcurrency int `env:"ILLA_ACTION_BATCH_RUN_CONCURRENCY" envDefault:"8"`
+	// action scheduler config
+	ActionSchedulerEnabled     string `env:"ILLA_ACTION_SCHEDULER_ENABLED" envDefault:"true"`
+	ActionSchedulerIntervalRaw string `env:"ILLA_ACTION_SCHEDULER_INTERVAL" envDefault:"10s"`
+	ActionSchedulerInterval    time.Duration
+	// the ttl of cached resource meta info, 0 disables the cache
+	ResourceMetaCacheTTLRaw string `env:"ILLA_RESOURCE_META_CACHE_TTL" envDefault:"1h"`
+	ResourceMetaCacheTTL    time.Duration
+	// websocket server internal API, for pushing server side events to rooms
+	WebsocketInternalRestAPI string `env:"ILLA_WEBSOCKET_INTERNAL_API" envDefault:"http://127.0.0.1:8002"`
 	// supervisor API
 	IllaSupervisorInternalRestAPI string `env:"ILLA_SUPERVISOR_INTERNAL_API" envDefault:"http://127.0.0.1:9001/api/v1"`
 
@@ -112,6 +130,18 @@ func getConfig() (*Config, error) {
 	if errInParseDuration != nil {
 		return nil, errInParseDuration
 	}
+	cfg.ActionRunTimeout, errInParseDuration = time.ParseDuration(cfg.ActionRunTimeoutRaw)
+	if errInParseDuration != nil {
+		return nil, errInParseDuration
+	}
+	cfg.ActionSchedulerInterval, errInParseDuration = time.ParseDuration(cfg.ActionSchedulerIntervalRaw)
+	if errInParseDuration != nil {
+		return nil, errInParseDuration
+	}
+	cfg.ResourceMetaCacheTTL, errInParseDuration = time.ParseDuration(cfg.ResourceMetaCacheTTLRaw)
+	if errInParseDuration != nil {
+		return nil, errInParseDuration
+	}
 	// ok
 	fmt.Printf("----------------\n")
 	fmt.Printf("run by following config: %+v\n", cfg)
@@ -158,6 +188,11 @@ func (c *Config) GetWebsocketProtocol() string {
 	return PROTOCOL_WEBSOCKET
 }
 
+// IsWebsocketClusterEnabled check if the websocket server fan-out messages to other replicas via redis pub/sub.
+func (c *Config) IsWebsocketClusterEnabled() bool {
+	return c.WebsocketClusterEnabled == "true"
+}
+
 func (c *Config) GetRuntimeEnv() string {
 	if c.IsCloudBetaMode() {
 		return DEPLOY_MODE_CLOUD_BETA
@@ -279,6 +314,42 @@ func (c *Config) GetMINIOTimeout() time.Duration {
 	return c.DriveUploadTimeout
 }
 
+func (c *Config) GetActionRunTimeout() time.Duration {
+	return c.ActionRunTimeout
+}
+
+func (c *Config) GetActionResultMaxRows() int {
+	return c.ActionResultMaxRows
+}
+
+func (c *Config) GetActionResultMaxBytes() int64 {
+	return c.ActionResultMaxBytes
+}
+
+func (c *Config) GetActionBatchRunConcurrency() int {
+	if c.ActionBatchRunConcurrency <= 0 {
+		return 1
+	}
+	return c.ActionBatchRunConcurrency
+}
+
+// IsActionSchedulerEnabled check if this instance runs the scheduled actions.
+func (c *Config) IsActionSchedulerEnabled() bool {
+	return c.ActionSchedulerEnabled == "true"
+}
+
+func (c *Config) GetActionSchedulerInterval() time.Duration {
+	return c.ActionSchedulerInterval
+}
+
+func (c *Config) GetResourceMetaCacheTTL() time.Duration {
+	return c.ResourceMetaCacheTTL
+}
+
+func (c *Config) GetWebsocketInternalRestAPI() string {
+	return c.WebsocketInternalRestAPI
+}
+
 func (c *Config) GetControlToken() string {
 	return c.ControlToken
 }
@@ -0,0 +1,214 @@
+package cronexpr
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule describe when a job should run.
+type Schedule interface {
+	// Next return the next activation time, later than the given time.
+	Next(t time.Time) time.Time
+}
+
+const (
+	DESCRIPTOR_EVERY   = "@every "
+	MIN_EVERY_INTERVAL = time.Second
+	// give up searching when the expression can not match in 5 years, like "0 0 30 2 *"
+	MAX_SEARCH_YEARS = 5
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type fieldBound struct {
+	min int
+	max int
+}
+
+var (
+	minuteBound = fieldBound{0, 59}
+	hourBound   = fieldBound{0, 23}
+	domBound    = fieldBound{1, 31}
+	monthBound  = fieldBound{1, 12}
+	dowBound    = fieldBound{0, 7} // 0 and 7 are both sunday
+)
+
+// CronSchedule is a standard 5 fields cron expression: minute hour day-of-month month day-of-week.
+type CronSchedule struct {
+	minute   uint64
+	hour     uint64
+	dom      uint64
+	month    uint64
+	dow      uint64
+	domStar  bool
+	dowStar  bool
+	location *time.Location
+}
+
+// IntervalSchedule run every fixed duration, defined by "@every 5m".
+type IntervalSchedule struct {
+	Interval time.Duration
+}
+
+// Parse parse the cron expression, supports:
+// - standard 5 fields like "*/5 * * * *", with list (1,2), range (1-5) and step (*/2, 1-10/3)
+// - descriptors like "@hourly", "@daily"
+// - fixed interval like "@every 30m"
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, errors.New("empty cron expression")
+	}
+	if strings.HasPrefix(spec, DESCRIPTOR_EVERY) {
+		interval, errInParseDuration := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, DESCRIPTOR_EVERY)))
+		if errInParseDuration != nil {
+			return nil, errors.New("invalid interval: " + errInParseDuration.Error())
+		}
+		if interval < MIN_EVERY_INTERVAL {
+			return nil, errors.New("interval should not less than " + MIN_EVERY_INTERVAL.String())
+		}
+		return &IntervalSchedule{Interval: interval}, nil
+	}
+	if expanded, hit := descriptors[spec]; hit {
+		spec = expanded
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.New("cron expression should have 5 fields, got " + strconv.Itoa(len(fields)))
+	}
+	schedule := &CronSchedule{location: time.UTC}
+	var errInParse error
+	if schedule.minute, errInParse = parseField(fields[0], minuteBound); errInParse != nil {
+		return nil, errors.New("invalid minute field: " + errInParse.Error())
+	}
+	if schedule.hour, errInParse = parseField(fields[1], hourBound); errInParse != nil {
+		return nil, errors.New("invalid hour field: " + errInParse.Error())
+	}
+	if schedule.dom, errInParse = parseField(fields[2], domBound); errInParse != nil {
+		return nil, errors.New("invalid day of month field: " + errInParse.Error())
+	}
+	if schedule.month, errInParse = parseField(fields[3], monthBound); errInParse != nil {
+		return nil, errors.New("invalid month field: " + errInParse.Error())
+	}
+	if schedule.dow, errInParse = parseField(fields[4], dowBound); errInParse != nil {
+		return nil, errors.New("invalid day of week field: " + errInParse.Error())
+	}
+	// sunday can be 0 or 7
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+	schedule.domStar = strings.HasPrefix(fields[2], "*")
+	schedule.dowStar = strings.HasPrefix(fields[4], "*")
+	return schedule, nil
+}
+
+func parseField(field string, bound fieldBound) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		partBits, errInParse := parsePart(part, bound)
+		if errInParse != nil {
+			return 0, errInParse
+		}
+		bits |= partBits
+	}
+	return bits, nil
+}
+
+func parsePart(part string, bound fieldBound) (uint64, error) {
+	step := 1
+	rangeAndStep := strings.SplitN(part, "/", 2)
+	if len(rangeAndStep) == 2 {
+		var errInAtoi error
+		step, errInAtoi = strconv.Atoi(rangeAndStep[1])
+		if errInAtoi != nil || step <= 0 {
+			return 0, errors.New("invalid step: " + part)
+		}
+	}
+	start, end := bound.min, bound.max
+	switch {
+	case rangeAndStep[0] == "*":
+	case strings.Contains(rangeAndStep[0], "-"):
+		startAndEnd := strings.SplitN(rangeAndStep[0], "-", 2)
+		var errInStart, errInEnd error
+		start, errInStart = strconv.Atoi(startAndEnd[0])
+		end, errInEnd = strconv.Atoi(startAndEnd[1])
+		if errInStart != nil || errInEnd != nil {
+			return 0, errors.New("invalid range: " + part)
+		}
+	default:
+		value, errInAtoi := strconv.Atoi(rangeAndStep[0])
+		if errInAtoi != nil {
+			return 0, errors.New("invalid value: " + part)
+		}
+		start = value
+		end = value
+		// "5/10" means start from 5 with step 10
+		if len(rangeAndStep) == 2 {
+			end = bound.max
+		}
+	}
+	if start < bound.min || end > bound.max || start > end {
+		return 0, errors.New("value out of range: " + part)
+	}
+	var bits uint64
+	for i := start; i <= end; i += step {
+		bits |= 1 << uint(i)
+	}
+	return bits, nil
+}
+
+func hasBit(bits uint64, i int) bool {
+	return bits&(1<<uint(i)) != 0
+}
+
+func (schedule *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := hasBit(schedule.dom, t.Day())
+	dowMatch := hasBit(schedule.dow, int(t.Weekday()))
+	// the standard cron behavior, when both day fields restricted, match either of them
+	if schedule.domStar || schedule.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// Next return the next matched minute after t, zero time when no match found.
+func (schedule *CronSchedule) Next(t time.Time) time.Time {
+	originLocation := t.Location()
+	t = t.In(schedule.location).Add(time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+	yearLimit := t.Year() + MAX_SEARCH_YEARS
+
+	for t.Year() <= yearLimit {
+		if !hasBit(schedule.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, schedule.location)
+			continue
+		}
+		if !schedule.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, schedule.location)
+			continue
+		}
+		if !hasBit(schedule.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, schedule.location)
+			continue
+		}
+		if !hasBit(schedule.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t.In(originLocation)
+	}
+	return time.Time{}
+}
+
+func (schedule *IntervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(schedule.Interval)
+}
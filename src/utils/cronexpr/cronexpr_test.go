@@ -0,0 +1,36 @@
+package cronexpr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	base := time.Date(2023, 5, 10, 10, 7, 30, 0, time.UTC) // wednesday
+	cases := []struct {
+		spec string
+		next time.Time
+	}{
+		{"*/5 * * * *", time.Date(2023, 5, 10, 10, 10, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2023, 5, 10, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2023, 5, 11, 0, 0, 0, 0, time.UTC)},
+		{"30 9 * * 1-5", time.Date(2023, 5, 11, 9, 30, 0, 0, time.UTC)},
+		{"0 0 1 */3 *", time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2023, 5, 14, 12, 0, 0, 0, time.UTC)},
+		{"@every 90s", base.Add(90 * time.Second)},
+	}
+	for _, c := range cases {
+		schedule, errInParse := Parse(c.spec)
+		assert.Nil(t, errInParse, c.spec)
+		assert.Equal(t, c.next, schedule.Next(base), c.spec)
+	}
+}
+
+func TestCronScheduleParseFailed(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "*/0 * * * *", "@every 1ms", "a b c d e"} {
+		_, errInParse := Parse(spec)
+		assert.NotNil(t, errInParse, spec)
+	}
+}
@@ -0,0 +1,49 @@
+package illawebsocketsdk
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/illacloud/builder-backend/src/utils/config"
+	"github.com/illacloud/builder-backend/src/utils/tokenvalidator"
+)
+
+const (
+	// api route part
+	PUSH_SCHEDULED_ACTION_RESULT_INTERNAL_API = "/api/v1/internal/teams/%d/apps/%d/scheduledActionResult"
+)
+
+const REQUEST_TIMEOUT = 10 * time.Second
+
+// IllaWebsocketRestAPI call the internal api of websocket server, for pushing server side events to rooms.
+type IllaWebsocketRestAPI struct {
+	Config    *config.Config
+	Validator *tokenvalidator.RequestTokenValidator
+}
+
+func NewIllaWebsocketRestAPI() *IllaWebsocketRestAPI {
+	return &IllaWebsocketRestAPI{
+		Config:    config.GetInstance(),
+		Validator: tokenvalidator.NewRequestTokenValidator(),
+	}
+}
+
+// PushScheduledActionResult broadcast the scheduled action result to all clients in app room.
+func (r *IllaWebsocketRestAPI) PushScheduledActionResult(teamID int, appID int, result *ScheduledActionResult) error {
+	client := resty.New().SetTimeout(REQUEST_TIMEOUT)
+	resp, err := client.R().
+		SetHeader("Request-Token", r.Validator.GenerateValidateToken(strconv.Itoa(teamID), strconv.Itoa(appID))).
+		SetBody(result).
+		Post(r.Config.GetWebsocketInternalRestAPI() + fmt.Sprintf(PUSH_SCHEDULED_ACTION_RESULT_INTERNAL_API, teamID, appID))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return errors.New(resp.String())
+	}
+	return nil
+}
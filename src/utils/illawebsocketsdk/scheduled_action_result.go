@@ -0,0 +1,14 @@
+package illawebsocketsdk
+
+import "time"
+
+type ScheduledActionResult struct {
+	ScheduleID string      `json:"scheduleID"`
+	ActionID   string      `json:"actionID"`
+	ActionName string      `json:"actionName"`
+	Status     string      `json:"status"`
+	Result     interface{} `json:"result"`
+	Error      string      `json:"error"`
+	StartedAt  time.Time   `json:"startedAt"`
+	Duration   int64       `json:"duration"`
+}
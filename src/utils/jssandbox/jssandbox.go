@@ -0,0 +1,232 @@
+package jssandbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"runtime/metrics"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+const (
+	DEFAULT_TIMEOUT               = 5 * time.Second
+	DEFAULT_MAX_HEAP_GROWTH_BYTES = 256 << 20
+	DEFAULT_MAX_INPUT_BYTES       = 8 << 20
+	DEFAULT_MAX_OUTPUT_BYTES      = 8 << 20
+	DEFAULT_MAX_CODE_BYTES        = 64 << 10
+	DEFAULT_MAX_LOG_LINES         = 100
+	MAX_CALL_STACK_SIZE           = 1024
+	MAX_LOG_LINE_LENGTH           = 1024
+	HEAP_CHECK_INTERVAL           = 10 * time.Millisecond
+	HEAP_OBJECTS_METRIC           = "/memory/classes/heap/objects:bytes"
+)
+
+var (
+	ErrTimeout                 = errors.New("javascript execution timeout")
+	ErrHeapGrowthLimitExceeded = errors.New("javascript execution interrupted since the server heap grew over the limit")
+	ErrCanceled                = errors.New("javascript execution canceled")
+)
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// Limits restrict the resource usage of one execution.
+// the runtime can not account the memory of one execution, so there is no per execution memory limit.
+// the MaxHeapGrowthBytes guards the server instead: the execution is interrupted when the heap of whole process grew more than it since the execution started,
+// which counts the allocations of concurrent executions and requests too.
+type Limits struct {
+	Timeout            time.Duration
+	MaxHeapGrowthBytes uint64
+	MaxInputBytes      int
+	MaxOutputBytes     int
+	MaxCodeBytes       int
+	MaxLogLines        int
+}
+
+func NewDefaultLimits() *Limits {
+	return &Limits{
+		Timeout:            DEFAULT_TIMEOUT,
+		MaxHeapGrowthBytes: DEFAULT_MAX_HEAP_GROWTH_BYTES,
+		MaxInputBytes:      DEFAULT_MAX_INPUT_BYTES,
+		MaxOutputBytes:     DEFAULT_MAX_OUTPUT_BYTES,
+		MaxCodeBytes:       DEFAULT_MAX_CODE_BYTES,
+		MaxLogLines:        DEFAULT_MAX_LOG_LINES,
+	}
+}
+
+type Result struct {
+	Value interface{}
+	Logs  []string
+}
+
+// Run execute code as the body of a function, the inputs are passed as the function arguments by name.
+// every execution has its own runtime, only the ECMAScript builtins and a console are available,
+// there is no module loader, network or filesystem access.
+// the inputs and the return value are exchanged by JSON, so no host object leaks into the runtime.
+func Run(ctx context.Context, code string, inputs map[string]interface{}, limits *Limits) (*Result, error) {
+	if limits == nil {
+		limits = NewDefaultLimits()
+	}
+	if len(code) > limits.MaxCodeBytes {
+		return nil, fmt.Errorf("javascript code exceeds %d bytes", limits.MaxCodeBytes)
+	}
+
+	// serialize inputs
+	names := make([]string, 0, len(inputs))
+	for name := range inputs {
+		if !identifierPattern.MatchString(name) {
+			return nil, errors.New("invalid input name: " + name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	inputsInJSON, errInMarshal := json.Marshal(inputs)
+	if errInMarshal != nil {
+		return nil, errors.New("serialize inputs failed: " + errInMarshal.Error())
+	}
+	if len(inputsInJSON) > limits.MaxInputBytes {
+		return nil, fmt.Errorf("javascript inputs exceed %d bytes", limits.MaxInputBytes)
+	}
+
+	// compile first, so syntax errors are reported without starting the runtime
+	wrapped := "(function(" + strings.Join(names, ", ") + ") {\n" + code + "\n})"
+	program, errInCompile := goja.Compile("transformer.js", wrapped, true)
+	if errInCompile != nil {
+		return nil, errors.New("compile javascript failed: " + errInCompile.Error())
+	}
+
+	vm := goja.New()
+	vm.SetMaxCallStackSize(MAX_CALL_STACK_SIZE)
+	logs := make([]string, 0)
+	installConsole(vm, &logs, limits.MaxLogLines)
+
+	// watch the execution, interrupt it when timeout, canceled or the heap grew over the limit
+	done := make(chan struct{})
+	defer close(done)
+	go watch(ctx, vm, limits, readHeapBytes, done)
+
+	function, errInRun := vm.RunProgram(program)
+	if errInRun != nil {
+		return nil, convertError(errInRun)
+	}
+	callable, assertPass := goja.AssertFunction(function)
+	if !assertPass {
+		return nil, errors.New("javascript code is not a function body")
+	}
+	args := make([]goja.Value, 0, len(names))
+	parse, _ := goja.AssertFunction(vm.Get("JSON").ToObject(vm).Get("parse"))
+	for _, name := range names {
+		inputInJSON, _ := json.Marshal(inputs[name])
+		arg, errInParse := parse(goja.Undefined(), vm.ToValue(string(inputInJSON)))
+		if errInParse != nil {
+			return nil, convertError(errInParse)
+		}
+		args = append(args, arg)
+	}
+	returned, errInCall := callable(goja.Undefined(), args...)
+	if errInCall != nil {
+		return nil, convertError(errInCall)
+	}
+
+	// export the return value by JSON
+	result := &Result{Logs: logs}
+	if returned == nil || goja.IsUndefined(returned) || goja.IsNull(returned) {
+		return result, nil
+	}
+	stringify, _ := goja.AssertFunction(vm.Get("JSON").ToObject(vm).Get("stringify"))
+	returnedInJSON, errInStringify := stringify(goja.Undefined(), returned)
+	if errInStringify != nil {
+		return nil, convertError(errInStringify)
+	}
+	if goja.IsUndefined(returnedInJSON) {
+		return result, nil
+	}
+	output := returnedInJSON.String()
+	if len(output) > limits.MaxOutputBytes {
+		return nil, fmt.Errorf("javascript return value exceeds %d bytes", limits.MaxOutputBytes)
+	}
+	if errInUnmarshal := json.Unmarshal([]byte(output), &result.Value); errInUnmarshal != nil {
+		return nil, errors.New("export return value failed: " + errInUnmarshal.Error())
+	}
+	return result, nil
+}
+
+func installConsole(vm *goja.Runtime, logs *[]string, maxLines int) {
+	log := func(call goja.FunctionCall) goja.Value {
+		if len(*logs) >= maxLines {
+			return goja.Undefined()
+		}
+		parts := make([]string, 0, len(call.Arguments))
+		for _, argument := range call.Arguments {
+			parts = append(parts, argument.String())
+		}
+		line := strings.Join(parts, " ")
+		if len(line) > MAX_LOG_LINE_LENGTH {
+			line = line[:MAX_LOG_LINE_LENGTH]
+		}
+		*logs = append(*logs, line)
+		return goja.Undefined()
+	}
+	console := vm.NewObject()
+	console.Set("log", log)
+	console.Set("info", log)
+	console.Set("warn", log)
+	console.Set("error", log)
+	vm.Set("console", console)
+}
+
+func watch(ctx context.Context, vm *goja.Runtime, limits *Limits, readHeap func() uint64, done chan struct{}) {
+	timer := time.NewTimer(limits.Timeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(HEAP_CHECK_INTERVAL)
+	defer ticker.Stop()
+	baseline := readHeap()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			vm.Interrupt(ErrCanceled)
+			return
+		case <-timer.C:
+			vm.Interrupt(ErrTimeout)
+			return
+		case <-ticker.C:
+			if current := readHeap(); current > baseline && current-baseline > limits.MaxHeapGrowthBytes {
+				vm.Interrupt(ErrHeapGrowthLimitExceeded)
+				return
+			}
+		}
+	}
+}
+
+// readHeapBytes read the heap of whole process, it is replaced in tests.
+var readHeapBytes = heapObjectsBytes
+
+func heapObjectsBytes() uint64 {
+	samples := []metrics.Sample{{Name: HEAP_OBJECTS_METRIC}}
+	metrics.Read(samples)
+	if samples[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return samples[0].Value.Uint64()
+}
+
+func convertError(err error) error {
+	var interrupted *goja.InterruptedError
+	if errors.As(err, &interrupted) {
+		if reason, assertPass := interrupted.Value().(error); assertPass {
+			return reason
+		}
+	}
+	var exception *goja.Exception
+	if errors.As(err, &exception) {
+		return errors.New("javascript error: " + exception.Value().String())
+	}
+	return err
+}
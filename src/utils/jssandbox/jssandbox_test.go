@@ -0,0 +1,51 @@
+package jssandbox
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	inputs := map[string]interface{}{
+		"data":    []interface{}{map[string]interface{}{"id": 1}, map[string]interface{}{"id": 2}},
+		"context": map[string]interface{}{"factor": 10},
+	}
+	result, errInRun := Run(context.Background(), `console.log("rows", data.length); return data.map(row => ({id: row.id * context.factor}))`, inputs, nil)
+	assert.Nil(t, errInRun)
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": float64(10)}, map[string]interface{}{"id": float64(20)}}, result.Value)
+	assert.Equal(t, []string{"rows 2"}, result.Logs)
+}
+
+func TestRunSandboxed(t *testing.T) {
+	_, errInRequire := Run(context.Background(), `return require("fs")`, nil, nil)
+	assert.NotNil(t, errInRequire)
+
+	_, errInThrow := Run(context.Background(), `throw new Error("boom")`, nil, nil)
+	assert.Contains(t, errInThrow.Error(), "boom")
+
+	_, errInSyntax := Run(context.Background(), `return (`, nil, nil)
+	assert.Contains(t, errInSyntax.Error(), "compile javascript failed")
+}
+
+func TestRunLimits(t *testing.T) {
+	limits := NewDefaultLimits()
+	limits.Timeout = 100 * time.Millisecond
+	_, errInLoop := Run(context.Background(), `while (true) {}`, nil, limits)
+	assert.Equal(t, ErrTimeout, errInLoop)
+
+	// the heap is faked to grow 1MB on each check, so the guard does not depend on the other goroutines
+	var heapBytes uint64
+	readHeapBytes = func() uint64 { return atomic.AddUint64(&heapBytes, 1<<20) }
+	defer func() { readHeapBytes = heapObjectsBytes }()
+	limits = NewDefaultLimits()
+	limits.MaxHeapGrowthBytes = 8 << 20
+	_, errInGrowth := Run(context.Background(), `while (true) {}`, nil, limits)
+	assert.Equal(t, ErrHeapGrowthLimitExceeded, errInGrowth)
+
+	_, errInRecursion := Run(context.Background(), `const f = () => f(); return f()`, nil, nil)
+	assert.NotNil(t, errInRecursion)
+}
@@ -87,8 +87,6 @@ func RefreshOAuthToken(refreshToken string) (*RefreshTokenResponse, error) {
 		}).
 		Post(GOOGLE_OAUTH2_API)
 
-	fmt.Printf("[DUMP] RefreshOAuthToken.resp:%+v\n", resp)
-	fmt.Printf("[DUMP] RefreshOAuthToken.resp.Body():%+v\n", string(resp.Body()))
 	fmt.Printf("[DUMP] RefreshOAuthToken.resp.IsError():%+v\n", resp.IsError())
 	if resp.IsError() {
 		return nil, errors.New("RefreshOAuthToken failed.")
@@ -0,0 +1,632 @@
+package parser_sql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/illacloud/builder-backend/src/utils/resourcelist"
+)
+
+// statement kinds
+const (
+	STATEMENT_KIND_UNKNOWN = iota
+	STATEMENT_KIND_READ
+	STATEMENT_KIND_DML
+	STATEMENT_KIND_DDL
+	STATEMENT_KIND_DCL
+	STATEMENT_KIND_TRANSACTION
+)
+
+var statementKindNameMap = map[int]string{
+	STATEMENT_KIND_UNKNOWN:     "unknown",
+	STATEMENT_KIND_READ:        "read",
+	STATEMENT_KIND_DML:         "dml",
+	STATEMENT_KIND_DDL:         "ddl",
+	STATEMENT_KIND_DCL:         "dcl",
+	STATEMENT_KIND_TRANSACTION: "transaction",
+}
+
+// dialects
+const (
+	SQL_DIALECT_ANSI = iota
+	SQL_DIALECT_MYSQL
+	SQL_DIALECT_POSTGRES
+	SQL_DIALECT_MSSQL
+	SQL_DIALECT_ORACLE
+	SQL_DIALECT_CLICKHOUSE
+	SQL_DIALECT_SNOWFLAKE
+)
+
+var SQLDialectMap = map[int]int{
+	resourcelist.TYPE_MYSQL_ID:      SQL_DIALECT_MYSQL,
+	resourcelist.TYPE_MARIADB_ID:    SQL_DIALECT_MYSQL,
+	resourcelist.TYPE_TIDB_ID:       SQL_DIALECT_MYSQL,
+	resourcelist.TYPE_POSTGRESQL_ID: SQL_DIALECT_POSTGRES,
+	resourcelist.TYPE_SUPABASEDB_ID: SQL_DIALECT_POSTGRES,
+	resourcelist.TYPE_NEON_ID:       SQL_DIALECT_POSTGRES,
+	resourcelist.TYPE_HYDRA_ID:      SQL_DIALECT_POSTGRES,
+	resourcelist.TYPE_MSSQL_ID:      SQL_DIALECT_MSSQL,
+	resourcelist.TYPE_ORACLE_ID:     SQL_DIALECT_ORACLE,
+	resourcelist.TYPE_ORACLE_9I_ID:  SQL_DIALECT_ORACLE,
+	resourcelist.TYPE_CLICKHOUSE_ID: SQL_DIALECT_CLICKHOUSE,
+	resourcelist.TYPE_SNOWFLAKE_ID:  SQL_DIALECT_SNOWFLAKE,
+}
+
+// the statement kind by the leading keyword, the keywords not listed are unknown.
+var leadingKeywordKinds = map[string]int{
+	"select":   STATEMENT_KIND_READ,
+	"with":     STATEMENT_KIND_READ,
+	"show":     STATEMENT_KIND_READ,
+	"describe": STATEMENT_KIND_READ,
+	"desc":     STATEMENT_KIND_READ,
+	"explain":  STATEMENT_KIND_READ,
+	"values":   STATEMENT_KIND_READ,
+	"table":    STATEMENT_KIND_READ,
+	"use":      STATEMENT_KIND_READ,
+
+	"insert":  STATEMENT_KIND_DML,
+	"update":  STATEMENT_KIND_DML,
+	"delete":  STATEMENT_KIND_DML,
+	"merge":   STATEMENT_KIND_DML,
+	"replace": STATEMENT_KIND_DML,
+	"upsert":  STATEMENT_KIND_DML,
+	"copy":    STATEMENT_KIND_DML,
+	"load":    STATEMENT_KIND_DML,
+	"put":     STATEMENT_KIND_DML,
+	"remove":  STATEMENT_KIND_DML,
+
+	"create":   STATEMENT_KIND_DDL,
+	"alter":    STATEMENT_KIND_DDL,
+	"drop":     STATEMENT_KIND_DDL,
+	"truncate": STATEMENT_KIND_DDL,
+	"rename":   STATEMENT_KIND_DDL,
+	"comment":  STATEMENT_KIND_DDL,
+	"optimize": STATEMENT_KIND_DDL,
+	"attach":   STATEMENT_KIND_DDL,
+	"detach":   STATEMENT_KIND_DDL,
+	"vacuum":   STATEMENT_KIND_DDL,
+	"analyze":  STATEMENT_KIND_DDL,
+	"reindex":  STATEMENT_KIND_DDL,
+	"cluster":  STATEMENT_KIND_DDL,
+	"refresh":  STATEMENT_KIND_DDL,
+	"undrop":   STATEMENT_KIND_DDL,
+
+	"grant":  STATEMENT_KIND_DCL,
+	"revoke": STATEMENT_KIND_DCL,
+	"deny":   STATEMENT_KIND_DCL,
+	"kill":   STATEMENT_KIND_DCL,
+
+	"begin":     STATEMENT_KIND_TRANSACTION,
+	"commit":    STATEMENT_KIND_TRANSACTION,
+	"rollback":  STATEMENT_KIND_TRANSACTION,
+	"savepoint": STATEMENT_KIND_TRANSACTION,
+	"release":   STATEMENT_KIND_TRANSACTION,
+	"end":       STATEMENT_KIND_TRANSACTION,
+	"abort":     STATEMENT_KIND_TRANSACTION,
+	"xa":        STATEMENT_KIND_TRANSACTION,
+}
+
+// the keywords which make a read statement write when they appear anywhere in it (not as function call),
+// like "WITH ... DELETE", "SELECT ... INTO", "EXPLAIN ANALYZE UPDATE" and the T-SQL statements without separator.
+var writeKeywordKinds = map[string]int{
+	"insert":   STATEMENT_KIND_DML,
+	"update":   STATEMENT_KIND_DML,
+	"delete":   STATEMENT_KIND_DML,
+	"merge":    STATEMENT_KIND_DML,
+	"into":     STATEMENT_KIND_DML,
+	"create":   STATEMENT_KIND_DDL,
+	"alter":    STATEMENT_KIND_DDL,
+	"drop":     STATEMENT_KIND_DDL,
+	"truncate": STATEMENT_KIND_DDL,
+	"grant":    STATEMENT_KIND_DCL,
+	"revoke":   STATEMENT_KIND_DCL,
+}
+
+var dialectWriteKeywordKinds = map[int]map[string]int{
+	SQL_DIALECT_MYSQL: {"replace": STATEMENT_KIND_DML},
+	SQL_DIALECT_MSSQL: {"exec": STATEMENT_KIND_UNKNOWN, "execute": STATEMENT_KIND_UNKNOWN, "deny": STATEMENT_KIND_DCL},
+}
+
+// the function calls which make a read statement write, since they have side effects on the server,
+// like "SELECT pg_terminate_backend(pid)" and "SELECT setval('seq', 1)".
+var dialectSideEffectFunctionKinds = map[int]map[string]int{
+	SQL_DIALECT_POSTGRES: {
+		"nextval":                             STATEMENT_KIND_DML,
+		"setval":                              STATEMENT_KIND_DML,
+		"lo_create":                           STATEMENT_KIND_DML,
+		"lo_creat":                            STATEMENT_KIND_DML,
+		"lo_import":                           STATEMENT_KIND_DML,
+		"lo_export":                           STATEMENT_KIND_DML,
+		"lo_unlink":                           STATEMENT_KIND_DML,
+		"lo_from_bytea":                       STATEMENT_KIND_DML,
+		"lo_put":                              STATEMENT_KIND_DML,
+		"lo_truncate":                         STATEMENT_KIND_DML,
+		"dblink_exec":                         STATEMENT_KIND_DML,
+		"pg_file_write":                       STATEMENT_KIND_DML,
+		"pg_file_unlink":                      STATEMENT_KIND_DML,
+		"pg_file_rename":                      STATEMENT_KIND_DML,
+		"set_config":                          STATEMENT_KIND_DCL,
+		"pg_terminate_backend":                STATEMENT_KIND_DCL,
+		"pg_cancel_backend":                   STATEMENT_KIND_DCL,
+		"pg_reload_conf":                      STATEMENT_KIND_DCL,
+		"pg_rotate_logfile":                   STATEMENT_KIND_DCL,
+		"pg_promote":                          STATEMENT_KIND_DCL,
+		"pg_switch_wal":                       STATEMENT_KIND_DCL,
+		"pg_create_restore_point":             STATEMENT_KIND_DCL,
+		"pg_create_physical_replication_slot": STATEMENT_KIND_DCL,
+		"pg_create_logical_replication_slot":  STATEMENT_KIND_DCL,
+		"pg_drop_replication_slot":            STATEMENT_KIND_DCL,
+		"pg_advisory_lock":                    STATEMENT_KIND_DCL,
+		"pg_advisory_xact_lock":               STATEMENT_KIND_DCL,
+		"pg_try_advisory_lock":                STATEMENT_KIND_DCL,
+		"pg_advisory_unlock_all":              STATEMENT_KIND_DCL,
+	},
+	SQL_DIALECT_MYSQL: {
+		"get_lock":          STATEMENT_KIND_DCL,
+		"release_lock":      STATEMENT_KIND_DCL,
+		"release_all_locks": STATEMENT_KIND_DCL,
+	},
+	SQL_DIALECT_MSSQL: {
+		"openrowset":     STATEMENT_KIND_UNKNOWN,
+		"opendatasource": STATEMENT_KIND_UNKNOWN,
+		"openquery":      STATEMENT_KIND_UNKNOWN,
+	},
+}
+
+// the objects which make create, alter and drop statements DCL
+var principalObjects = map[string]bool{
+	"user":  true,
+	"role":  true,
+	"login": true,
+	"group": true,
+}
+
+// the objects which body may contain semicolons, the statement lasts until the end of script
+var routineObjects = map[string]bool{
+	"procedure": true,
+	"function":  true,
+	"trigger":   true,
+	"event":     true,
+	"package":   true,
+	"type":      true,
+}
+
+const (
+	CLASSIFIER_TOKEN_WORD = iota
+	CLASSIFIER_TOKEN_LEFT_PAREN
+	CLASSIFIER_TOKEN_SEMICOLON
+	CLASSIFIER_TOKEN_OTHER
+)
+
+type classifierToken struct {
+	tokenType int
+	value     string
+	lineNum   int
+	pos       int
+}
+
+// the clauses which make the DML statement return the affected rows
+var returningClauseKeywords = map[int]string{
+	SQL_DIALECT_ANSI:       "returning",
+	SQL_DIALECT_MYSQL:      "returning",
+	SQL_DIALECT_POSTGRES:   "returning",
+	SQL_DIALECT_MSSQL:      "output",
+	SQL_DIALECT_ORACLE:     "returning",
+	SQL_DIALECT_CLICKHOUSE: "returning",
+	SQL_DIALECT_SNOWFLAKE:  "returning",
+}
+
+// ClassifiedStatement is a statement of the script, the Keyword is the keyword decided the kind.
+// the Returning is true when the statement returns the affected rows, like "RETURNING" in postgresql and "OUTPUT" in mssql.
+type ClassifiedStatement struct {
+	LineNum   int
+	Kind      int
+	Keyword   string
+	SQL       string
+	Returning bool
+}
+
+func (statement *ClassifiedStatement) ExportKindName() string {
+	return statementKindNameMap[statement.Kind]
+}
+
+func (statement *ClassifiedStatement) IsRead() bool {
+	return statement.Kind == STATEMENT_KIND_READ
+}
+
+func (statement *ClassifiedStatement) IsDML() bool {
+	return statement.Kind == STATEMENT_KIND_DML
+}
+
+// SQLClassifier split the script into statements and classify each of them by the dialect of resource.
+type SQLClassifier struct {
+	ResourceType int `json:"resourceType"`
+	dialect      int
+}
+
+func NewSQLClassifier(resourceType int) *SQLClassifier {
+	return &SQLClassifier{
+		ResourceType: resourceType,
+		dialect:      SQLDialectMap[resourceType],
+	}
+}
+
+// Classify split the script by the top level semicolons (the ones in strings, quoted identifiers and comments are ignored),
+// and classify every statement. the empty statements are skipped.
+func (classifier *SQLClassifier) Classify(sql string) ([]*ClassifiedStatement, error) {
+	tokens, errInTokenize := classifier.tokenize(sql)
+	if errInTokenize != nil {
+		return nil, errInTokenize
+	}
+	statements := make([]*ClassifiedStatement, 0)
+	for len(tokens) > 0 {
+		// skip empty statements
+		if tokens[0].tokenType == CLASSIFIER_TOKEN_SEMICOLON {
+			tokens = tokens[1:]
+			continue
+		}
+		end := len(tokens)
+		if !classifier.isBlockStatement(tokens) {
+			for i, token := range tokens {
+				if token.tokenType == CLASSIFIER_TOKEN_SEMICOLON {
+					end = i
+					break
+				}
+			}
+		}
+		statement := classifier.classifyStatement(tokens[:end])
+		statementEnd := len(sql)
+		if end < len(tokens) {
+			statementEnd = tokens[end].pos
+		}
+		statement.SQL = strings.TrimSpace(sql[tokens[0].pos:statementEnd])
+		statements = append(statements, statement)
+		tokens = tokens[end:]
+	}
+	return statements, nil
+}
+
+// CheckReadOnly return error when the script contains any statement other than read and transaction control.
+func (classifier *SQLClassifier) CheckReadOnly(sql string) (*ClassifiedStatement, error) {
+	statements, errInClassify := classifier.Classify(sql)
+	if errInClassify != nil {
+		return nil, errInClassify
+	}
+	for _, statement := range statements {
+		if statement.Kind == STATEMENT_KIND_READ || statement.Kind == STATEMENT_KIND_TRANSACTION {
+			continue
+		}
+		return statement, fmt.Errorf("line %d: %s statement (%s) is not allowed on read-only resource", statement.LineNum, statement.ExportKindName(), strings.ToUpper(statement.Keyword))
+	}
+	return nil, nil
+}
+
+// isBlockStatement check if the statement is a routine definition or a PL/SQL (T-SQL) block, which contains semicolons.
+func (classifier *SQLClassifier) isBlockStatement(tokens []*classifierToken) bool {
+	words := leadingWords(tokens, 5)
+	if len(words) == 0 {
+		return false
+	}
+	switch words[0] {
+	case "create":
+		for _, word := range words[1:] {
+			if routineObjects[word] {
+				return true
+			}
+		}
+	case "begin":
+		if classifier.dialect == SQL_DIALECT_ORACLE || classifier.dialect == SQL_DIALECT_MSSQL {
+			return !isTransactionBegin(words)
+		}
+	case "declare":
+		return classifier.dialect == SQL_DIALECT_ORACLE
+	}
+	return false
+}
+
+func (classifier *SQLClassifier) classifyStatement(tokens []*classifierToken) *ClassifiedStatement {
+	statement := &ClassifiedStatement{
+		LineNum: tokens[0].lineNum,
+		Kind:    STATEMENT_KIND_UNKNOWN,
+	}
+	words := leadingWords(tokens, 5)
+	if len(words) == 0 {
+		return statement
+	}
+	statement.Keyword = words[0]
+	kind, hit := leadingKeywordKinds[words[0]]
+	if !hit {
+		return statement
+	}
+	statement.Kind = kind
+
+	switch words[0] {
+	case "create", "alter", "drop":
+		for _, word := range words[1:] {
+			if principalObjects[word] {
+				statement.Kind = STATEMENT_KIND_DCL
+				break
+			}
+		}
+	case "begin":
+		if (classifier.dialect == SQL_DIALECT_ORACLE || classifier.dialect == SQL_DIALECT_MSSQL) && !isTransactionBegin(words) {
+			statement.Kind = STATEMENT_KIND_UNKNOWN
+		}
+	}
+	if statement.Kind == STATEMENT_KIND_DML {
+		statement.Returning = classifier.hasReturningClause(tokens)
+	}
+	if statement.Kind == STATEMENT_KIND_TRANSACTION && hasReadWriteAccessMode(tokens) {
+		// like "BEGIN READ WRITE", which escapes the read only session
+		statement.Kind = STATEMENT_KIND_UNKNOWN
+		statement.Keyword = "read write"
+		return statement
+	}
+	if statement.Kind != STATEMENT_KIND_READ {
+		return statement
+	}
+
+	// the read statement becomes write when any write keyword (not function call) or side effect function call inside
+	for i, token := range tokens {
+		if token.tokenType != CLASSIFIER_TOKEN_WORD {
+			continue
+		}
+		if i+1 < len(tokens) && tokens[i+1].tokenType == CLASSIFIER_TOKEN_LEFT_PAREN {
+			if sideEffectKind, hit := dialectSideEffectFunctionKinds[classifier.dialect][token.value]; hit {
+				statement.Kind = sideEffectKind
+				statement.Keyword = token.value
+				statement.LineNum = token.lineNum
+				return statement
+			}
+			continue
+		}
+		writeKind, hit := writeKeywordKinds[token.value]
+		if !hit {
+			writeKind, hit = dialectWriteKeywordKinds[classifier.dialect][token.value]
+		}
+		if hit {
+			statement.Kind = writeKind
+			statement.Keyword = token.value
+			statement.LineNum = token.lineNum
+			return statement
+		}
+	}
+	return statement
+}
+
+func (classifier *SQLClassifier) hasReturningClause(tokens []*classifierToken) bool {
+	for _, token := range tokens {
+		if token.tokenType == CLASSIFIER_TOKEN_WORD && token.value == returningClauseKeywords[classifier.dialect] {
+			return true
+		}
+	}
+	return false
+}
+
+func hasReadWriteAccessMode(tokens []*classifierToken) bool {
+	for i := 0; i+1 < len(tokens); i++ {
+		if tokens[i].value == "read" && tokens[i+1].value == "write" {
+			return true
+		}
+	}
+	return false
+}
+
+func isTransactionBegin(words []string) bool {
+	if len(words) < 2 {
+		return true
+	}
+	switch words[1] {
+	case "tran", "transaction", "distributed", "work":
+		return true
+	}
+	return false
+}
+
+// leadingWords return the first n words of statement, the leading parentheses are skipped.
+func leadingWords(tokens []*classifierToken, n int) []string {
+	words := make([]string, 0, n)
+	for _, token := range tokens {
+		if token.tokenType == CLASSIFIER_TOKEN_WORD {
+			words = append(words, token.value)
+			if len(words) == n {
+				break
+			}
+			continue
+		}
+		if token.tokenType == CLASSIFIER_TOKEN_LEFT_PAREN && len(words) == 0 {
+			continue
+		}
+		if len(words) == 0 {
+			break
+		}
+	}
+	return words
+}
+
+// isMySQLDoubleDashComment check the "--" starts a comment in mysql, which requires a whitespace or control character after it,
+// like "SELECT 1 --1" is "SELECT 1 - (-1)".
+func isMySQLDoubleDashComment(sql string) bool {
+	return len(sql) == 2 || sql[2] <= ' ' || sql[2] == 0x7f
+}
+
+// tokenize scan the words (in lowercase), left parentheses and semicolons of script,
+// the strings, quoted identifiers and comments are skipped by the dialect rules.
+func (classifier *SQLClassifier) tokenize(sql string) ([]*classifierToken, error) {
+	tokens := make([]*classifierToken, 0)
+	lineNum := 1
+	for pos := 0; pos < len(sql); {
+		c := sql[pos]
+		switch {
+		case c == '\n':
+			lineNum++
+			pos++
+		case isWhiteSpace(c):
+			pos++
+		case strings.HasPrefix(sql[pos:], "--") && (classifier.dialect != SQL_DIALECT_MYSQL || isMySQLDoubleDashComment(sql[pos:])),
+			c == '#' && (classifier.dialect == SQL_DIALECT_MYSQL || classifier.dialect == SQL_DIALECT_CLICKHOUSE),
+			strings.HasPrefix(sql[pos:], "//") && classifier.dialect == SQL_DIALECT_SNOWFLAKE:
+			for pos < len(sql) && sql[pos] != '\n' {
+				pos++
+			}
+		case strings.HasPrefix(sql[pos:], "/*!") && classifier.dialect == SQL_DIALECT_MYSQL:
+			// mysql executes the content of "/*! ... */" comment, so scan it as normal sql
+			pos += 3
+			for pos < len(sql) && isDigit(sql[pos]) {
+				pos++
+			}
+		case strings.HasPrefix(sql[pos:], "*/") && classifier.dialect == SQL_DIALECT_MYSQL:
+			pos += 2
+		case strings.HasPrefix(sql[pos:], "/*"):
+			end, lines, errInSkip := classifier.skipBlockComment(sql, pos)
+			if errInSkip != nil {
+				return nil, errInSkip
+			}
+			pos, lineNum = end, lineNum+lines
+		case c == '\'' || c == '"' || c == '`' || (c == '[' && classifier.dialect == SQL_DIALECT_MSSQL):
+			end, lines, errInSkip := classifier.skipQuoted(sql, pos)
+			if errInSkip != nil {
+				return nil, errInSkip
+			}
+			pos, lineNum = end, lineNum+lines
+		case c == '$' && (classifier.dialect == SQL_DIALECT_POSTGRES || classifier.dialect == SQL_DIALECT_SNOWFLAKE) && isDollarQuoteStart(sql[pos:]):
+			end, lines, errInSkip := skipDollarQuoted(sql, pos)
+			if errInSkip != nil {
+				return nil, errInSkip
+			}
+			pos, lineNum = end, lineNum+lines
+		case c == '_' || isLetter(c):
+			start := pos
+			for pos < len(sql) && (sql[pos] == '_' || sql[pos] == '$' || isLetter(sql[pos]) || isDigit(sql[pos])) {
+				pos++
+			}
+			word := strings.ToLower(sql[start:pos])
+			// the prefixed strings, like E'...' in postgresql and q'[...]' in oracle
+			if pos < len(sql) && sql[pos] == '\'' && (word == "e" || word == "n" || word == "q" || word == "x" || word == "b") {
+				end, lines, errInSkip := classifier.skipPrefixedString(sql, pos, word)
+				if errInSkip != nil {
+					return nil, errInSkip
+				}
+				pos, lineNum = end, lineNum+lines
+				continue
+			}
+			tokens = append(tokens, &classifierToken{tokenType: CLASSIFIER_TOKEN_WORD, value: word, lineNum: lineNum, pos: start})
+		case c == '(':
+			tokens = append(tokens, &classifierToken{tokenType: CLASSIFIER_TOKEN_LEFT_PAREN, value: "(", lineNum: lineNum, pos: pos})
+			pos++
+		case c == ';':
+			tokens = append(tokens, &classifierToken{tokenType: CLASSIFIER_TOKEN_SEMICOLON, value: ";", lineNum: lineNum, pos: pos})
+			pos++
+		default:
+			tokens = append(tokens, &classifierToken{tokenType: CLASSIFIER_TOKEN_OTHER, value: string(c), lineNum: lineNum, pos: pos})
+			pos++
+		}
+	}
+	return tokens, nil
+}
+
+// skipBlockComment skip the "/* */" comment, which is nestable in postgresql and mssql.
+func (classifier *SQLClassifier) skipBlockComment(sql string, pos int) (int, int, error) {
+	nestable := classifier.dialect == SQL_DIALECT_POSTGRES || classifier.dialect == SQL_DIALECT_MSSQL
+	depth, lines := 0, 0
+	for pos < len(sql) {
+		switch {
+		case strings.HasPrefix(sql[pos:], "/*") && (depth == 0 || nestable):
+			depth++
+			pos += 2
+		case strings.HasPrefix(sql[pos:], "*/"):
+			depth--
+			pos += 2
+			if depth == 0 {
+				return pos, lines, nil
+			}
+		default:
+			if sql[pos] == '\n' {
+				lines++
+			}
+			pos++
+		}
+	}
+	return pos, lines, errors.New("unterminated comment")
+}
+
+// skipQuoted skip the quoted string or identifier, the doubled quote is escaped,
+// and the backslash escapes in mysql and clickhouse.
+func (classifier *SQLClassifier) skipQuoted(sql string, pos int) (int, int, error) {
+	quote := sql[pos]
+	if quote == '[' {
+		quote = ']'
+	}
+	backslashEscape := quote != ']' && quote != '`' && (classifier.dialect == SQL_DIALECT_MYSQL || classifier.dialect == SQL_DIALECT_CLICKHOUSE)
+	return skipUntilQuote(sql, pos+1, quote, backslashEscape)
+}
+
+// skipPrefixedString skip the E'...' (with backslash escapes) and q'X...X' (oracle alternative quoting) strings.
+func (classifier *SQLClassifier) skipPrefixedString(sql string, pos int, prefix string) (int, int, error) {
+	if prefix == "q" && classifier.dialect == SQL_DIALECT_ORACLE && pos+1 < len(sql) {
+		closing := map[byte]byte{'[': ']', '{': '}', '(': ')', '<': '>'}
+		delimiter := sql[pos+1]
+		if matched, hit := closing[delimiter]; hit {
+			delimiter = matched
+		}
+		end := strings.Index(sql[pos+2:], string(delimiter)+"'")
+		if end < 0 {
+			return len(sql), 0, errors.New("unterminated string")
+		}
+		end += pos + 2 + 2
+		return end, strings.Count(sql[pos:end], "\n"), nil
+	}
+	backslashEscape := (prefix == "e" && classifier.dialect == SQL_DIALECT_POSTGRES) || classifier.dialect == SQL_DIALECT_MYSQL || classifier.dialect == SQL_DIALECT_CLICKHOUSE
+	return skipUntilQuote(sql, pos+1, '\'', backslashEscape)
+}
+
+func skipUntilQuote(sql string, pos int, quote byte, backslashEscape bool) (int, int, error) {
+	lines := 0
+	for pos < len(sql) {
+		c := sql[pos]
+		switch {
+		case c == '\\' && backslashEscape:
+			if pos+1 < len(sql) && sql[pos+1] == '\n' {
+				lines++
+			}
+			pos += 2
+			continue
+		case c == quote:
+			if pos+1 < len(sql) && sql[pos+1] == quote {
+				pos += 2
+				continue
+			}
+			return pos + 1, lines, nil
+		case c == '\n':
+			lines++
+		}
+		pos++
+	}
+	return len(sql), lines, errors.New("unterminated quoted string or identifier")
+}
+
+// isDollarQuoteStart check the "$tag$" start, the "$1" parameters are not dollar quote.
+func isDollarQuoteStart(sql string) bool {
+	for i := 1; i < len(sql); i++ {
+		c := sql[i]
+		if c == '$' {
+			return true
+		}
+		if !(c == '_' || isLetter(c) || (isDigit(c) && i > 1)) {
+			return false
+		}
+	}
+	return false
+}
+
+func skipDollarQuoted(sql string, pos int) (int, int, error) {
+	tagEnd := strings.IndexByte(sql[pos+1:], '$') + pos + 2
+	tag := sql[pos:tagEnd]
+	end := strings.Index(sql[tagEnd:], tag)
+	if end < 0 {
+		return len(sql), 0, errors.New("unterminated dollar-quoted string")
+	}
+	end += tagEnd + len(tag)
+	return end, strings.Count(sql[pos:end], "\n"), nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser_sql
+
+import (
+	"testing"
+
+	"github.com/illacloud/builder-backend/src/utils/resourcelist"
+	"github.com/stretchr/testify/assert"
+)
+
+func exportStatementKinds(statements []*ClassifiedStatement) []int {
+	kinds := make([]int, 0, len(statements))
+	for _, statement := range statements {
+		kinds = append(kinds, statement.Kind)
+	}
+	return kinds
+}
+
+func TestClassifyMultiStatementScript(t *testing.T) {
+	sql := `
+	-- comment with ; inside
+	SELECT * FROM users WHERE name = 'a;b';
+	WITH deleted AS (DELETE FROM users WHERE id = 1 RETURNING *) SELECT * FROM deleted;
+	BEGIN;
+	CREATE ROLE reader;
+	GRANT SELECT ON users TO reader;
+	SELECT replace(name, 'a', 'b') FROM users;
+	`
+	statements, err := NewSQLClassifier(resourcelist.TYPE_POSTGRESQL_ID).Classify(sql)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{
+		STATEMENT_KIND_READ,
+		STATEMENT_KIND_DML,
+		STATEMENT_KIND_TRANSACTION,
+		STATEMENT_KIND_DCL,
+		STATEMENT_KIND_DCL,
+		STATEMENT_KIND_READ,
+	}, exportStatementKinds(statements))
+	assert.Equal(t, 3, statements[0].LineNum)
+	assert.Equal(t, "delete", statements[1].Keyword)
+}
+
+func TestClassifySelectInto(t *testing.T) {
+	statements, err := NewSQLClassifier(resourcelist.TYPE_MSSQL_ID).Classify("SELECT * INTO backup FROM [users;]")
+	assert.Nil(t, err)
+	assert.Equal(t, []int{STATEMENT_KIND_DML}, exportStatementKinds(statements))
+}
+
+func TestClassifyDollarQuotedFunction(t *testing.T) {
+	sql := `CREATE FUNCTION f() RETURNS int AS $$ BEGIN DELETE FROM t; RETURN 1; END; $$ LANGUAGE plpgsql; SELECT $1`
+	statements, err := NewSQLClassifier(resourcelist.TYPE_POSTGRESQL_ID).Classify(sql)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{STATEMENT_KIND_DDL}, exportStatementKinds(statements))
+}
+
+func TestClassifyMySQLComments(t *testing.T) {
+	sql := "# comment; DROP TABLE t\nSELECT 'it\\'s;' FROM t /*! ; DELETE FROM t */"
+	statements, err := NewSQLClassifier(resourcelist.TYPE_MYSQL_ID).Classify(sql)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{STATEMENT_KIND_READ, STATEMENT_KIND_DML}, exportStatementKinds(statements))
+
+	// "--" without the following whitespace is not a comment in mysql
+	for _, resourceType := range []int{resourcelist.TYPE_MYSQL_ID, resourcelist.TYPE_MARIADB_ID, resourcelist.TYPE_TIDB_ID} {
+		_, err = NewSQLClassifier(resourceType).CheckReadOnly("SELECT secret --1 INTO OUTFILE '/tmp/x' FROM users")
+		assert.NotNil(t, err)
+		_, err = NewSQLClassifier(resourceType).CheckReadOnly("SELECT 1 -- INTO OUTFILE '/tmp/x'\n")
+		assert.Nil(t, err)
+	}
+}
+
+func TestCheckReadOnly(t *testing.T) {
+	classifier := NewSQLClassifier(resourcelist.TYPE_MYSQL_ID)
+	_, err := classifier.CheckReadOnly("SELECT 1; SHOW TABLES; COMMIT")
+	assert.Nil(t, err)
+	statement, err := classifier.CheckReadOnly("SELECT 1;\nUPDATE t SET a = 1")
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, statement.LineNum)
+	_, err = classifier.CheckReadOnly("SELECT 'unterminated")
+	assert.NotNil(t, err)
+}
+
+func TestCheckReadOnlySideEffects(t *testing.T) {
+	classifier := NewSQLClassifier(resourcelist.TYPE_POSTGRESQL_ID)
+	for _, sql := range []string{
+		"SELECT pg_terminate_backend(123)",
+		"SELECT pg_catalog.setval('users_id_seq', 1)",
+		"SELECT lo_export(16384, '/tmp/x')",
+		"BEGIN READ WRITE",
+	} {
+		_, err := classifier.CheckReadOnly(sql)
+		assert.NotNil(t, err, sql)
+	}
+	_, err := classifier.CheckReadOnly("SELECT setval FROM settings; BEGIN READ ONLY")
+	assert.Nil(t, err)
+	_, err = NewSQLClassifier(resourcelist.TYPE_MYSQL_ID).CheckReadOnly("SELECT GET_LOCK('lock', 10)")
+	assert.NotNil(t, err)
+}
+
+func TestClassifyReturningClause(t *testing.T) {
+	statements, err := NewSQLClassifier(resourcelist.TYPE_POSTGRESQL_ID).Classify("DELETE FROM users WHERE id = $1 RETURNING *")
+	assert.Nil(t, err)
+	assert.True(t, statements[0].Returning)
+	statements, err = NewSQLClassifier(resourcelist.TYPE_MSSQL_ID).Classify("UPDATE users SET name = 'a' OUTPUT inserted.* WHERE id = 1")
+	assert.Nil(t, err)
+	assert.True(t, statements[0].Returning)
+	statements, err = NewSQLClassifier(resourcelist.TYPE_MSSQL_ID).Classify("UPDATE users SET name = 'returning'")
+	assert.Nil(t, err)
+	assert.False(t, statements[0].Returning)
+}
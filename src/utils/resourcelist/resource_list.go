@@ -1,5 +1,10 @@
 package resourcelist
 
+import (
+	"fmt"
+	"sync"
+)
+
 var (
 	TYPE_TRANSFORMER             = "transformer"
 	TYPE_RESTAPI                 = "restapi"
@@ -72,7 +77,7 @@ var (
 	TYPE_SERVER_SIDE_TRANSFORMER_ID = 32
 )
 
-var type_array = []string{
+var type_array = map[int]string{
 	0:  TYPE_TRANSFORMER,
 	1:  TYPE_RESTAPI,
 	2:  TYPE_GRAPHQL,
@@ -145,13 +150,15 @@ var type_map = map[string]int{
 }
 
 var virtualResourceList = map[string]bool{
-	TYPE_TRANSFORMER: true,
-	TYPE_AI_AGENT:    true,
-	TYPE_ILLA_DRIVE:  true,
+	TYPE_TRANSFORMER:             true,
+	TYPE_AI_AGENT:                true,
+	TYPE_ILLA_DRIVE:              true,
+	TYPE_SERVER_SIDE_TRANSFORMER: true,
 }
 
 var localVirtualResourceList = map[string]bool{
-	TYPE_TRANSFORMER: true,
+	TYPE_TRANSFORMER:             true,
+	TYPE_SERVER_SIDE_TRANSFORMER: true,
 }
 
 var remoteVirtualResourceList = map[string]bool{
@@ -160,7 +167,8 @@ var remoteVirtualResourceList = map[string]bool{
 }
 
 var emptyOptionResourceList = map[string]bool{
-	TYPE_TRANSFORMER: true,
+	TYPE_TRANSFORMER:             true,
+	TYPE_SERVER_SIDE_TRANSFORMER: true,
 }
 
 var canCreateOAuthTokenResourceList = map[string]bool{
@@ -171,11 +179,44 @@ var needFetchResourceInfoFromSourceManagerList = map[string]bool{
 	TYPE_AI_AGENT: true,
 }
 
+// secretFieldsList is the secret fields in resource options declared by the connector of every type (see RegisterSecretFields),
+// the field path is split by ".", these fields will be encrypted at rest and masked in api response.
+var secretFieldsList = map[string][]string{}
+
+// typeRegistryLock guard the type maps for the types registered by connectors
+var typeRegistryLock sync.RWMutex
+
+// RegisterResourceType add the resource type declared by connector, registering a built-in type with its own ID is a no-op.
+// it panics when the name or the ID is taken by another type.
+func RegisterResourceType(name string, id int) {
+	typeRegistryLock.Lock()
+	defer typeRegistryLock.Unlock()
+	if registeredID, hit := type_map[name]; hit && registeredID != id {
+		panic(fmt.Sprintf("resourcelist: resource type %s is registered with ID %d", name, registeredID))
+	}
+	if registeredName, hit := type_array[id]; hit && registeredName != name {
+		panic(fmt.Sprintf("resourcelist: resource type ID %d is registered by %s", id, registeredName))
+	}
+	type_map[name] = id
+	type_array[id] = name
+}
+
+// RegisterSecretFields declare the secret fields in resource options of the registered type.
+func RegisterSecretFields(name string, secretFields []string) {
+	typeRegistryLock.Lock()
+	defer typeRegistryLock.Unlock()
+	secretFieldsList[name] = secretFields
+}
+
 func GetResourceIDMappedType(id int) string {
+	typeRegistryLock.RLock()
+	defer typeRegistryLock.RUnlock()
 	return type_array[id]
 }
 
 func GetResourceNameMappedID(name string) int {
+	typeRegistryLock.RLock()
+	defer typeRegistryLock.RUnlock()
 	return type_map[name]
 }
 
@@ -229,3 +270,10 @@ func NeedFetchResourceInfoFromSourceManager(resourceType string) bool {
 	itIs, hit := needFetchResourceInfoFromSourceManagerList[resourceType]
 	return itIs && hit
 }
+
+func GetSecretFieldsByIntType(resourceType int) []string {
+	resourceTypeString := GetResourceIDMappedType(resourceType)
+	typeRegistryLock.RLock()
+	defer typeRegistryLock.RUnlock()
+	return secretFieldsList[resourceTypeString]
+}
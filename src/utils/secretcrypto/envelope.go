@@ -0,0 +1,78 @@
+package secretcrypto
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+)
+
+const (
+	ENVELOPE_PREFIX   = "illa-enc:v1:"
+	DATA_KEY_LENGTH   = 32
+	ENVELOPE_PART_NUM = 2
+)
+
+// IsEncryptedValue check if the value is an envelope produced by EncryptValue.
+func IsEncryptedValue(value interface{}) bool {
+	valueAsserted, assertPass := value.(string)
+	return assertPass && strings.HasPrefix(valueAsserted, ENVELOPE_PREFIX)
+}
+
+// EncryptValue encrypt value with a random data key, and the data key is wrapped by KeyManager.
+// the value is json encoded first, so non-string values (like firebase private key object) are supported.
+// the output format is "illa-enc:v1:<wrapped data key>:<sealed value>" in base64.
+func EncryptValue(value interface{}) (string, error) {
+	plaintext, errInMarshal := json.Marshal(value)
+	if errInMarshal != nil {
+		return "", errInMarshal
+	}
+	dataKey := make([]byte, DATA_KEY_LENGTH)
+	if _, errInReadKey := io.ReadFull(rand.Reader, dataKey); errInReadKey != nil {
+		return "", errInReadKey
+	}
+	sealed, errInSeal := sealWithKey(dataKey, plaintext)
+	if errInSeal != nil {
+		return "", errInSeal
+	}
+	wrappedKey, errInWrapKey := GetKeyManager().WrapKey(dataKey)
+	if errInWrapKey != nil {
+		return "", errInWrapKey
+	}
+	return ENVELOPE_PREFIX + base64.StdEncoding.EncodeToString(wrappedKey) + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptValue decrypt the envelope produced by EncryptValue.
+// the value which is not an envelope (like the legacy plaintext option) will be returned as is.
+func DecryptValue(value interface{}) (interface{}, error) {
+	if !IsEncryptedValue(value) {
+		return value, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(value.(string), ENVELOPE_PREFIX), ":")
+	if len(parts) != ENVELOPE_PART_NUM {
+		return nil, errors.New("invalid encrypted value")
+	}
+	wrappedKey, errInDecodeKey := base64.StdEncoding.DecodeString(parts[0])
+	if errInDecodeKey != nil {
+		return nil, errInDecodeKey
+	}
+	sealed, errInDecodeValue := base64.StdEncoding.DecodeString(parts[1])
+	if errInDecodeValue != nil {
+		return nil, errInDecodeValue
+	}
+	dataKey, errInUnwrapKey := GetKeyManager().UnwrapKey(wrappedKey)
+	if errInUnwrapKey != nil {
+		return nil, errInUnwrapKey
+	}
+	plaintext, errInOpen := openWithKey(dataKey, sealed)
+	if errInOpen != nil {
+		return nil, errInOpen
+	}
+	var decrypted interface{}
+	if errInUnmarshal := json.Unmarshal(plaintext, &decrypted); errInUnmarshal != nil {
+		return nil, errInUnmarshal
+	}
+	return decrypted, nil
+}
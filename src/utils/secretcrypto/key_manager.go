@@ -0,0 +1,97 @@
+package secretcrypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/illacloud/builder-backend/src/utils/config"
+)
+
+// KeyManager wrap and unwrap the data keys used by envelope encryption.
+// the default LocalKeyManager derive the master key from ILLA_SECRET_KEY,
+// an external KMS can be plugged in by SetKeyManager.
+type KeyManager interface {
+	WrapKey(dataKey []byte) ([]byte, error)
+	UnwrapKey(wrappedKey []byte) ([]byte, error)
+}
+
+var keyManager KeyManager
+var keyManagerMutex sync.RWMutex
+
+func SetKeyManager(km KeyManager) {
+	keyManagerMutex.Lock()
+	defer keyManagerMutex.Unlock()
+	keyManager = km
+}
+
+func GetKeyManager() KeyManager {
+	keyManagerMutex.RLock()
+	km := keyManager
+	keyManagerMutex.RUnlock()
+	if km != nil {
+		return km
+	}
+	keyManagerMutex.Lock()
+	defer keyManagerMutex.Unlock()
+	if keyManager == nil {
+		keyManager = NewLocalKeyManager(config.GetInstance().GetSecretKey())
+	}
+	return keyManager
+}
+
+type LocalKeyManager struct {
+	masterKey []byte
+}
+
+func NewLocalKeyManager(secretKey string) *LocalKeyManager {
+	masterKey := sha256.Sum256([]byte(secretKey))
+	return &LocalKeyManager{
+		masterKey: masterKey[:],
+	}
+}
+
+func (km *LocalKeyManager) WrapKey(dataKey []byte) ([]byte, error) {
+	return sealWithKey(km.masterKey, dataKey)
+}
+
+func (km *LocalKeyManager) UnwrapKey(wrappedKey []byte) ([]byte, error) {
+	return openWithKey(km.masterKey, wrappedKey)
+}
+
+// sealWithKey encrypt plaintext by AES-GCM, the nonce is prepended to the ciphertext.
+func sealWithKey(key []byte, plaintext []byte) ([]byte, error) {
+	block, errInNewCipher := aes.NewCipher(key)
+	if errInNewCipher != nil {
+		return nil, errInNewCipher
+	}
+	gcm, errInNewGCM := cipher.NewGCM(block)
+	if errInNewGCM != nil {
+		return nil, errInNewGCM
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, errInReadNonce := io.ReadFull(rand.Reader, nonce); errInReadNonce != nil {
+		return nil, errInReadNonce
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func openWithKey(key []byte, sealed []byte) ([]byte, error) {
+	block, errInNewCipher := aes.NewCipher(key)
+	if errInNewCipher != nil {
+		return nil, errInNewCipher
+	}
+	gcm, errInNewGCM := cipher.NewGCM(block)
+	if errInNewGCM != nil {
+		return nil, errInNewGCM
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("invalid sealed data")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
@@ -27,7 +27,6 @@ func SignalCooperateAttach(hub *websocket.Hub, message *websocket.Message) error
 	}
 
 	// attach components
-	inRoomUsers := hub.GetInRoomUsersByRoomID(currentClient.APPID)
 	displayNames := make([]string, 0)
 	for _, displayNameInterface := range message.Payload {
 		displayName, assertCorrectly := displayNameInterface.(string)
@@ -36,7 +35,9 @@ func SignalCooperateAttach(hub *websocket.Hub, message *websocket.Message) error
 		}
 		displayNames = append(displayNames, displayName)
 	}
-	inRoomUsers.AttachComponent(currentClient.ExportMappedUserIDToString(), displayNames)
+	inRoomUsers := hub.UpdateInRoomUsers(currentClient.APPID, func(inRoomUsers *websocket.InRoomUsers) {
+		inRoomUsers.AttachComponent(currentClient.ExportMappedUserIDToString(), displayNames)
+	})
 
 	// broadcast attached components users
 	message.SetBroadcastType(websocket.BROADCAST_TYPE_ATTACH_COMPONENT)
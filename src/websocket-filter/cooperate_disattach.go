@@ -27,7 +27,6 @@ func SignalCooperateDisattach(hub *websocket.Hub, message *websocket.Message) er
 	}
 
 	// disattach components
-	inRoomUsers := hub.GetInRoomUsersByRoomID(currentClient.APPID)
 	displayNames := make([]string, 0)
 	for _, displayNameInterface := range message.Payload {
 		displayName, assertCorrectly := displayNameInterface.(string)
@@ -36,7 +35,9 @@ func SignalCooperateDisattach(hub *websocket.Hub, message *websocket.Message) er
 		}
 		displayNames = append(displayNames, displayName)
 	}
-	inRoomUsers.DisattachComponent(currentClient.ExportMappedUserIDToString(), displayNames)
+	inRoomUsers := hub.UpdateInRoomUsers(currentClient.APPID, func(inRoomUsers *websocket.InRoomUsers) {
+		inRoomUsers.DisattachComponent(currentClient.ExportMappedUserIDToString(), displayNames)
+	})
 
 	// broadcast attachedn components users
 	message.SetBroadcastType(websocket.BROADCAST_TYPE_ATTACH_COMPONENT)
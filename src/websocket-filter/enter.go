@@ -88,8 +88,9 @@ func SignalEnter(hub *websocket.Hub, message *websocket.Message) error {
 	}
 
 	// broadcast in room users
-	inRoomUsers := hub.GetInRoomUsersByRoomID(currentClient.APPID)
-	inRoomUsers.EnterRoom(user)
+	inRoomUsers := hub.UpdateInRoomUsers(currentClient.APPID, func(inRoomUsers *websocket.InRoomUsers) {
+		inRoomUsers.EnterRoom(user)
+	})
 	message.SetBroadcastPayload(inRoomUsers.FetchAllInRoomUsers())
 	message.RewriteBroadcast()
 	hub.BroadcastToRoomAllClients(message, currentClient)
@@ -51,6 +51,12 @@ func Run(hub *websocket.Hub) {
 			SignalFilter(hub, message)
 		case message := <-hub.OnBinaryMessage:
 			BinarySignalFilter(hub, message)
+		// handle messages from other websocket server replicas
+		case message := <-hub.OnClusterMessage:
+			hub.DeliverClusterMessage(message)
+		// refresh the room presence of this replica before it expired
+		case <-hub.ClusterHeartbeat:
+			hub.RefreshClusterInRoomUsers()
 		}
 
 	}
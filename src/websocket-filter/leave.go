@@ -27,8 +27,9 @@ func SignalLeave(hub *websocket.Hub, message *websocket.Message) error {
 	}
 
 	// broadcast in room users
-	inRoomUsers := hub.GetInRoomUsersByRoomID(currentClient.APPID)
-	inRoomUsers.LeaveRoom(currentClient.ExportMappedUserIDToString())
+	inRoomUsers := hub.UpdateInRoomUsers(currentClient.APPID, func(inRoomUsers *websocket.InRoomUsers) {
+		inRoomUsers.LeaveRoom(currentClient.ExportMappedUserIDToString())
+	})
 	message.SetBroadcastType(websocket.BROADCAST_TYPE_ENTER)
 	message.RewriteBroadcast()
 	message.SetBroadcastPayload(inRoomUsers.FetchAllInRoomUsers())
@@ -0,0 +1,184 @@
+// Copyright 2022 The ILLA Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	redis "github.com/redis/go-redis/v9"
+)
+
+const (
+	CLUSTER_MESSAGE_TYPE_TEXT   = 1
+	CLUSTER_MESSAGE_TYPE_BINARY = 2
+)
+
+const (
+	CLUSTER_BROADCAST_CHANNEL      = "illa_builder_websocket_broadcast"
+	CLUSTER_IN_ROOM_USERS_KEY      = "illa_builder_websocket_in_room_users:"
+	CLUSTER_IN_ROOM_REPLICAS_KEY   = "illa_builder_websocket_in_room_replicas:"
+	CLUSTER_IN_ROOM_USERS_TTL      = 30 * time.Second
+	CLUSTER_HEARTBEAT_INTERVAL     = 10 * time.Second
+	CLUSTER_RESUBSCRIBE_BACKOFF    = 3 * time.Second
+	CLUSTER_MESSAGE_CHANNEL_BUFFER = 1024
+)
+
+// ClusterMessage is the broadcast message delivered to the clients on all websocket server replicas.
+// the payload is the serialized feedback (or the raw binary message), and the other fields describe which clients receive it.
+type ClusterMessage struct {
+	InstanceID      string    `json:"instanceID"`
+	Type            int       `json:"type"`
+	TeamID          int       `json:"teamID"`
+	APPID           int       `json:"appID"`
+	FilterByTeamID  bool      `json:"filterByTeamID"`
+	FilterByAPPID   bool      `json:"filterByAPPID"`
+	ExcludeClientID uuid.UUID `json:"excludeClientID"`
+	Payload         []byte    `json:"payload"`
+}
+
+// Cluster fan-out the broadcast messages to other websocket server replicas by redis pub/sub,
+// and keep the room presence in redis, so collaborators connected to different replicas can see each other.
+// every replica owns the presence of its own clients, and refresh it by heartbeat before the short ttl expired.
+type Cluster struct {
+	InstanceID  string
+	RedisClient *redis.Client
+	degraded    atomic.Bool
+}
+
+func NewCluster(redisClient *redis.Client) *Cluster {
+	return &Cluster{
+		InstanceID:  uuid.New().String(),
+		RedisClient: redisClient,
+	}
+}
+
+func (cluster *Cluster) Publish(message *ClusterMessage) error {
+	message.InstanceID = cluster.InstanceID
+	messageInJSON, errInMarshal := json.Marshal(message)
+	if errInMarshal != nil {
+		return errInMarshal
+	}
+	return cluster.RedisClient.Publish(context.Background(), CLUSTER_BROADCAST_CHANNEL, messageInJSON).Err()
+}
+
+// Subscribe receive the messages published by other replicas and send them to the hub, it blocks forever.
+func (cluster *Cluster) Subscribe(hub *Hub) {
+	for {
+		pubsub := cluster.RedisClient.Subscribe(context.Background(), CLUSTER_BROADCAST_CHANNEL)
+		for redisMessage := range pubsub.Channel() {
+			message := &ClusterMessage{}
+			if errInUnmarshal := json.Unmarshal([]byte(redisMessage.Payload), message); errInUnmarshal != nil {
+				log.Printf("[Cluster] unmarshal cluster message failed: %s\n", errInUnmarshal.Error())
+				continue
+			}
+			// skip the messages published by self, they are already delivered
+			if message.InstanceID == cluster.InstanceID {
+				continue
+			}
+			hub.OnClusterMessage <- message
+		}
+		pubsub.Close()
+		log.Printf("[Cluster] subscription closed, resubscribe after %s\n", CLUSTER_RESUBSCRIBE_BACKOFF)
+		time.Sleep(CLUSTER_RESUBSCRIBE_BACKOFF)
+	}
+}
+
+func (cluster *Cluster) inRoomUsersKey(roomID int, instanceID string) string {
+	return CLUSTER_IN_ROOM_USERS_KEY + strconv.Itoa(roomID) + ":" + instanceID
+}
+
+func (cluster *Cluster) inRoomReplicasKey(roomID int) string {
+	return CLUSTER_IN_ROOM_REPLICAS_KEY + strconv.Itoa(roomID)
+}
+
+// SaveInRoomUsers store the room presence of this replica under its own key with a short ttl,
+// the key expires soon after the replica is gone, so the users connected to it leave the room with it.
+func (cluster *Cluster) SaveInRoomUsers(inRoomUsers *InRoomUsers) error {
+	ctx := context.Background()
+	key := cluster.inRoomUsersKey(inRoomUsers.RoomID, cluster.InstanceID)
+	replicasKey := cluster.inRoomReplicasKey(inRoomUsers.RoomID)
+	_, errInExec := cluster.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if inRoomUsers.Count() == 0 {
+			pipe.Del(ctx, key)
+			pipe.SRem(ctx, replicasKey, cluster.InstanceID)
+			return nil
+		}
+		pipe.Set(ctx, key, inRoomUsers.ExportSnapshot(), CLUSTER_IN_ROOM_USERS_TTL)
+		pipe.SAdd(ctx, replicasKey, cluster.InstanceID)
+		pipe.Expire(ctx, replicasKey, CLUSTER_IN_ROOM_USERS_TTL)
+		return nil
+	})
+	return errInExec
+}
+
+// LoadInRoomUsers merge the room presence of all alive replicas, the replicas whose key expired are removed from the room.
+func (cluster *Cluster) LoadInRoomUsers(roomID int) (*InRoomUsers, error) {
+	ctx := context.Background()
+	replicasKey := cluster.inRoomReplicasKey(roomID)
+	instanceIDs, errInMembers := cluster.RedisClient.SMembers(ctx, replicasKey).Result()
+	if errInMembers != nil {
+		return nil, errInMembers
+	}
+	inRoomUsers := NewInRoomUsers(roomID)
+	if len(instanceIDs) == 0 {
+		return inRoomUsers, nil
+	}
+	keys := make([]string, 0, len(instanceIDs))
+	for _, instanceID := range instanceIDs {
+		keys = append(keys, cluster.inRoomUsersKey(roomID, instanceID))
+	}
+	snapshots, errInGet := cluster.RedisClient.MGet(ctx, keys...).Result()
+	if errInGet != nil {
+		return nil, errInGet
+	}
+	for i, snapshot := range snapshots {
+		snapshotAsserted, assertPass := snapshot.(string)
+		if !assertPass {
+			// the replica stopped heartbeat, drop it from the room
+			cluster.RedisClient.SRem(ctx, replicasKey, instanceIDs[i])
+			continue
+		}
+		if errInMerge := inRoomUsers.MergeSnapshot([]byte(snapshotAsserted)); errInMerge != nil {
+			return nil, errInMerge
+		}
+	}
+	return inRoomUsers, nil
+}
+
+// MarkDegraded record the redis failure, the room presence only contains the users on this replica until redis recovered.
+func (cluster *Cluster) MarkDegraded(err error) {
+	if cluster.degraded.CompareAndSwap(false, true) {
+		log.Printf("[Cluster] redis unavailable, room presence degraded to this replica only: %s\n", err.Error())
+		return
+	}
+	log.Printf("[Cluster] redis still unavailable: %s\n", err.Error())
+}
+
+// MarkRecovered clear the degraded mark after the redis operation succeeded again.
+func (cluster *Cluster) MarkRecovered() {
+	if cluster.degraded.CompareAndSwap(true, false) {
+		log.Printf("[Cluster] redis recovered, room presence shared between replicas again\n")
+	}
+}
+
+func (cluster *Cluster) IsDegraded() bool {
+	return cluster.degraded.Load()
+}
@@ -1,6 +1,8 @@
 package websocket
 
 import (
+	"encoding/json"
+
 	"github.com/illacloud/builder-backend/src/model"
 )
 
@@ -15,6 +17,7 @@ type InRoomUsers struct {
 
 func NewInRoomUsers(roomID int) *InRoomUsers {
 	iru := &InRoomUsers{}
+	iru.RoomID = roomID
 	iru.All = make([]*UserForCooperateFeedback, DEFAULT_ROOM_SLOT)
 	iru.AllUsers = make(map[string]*UserForCooperateFeedback)
 	iru.AttachedUserList = make(map[string][]*UserForCooperateFeedback)
@@ -90,6 +93,63 @@ func (iru *InRoomUsers) DisattachComponent(userID string, componentDisplayNames
 	}
 }
 
+// inRoomUserSnapshot is the serialized in room user, for sharing the room presence between websocket server replicas.
+type inRoomUserSnapshot struct {
+	ID                 string   `json:"id"`
+	Nickname           string   `json:"nickname"`
+	Avatar             string   `json:"avatar"`
+	AttachedComponents []string `json:"attachedComponents"`
+}
+
+func NewInRoomUsersBySnapshot(roomID int, snapshot []byte) (*InRoomUsers, error) {
+	iru := NewInRoomUsers(roomID)
+	if errInMerge := iru.MergeSnapshot(snapshot); errInMerge != nil {
+		return nil, errInMerge
+	}
+	return iru, nil
+}
+
+// MergeSnapshot add the users in snapshot to the room, the user already in room keeps one entry with attached components merged.
+func (iru *InRoomUsers) MergeSnapshot(snapshot []byte) error {
+	userSnapshots := make([]*inRoomUserSnapshot, 0)
+	if errInUnmarshal := json.Unmarshal(snapshot, &userSnapshots); errInUnmarshal != nil {
+		return errInUnmarshal
+	}
+	for _, userSnapshot := range userSnapshots {
+		fuser, hit := iru.AllUsers[userSnapshot.ID]
+		if !hit {
+			fuser = &UserForCooperateFeedback{
+				ID:                 userSnapshot.ID,
+				Nickname:           userSnapshot.Nickname,
+				Avatar:             userSnapshot.Avatar,
+				AttachedComponents: make(map[string]string),
+			}
+			iru.All = append(iru.All, fuser)
+			iru.AllUsers[fuser.ID] = fuser
+		}
+		iru.AttachComponent(fuser.ID, userSnapshot.AttachedComponents)
+	}
+	return nil
+}
+
+func (iru *InRoomUsers) ExportSnapshot() []byte {
+	userSnapshots := make([]*inRoomUserSnapshot, 0, len(iru.All))
+	for _, fuser := range iru.All {
+		attachedComponents := make([]string, 0, len(fuser.AttachedComponents))
+		for _, displayName := range fuser.AttachedComponents {
+			attachedComponents = append(attachedComponents, displayName)
+		}
+		userSnapshots = append(userSnapshots, &inRoomUserSnapshot{
+			ID:                 fuser.ID,
+			Nickname:           fuser.Nickname,
+			Avatar:             fuser.Avatar,
+			AttachedComponents: attachedComponents,
+		})
+	}
+	snapshot, _ := json.Marshal(userSnapshots)
+	return snapshot
+}
+
 type InRoomUsersFeedback struct {
 	InRoomUsers []*UserForCooperateFeedback `json:"inRoomUsers"`
 }
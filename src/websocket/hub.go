@@ -16,6 +16,8 @@ package websocket
 
 import (
 	"errors"
+	"log"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/illacloud/builder-backend/src/storage"
@@ -37,6 +39,9 @@ type Hub struct {
 
 	OnBinaryMessage chan []byte
 
+	// messages from other websocket server replicas
+	OnClusterMessage chan *ClusterMessage
+
 	// register requests from the clients.
 	Register       chan *Client
 	RegisterBinary chan *Client
@@ -47,6 +52,12 @@ type Hub struct {
 	// InRoomUsers
 	InRoomUsersMap map[int]*InRoomUsers // map[roomID]*InRoomUsers
 
+	// cluster fan-out, nil when running as single instance
+	Cluster *Cluster
+
+	// ticks to refresh the room presence of this replica in cluster, nil when running as single instance
+	ClusterHeartbeat <-chan time.Time
+
 	// sotrage
 	Storage *storage.Storage
 
@@ -56,20 +67,32 @@ type Hub struct {
 
 func NewHub(s *storage.Storage, attrg *accesscontrol.AttributeGroup) *Hub {
 	return &Hub{
-		Clients:         make(map[uuid.UUID]*Client),
-		BinaryClients:   make(map[uuid.UUID]*Client),
-		Broadcast:       make(chan []byte),
-		OnTextMessage:   make(chan *Message),
-		OnBinaryMessage: make(chan []byte),
-		Register:        make(chan *Client),
-		RegisterBinary:  make(chan *Client),
-		Unregister:      make(chan *Client),
-		InRoomUsersMap:  make(map[int]*InRoomUsers),
-		Storage:         s,
-		AttributeGroup:  attrg,
+		Clients:          make(map[uuid.UUID]*Client),
+		BinaryClients:    make(map[uuid.UUID]*Client),
+		Broadcast:        make(chan []byte),
+		OnTextMessage:    make(chan *Message),
+		OnBinaryMessage:  make(chan []byte),
+		OnClusterMessage: make(chan *ClusterMessage, CLUSTER_MESSAGE_CHANNEL_BUFFER),
+		Register:         make(chan *Client),
+		RegisterBinary:   make(chan *Client),
+		Unregister:       make(chan *Client),
+		InRoomUsersMap:   make(map[int]*InRoomUsers),
+		Storage:          s,
+		AttributeGroup:   attrg,
 	}
 }
 
+// EnableCluster let the hub fan-out broadcast messages and share room presence with other replicas.
+func (hub *Hub) EnableCluster(cluster *Cluster) {
+	hub.Cluster = cluster
+	hub.ClusterHeartbeat = time.NewTicker(CLUSTER_HEARTBEAT_INTERVAL).C
+	go cluster.Subscribe(hub)
+}
+
+func (hub *Hub) IsClusterEnabled() bool {
+	return hub.Cluster != nil
+}
+
 func (hub *Hub) GetInRoomUsersByRoomID(roomID int) *InRoomUsers {
 	inRoomUsers, hit := hub.InRoomUsersMap[roomID]
 	if !hit {
@@ -79,6 +102,44 @@ func (hub *Hub) GetInRoomUsersByRoomID(roomID int) *InRoomUsers {
 	return inRoomUsers
 }
 
+// UpdateInRoomUsers apply the update to room presence of this replica and return the updated presence.
+// when cluster enabled, the returned presence is merged with the other replicas in redis.
+func (hub *Hub) UpdateInRoomUsers(roomID int, update func(inRoomUsers *InRoomUsers)) *InRoomUsers {
+	inRoomUsers := hub.GetInRoomUsersByRoomID(roomID)
+	update(inRoomUsers)
+	if !hub.IsClusterEnabled() {
+		return inRoomUsers
+	}
+	if errInSave := hub.Cluster.SaveInRoomUsers(inRoomUsers); errInSave != nil {
+		hub.Cluster.MarkDegraded(errInSave)
+		return inRoomUsers
+	}
+	clusterInRoomUsers, errInLoad := hub.Cluster.LoadInRoomUsers(roomID)
+	if errInLoad != nil {
+		hub.Cluster.MarkDegraded(errInLoad)
+		return inRoomUsers
+	}
+	hub.Cluster.MarkRecovered()
+	return clusterInRoomUsers
+}
+
+// RefreshClusterInRoomUsers re-save the room presence of this replica, so it will not expire while the users still in room.
+func (hub *Hub) RefreshClusterInRoomUsers() {
+	if !hub.IsClusterEnabled() {
+		return
+	}
+	for _, inRoomUsers := range hub.InRoomUsersMap {
+		if inRoomUsers.Count() == 0 {
+			continue
+		}
+		if errInSave := hub.Cluster.SaveInRoomUsers(inRoomUsers); errInSave != nil {
+			hub.Cluster.MarkDegraded(errInSave)
+			return
+		}
+	}
+	hub.Cluster.MarkRecovered()
+}
+
 func (hub *Hub) GetClientByID(clientID uuid.UUID) (*Client, error) {
 	currentClient, hit := hub.Clients[clientID]
 	if !hit {
@@ -89,7 +150,7 @@ func (hub *Hub) GetClientByID(clientID uuid.UUID) (*Client, error) {
 
 func (hub *Hub) CleanRoom(roomID int) {
 	inRoomUsers, hit := hub.InRoomUsersMap[roomID]
-	if inRoomUsers.Count() != 0 || !hit {
+	if !hit || inRoomUsers.Count() != 0 {
 		return
 	}
 	delete(hub.InRoomUsersMap, roomID)
@@ -101,53 +162,74 @@ func (hub *Hub) RemoveClient(client *Client) {
 	delete(hub.BinaryClients, client.GetID())
 }
 
-func (hub *Hub) BroadcastToOtherClients(message *Message, currentClient *Client) {
-	if !message.NeedBroadcast {
+// broadcast deliver message to the local clients, and publish it to other replicas when cluster enabled.
+func (hub *Hub) broadcast(message *ClusterMessage) {
+	hub.DeliverClusterMessage(message)
+	if !hub.IsClusterEnabled() {
 		return
 	}
-	feedOtherClient := Feedback{
-		ErrorCode:    ERROR_CODE_BROADCAST,
-		ErrorMessage: "",
-		Broadcast:    message.Broadcast,
-		Data:         nil,
+	if errInPublish := hub.Cluster.Publish(message); errInPublish != nil {
+		log.Printf("[broadcast] publish cluster message failed: %s\n", errInPublish.Error())
 	}
-	feedbyte, _ := feedOtherClient.Serialization()
+}
 
-	for clientid, client := range hub.Clients {
+// DeliverClusterMessage send the message to the local clients which match the message filter.
+func (hub *Hub) DeliverClusterMessage(message *ClusterMessage) {
+	clients := hub.Clients
+	if message.Type == CLUSTER_MESSAGE_TYPE_BINARY {
+		clients = hub.BinaryClients
+	}
+	for clientid, client := range clients {
 		if client.IsDead() {
 			hub.RemoveClient(client)
 			continue
 		}
-		if clientid == currentClient.ID {
+		if clientid == message.ExcludeClientID {
 			continue
 		}
-		if client.TeamID != currentClient.TeamID {
+		if message.FilterByTeamID && client.TeamID != message.TeamID {
 			continue
 		}
-		if client.APPID != currentClient.APPID {
+		if message.FilterByAPPID && client.APPID != message.APPID {
 			continue
 		}
-		client.Send <- feedbyte
+		client.Send <- message.Payload
 	}
 }
 
-func (hub *Hub) BroadcastBinaryToOtherClients(message []byte, currentClient *Client) {
-	for clientid, client := range hub.BinaryClients {
-		if client.IsDead() {
-			hub.RemoveClient(client)
-			continue
-		}
-		if clientid == currentClient.ID {
-			continue
-		}
-		if client.TeamID != currentClient.TeamID {
-			continue
-		}
-		if client.APPID != currentClient.APPID {
-			continue
-		}
-		client.Send <- message
+func (hub *Hub) BroadcastToOtherClients(message *Message, currentClient *Client) {
+	if !message.NeedBroadcast {
+		return
 	}
+	feedOtherClient := Feedback{
+		ErrorCode:    ERROR_CODE_BROADCAST,
+		ErrorMessage: "",
+		Broadcast:    message.Broadcast,
+		Data:         nil,
+	}
+	feedbyte, _ := feedOtherClient.Serialization()
+
+	hub.broadcast(&ClusterMessage{
+		Type:            CLUSTER_MESSAGE_TYPE_TEXT,
+		TeamID:          currentClient.TeamID,
+		APPID:           currentClient.APPID,
+		FilterByTeamID:  true,
+		FilterByAPPID:   true,
+		ExcludeClientID: currentClient.ID,
+		Payload:         feedbyte,
+	})
+}
+
+func (hub *Hub) BroadcastBinaryToOtherClients(message []byte, currentClient *Client) {
+	hub.broadcast(&ClusterMessage{
+		Type:            CLUSTER_MESSAGE_TYPE_BINARY,
+		TeamID:          currentClient.TeamID,
+		APPID:           currentClient.APPID,
+		FilterByTeamID:  true,
+		FilterByAPPID:   true,
+		ExcludeClientID: currentClient.ID,
+		Payload:         message,
+	})
 }
 
 func (hub *Hub) BroadcastToRoomAllClients(message *Message, currentClient *Client) {
@@ -162,19 +244,14 @@ func (hub *Hub) BroadcastToRoomAllClients(message *Message, currentClient *Clien
 	}
 	feedbyte, _ := feedOtherClient.Serialization()
 
-	for _, client := range hub.Clients {
-		if client.IsDead() {
-			hub.RemoveClient(client)
-			continue
-		}
-		if client.TeamID != currentClient.TeamID {
-			continue
-		}
-		if client.APPID != currentClient.APPID {
-			continue
-		}
-		client.Send <- feedbyte
-	}
+	hub.broadcast(&ClusterMessage{
+		Type:           CLUSTER_MESSAGE_TYPE_TEXT,
+		TeamID:         currentClient.TeamID,
+		APPID:          currentClient.APPID,
+		FilterByTeamID: true,
+		FilterByAPPID:  true,
+		Payload:        feedbyte,
+	})
 }
 
 func (hub *Hub) SendFeedbackToTargetRoomAllClients(errorCode int, message *Message, teamID int, appID int) {
@@ -189,19 +266,14 @@ func (hub *Hub) SendFeedbackToTargetRoomAllClients(errorCode int, message *Messa
 	}
 	feedbyte, _ := feedOtherClient.Serialization()
 
-	for _, client := range hub.Clients {
-		if client.IsDead() {
-			hub.RemoveClient(client)
-			continue
-		}
-		if client.TeamID != teamID {
-			continue
-		}
-		if client.APPID != appID {
-			continue
-		}
-		client.Send <- feedbyte
-	}
+	hub.broadcast(&ClusterMessage{
+		Type:           CLUSTER_MESSAGE_TYPE_TEXT,
+		TeamID:         teamID,
+		APPID:          appID,
+		FilterByTeamID: true,
+		FilterByAPPID:  true,
+		Payload:        feedbyte,
+	})
 }
 
 func (hub *Hub) BroadcastToTeamAllClients(message *Message, currentClient *Client, includeCurrentClient bool) {
@@ -212,19 +284,16 @@ func (hub *Hub) BroadcastToTeamAllClients(message *Message, currentClient *Clien
 		Data:         nil,
 	}
 	feedbyte, _ := feed.Serialization()
-	for clientid, client := range hub.Clients {
-		if client.IsDead() {
-			hub.RemoveClient(client)
-			continue
-		}
-		if client.TeamID != currentClient.TeamID {
-			continue
-		}
-		if clientid == currentClient.ID && !includeCurrentClient {
-			continue
-		}
-		client.Send <- feedbyte
+	clusterMessage := &ClusterMessage{
+		Type:           CLUSTER_MESSAGE_TYPE_TEXT,
+		TeamID:         currentClient.TeamID,
+		FilterByTeamID: true,
+		Payload:        feedbyte,
 	}
+	if !includeCurrentClient {
+		clusterMessage.ExcludeClientID = currentClient.ID
+	}
+	hub.broadcast(clusterMessage)
 }
 
 // WARRING: This method will broadcast to server all clients. Use it carefully.
@@ -236,16 +305,14 @@ func (hub *Hub) BroadcastToGlobal(message *Message, currentClient *Client, inclu
 		Data:         nil,
 	}
 	feedbyte, _ := feed.Serialization()
-	for clientid, client := range hub.Clients {
-		if client.IsDead() {
-			hub.RemoveClient(client)
-			continue
-		}
-		if clientid == currentClient.ID && !includeCurrentClient {
-			continue
-		}
-		client.Send <- feedbyte
+	clusterMessage := &ClusterMessage{
+		Type:    CLUSTER_MESSAGE_TYPE_TEXT,
+		Payload: feedbyte,
+	}
+	if !includeCurrentClient {
+		clusterMessage.ExcludeClientID = currentClient.ID
 	}
+	hub.broadcast(clusterMessage)
 }
 
 func (hub *Hub) KickClient(client *Client) {
@@ -37,6 +37,7 @@ const HTTP_SERVER_CLIENT_ID = "a97bce38-703b-4f03-b59b-111a00000001"
 const BROADCAST_TYPE_SUFFIX = "/remote"
 const BROADCAST_TYPE_ENTER = "enter"
 const BROADCAST_TYPE_ATTACH_COMPONENT = "attachComponent"
+const BROADCAST_TYPE_SCHEDULED_ACTION_RESULT = "scheduledActionResult"
 
 type Broadcast struct {
 	Type    string      `json:"type"`